@@ -3,20 +3,35 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"expvar"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/vladimish/talk/db/generated"
 	"github.com/vladimish/talk/internal/adapter/in/tg"
+	"github.com/vladimish/talk/internal/adapter/out/anthropic"
+	"github.com/vladimish/talk/internal/adapter/out/catalog"
+	"github.com/vladimish/talk/internal/adapter/out/document"
 	minioAdapter "github.com/vladimish/talk/internal/adapter/out/minio"
 	"github.com/vladimish/talk/internal/adapter/out/openai"
 	pgAdapter "github.com/vladimish/talk/internal/adapter/out/pg"
 	redisAdapter "github.com/vladimish/talk/internal/adapter/out/redis"
+	"github.com/vladimish/talk/internal/adapter/out/router"
 	"github.com/vladimish/talk/internal/adapter/out/telegramify"
 	tgAdapter "github.com/vladimish/talk/internal/adapter/out/tg"
+	"github.com/vladimish/talk/internal/adapter/out/tts"
+	"github.com/vladimish/talk/internal/adapter/out/whisper"
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/filestorage"
 	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/pkg/resilience"
 	"github.com/vladimish/talk/pkg/slogctx"
 
 	"github.com/go-telegram/bot"
@@ -26,6 +41,10 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
+// defaultSpeechRate is what spoken replies cost in regular tokens per million characters:
+// $15 per million characters of tts-1 at 200 regular tokens per dollar.
+const defaultSpeechRate = 3000
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -44,13 +63,31 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = runMigrations(ctx, log, pg.DB); err != nil {
+	// Initialize MinIO/S3 storage
+	minioConfig := minioAdapter.Config{
+		Endpoint:        getEnvOrDefault("MINIO_ENDPOINT", "localhost:9000"),
+		AccessKeyID:     getEnvOrDefault("MINIO_ACCESS_KEY", "minioadmin"),
+		SecretAccessKey: getEnvOrDefault("MINIO_SECRET_KEY", "minioadmin"),
+		UseSSL:          getEnvOrDefault("MINIO_USE_SSL", "false") == "true",
+		BucketName:      getEnvOrDefault("MINIO_BUCKET", "telegram-images"),
+		PublicDomain:    getEnvOrDefault("MINIO_PUBLIC_DOMAIN", "s3.vladimish.com"),
+	}
+
+	// For now, continue without file storage if it's unavailable (files won't work)
+	var fileStorage filestorage.FileStorage
+	if minioStorage, minioErr := minioAdapter.NewFileStorage(minioConfig); minioErr != nil {
+		log.Error("failed to initialize MinIO storage", "error", minioErr)
+	} else {
+		fileStorage = minioStorage
+	}
+
+	if err = runMigrations(ctx, log, pg.DB, fileStorage); err != nil {
 		log.Error("failed to run migrations", "error", err)
 		os.Exit(1)
 	}
 
 	queries := generated.New(pg)
-	store := pgAdapter.NewPg(queries)
+	store := pgAdapter.NewPg(pg.DB, queries)
 
 	tgToken := os.Getenv("TG_TOKEN")
 	b, err := bot.New(tgToken)
@@ -58,21 +95,27 @@ func main() {
 		panic(err)
 	}
 
-	// Initialize MinIO/S3 storage
-	minioConfig := minioAdapter.Config{
-		Endpoint:        getEnvOrDefault("MINIO_ENDPOINT", "localhost:9000"),
-		AccessKeyID:     getEnvOrDefault("MINIO_ACCESS_KEY", "minioadmin"),
-		SecretAccessKey: getEnvOrDefault("MINIO_SECRET_KEY", "minioadmin"),
-		UseSSL:          getEnvOrDefault("MINIO_USE_SSL", "false") == "true",
-		BucketName:      getEnvOrDefault("MINIO_BUCKET", "telegram-images"),
-		PublicDomain:    getEnvOrDefault("MINIO_PUBLIC_DOMAIN", "s3.vladimish.com"),
-	}
+	// Outbound HTTP calls share the timeout, retry and circuit breaker settings
+	httpConfig := resilience.DefaultConfig()
+	httpConfig.Timeout = getEnvDurationOrDefault(log, "HTTP_TIMEOUT", httpConfig.Timeout)
+	httpConfig.MaxRetries = getEnvIntOrDefault(log, "HTTP_MAX_RETRIES", httpConfig.MaxRetries)
+	httpConfig.FailureThreshold = getEnvIntOrDefault(log, "HTTP_BREAKER_THRESHOLD", httpConfig.FailureThreshold)
+	httpConfig.OpenTimeout = getEnvDurationOrDefault(log, "HTTP_BREAKER_COOLDOWN", httpConfig.OpenTimeout)
+	// Completions stream for minutes and aren't retried, the fallback models answer instead
+	completionConfig := httpConfig
+	completionConfig.Timeout = 0
+	completionConfig.HeaderTimeout = getEnvDurationOrDefault(log, "COMPLETION_HEADER_TIMEOUT", 2*time.Minute)
+	completionConfig.MaxRetries = 0
+	// Formatting has no side effects, its requests are safe to repeat
+	formatterConfig := httpConfig
+	formatterConfig.RetryPost = true
+	// Transcriptions have no side effects either, long voice messages take a while
+	transcriptionConfig := httpConfig
+	transcriptionConfig.RetryPost = true
+	transcriptionConfig.Timeout = getEnvDurationOrDefault(log, "TRANSCRIPTION_TIMEOUT", 2*time.Minute)
 
-	fileStorage, err := minioAdapter.NewFileStorage(minioConfig)
-	if err != nil {
-		log.Error("failed to initialize MinIO storage", "error", err)
-		// For now, continue without file storage (images won't work)
-		fileStorage = nil
+	if metricsAddr := os.Getenv("METRICS_ADDR"); metricsAddr != "" {
+		go serveMetrics(ctx, log, metricsAddr)
 	}
 
 	openAIKey := os.Getenv("OPENAI_API_KEY")
@@ -80,14 +123,41 @@ func main() {
 		log.Error("OPENAI_API_KEY environment variable is required")
 		os.Exit(1)
 	}
-	completion := openai.NewOpenAICompletion(openAIKey)
+	// OpenRouter serves every model whose provider isn't configured
+	providers := map[string]completion.Completion{}
+	if key := os.Getenv("OPENAI_PLATFORM_API_KEY"); key != "" {
+		providers[domain.ProviderOpenAI] = openai.NewCompatibleCompletion(
+			domain.ProviderOpenAI,
+			getEnvOrDefault("OPENAI_PLATFORM_URL", "https://api.openai.com/v1"),
+			key,
+			resilience.NewClient(domain.ProviderOpenAI, log, completionConfig),
+		)
+	}
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		providers[domain.ProviderAnthropic] = anthropic.NewAnthropicCompletion(
+			key, resilience.NewClient(domain.ProviderAnthropic, log, completionConfig),
+		)
+	}
+	if url := os.Getenv("LOCAL_LLM_URL"); url != "" {
+		providers[domain.ProviderLocal] = openai.NewCompatibleCompletion(
+			domain.ProviderLocal,
+			url,
+			os.Getenv("LOCAL_LLM_API_KEY"),
+			resilience.NewClient(domain.ProviderLocal, log, completionConfig),
+		)
+	}
+	completionRouter := router.NewRouter(
+		log,
+		openai.NewOpenAICompletion(openAIKey, resilience.NewClient(domain.ProviderOpenRouter, log, completionConfig)),
+		providers,
+	)
 
 	telegramifyURL := os.Getenv("TELEGRAMIFY_URL")
 	if telegramifyURL == "" {
 		telegramifyURL = "http://localhost:8000"
 		log.Warn("TELEGRAMIFY_URL not set, using default", "url", telegramifyURL)
 	}
-	formatter := telegramify.New(telegramifyURL)
+	formatter := telegramify.New(telegramifyURL, resilience.NewClient("telegramify", log, formatterConfig))
 
 	// Initialize Redis queue
 	redisURL := os.Getenv("REDIS_URL")
@@ -108,8 +178,54 @@ func main() {
 	}()
 
 	sender := tgAdapter.NewSender(b, formatter, log)
-	updateService := service.NewUpdateService(log, store, sender, completion, redisQueue, fileStorage)
-	botAdapter := tg.NewBot(log, updateService, b, tgToken)
+	updateService := service.NewUpdateService(log, store, sender, completionRouter, redisQueue, fileStorage)
+	// Image models are served by the providers of the other models
+	updateService.SetImageGeneration(completionRouter)
+	updateService.SetHistoryFileLimit(
+		getEnvIntOrDefault(log, "HISTORY_FILE_LIMIT", service.DefaultHistoryFileLimit),
+	)
+	updateService.SetDocumentExtraction(document.New(
+		getEnvIntOrDefault(log, "DOCUMENT_MAX_CHARACTERS", document.DefaultMaxCharacters),
+	))
+	if url := os.Getenv("TRANSCRIPTION_URL"); url != "" {
+		updateService.SetTranscription(whisper.New(
+			url,
+			os.Getenv("TRANSCRIPTION_API_KEY"),
+			getEnvOrDefault("TRANSCRIPTION_MODEL", whisper.DefaultModel),
+			resilience.NewClient("transcription", log, transcriptionConfig),
+		))
+	}
+	if url := os.Getenv("SPEECH_URL"); url != "" {
+		speechModel := getEnvOrDefault("SPEECH_MODEL", tts.DefaultModel)
+		// Synthesis has no side effects and takes as long as transcriptions
+		updateService.SetSpeech(tts.New(
+			url,
+			os.Getenv("SPEECH_API_KEY"),
+			speechModel,
+			getEnvOrDefault("SPEECH_VOICE", tts.DefaultVoice),
+			resilience.NewClient("speech", log, transcriptionConfig),
+		), domain.SpeechPrice{
+			Model:     speechModel,
+			TokenType: domain.TokenTypeRegular,
+			Rate:      int64(getEnvIntOrDefault(log, "SPEECH_RATE", defaultSpeechRate)),
+		})
+	}
+	botAdapter := tg.NewBot(
+		log, updateService, b, tgToken, resilience.NewClient("telegram-files", log, httpConfig),
+	)
+
+	// Load the model catalog and move users of retired models to their replacements
+	catalogLoader := catalog.NewLoader(log, catalog.Config{
+		Path:     os.Getenv("MODEL_CATALOG_PATH"),
+		Sync:     getEnvOrDefault("MODEL_CATALOG_SYNC", "false") == "true",
+		APIKey:   openAIKey,
+		Interval: getEnvDurationOrDefault(log, "MODEL_CATALOG_RELOAD_INTERVAL", time.Minute),
+	}, resilience.NewClient("openrouter-models", log, httpConfig), updateService.MigrateRetiredModels)
+	if err = catalogLoader.Load(ctx); err != nil {
+		log.Error("failed to load model catalog", "error", err)
+		os.Exit(1)
+	}
+	go catalogLoader.Run(ctx)
 
 	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.Message != nil && update.Message.SuccessfulPayment != nil
@@ -118,6 +234,10 @@ func main() {
 	b.RegisterHandler(bot.HandlerTypeMessageText, "", bot.MatchTypeContains, botAdapter.Handle)
 	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "", bot.MatchTypePrefix, botAdapter.HandleCallback)
 
+	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		return update.EditedMessage != nil
+	}, botAdapter.HandleEditedMessage)
+
 	// Use a general update handler for payment events
 	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.PreCheckoutQuery != nil
@@ -129,14 +249,23 @@ func main() {
 	log.Info("shutting down")
 }
 
-func runMigrations(ctx context.Context, log *slog.Logger, db *sql.DB) error {
+func runMigrations(ctx context.Context, log *slog.Logger, db *sql.DB, fileStorage filestorage.FileStorage) error {
 	log.InfoContext(ctx, "running database migrations")
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return err
 	}
 
-	if err := goose.Up(db, "db/migrations"); err != nil {
+	// Moving the files of messages to the file storage needs the storage, so it's registered here. Without
+	// the storage the schema migrations still run, and the move is applied out of order on a later start.
+	if fileStorage != nil {
+		up, down := pgAdapter.MoveMessageFileData(fileStorage)
+		goose.AddNamedMigrationNoTxContext(pgAdapter.MessageFilesMigration, up, down)
+	} else {
+		log.WarnContext(ctx, "file storage is unavailable, message files are moved to it on a later start")
+	}
+
+	if err := goose.Up(db, "db/migrations", goose.WithAllowMissing()); err != nil {
 		return err
 	}
 
@@ -151,3 +280,54 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDurationOrDefault returns the duration in an environment variable or a default value if not set.
+// It exits when the value isn't a valid duration.
+func getEnvDurationOrDefault(log *slog.Logger, key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Error("invalid duration in environment variable", "key", key, "error", err)
+		os.Exit(1)
+	}
+	return duration
+}
+
+// getEnvIntOrDefault returns the number in an environment variable or a default value if not set.
+// It exits when the value isn't a valid number.
+func getEnvIntOrDefault(log *slog.Logger, key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Error("invalid number in environment variable", "key", key, "error", err)
+		os.Exit(1)
+	}
+	return number
+}
+
+// serveMetrics serves the expvar metrics, the circuit breaker states among them, at /debug/vars.
+func serveMetrics(ctx context.Context, log *slog.Logger, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second, //nolint:mnd // timeout
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	log.InfoContext(ctx, "serving metrics", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.ErrorContext(ctx, "metrics server failed", "error", err)
+	}
+}
@@ -41,3 +41,39 @@ func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentPara
 	)
 	return i, err
 }
+
+const getAttachmentsByMessageID = `-- name: GetAttachmentsByMessageID :many
+SELECT id, message_id, s3_name, content_type, size, created_at FROM attachments
+WHERE message_id = $1
+ORDER BY id ASC
+`
+
+func (q *Queries) GetAttachmentsByMessageID(ctx context.Context, messageID int64) ([]Attachment, error) {
+	rows, err := q.db.QueryContext(ctx, getAttachmentsByMessageID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessageID,
+			&i.S3Name,
+			&i.ContentType,
+			&i.Size,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,20 +7,23 @@ package generated
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
 const createConversation = `-- name: CreateConversation :one
-INSERT INTO conversations (name, user_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, name, user_id, created_at, updated_at
+INSERT INTO conversations (name, user_id, created_at, updated_at, system_prompt, parent_conversation_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, user_id, created_at, updated_at, system_prompt, parent_conversation_id, pinned_at, archived_at, deleted_at
 `
 
 type CreateConversationParams struct {
-	Name      string
-	UserID    int64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Name                 string
+	UserID               int64
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	SystemPrompt         sql.NullString
+	ParentConversationID sql.NullInt64
 }
 
 func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error) {
@@ -29,6 +32,8 @@ func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversation
 		arg.UserID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.SystemPrompt,
+		arg.ParentConversationID,
 	)
 	var i Conversation
 	err := row.Scan(
@@ -37,12 +42,57 @@ func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversation
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SystemPrompt,
+		&i.ParentConversationID,
+		&i.PinnedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const getArchivedConversationsByUserID = `-- name: GetArchivedConversationsByUserID :many
+SELECT id, name, user_id, created_at, updated_at, system_prompt, parent_conversation_id, pinned_at, archived_at, deleted_at FROM conversations
+WHERE user_id = $1 AND archived_at IS NOT NULL AND deleted_at IS NULL
+ORDER BY archived_at DESC
+`
+
+func (q *Queries) GetArchivedConversationsByUserID(ctx context.Context, userID int64) ([]Conversation, error) {
+	rows, err := q.db.QueryContext(ctx, getArchivedConversationsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Conversation
+	for rows.Next() {
+		var i Conversation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SystemPrompt,
+			&i.ParentConversationID,
+			&i.PinnedAt,
+			&i.ArchivedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getConversationByID = `-- name: GetConversationByID :one
-SELECT id, name, user_id, created_at, updated_at FROM conversations
+SELECT id, name, user_id, created_at, updated_at, system_prompt, parent_conversation_id, pinned_at, archived_at, deleted_at FROM conversations
 WHERE id = $1
 LIMIT 1
 `
@@ -56,14 +106,19 @@ func (q *Queries) GetConversationByID(ctx context.Context, id int64) (Conversati
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SystemPrompt,
+		&i.ParentConversationID,
+		&i.PinnedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getConversationsByUserID = `-- name: GetConversationsByUserID :many
-SELECT id, name, user_id, created_at, updated_at FROM conversations
-WHERE user_id = $1
-ORDER BY updated_at DESC
+SELECT id, name, user_id, created_at, updated_at, system_prompt, parent_conversation_id, pinned_at, archived_at, deleted_at FROM conversations
+WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL
+ORDER BY pinned_at IS NULL, updated_at DESC
 `
 
 func (q *Queries) GetConversationsByUserID(ctx context.Context, userID int64) ([]Conversation, error) {
@@ -81,6 +136,11 @@ func (q *Queries) GetConversationsByUserID(ctx context.Context, userID int64) ([
 			&i.UserID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SystemPrompt,
+			&i.ParentConversationID,
+			&i.PinnedAt,
+			&i.ArchivedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -95,6 +155,44 @@ func (q *Queries) GetConversationsByUserID(ctx context.Context, userID int64) ([
 	return items, nil
 }
 
+const restoreConversation = `-- name: RestoreConversation :exec
+UPDATE conversations
+SET deleted_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) RestoreConversation(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, restoreConversation, id)
+	return err
+}
+
+const softDeleteConversation = `-- name: SoftDeleteConversation :exec
+UPDATE conversations
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteConversation(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteConversation, id)
+	return err
+}
+
+const updateConversationArchived = `-- name: UpdateConversationArchived :exec
+UPDATE conversations
+SET archived_at = CASE WHEN $1::bool THEN NOW() END
+WHERE id = $2
+`
+
+type UpdateConversationArchivedParams struct {
+	Archived bool
+	ID       int64
+}
+
+func (q *Queries) UpdateConversationArchived(ctx context.Context, arg UpdateConversationArchivedParams) error {
+	_, err := q.db.ExecContext(ctx, updateConversationArchived, arg.Archived, arg.ID)
+	return err
+}
+
 const updateConversationName = `-- name: UpdateConversationName :exec
 UPDATE conversations
 SET name = $2, updated_at = NOW()
@@ -111,6 +209,38 @@ func (q *Queries) UpdateConversationName(ctx context.Context, arg UpdateConversa
 	return err
 }
 
+const updateConversationPinned = `-- name: UpdateConversationPinned :exec
+UPDATE conversations
+SET pinned_at = CASE WHEN $1::bool THEN NOW() END
+WHERE id = $2
+`
+
+type UpdateConversationPinnedParams struct {
+	Pinned bool
+	ID     int64
+}
+
+func (q *Queries) UpdateConversationPinned(ctx context.Context, arg UpdateConversationPinnedParams) error {
+	_, err := q.db.ExecContext(ctx, updateConversationPinned, arg.Pinned, arg.ID)
+	return err
+}
+
+const updateConversationSystemPrompt = `-- name: UpdateConversationSystemPrompt :exec
+UPDATE conversations
+SET system_prompt = $2
+WHERE id = $1
+`
+
+type UpdateConversationSystemPromptParams struct {
+	ID           int64
+	SystemPrompt sql.NullString
+}
+
+func (q *Queries) UpdateConversationSystemPrompt(ctx context.Context, arg UpdateConversationSystemPromptParams) error {
+	_, err := q.db.ExecContext(ctx, updateConversationSystemPrompt, arg.ID, arg.SystemPrompt)
+	return err
+}
+
 const updateConversationTimestamp = `-- name: UpdateConversationTimestamp :exec
 UPDATE conversations
 SET updated_at = NOW()
@@ -63,6 +63,8 @@ func (q *Queries) GetForeignMessageByForeignID(ctx context.Context, foreignMessa
 const getForeignMessageByMessageID = `-- name: GetForeignMessageByMessageID :one
 SELECT id, message_id, foreign_message_id, created_at FROM foreign_messages
 WHERE message_id = $1
+ORDER BY id ASC
+LIMIT 1
 `
 
 func (q *Queries) GetForeignMessageByMessageID(ctx context.Context, messageID int32) (ForeignMessage, error) {
@@ -76,3 +78,37 @@ func (q *Queries) GetForeignMessageByMessageID(ctx context.Context, messageID in
 	)
 	return i, err
 }
+
+const getForeignMessagesByMessageID = `-- name: GetForeignMessagesByMessageID :many
+SELECT id, message_id, foreign_message_id, created_at FROM foreign_messages
+WHERE message_id = $1
+ORDER BY id ASC
+`
+
+func (q *Queries) GetForeignMessagesByMessageID(ctx context.Context, messageID int32) ([]ForeignMessage, error) {
+	rows, err := q.db.QueryContext(ctx, getForeignMessagesByMessageID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ForeignMessage
+	for rows.Next() {
+		var i ForeignMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessageID,
+			&i.ForeignMessageID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
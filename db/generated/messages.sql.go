@@ -16,9 +16,11 @@ INSERT INTO messages (
     message_type,
     user_id,
     sent_by,
-    conversation_id
+    conversation_id,
+    created_at,
+    updated_at
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
 RETURNING id, message_type, user_id, sent_by, created_at, updated_at, conversation_id
 `
@@ -28,6 +30,8 @@ type CreateMessageParams struct {
 	UserID         int64
 	SentBy         MessageSender
 	ConversationID sql.NullInt64
+	CreatedAt      sql.NullTime
+	UpdatedAt      sql.NullTime
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
@@ -36,6 +40,8 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		arg.UserID,
 		arg.SentBy,
 		arg.ConversationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
 	)
 	var i Message
 	err := row.Scan(
@@ -50,10 +56,35 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 	return i, err
 }
 
+const deleteMessage = `-- name: DeleteMessage :exec
+DELETE FROM messages
+WHERE id = $1
+`
+
+func (q *Queries) DeleteMessage(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteMessage, id)
+	return err
+}
+
+const deleteMessagesAfterID = `-- name: DeleteMessagesAfterID :exec
+DELETE FROM messages
+WHERE conversation_id = $1 AND id > $2
+`
+
+type DeleteMessagesAfterIDParams struct {
+	ConversationID sql.NullInt64
+	ID             int64
+}
+
+func (q *Queries) DeleteMessagesAfterID(ctx context.Context, arg DeleteMessagesAfterIDParams) error {
+	_, err := q.db.ExecContext(ctx, deleteMessagesAfterID, arg.ConversationID, arg.ID)
+	return err
+}
+
 const getLatestMessageByConversationID = `-- name: GetLatestMessageByConversationID :one
 SELECT id, message_type, user_id, sent_by, created_at, updated_at, conversation_id FROM messages
 WHERE conversation_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT 1
 `
 
@@ -72,10 +103,58 @@ func (q *Queries) GetLatestMessageByConversationID(ctx context.Context, conversa
 	return i, err
 }
 
+const getMessageByForeignID = `-- name: GetMessageByForeignID :one
+SELECT m.id, m.message_type, m.user_id, m.sent_by, m.created_at, m.updated_at, m.conversation_id FROM messages m
+JOIN foreign_messages fm ON fm.message_id = m.id
+WHERE fm.foreign_message_id = $1 AND m.user_id = $2
+ORDER BY fm.id DESC
+LIMIT 1
+`
+
+type GetMessageByForeignIDParams struct {
+	ForeignMessageID int32
+	UserID           int64
+}
+
+func (q *Queries) GetMessageByForeignID(ctx context.Context, arg GetMessageByForeignIDParams) (Message, error) {
+	row := q.db.QueryRowContext(ctx, getMessageByForeignID, arg.ForeignMessageID, arg.UserID)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.MessageType,
+		&i.UserID,
+		&i.SentBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ConversationID,
+	)
+	return i, err
+}
+
+const getMessageByID = `-- name: GetMessageByID :one
+SELECT id, message_type, user_id, sent_by, created_at, updated_at, conversation_id FROM messages
+WHERE id = $1
+`
+
+func (q *Queries) GetMessageByID(ctx context.Context, id int64) (Message, error) {
+	row := q.db.QueryRowContext(ctx, getMessageByID, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.MessageType,
+		&i.UserID,
+		&i.SentBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ConversationID,
+	)
+	return i, err
+}
+
 const getMessagesByConversationID = `-- name: GetMessagesByConversationID :many
 SELECT id, message_type, user_id, sent_by, created_at, updated_at, conversation_id FROM messages
 WHERE conversation_id = $1
-ORDER BY created_at ASC
+ORDER BY created_at ASC, id ASC
 `
 
 func (q *Queries) GetMessagesByConversationID(ctx context.Context, conversationID sql.NullInt64) ([]Message, error) {
@@ -112,7 +191,7 @@ func (q *Queries) GetMessagesByConversationID(ctx context.Context, conversationI
 const getMessagesByUserID = `-- name: GetMessagesByUserID :many
 SELECT id, message_type, user_id, sent_by, created_at, updated_at, conversation_id FROM messages
 WHERE user_id = $1
-ORDER BY created_at ASC
+ORDER BY created_at ASC, id ASC
 `
 
 func (q *Queries) GetMessagesByUserID(ctx context.Context, userID int64) ([]Message, error) {
@@ -145,3 +224,745 @@ func (q *Queries) GetMessagesByUserID(ctx context.Context, userID int64) ([]Mess
 	}
 	return items, nil
 }
+
+const searchMessagesEnglish = `-- name: SearchMessagesEnglish :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('english'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('english'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('english'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('english'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesEnglishParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesEnglishRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesEnglish(ctx context.Context, arg SearchMessagesEnglishParams) ([]SearchMessagesEnglishRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesEnglish, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesEnglishRow
+	for rows.Next() {
+		var i SearchMessagesEnglishRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesRussian = `-- name: SearchMessagesRussian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('russian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('russian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('russian'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('russian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesRussianParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesRussianRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesRussian(ctx context.Context, arg SearchMessagesRussianParams) ([]SearchMessagesRussianRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesRussian, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesRussianRow
+	for rows.Next() {
+		var i SearchMessagesRussianRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesGerman = `-- name: SearchMessagesGerman :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('german'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('german'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('german'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('german'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesGermanParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesGermanRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesGerman(ctx context.Context, arg SearchMessagesGermanParams) ([]SearchMessagesGermanRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesGerman, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesGermanRow
+	for rows.Next() {
+		var i SearchMessagesGermanRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesFrench = `-- name: SearchMessagesFrench :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('french'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('french'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('french'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('french'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesFrenchParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesFrenchRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesFrench(ctx context.Context, arg SearchMessagesFrenchParams) ([]SearchMessagesFrenchRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesFrench, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesFrenchRow
+	for rows.Next() {
+		var i SearchMessagesFrenchRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesSpanish = `-- name: SearchMessagesSpanish :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('spanish'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('spanish'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('spanish'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('spanish'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesSpanishParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesSpanishRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesSpanish(ctx context.Context, arg SearchMessagesSpanishParams) ([]SearchMessagesSpanishRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesSpanish, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesSpanishRow
+	for rows.Next() {
+		var i SearchMessagesSpanishRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesItalian = `-- name: SearchMessagesItalian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('italian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('italian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('italian'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('italian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesItalianParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesItalianRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesItalian(ctx context.Context, arg SearchMessagesItalianParams) ([]SearchMessagesItalianRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesItalian, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesItalianRow
+	for rows.Next() {
+		var i SearchMessagesItalianRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesPortuguese = `-- name: SearchMessagesPortuguese :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('portuguese'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('portuguese'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('portuguese'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('portuguese'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesPortugueseParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesPortugueseRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesPortuguese(ctx context.Context, arg SearchMessagesPortugueseParams) ([]SearchMessagesPortugueseRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesPortuguese, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesPortugueseRow
+	for rows.Next() {
+		var i SearchMessagesPortugueseRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesArabic = `-- name: SearchMessagesArabic :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('arabic'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('arabic'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('arabic'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('arabic'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesArabicParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesArabicRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesArabic(ctx context.Context, arg SearchMessagesArabicParams) ([]SearchMessagesArabicRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesArabic, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesArabicRow
+	for rows.Next() {
+		var i SearchMessagesArabicRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesHindi = `-- name: SearchMessagesHindi :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('hindi'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('hindi'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('hindi'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('hindi'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesHindiParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesHindiRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesHindi(ctx context.Context, arg SearchMessagesHindiParams) ([]SearchMessagesHindiRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesHindi, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesHindiRow
+	for rows.Next() {
+		var i SearchMessagesHindiRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesArmenian = `-- name: SearchMessagesArmenian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('armenian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('armenian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('armenian'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('armenian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesArmenianParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesArmenianRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesArmenian(ctx context.Context, arg SearchMessagesArmenianParams) ([]SearchMessagesArmenianRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesArmenian, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesArmenianRow
+	for rows.Next() {
+		var i SearchMessagesArmenianRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchMessagesSimple = `-- name: SearchMessagesSimple :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('simple'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('simple'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('simple'::regconfig, $1::text) q
+WHERE m.user_id = $2
+  AND to_tsvector('simple'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT $3
+`
+
+type SearchMessagesSimpleParams struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type SearchMessagesSimpleRow struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+func (q *Queries) SearchMessagesSimple(ctx context.Context, arg SearchMessagesSimpleParams) ([]SearchMessagesSimpleRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessagesSimple, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesSimpleRow
+	for rows.Next() {
+		var i SearchMessagesSimpleRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.ConversationName,
+			&i.SentBy,
+			&i.CreatedAt,
+			&i.Snippet,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateMessageType = `-- name: UpdateMessageType :exec
+UPDATE messages
+SET message_type = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateMessageTypeParams struct {
+	ID          int64
+	MessageType json.RawMessage
+}
+
+func (q *Queries) UpdateMessageType(ctx context.Context, arg UpdateMessageTypeParams) error {
+	_, err := q.db.ExecContext(ctx, updateMessageType, arg.ID, arg.MessageType)
+	return err
+}
@@ -64,11 +64,24 @@ type Attachment struct {
 }
 
 type Conversation struct {
-	ID        int64
-	Name      string
-	UserID    int64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                   int64
+	Name                 string
+	UserID               int64
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	SystemPrompt         sql.NullString
+	ParentConversationID sql.NullInt64
+	PinnedAt             sql.NullTime
+	ArchivedAt           sql.NullTime
+	DeletedAt            sql.NullTime
+}
+
+type ConversationSummary struct {
+	ConversationID int64
+	Summary        string
+	LastMessageID  int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type ForeignMessage struct {
@@ -88,6 +101,26 @@ type Message struct {
 	ConversationID sql.NullInt64
 }
 
+type MessageUsage struct {
+	ID               int64
+	UserID           int64
+	MessageID        sql.NullInt64
+	Model            string
+	PromptTokens     int32
+	CompletionTokens int32
+	ReasoningTokens  int32
+	Cost             sql.NullFloat64
+	CreatedAt        time.Time
+}
+
+type MessageVersion struct {
+	ID        int64
+	MessageID int64
+	Text      string
+	Model     string
+	CreatedAt time.Time
+}
+
 type Payment struct {
 	ID                      int64
 	UserID                  int64
@@ -138,4 +171,7 @@ type User struct {
 	CurrentConversation    sql.NullInt64
 	ConversationListOffset int32
 	WebSearchEnabled       bool
+	DefaultSystemPrompt    sql.NullString
+	ShowUsage              bool
+	VoiceReplies           bool
 }
@@ -14,7 +14,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (foreign_id, language, current_step, selected_model, conversation_list_offset, web_search_enabled, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, foreign_id, language, created_at, updated_at, current_step, selected_model, current_conversation, conversation_list_offset, web_search_enabled
+RETURNING id, foreign_id, language, created_at, updated_at, current_step, selected_model, current_conversation, conversation_list_offset, web_search_enabled, default_system_prompt, show_usage, voice_replies
 `
 
 type CreateUserParams struct {
@@ -51,12 +51,43 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CurrentConversation,
 		&i.ConversationListOffset,
 		&i.WebSearchEnabled,
+		&i.DefaultSystemPrompt,
+		&i.ShowUsage,
+		&i.VoiceReplies,
 	)
 	return i, err
 }
 
+const getSelectedModels = `-- name: GetSelectedModels :many
+SELECT DISTINCT selected_model
+FROM users
+`
+
+func (q *Queries) GetSelectedModels(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getSelectedModels)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var selected_model string
+		if err := rows.Scan(&selected_model); err != nil {
+			return nil, err
+		}
+		items = append(items, selected_model)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByForeignID = `-- name: GetUserByForeignID :one
-SELECT id, foreign_id, language, created_at, updated_at, current_step, selected_model, current_conversation, conversation_list_offset, web_search_enabled
+SELECT id, foreign_id, language, created_at, updated_at, current_step, selected_model, current_conversation, conversation_list_offset, web_search_enabled, default_system_prompt, show_usage, voice_replies
 FROM users
 WHERE foreign_id = $1
 LIMIT 1
@@ -76,6 +107,9 @@ func (q *Queries) GetUserByForeignID(ctx context.Context, foreignID int64) (User
 		&i.CurrentConversation,
 		&i.ConversationListOffset,
 		&i.WebSearchEnabled,
+		&i.DefaultSystemPrompt,
+		&i.ShowUsage,
+		&i.VoiceReplies,
 	)
 	return i, err
 }
@@ -128,6 +162,22 @@ func (q *Queries) UpdateUserCurrentStep(ctx context.Context, arg UpdateUserCurre
 	return err
 }
 
+const updateUserDefaultSystemPrompt = `-- name: UpdateUserDefaultSystemPrompt :exec
+UPDATE users
+SET default_system_prompt = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserDefaultSystemPromptParams struct {
+	ID                  int64
+	DefaultSystemPrompt sql.NullString
+}
+
+func (q *Queries) UpdateUserDefaultSystemPrompt(ctx context.Context, arg UpdateUserDefaultSystemPromptParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserDefaultSystemPrompt, arg.ID, arg.DefaultSystemPrompt)
+	return err
+}
+
 const updateUserLanguage = `-- name: UpdateUserLanguage :exec
 UPDATE users
 SET language = $2, updated_at = NOW()
@@ -160,6 +210,38 @@ func (q *Queries) UpdateUserSelectedModel(ctx context.Context, arg UpdateUserSel
 	return err
 }
 
+const updateUserShowUsage = `-- name: UpdateUserShowUsage :exec
+UPDATE users
+SET show_usage = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserShowUsageParams struct {
+	ID        int64
+	ShowUsage bool
+}
+
+func (q *Queries) UpdateUserShowUsage(ctx context.Context, arg UpdateUserShowUsageParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserShowUsage, arg.ID, arg.ShowUsage)
+	return err
+}
+
+const updateUserVoiceReplies = `-- name: UpdateUserVoiceReplies :exec
+UPDATE users
+SET voice_replies = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserVoiceRepliesParams struct {
+	ID           int64
+	VoiceReplies bool
+}
+
+func (q *Queries) UpdateUserVoiceReplies(ctx context.Context, arg UpdateUserVoiceRepliesParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserVoiceReplies, arg.ID, arg.VoiceReplies)
+	return err
+}
+
 const updateUserWebSearchEnabled = `-- name: UpdateUserWebSearchEnabled :exec
 UPDATE users
 SET web_search_enabled = $2, updated_at = NOW()
@@ -175,3 +257,22 @@ func (q *Queries) UpdateUserWebSearchEnabled(ctx context.Context, arg UpdateUser
 	_, err := q.db.ExecContext(ctx, updateUserWebSearchEnabled, arg.ID, arg.WebSearchEnabled)
 	return err
 }
+
+const updateUsersSelectedModel = `-- name: UpdateUsersSelectedModel :execrows
+UPDATE users
+SET selected_model = $1, updated_at = NOW()
+WHERE selected_model = $2
+`
+
+type UpdateUsersSelectedModelParams struct {
+	NewModel string
+	OldModel string
+}
+
+func (q *Queries) UpdateUsersSelectedModel(ctx context.Context, arg UpdateUsersSelectedModelParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUsersSelectedModel, arg.NewModel, arg.OldModel)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN default_system_prompt TEXT;
+ALTER TABLE conversations ADD COLUMN system_prompt TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE conversations DROP COLUMN system_prompt;
+ALTER TABLE users DROP COLUMN default_system_prompt;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE message_versions (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    model TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_versions_message_id ON message_versions(message_id);
+
+-- A long bot answer is split into several Telegram messages, all of which map to the same message.
+-- Telegram message IDs are only unique within a chat, so they can't be unique across users either.
+ALTER TABLE foreign_messages DROP CONSTRAINT foreign_messages_message_id_key;
+ALTER TABLE foreign_messages DROP CONSTRAINT foreign_messages_foreign_message_id_key;
+ALTER TABLE foreign_messages ADD CONSTRAINT foreign_messages_message_id_foreign_message_id_key
+    UNIQUE (message_id, foreign_message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE foreign_messages DROP CONSTRAINT foreign_messages_message_id_foreign_message_id_key;
+DELETE FROM foreign_messages a USING foreign_messages b
+WHERE a.id > b.id AND (a.message_id = b.message_id OR a.foreign_message_id = b.foreign_message_id);
+ALTER TABLE foreign_messages ADD CONSTRAINT foreign_messages_message_id_key UNIQUE (message_id);
+ALTER TABLE foreign_messages ADD CONSTRAINT foreign_messages_foreign_message_id_key UNIQUE (foreign_message_id);
+
+DROP TABLE IF EXISTS message_versions;
+-- +goose StatementEnd
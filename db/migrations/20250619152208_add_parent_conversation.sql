@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE conversations
+    ADD COLUMN parent_conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL;
+CREATE INDEX idx_conversations_parent_conversation_id ON conversations(parent_conversation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_conversations_parent_conversation_id;
+ALTER TABLE conversations DROP COLUMN parent_conversation_id;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_messages_search_english ON messages
+    USING GIN (to_tsvector('english'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_russian ON messages
+    USING GIN (to_tsvector('russian'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_simple ON messages
+    USING GIN (to_tsvector('simple'::regconfig, message_type->>'text'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_search_simple;
+DROP INDEX IF EXISTS idx_messages_search_russian;
+DROP INDEX IF EXISTS idx_messages_search_english;
+-- +goose StatementEnd
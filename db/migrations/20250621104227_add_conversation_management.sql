@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE conversations
+    ADD COLUMN pinned_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE conversations
+    DROP COLUMN deleted_at,
+    DROP COLUMN archived_at,
+    DROP COLUMN pinned_at;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE message_usages (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Usage outlives the answer it was spent on, so totals don't shrink when messages are deleted
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    model TEXT NOT NULL,
+    prompt_tokens INTEGER NOT NULL,
+    completion_tokens INTEGER NOT NULL,
+    reasoning_tokens INTEGER NOT NULL,
+    -- Cost in USD as reported by the provider, NULL when it wasn't reported
+    cost DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_usages_user_id ON message_usages(user_id);
+CREATE INDEX idx_message_usages_message_id ON message_usages(message_id);
+
+ALTER TABLE users ADD COLUMN show_usage BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN show_usage;
+
+DROP TABLE IF EXISTS message_usages;
+-- +goose StatementEnd
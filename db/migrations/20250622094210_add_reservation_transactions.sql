@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions DROP CONSTRAINT transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check CHECK (
+    transaction_type IN (
+        'initial_credit', 'message_cost', 'admin_credit', 'admin_debit', 'reservation', 'reservation_release'
+    )
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM transactions WHERE transaction_type IN ('reservation', 'reservation_release');
+ALTER TABLE transactions DROP CONSTRAINT transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check CHECK (
+    transaction_type IN ('initial_credit', 'message_cost', 'admin_credit', 'admin_debit')
+);
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Messages keep a list of files instead of a single image and a single PDF
+UPDATE messages
+SET message_type = (message_type - 'image_data' - 'image_mime_type' - 'pdf_data' - 'pdf_mime_type' - 'pdf_filename')
+    || jsonb_build_object('files',
+        CASE WHEN COALESCE(message_type->>'image_data', '') <> '' THEN jsonb_build_array(jsonb_build_object(
+            'data', message_type->'image_data',
+            'mime_type', COALESCE(NULLIF(message_type->>'image_mime_type', ''), 'image/jpeg')
+        )) ELSE '[]'::jsonb END
+        || CASE WHEN COALESCE(message_type->>'pdf_data', '') <> '' THEN jsonb_build_array(jsonb_build_object(
+            'data', message_type->'pdf_data',
+            'mime_type', 'application/pdf',
+            'file_name', COALESCE(message_type->>'pdf_filename', '')
+        )) ELSE '[]'::jsonb END
+    )
+WHERE message_type ?| ARRAY['image_data', 'pdf_data'];
+
+UPDATE messages
+SET message_type = message_type - 'files'
+WHERE message_type->'files' = '[]'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Only the first image and the first PDF of a message are kept
+UPDATE messages
+SET message_type = (message_type - 'files') || jsonb_build_object(
+    'image_data', (
+        SELECT file->'data' FROM jsonb_array_elements(message_type->'files') AS file
+        WHERE file->>'mime_type' LIKE 'image/%' LIMIT 1
+    ),
+    'image_mime_type', COALESCE((
+        SELECT file->>'mime_type' FROM jsonb_array_elements(message_type->'files') AS file
+        WHERE file->>'mime_type' LIKE 'image/%' LIMIT 1
+    ), ''),
+    'pdf_data', (
+        SELECT file->'data' FROM jsonb_array_elements(message_type->'files') AS file
+        WHERE file->>'mime_type' = 'application/pdf' LIMIT 1
+    ),
+    'pdf_mime_type', CASE WHEN message_type->'files' @> '[{"mime_type": "application/pdf"}]'::jsonb
+        THEN 'application/pdf' ELSE '' END,
+    'pdf_filename', COALESCE((
+        SELECT file->>'file_name' FROM jsonb_array_elements(message_type->'files') AS file
+        WHERE file->>'mime_type' = 'application/pdf' LIMIT 1
+    ), '')
+)
+WHERE message_type ? 'files';
+-- +goose StatementEnd
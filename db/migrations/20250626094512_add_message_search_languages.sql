@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Armenian and Hindi stemmers come with PostgreSQL 14
+CREATE INDEX idx_messages_search_german ON messages
+    USING GIN (to_tsvector('german'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_french ON messages
+    USING GIN (to_tsvector('french'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_spanish ON messages
+    USING GIN (to_tsvector('spanish'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_italian ON messages
+    USING GIN (to_tsvector('italian'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_portuguese ON messages
+    USING GIN (to_tsvector('portuguese'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_arabic ON messages
+    USING GIN (to_tsvector('arabic'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_hindi ON messages
+    USING GIN (to_tsvector('hindi'::regconfig, message_type->>'text'));
+CREATE INDEX idx_messages_search_armenian ON messages
+    USING GIN (to_tsvector('armenian'::regconfig, message_type->>'text'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_search_armenian;
+DROP INDEX IF EXISTS idx_messages_search_hindi;
+DROP INDEX IF EXISTS idx_messages_search_arabic;
+DROP INDEX IF EXISTS idx_messages_search_portuguese;
+DROP INDEX IF EXISTS idx_messages_search_italian;
+DROP INDEX IF EXISTS idx_messages_search_spanish;
+DROP INDEX IF EXISTS idx_messages_search_french;
+DROP INDEX IF EXISTS idx_messages_search_german;
+-- +goose StatementEnd
@@ -1,4 +1,9 @@
 -- name: CreateAttachment :one
 INSERT INTO attachments (message_id, s3_name, content_type, size)
 VALUES ($1, $2, $3, $4)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetAttachmentsByMessageID :many
+SELECT * FROM attachments
+WHERE message_id = $1
+ORDER BY id ASC;
@@ -0,0 +1,16 @@
+-- name: GetConversationSummary :one
+SELECT * FROM conversation_summaries
+WHERE conversation_id = $1
+LIMIT 1;
+
+-- name: UpsertConversationSummary :exec
+INSERT INTO conversation_summaries (conversation_id, summary, last_message_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (conversation_id) DO UPDATE
+SET summary = EXCLUDED.summary,
+    last_message_id = EXCLUDED.last_message_id,
+    updated_at = NOW();
+
+-- name: DeleteConversationSummary :exec
+DELETE FROM conversation_summaries
+WHERE conversation_id = $1;
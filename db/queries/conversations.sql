@@ -1,12 +1,17 @@
 -- name: CreateConversation :one
-INSERT INTO conversations (name, user_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO conversations (name, user_id, created_at, updated_at, system_prompt, parent_conversation_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetConversationsByUserID :many
 SELECT * FROM conversations
-WHERE user_id = $1
-ORDER BY updated_at DESC;
+WHERE user_id = $1 AND archived_at IS NULL AND deleted_at IS NULL
+ORDER BY pinned_at IS NULL, updated_at DESC;
+
+-- name: GetArchivedConversationsByUserID :many
+SELECT * FROM conversations
+WHERE user_id = $1 AND archived_at IS NOT NULL AND deleted_at IS NULL
+ORDER BY archived_at DESC;
 
 -- name: GetConversationByID :one
 SELECT * FROM conversations
@@ -21,4 +26,29 @@ WHERE id = $1;
 -- name: UpdateConversationTimestamp :exec
 UPDATE conversations
 SET updated_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateConversationSystemPrompt :exec
+UPDATE conversations
+SET system_prompt = $2
+WHERE id = $1;
+
+-- name: UpdateConversationPinned :exec
+UPDATE conversations
+SET pinned_at = CASE WHEN sqlc.arg(pinned)::bool THEN NOW() END
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateConversationArchived :exec
+UPDATE conversations
+SET archived_at = CASE WHEN sqlc.arg(archived)::bool THEN NOW() END
+WHERE id = sqlc.arg(id);
+
+-- name: SoftDeleteConversation :exec
+UPDATE conversations
+SET deleted_at = NOW()
+WHERE id = $1;
+
+-- name: RestoreConversation :exec
+UPDATE conversations
+SET deleted_at = NULL
+WHERE id = $1;
@@ -6,7 +6,14 @@ RETURNING *;
 
 -- name: GetForeignMessageByMessageID :one
 SELECT * FROM foreign_messages
-WHERE message_id = $1;
+WHERE message_id = $1
+ORDER BY id ASC
+LIMIT 1;
+
+-- name: GetForeignMessagesByMessageID :many
+SELECT * FROM foreign_messages
+WHERE message_id = $1
+ORDER BY id ASC;
 
 -- name: GetForeignMessageByForeignID :one
 SELECT * FROM foreign_messages
@@ -14,4 +21,4 @@ WHERE foreign_message_id = $1;
 
 -- name: DeleteForeignMessage :exec
 DELETE FROM foreign_messages
-WHERE message_id = $1;
\ No newline at end of file
+WHERE message_id = $1;
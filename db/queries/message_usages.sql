@@ -0,0 +1,13 @@
+-- name: CreateMessageUsage :one
+INSERT INTO message_usages (user_id, message_id, model, prompt_tokens, completion_tokens, reasoning_tokens, cost)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetUserUsageTotals :one
+SELECT COUNT(*)::bigint AS answers,
+       COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+       COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+       COALESCE(SUM(reasoning_tokens), 0)::bigint AS reasoning_tokens,
+       COALESCE(SUM(cost), 0)::double precision AS cost
+FROM message_usages
+WHERE user_id = $1;
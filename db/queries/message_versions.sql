@@ -0,0 +1,13 @@
+-- name: CreateMessageVersion :one
+INSERT INTO message_versions (message_id, text, model)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetMessageVersionsByMessageID :many
+SELECT * FROM message_versions
+WHERE message_id = $1
+ORDER BY id ASC;
+
+-- name: DeleteMessageVersionsByMessageID :exec
+DELETE FROM message_versions
+WHERE message_id = $1;
@@ -3,24 +3,248 @@ INSERT INTO messages (
     message_type,
     user_id,
     sent_by,
-    conversation_id
+    conversation_id,
+    created_at,
+    updated_at
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
 -- name: GetMessagesByUserID :many
 SELECT * FROM messages
 WHERE user_id = $1
-ORDER BY created_at ASC;
+ORDER BY created_at ASC, id ASC;
 
 -- name: GetMessagesByConversationID :many
 SELECT * FROM messages
 WHERE conversation_id = $1
-ORDER BY created_at ASC;
+ORDER BY created_at ASC, id ASC;
 
 -- name: GetLatestMessageByConversationID :one
 SELECT * FROM messages
 WHERE conversation_id = $1
-ORDER BY created_at DESC
-LIMIT 1;
\ No newline at end of file
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: GetMessageByID :one
+SELECT * FROM messages
+WHERE id = $1;
+
+-- name: UpdateMessageType :exec
+UPDATE messages
+SET message_type = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetMessageByForeignID :one
+SELECT m.* FROM messages m
+JOIN foreign_messages fm ON fm.message_id = m.id
+WHERE fm.foreign_message_id = $1 AND m.user_id = $2
+ORDER BY fm.id DESC
+LIMIT 1;
+
+-- name: DeleteMessagesAfterID :exec
+DELETE FROM messages
+WHERE conversation_id = $1 AND id > $2;
+
+-- name: DeleteMessage :exec
+DELETE FROM messages
+WHERE id = $1;
+
+-- name: SearchMessagesEnglish :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('english'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('english'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('english'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('english'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesRussian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('russian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('russian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('russian'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('russian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesGerman :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('german'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('german'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('german'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('german'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesFrench :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('french'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('french'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('french'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('french'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesSpanish :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('spanish'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('spanish'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('spanish'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('spanish'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesItalian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('italian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('italian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('italian'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('italian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesPortuguese :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('portuguese'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('portuguese'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('portuguese'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('portuguese'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesArabic :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('arabic'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('arabic'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('arabic'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('arabic'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesHindi :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('hindi'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('hindi'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('hindi'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('hindi'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesArmenian :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('armenian'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('armenian'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('armenian'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('armenian'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchMessagesSimple :many
+SELECT
+    m.id,
+    m.conversation_id,
+    c.name AS conversation_name,
+    m.sent_by,
+    m.created_at,
+    ts_headline('simple'::regconfig, m.message_type->>'text', q,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet,
+    ts_rank(to_tsvector('simple'::regconfig, m.message_type->>'text'), q)::real AS rank
+FROM messages m
+JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+CROSS JOIN websearch_to_tsquery('simple'::regconfig, sqlc.arg(query)::text) q
+WHERE m.user_id = sqlc.arg(user_id)
+  AND to_tsvector('simple'::regconfig, m.message_type->>'text') @@ q
+ORDER BY rank DESC, m.created_at DESC
+LIMIT sqlc.arg(result_limit);
@@ -37,4 +37,28 @@ WHERE id = $1;
 -- name: UpdateUserWebSearchEnabled :exec
 UPDATE users
 SET web_search_enabled = $2, updated_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateUserDefaultSystemPrompt :exec
+UPDATE users
+SET default_system_prompt = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserShowUsage :exec
+UPDATE users
+SET show_usage = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserVoiceReplies :exec
+UPDATE users
+SET voice_replies = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetSelectedModels :many
+SELECT DISTINCT selected_model
+FROM users;
+
+-- name: UpdateUsersSelectedModel :execrows
+UPDATE users
+SET selected_model = sqlc.arg(new_model), updated_at = NOW()
+WHERE selected_model = sqlc.arg(old_model);
@@ -6,11 +6,14 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vladimish/talk/internal/domain"
 	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/pkg/resilience"
 	"github.com/vladimish/talk/pkg/slogctx"
 
 	"github.com/go-telegram/bot"
@@ -22,14 +25,23 @@ type Bot struct {
 	s     *service.UpdateService
 	bot   *bot.Bot
 	token string
+	// files downloads the photos and documents users send
+	files *resilience.Client
 }
 
-func NewBot(l *slog.Logger, s *service.UpdateService, telegramBot *bot.Bot, token string) *Bot {
+func NewBot(
+	l *slog.Logger,
+	s *service.UpdateService,
+	telegramBot *bot.Bot,
+	token string,
+	files *resilience.Client,
+) *Bot {
 	return &Bot{
 		l:     l,
 		s:     s,
 		bot:   telegramBot,
 		token: token,
+		files: files,
 	}
 }
 
@@ -53,18 +65,17 @@ func (b *Bot) Handle(ctx context.Context, _ *bot.Bot, update *models.Update) {
 		return
 	}
 
-	// Extract image data if present
-	var imageData []byte
-	var imageMimeType string
-	var pdfData []byte
-	var pdfMimeType string
-	var pdfFileName string
+	// Extract attached files if present
+	var files []domain.File
 	messageText := update.Message.Text
 
 	// Handle photo messages
 	if len(update.Message.Photo) > 0 {
 		largestPhoto := update.Message.Photo[len(update.Message.Photo)-1]
-		imageData, imageMimeType = b.downloadPhoto(ctx, largestPhoto)
+		imageData, imageMimeType := b.downloadPhoto(ctx, largestPhoto)
+		if len(imageData) > 0 {
+			files = append(files, domain.File{Data: imageData, MimeType: imageMimeType})
+		}
 
 		// Use caption as message text if no text is provided
 		if messageText == "" && update.Message.Caption != "" {
@@ -79,8 +90,11 @@ func (b *Bot) Handle(ctx context.Context, _ *bot.Bot, update *models.Update) {
 
 	// Handle document messages (for PDFs)
 	if update.Message.Document != nil && update.Message.Document.MimeType == "application/pdf" {
-		pdfData, pdfMimeType = b.downloadDocument(ctx, update.Message.Document)
-		pdfFileName = update.Message.Document.FileName
+		pdfData, pdfMimeType := b.downloadDocument(ctx, update.Message.Document)
+		pdfFileName := update.Message.Document.FileName
+		if len(pdfData) > 0 {
+			files = append(files, domain.File{Data: pdfData, MimeType: pdfMimeType, FileName: pdfFileName})
+		}
 
 		// Use caption as message text if no text is provided
 		if messageText == "" && update.Message.Caption != "" {
@@ -94,17 +108,74 @@ func (b *Bot) Handle(ctx context.Context, _ *bot.Bot, update *models.Update) {
 			slog.Int("downloaded_size", len(pdfData)))
 	}
 
+	// Handle Office, text and source documents, whose text the service extracts
+	if update.Message.Document != nil && isTextDocument(update.Message.Document) {
+		document := update.Message.Document
+		data, mimeType := b.downloadDocument(ctx, document)
+		if len(data) > 0 {
+			files = append(files, domain.File{Data: data, MimeType: mimeType, FileName: document.FileName})
+		}
+
+		// Use caption as message text if no text is provided
+		if messageText == "" && update.Message.Caption != "" {
+			messageText = update.Message.Caption
+		}
+
+		b.l.InfoContext(ctx, "Text document received",
+			slog.String("file_id", document.FileID),
+			slog.Int64("file_size", document.FileSize),
+			slog.String("file_name", document.FileName),
+			slog.String("mime_type", document.MimeType),
+			slog.Int("downloaded_size", len(data)))
+	}
+
+	// Handle JSON documents, the service imports data exports and reads the others as text
+	var documentData []byte
+	var documentFileName string
+	if update.Message.Document != nil && isJSONDocument(update.Message.Document) {
+		documentData, _ = b.downloadDocument(ctx, update.Message.Document)
+		documentFileName = update.Message.Document.FileName
+
+		// Use caption as message text if no text is provided
+		if messageText == "" && update.Message.Caption != "" {
+			messageText = update.Message.Caption
+		}
+
+		b.l.InfoContext(ctx, "JSON document received",
+			slog.String("file_id", update.Message.Document.FileID),
+			slog.Int64("file_size", update.Message.Document.FileSize),
+			slog.String("file_name", documentFileName),
+			slog.Int("downloaded_size", len(documentData)))
+	}
+
+	// Voice messages and audio files are transcribed by the service
+	voice := b.voiceFile(ctx, update.Message)
+	if voice != nil && messageText == "" {
+		messageText = update.Message.Caption
+	}
+
+	var replyToMessageID int
+	var quoteText string
+	if update.Message.ReplyToMessage != nil {
+		replyToMessageID = update.Message.ReplyToMessage.ID
+	}
+	if update.Message.Quote != nil {
+		quoteText = update.Message.Quote.Text
+	}
+
 	err := b.s.HandleUpdate(ctx, domain.Update{
 		ExternalID:        strconv.FormatInt(update.ID, 10),
 		ExternalUserID:    strconv.FormatInt(update.Message.From.ID, 10),
 		UserLanguage:      update.Message.From.LanguageCode,
 		MessageText:       messageText,
-		ImageData:         imageData,
-		ImageMimeType:     imageMimeType,
-		PDFData:           pdfData,
-		PDFMimeType:       pdfMimeType,
-		PDFFileName:       pdfFileName,
+		Files:             files,
+		Voice:             voice,
+		DocumentData:      documentData,
+		DocumentFileName:  documentFileName,
 		ExternalMessageID: update.Message.ID,
+		MediaGroupID:      update.Message.MediaGroupID,
+		ReplyToMessageID:  replyToMessageID,
+		QuoteText:         quoteText,
 		ReceivedAt:        time.Now(),
 	})
 	if err != nil {
@@ -131,6 +202,31 @@ func (b *Bot) HandleCallback(ctx context.Context, _ *bot.Bot, update *models.Upd
 	}
 }
 
+func (b *Bot) HandleEditedMessage(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	if update == nil || update.EditedMessage == nil || update.EditedMessage.From == nil ||
+		update.EditedMessage.From.ID == 0 {
+		return
+	}
+
+	ctx = slogctx.WithField(ctx, "update_id", update.ID)
+	b.l.DebugContext(ctx, "handling edited message")
+
+	text := update.EditedMessage.Text
+	if text == "" {
+		text = update.EditedMessage.Caption
+	}
+
+	err := b.s.HandleEditedMessage(ctx, domain.EditedMessage{
+		ExternalUserID:    strconv.FormatInt(update.EditedMessage.From.ID, 10),
+		UserLanguage:      update.EditedMessage.From.LanguageCode,
+		ExternalMessageID: update.EditedMessage.ID,
+		MessageText:       text,
+	})
+	if err != nil {
+		b.l.ErrorContext(ctx, fmt.Errorf("error while handling edited message: %w", err).Error())
+	}
+}
+
 func (b *Bot) HandlePreCheckoutQuery(ctx context.Context, _ *bot.Bot, update *models.Update) {
 	if update == nil || update.PreCheckoutQuery == nil || update.PreCheckoutQuery.From.ID == 0 {
 		return
@@ -177,66 +273,43 @@ func (b *Bot) HandleSuccessfulPayment(ctx context.Context, _ *bot.Bot, update *m
 }
 
 func (b *Bot) downloadPhoto(ctx context.Context, photo models.PhotoSize) ([]byte, string) {
-	// Download the photo using Telegram Bot API
-	file, err := b.bot.GetFile(ctx, &bot.GetFileParams{
-		FileID: photo.FileID,
-	})
-	if err != nil {
-		b.l.ErrorContext(ctx, "failed to get file info", slog.String("error", err.Error()))
-		return nil, ""
-	}
-
-	if file.FilePath == "" {
-		return nil, ""
-	}
-
-	// Download the actual file content
-	downloadURL := fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", b.token, file.FilePath)
-
-	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
-	if reqErr != nil {
-		b.l.ErrorContext(ctx, "failed to create request", slog.String("error", reqErr.Error()))
+	fileData := b.downloadFile(ctx, photo.FileID)
+	if len(fileData) == 0 {
 		return nil, ""
 	}
 
-	httpResp, httpErr := http.DefaultClient.Do(req)
-	if httpErr != nil {
-		b.l.ErrorContext(ctx, "failed to download file", slog.String("error", httpErr.Error()))
-		return nil, ""
-	}
-	defer httpResp.Body.Close()
+	b.l.InfoContext(ctx, "Successfully downloaded image",
+		slog.Int("size", len(fileData)))
 
-	if httpResp.StatusCode != http.StatusOK {
-		b.l.ErrorContext(ctx, "failed to download file",
-			slog.Int("status_code", httpResp.StatusCode))
-		return nil, ""
-	}
+	return fileData, "image/jpeg" // Telegram photos are always JPEG
+}
 
-	// Read the file content
-	fileData, readErr := io.ReadAll(httpResp.Body)
-	if readErr != nil {
-		b.l.ErrorContext(ctx, "failed to read file content", slog.String("error", readErr.Error()))
+func (b *Bot) downloadDocument(ctx context.Context, document *models.Document) ([]byte, string) {
+	fileData := b.downloadFile(ctx, document.FileID)
+	if len(fileData) == 0 {
 		return nil, ""
 	}
 
-	b.l.InfoContext(ctx, "Successfully downloaded image",
-		slog.Int("size", len(fileData)))
+	b.l.InfoContext(ctx, "Successfully downloaded document",
+		slog.Int("size", len(fileData)),
+		slog.String("mime_type", document.MimeType))
 
-	return fileData, "image/jpeg" // Telegram photos are always JPEG
+	return fileData, document.MimeType
 }
 
-func (b *Bot) downloadDocument(ctx context.Context, document *models.Document) ([]byte, string) {
-	// Download the document using Telegram Bot API
+// downloadFile downloads a file the user sent through the Telegram Bot API. Failures are logged
+// and return no data.
+func (b *Bot) downloadFile(ctx context.Context, fileID string) []byte {
 	file, err := b.bot.GetFile(ctx, &bot.GetFileParams{
-		FileID: document.FileID,
+		FileID: fileID,
 	})
 	if err != nil {
 		b.l.ErrorContext(ctx, "failed to get file info", slog.String("error", err.Error()))
-		return nil, ""
+		return nil
 	}
 
 	if file.FilePath == "" {
-		return nil, ""
+		return nil
 	}
 
 	// Download the actual file content
@@ -245,32 +318,71 @@ func (b *Bot) downloadDocument(ctx context.Context, document *models.Document) (
 	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if reqErr != nil {
 		b.l.ErrorContext(ctx, "failed to create request", slog.String("error", reqErr.Error()))
-		return nil, ""
+		return nil
 	}
 
-	httpResp, httpErr := http.DefaultClient.Do(req)
+	httpResp, httpErr := b.files.Do(req)
 	if httpErr != nil {
 		b.l.ErrorContext(ctx, "failed to download file", slog.String("error", httpErr.Error()))
-		return nil, ""
+		return nil
 	}
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode != http.StatusOK {
 		b.l.ErrorContext(ctx, "failed to download file",
 			slog.Int("status_code", httpResp.StatusCode))
-		return nil, ""
+		return nil
 	}
 
 	// Read the file content
 	fileData, readErr := io.ReadAll(httpResp.Body)
 	if readErr != nil {
 		b.l.ErrorContext(ctx, "failed to read file content", slog.String("error", readErr.Error()))
-		return nil, ""
+		return nil
 	}
 
-	b.l.InfoContext(ctx, "Successfully downloaded document",
-		slog.Int("size", len(fileData)),
-		slog.String("mime_type", document.MimeType))
+	return fileData
+}
 
-	return fileData, document.MimeType
+// voiceFile returns the voice message or the audio file of a message, nil when it has none.
+// The file is returned without data when it can't be downloaded, so the user is told it wasn't recognized.
+func (b *Bot) voiceFile(ctx context.Context, message *models.Message) *domain.File {
+	var fileID string
+	var voice domain.File
+	switch {
+	case message.Voice != nil:
+		fileID = message.Voice.FileID
+		// Voice messages are always OGG with Opus, the file name tells the format to the transcription API
+		voice = domain.File{MimeType: "audio/ogg", FileName: "voice.ogg"}
+	case message.Audio != nil:
+		fileID = message.Audio.FileID
+		voice = domain.File{MimeType: message.Audio.MimeType, FileName: message.Audio.FileName}
+	case message.Document != nil && strings.HasPrefix(message.Document.MimeType, "audio/"):
+		fileID = message.Document.FileID
+		voice = domain.File{MimeType: message.Document.MimeType, FileName: message.Document.FileName}
+	default:
+		return nil
+	}
+
+	voice.Data = b.downloadFile(ctx, fileID)
+	b.l.InfoContext(ctx, "Voice received",
+		slog.String("file_id", fileID),
+		slog.String("mime_type", voice.MimeType),
+		slog.Int("downloaded_size", len(voice.Data)))
+
+	return &voice
+}
+
+func isJSONDocument(document *models.Document) bool {
+	return document.MimeType == "application/json" ||
+		strings.EqualFold(path.Ext(document.FileName), ".json")
+}
+
+// isTextDocument reports whether a document is read as text. JSON files may be conversation imports,
+// the service tells them apart, and audio files are transcribed instead.
+func isTextDocument(document *models.Document) bool {
+	return document.MimeType != "application/pdf" &&
+		!isJSONDocument(document) &&
+		!strings.HasPrefix(document.MimeType, "audio/") &&
+		domain.DocumentFormatOf(document.MimeType, document.FileName) != ""
 }
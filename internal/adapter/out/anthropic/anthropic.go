@@ -0,0 +1,358 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+const (
+	apiURL             = "https://api.anthropic.com/v1"
+	apiVersion         = "2023-06-01"
+	httpErrorThreshold = 400
+
+	// maxTokens limits the answer length, thinking included.
+	maxTokens = 16000
+	// The thinking budgets of the reasoning efforts, the API takes no less than minThinkingBudget.
+	minThinkingBudget    = 1024
+	mediumThinkingBudget = 4000
+	highThinkingBudget   = 8000
+	// maxSearches limits the web searches of a single answer.
+	maxSearches = 5
+)
+
+// Completion streams answers from the Anthropic Messages API.
+type Completion struct {
+	logger     *slog.Logger
+	apiKey     string
+	baseURL    string
+	httpClient *resilience.Client
+}
+
+func NewAnthropicCompletion(apiKey string, httpClient *resilience.Client) *Completion {
+	return &Completion{
+		logger:     slog.Default(),
+		apiKey:     apiKey,
+		baseURL:    apiURL,
+		httpClient: httpClient,
+	}
+}
+
+type messagesRequest struct {
+	Model       string           `json:"model"`
+	MaxTokens   int              `json:"max_tokens"`
+	System      string           `json:"system,omitempty"`
+	Messages    []message        `json:"messages"`
+	Stream      bool             `json:"stream"`
+	Temperature *float64         `json:"temperature,omitempty"`
+	Thinking    *thinking        `json:"thinking,omitempty"`
+	Tools       []map[string]any `json:"tools,omitempty"`
+}
+
+type thinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type contentBlock struct {
+	Type   string  `json:"type"`
+	Text   string  `json:"text,omitempty"`
+	Source *source `json:"source,omitempty"`
+	Title  string  `json:"title,omitempty"`
+}
+
+type source struct {
+	Type      string `json:"type"`
+	URL       string `json:"url,omitempty"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+// streamEvent is a server-sent event of a streamed message.
+type streamEvent struct {
+	Type    string `json:"type"`
+	Message *struct {
+		Usage streamUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	Delta *struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		Thinking string `json:"thinking"`
+	} `json:"delta,omitempty"`
+	Usage *streamUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type streamUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+func (a *Completion) CompleteStream(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (<-chan completion.StreamToken, error) {
+	messages, err := toMessages(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := messagesRequest{
+		Model:     req.Model,
+		MaxTokens: maxTokens,
+		System:    req.SystemPrompt,
+		Messages:  messages,
+		Stream:    true,
+	}
+	if req.Parameters.MaxTokens > 0 {
+		reqBody.MaxTokens = req.Parameters.MaxTokens
+	}
+
+	// The API doesn't take a temperature while thinking
+	if budget := thinkingBudget(req.Parameters.Reasoning, reqBody.MaxTokens); budget > 0 {
+		reqBody.Thinking = &thinking{Type: "enabled", BudgetTokens: budget}
+	} else {
+		reqBody.Temperature = req.Parameters.Temperature
+	}
+
+	if req.Plugins.WebSearch {
+		reqBody.Tools = []map[string]any{
+			{"type": "web_search_20250305", "name": "web_search", "max_uses": maxSearches},
+		}
+	}
+
+	return a.createStream(ctx, reqBody)
+}
+
+// thinkingBudget returns the part of the answer tokens a model may think for with the reasoning effort,
+// zero for no thinking.
+func thinkingBudget(effort completion.ReasoningEffort, answerTokens int) int {
+	var budget int
+	switch effort {
+	case completion.ReasoningNone:
+		return 0
+	case completion.ReasoningLow:
+		budget = minThinkingBudget
+	case completion.ReasoningMedium:
+		budget = mediumThinkingBudget
+	case completion.ReasoningHigh:
+		budget = highThinkingBudget
+	}
+	// The budget has to leave room for the answer
+	if budget >= answerTokens || budget < minThinkingBudget {
+		return 0
+	}
+	return budget
+}
+
+// toMessages converts completion messages to the Messages API format.
+func toMessages(messages []completion.Message) ([]message, error) {
+	converted := make([]message, 0, len(messages))
+
+	for _, msg := range messages {
+		var attachments, text []contentBlock
+		for _, part := range msg.Parts {
+			if part.Type == completion.PartTypeText {
+				// The API rejects empty text blocks
+				if part.Text != "" {
+					text = append(text, contentBlock{Type: "text", Text: part.Text})
+				}
+				continue
+			}
+
+			block, err := toContentBlock(part)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, block)
+		}
+
+		// Empty messages are rejected too
+		if len(attachments) == 0 && len(text) == 0 {
+			continue
+		}
+		// Attachments go before the text, as the API recommends
+		converted = append(converted, message{Role: string(msg.Role), Content: append(attachments, text...)})
+	}
+
+	return converted, nil
+}
+
+// toContentBlock converts an image or a file. Audio isn't supported by the API.
+func toContentBlock(part completion.Part) (contentBlock, error) {
+	switch part.Type {
+	case completion.PartTypeImage:
+		return contentBlock{Type: "image", Source: toSource(part)}, nil
+	case completion.PartTypeFile:
+		block := contentBlock{Type: "document", Source: toSource(part), Title: part.FileName}
+		// Plain text documents are passed as text
+		if strings.HasPrefix(part.MimeType, "text/") && len(part.Data) > 0 {
+			block.Source = &source{Type: "text", MediaType: "text/plain", Data: string(part.Data)}
+		}
+		return block, nil
+	case completion.PartTypeText, completion.PartTypeAudio:
+	}
+	return contentBlock{}, fmt.Errorf("unsupported message part type %q", part.Type)
+}
+
+// toSource returns the source of a part: its data when it has any, otherwise its URL.
+func toSource(part completion.Part) *source {
+	if len(part.Data) == 0 {
+		return &source{Type: "url", URL: part.URL}
+	}
+	return &source{
+		Type:      "base64",
+		MediaType: part.MimeType,
+		Data:      base64.StdEncoding.EncodeToString(part.Data),
+	}
+}
+
+// createStream sends a streaming messages request.
+func (a *Completion) createStream(
+	ctx context.Context,
+	reqBody messagesRequest,
+) (<-chan completion.StreamToken, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", a.apiKey)
+	req.Header.Set("Anthropic-Version", apiVersion)
+
+	resp, err := a.httpClient.Do(req) //nolint:bodyclose // body is closed in the goroutine defer
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to create completion stream",
+			"error", err.Error(),
+			"model", reqBody.Model,
+			"messages_count", len(reqBody.Messages))
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode >= httpErrorThreshold {
+		// Read response body for error details
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if readErr != nil {
+			return nil, fmt.Errorf("HTTP error: %d (failed to read response body: %w)", resp.StatusCode, readErr)
+		}
+		return nil, fmt.Errorf("HTTP error: %d - Response: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	tokenChan := make(chan completion.StreamToken)
+
+	go func() {
+		defer close(tokenChan)
+		defer func() {
+			if closeErr := resp.Body.Close(); closeErr != nil {
+				a.logger.Error("failed to close response body", "error", closeErr)
+			}
+		}()
+
+		a.processStreamResponse(ctx, resp, tokenChan)
+	}()
+
+	return tokenChan, nil
+}
+
+func (a *Completion) processStreamResponse(
+	ctx context.Context,
+	resp *http.Response,
+	tokenChan chan<- completion.StreamToken,
+) {
+	var usage completion.Usage
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event streamEvent
+		if unmarshalErr := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); unmarshalErr != nil {
+			continue // Skip malformed lines
+		}
+
+		var token completion.StreamToken
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				usage.PromptTokens = event.Message.Usage.promptTokens()
+			}
+		case "content_block_delta":
+			if event.Delta != nil {
+				token.Content = event.Delta.Text
+				token.Reasoning = event.Delta.Thinking
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.CompletionTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			// The API doesn't report the cost, billing relies on the token counts
+			token.Usage = &usage
+		case "error":
+			if event.Error != nil {
+				token.Error = fmt.Errorf("stream error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+		}
+
+		if token.Content == "" && token.Reasoning == "" && token.Usage == nil && token.Error == nil {
+			continue
+		}
+
+		select {
+		case tokenChan <- token:
+		case <-ctx.Done():
+			return
+		}
+		if token.Usage != nil || token.Error != nil {
+			return
+		}
+	}
+
+	streamErr := errors.New("stream ended before the message was complete")
+	if scanErr := scanner.Err(); scanErr != nil {
+		streamErr = fmt.Errorf("scanner error: %w", scanErr)
+	}
+	// Every message ends with message_stop, a stream that ends earlier was cut off
+	select {
+	case tokenChan <- completion.StreamToken{Error: streamErr}:
+	case <-ctx.Done():
+	}
+}
+
+// promptTokens counts the cached prompt tokens too, they are reported apart from the rest.
+func (u streamUsage) promptTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
@@ -0,0 +1,145 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+// sse formats events the way the Messages API streams them.
+func sse(events ...string) string {
+	var stream strings.Builder
+	for _, event := range events {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(event), &typed)
+		fmt.Fprintf(&stream, "event: %s\ndata: %s\n\n", typed.Type, event)
+	}
+	return stream.String()
+}
+
+func TestCompletion_CompleteStream(t *testing.T) {
+	messageStart := `{"type":"message_start","message":{"usage":{"input_tokens":10,"cache_read_input_tokens":5}}}`
+	textDelta := func(text string) string {
+		return fmt.Sprintf(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":%q}}`, text)
+	}
+
+	tests := []struct {
+		name           string
+		status         int
+		stream         string
+		expectedTokens []completion.StreamToken
+		// expectedErr is part of the error of the last token or of the request, empty when there is none
+		expectedErr        string
+		expectedRequestErr string
+	}{
+		{
+			name:   "text deltas and usage",
+			status: http.StatusOK,
+			stream: sse(
+				messageStart,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Hmm"}}`,
+				`{"type":"ping"}`,
+				textDelta("Hel"),
+				textDelta("lo"),
+				`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+				`{"type":"message_stop"}`,
+			),
+			expectedTokens: []completion.StreamToken{
+				{Reasoning: "Hmm"},
+				{Content: "Hel"},
+				{Content: "lo"},
+				// Cached prompt tokens are counted too
+				{Usage: &completion.Usage{PromptTokens: 15, CompletionTokens: 7}},
+			},
+		},
+		{
+			name:   "error event ends the stream",
+			status: http.StatusOK,
+			stream: sse(
+				messageStart,
+				textDelta("Hel"),
+				`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+				textDelta("lo"),
+			),
+			expectedTokens: []completion.StreamToken{{Content: "Hel"}},
+			expectedErr:    "overloaded_error: Overloaded",
+		},
+		{
+			name:           "truncated stream",
+			status:         http.StatusOK,
+			stream:         sse(messageStart, textDelta("Hel")),
+			expectedTokens: []completion.StreamToken{{Content: "Hel"}},
+			expectedErr:    "stream ended before the message was complete",
+		},
+		{
+			name:               "error status",
+			status:             http.StatusBadRequest,
+			stream:             `{"type":"error","error":{"type":"invalid_request_error","message":"Bad model"}}`,
+			expectedRequestErr: "HTTP error: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/messages", r.URL.Path)
+				assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+				assert.Equal(t, apiVersion, r.Header.Get("Anthropic-Version"))
+
+				var body messagesRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "claude-sonnet-4-0", body.Model)
+				assert.True(t, body.Stream)
+				assert.Equal(t, "Be brief", body.System)
+
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.stream))
+			}))
+			defer server.Close()
+
+			client := NewAnthropicCompletion("key", resilience.NewClient("anthropic", slog.Default(), resilience.Config{}))
+			client.baseURL = server.URL
+
+			tokens, err := client.CompleteStream(t.Context(), completion.CompletionRequest{
+				Model:        "claude-sonnet-4-0",
+				SystemPrompt: "Be brief",
+				Messages: []completion.Message{{
+					Role:  completion.RoleUser,
+					Parts: []completion.Part{{Type: completion.PartTypeText, Text: "Hi"}},
+				}},
+			})
+			if tt.expectedRequestErr != "" {
+				require.ErrorContains(t, err, tt.expectedRequestErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var received []completion.StreamToken
+			for token := range tokens {
+				received = append(received, token)
+			}
+
+			if tt.expectedErr != "" {
+				require.NotEmpty(t, received)
+				last := received[len(received)-1]
+				require.ErrorContains(t, last.Error, tt.expectedErr)
+				received = received[:len(received)-1]
+			}
+			assert.Equal(t, tt.expectedTokens, received)
+		})
+	}
+}
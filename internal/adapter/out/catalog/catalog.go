@@ -0,0 +1,247 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+const (
+	modelsURL = "https://openrouter.ai/api/v1/models"
+	// syncInterval is how often capabilities and prices are synced with the provider.
+	syncInterval = time.Hour
+)
+
+// Config configures where the model catalog comes from.
+type Config struct {
+	Path     string        // JSON catalog file, the built-in catalog is used when empty
+	Sync     bool          // Whether capabilities and prices are synced with the OpenRouter models list
+	APIKey   string        // OpenRouter API key for the models list (optional)
+	Interval time.Duration // How often the catalog file is checked for changes
+}
+
+// Loader loads the model catalog and keeps it up to date while the bot runs.
+type Loader struct {
+	logger     *slog.Logger
+	config     Config
+	httpClient *resilience.Client
+	onReload   func(ctx context.Context) error
+
+	modTime  time.Time
+	syncedAt time.Time
+	remote   map[string]remoteModel
+}
+
+// NewLoader creates a catalog loader. onReload is called after every catalog that was loaded.
+func NewLoader(
+	logger *slog.Logger,
+	config Config,
+	httpClient *resilience.Client,
+	onReload func(ctx context.Context) error,
+) *Loader {
+	return &Loader{
+		logger:     logger,
+		config:     config,
+		httpClient: httpClient,
+		onReload:   onReload,
+	}
+}
+
+type modelsResponse struct {
+	Data []remoteModel `json:"data"`
+}
+
+type remoteModel struct {
+	ID           string `json:"id"`
+	Architecture struct {
+		InputModalities []string `json:"input_modalities"`
+	} `json:"architecture"`
+	Pricing struct {
+		Prompt     string `json:"prompt"`
+		Completion string `json:"completion"`
+	} `json:"pricing"`
+}
+
+// Load reads the catalog, syncs it with the provider when configured and makes it the catalog the bot uses.
+// A catalog that can't be read or isn't valid is rejected and the current one is kept.
+func (l *Loader) Load(ctx context.Context) error {
+	catalog, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	if l.config.Sync {
+		l.sync(ctx, catalog)
+		if err = catalog.Validate(); err != nil {
+			return fmt.Errorf("synced model catalog is invalid: %w", err)
+		}
+	}
+
+	domain.SetModelCatalog(catalog)
+	l.logger.InfoContext(ctx, "model catalog loaded",
+		slog.String("path", l.config.Path),
+		slog.Int("models", len(catalog.Models)))
+
+	if l.onReload != nil {
+		if err = l.onReload(ctx); err != nil {
+			return fmt.Errorf("can't apply model catalog: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Run reloads the catalog when its file changes or the provider sync is due, until ctx is done.
+func (l *Loader) Run(ctx context.Context) {
+	if l.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !l.changed() && !l.syncDue() {
+			continue
+		}
+		if err := l.Load(ctx); err != nil {
+			l.logger.ErrorContext(ctx, "failed to reload model catalog", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (l *Loader) read() (*domain.ModelCatalog, error) {
+	if l.config.Path == "" {
+		return domain.DefaultModelCatalog(), nil
+	}
+
+	info, err := os.Stat(l.config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("can't stat model catalog: %w", err)
+	}
+	data, err := os.ReadFile(l.config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read model catalog: %w", err)
+	}
+	// The file isn't read again until it changes, even if this version is rejected
+	l.modTime = info.ModTime()
+
+	return domain.ParseModelCatalog(data)
+}
+
+// changed reports whether the catalog file was modified since it was last read.
+func (l *Loader) changed() bool {
+	if l.config.Path == "" {
+		return false
+	}
+
+	info, err := os.Stat(l.config.Path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(l.modTime)
+}
+
+func (l *Loader) syncDue() bool {
+	return l.config.Sync && time.Since(l.syncedAt) >= syncInterval
+}
+
+// sync updates the capabilities and rates of OpenRouter models from the provider models list. When the list
+// can't be fetched, the last fetched one is used.
+func (l *Loader) sync(ctx context.Context, catalog *domain.ModelCatalog) {
+	if l.syncDue() || l.remote == nil {
+		remote, err := l.fetchModels(ctx)
+		if err != nil {
+			l.logger.WarnContext(ctx, "failed to fetch provider models", slog.String("error", err.Error()))
+		} else {
+			l.remote = remote
+			l.syncedAt = time.Now()
+		}
+	}
+	if l.remote == nil {
+		return
+	}
+
+	for i := range catalog.Models {
+		model := &catalog.Models[i]
+		if model.Retired || model.CompletionProvider() != domain.ProviderOpenRouter {
+			continue
+		}
+
+		remote, exists := l.remote[model.ID]
+		if !exists {
+			l.logger.WarnContext(ctx, "model is not offered by the provider", slog.String("model", model.ID))
+			continue
+		}
+
+		model.ImageSupport = slices.Contains(remote.Architecture.InputModalities, "image")
+		model.PDFSupport = slices.Contains(remote.Architecture.InputModalities, "file")
+
+		tokensPerUSD := catalog.TokensPerUSD[model.TokenType]
+		if tokensPerUSD <= 0 {
+			continue
+		}
+		if rate, ok := parseRate(remote.Pricing.Prompt, tokensPerUSD); ok {
+			model.PromptRate = rate
+		}
+		if rate, ok := parseRate(remote.Pricing.Completion, tokensPerUSD); ok {
+			model.CompletionRate = rate
+		}
+	}
+}
+
+func (l *Loader) fetchModels(ctx context.Context) (map[string]remoteModel, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if l.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.config.APIKey)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result modelsResponse
+	if decodeErr := json.NewDecoder(resp.Body).Decode(&result); decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+
+	models := make(map[string]remoteModel, len(result.Data))
+	for _, model := range result.Data {
+		models[model.ID] = model
+	}
+	return models, nil
+}
+
+// parseRate converts a price in USD per token into tokens per million model tokens.
+func parseRate(price string, tokensPerUSD float64) (int64, bool) {
+	usd, err := strconv.ParseFloat(price, 64)
+	if err != nil || usd < 0 {
+		return 0, false
+	}
+	return int64(math.Ceil(usd * 1e6 * tokensPerUSD)), true
+}
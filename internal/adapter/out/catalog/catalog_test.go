@@ -0,0 +1,232 @@
+package catalog_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/adapter/out/catalog"
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+// writeCatalog stores the built-in catalog changed by edit as the catalog file.
+func writeCatalog(t *testing.T, path string, edit func(*domain.ModelCatalog)) {
+	t.Helper()
+
+	modelCatalog := domain.DefaultModelCatalog()
+	edit(modelCatalog)
+	data, err := json.Marshal(modelCatalog)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func retire(modelID, replacedBy string) func(*domain.ModelCatalog) {
+	return func(modelCatalog *domain.ModelCatalog) {
+		for i := range modelCatalog.Models {
+			if modelCatalog.Models[i].ID == modelID {
+				modelCatalog.Models[i].Retired = true
+				modelCatalog.Models[i].ReplacedBy = replacedBy
+			}
+		}
+	}
+}
+
+func newLoader(path string, interval time.Duration, onReload func(ctx context.Context) error) *catalog.Loader {
+	return catalog.NewLoader(slog.Default(), catalog.Config{
+		Path:     path,
+		Interval: interval,
+	}, resilience.NewClient("test", slog.Default(), resilience.DefaultConfig()), onReload)
+}
+
+func TestLoader_Load(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*domain.ModelCatalog)
+		// expectedErr is part of the error, empty when the catalog is loaded
+		expectedErr string
+	}{
+		{
+			name: "valid catalog is loaded",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models[0].Names["en"] = "Quick one"
+			},
+		},
+		{
+			name: "duplicate model",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models = append(modelCatalog.Models, modelCatalog.Models[0])
+			},
+			expectedErr: "duplicate model",
+		},
+		{
+			name: "unknown provider",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models[0].Provider = "mistral"
+			},
+			expectedErr: "unknown provider",
+		},
+		{
+			name: "unknown token type",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models[0].TokenType = "gold"
+			},
+			expectedErr: "unknown token type",
+		},
+		{
+			name: "retired default model",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				retire(modelCatalog.DefaultModel, "")(modelCatalog)
+			},
+			expectedErr: "default model",
+		},
+		{
+			name:        "replacement that isn't available",
+			edit:        retire("openai/gpt-4o", "openai/gpt-5"),
+			expectedErr: "is replaced by",
+		},
+		{
+			name: "fallback to itself",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models[0].Fallbacks = []string{modelCatalog.Models[0].ID}
+			},
+			expectedErr: "falls back to",
+		},
+		{
+			name: "model without an English name",
+			edit: func(modelCatalog *domain.ModelCatalog) {
+				modelCatalog.Models[0].Names = map[string]string{"ru": "Быстрая"}
+			},
+			expectedErr: "no English name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+
+			path := filepath.Join(t.TempDir(), "models.json")
+			writeCatalog(t, path, tt.edit)
+			firstModel := domain.DefaultModelCatalog().Models[0].ID
+
+			reloads := 0
+			err := newLoader(path, 0, func(context.Context) error {
+				reloads++
+				return nil
+			}).Load(t.Context())
+
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				// The current catalog is kept
+				assert.Zero(t, reloads)
+				assert.NotEqual(t, "Quick one", domain.GetModelByID(firstModel).Names["en"])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, reloads)
+			assert.Equal(t, "Quick one", domain.GetModelByID(firstModel).Names["en"])
+		})
+	}
+}
+
+func TestLoader_Load_FileErrors(t *testing.T) {
+	t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+	dir := t.TempDir()
+
+	err := newLoader(filepath.Join(dir, "missing.json"), 0, nil).Load(t.Context())
+	require.ErrorContains(t, err, "can't stat model catalog")
+
+	path := filepath.Join(dir, "broken.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"models": [`), 0o600))
+	err = newLoader(path, 0, nil).Load(t.Context())
+	require.ErrorContains(t, err, "can't decode model catalog")
+
+	// Without a file the built-in catalog is used
+	require.NoError(t, newLoader("", 0, nil).Load(t.Context()))
+	assert.Equal(t, domain.DefaultModelCatalog().DefaultModel, domain.DefaultModelID())
+}
+
+func TestLoader_Run_ReloadsChangedFile(t *testing.T) {
+	t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+
+	path := filepath.Join(t.TempDir(), "models.json")
+	writeCatalog(t, path, func(*domain.ModelCatalog) {})
+
+	var reloads atomic.Int32
+	loader := newLoader(path, 10*time.Millisecond, func(context.Context) error {
+		reloads.Add(1)
+		return nil
+	})
+	require.NoError(t, loader.Load(t.Context()))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		loader.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// An unchanged file isn't loaded again
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load())
+
+	// An invalid version is rejected and the current catalog is kept
+	require.NoError(t, os.WriteFile(path, []byte(`{"models": []}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load())
+	assert.NotNil(t, domain.GetModelByID("openai/gpt-4o"))
+
+	writeCatalog(t, path, retire("openai/gpt-4o", "openai/gpt-4o-mini"))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Eventually(t, func() bool {
+		return domain.GetModelByID("openai/gpt-4o") == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), reloads.Load())
+}
+
+func TestLoader_Load_MigratesRetiredModels(t *testing.T) {
+	t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	updateService := service.NewUpdateService(
+		slog.Default(),
+		mockStorage,
+		mocks.NewMockSender(ctrl),
+		mocks.NewMockCompletion(ctrl),
+		mocks.NewMockQueue(ctrl),
+		mocks.NewMockFileStorage(ctrl),
+	)
+
+	path := filepath.Join(t.TempDir(), "models.json")
+	writeCatalog(t, path, retire("openai/gpt-4o", "openai/gpt-4o-mini"))
+
+	mockStorage.EXPECT().
+		GetSelectedModels(gomock.Any()).
+		Return([]string{"google/gemini-2.5-flash", "openai/gpt-4o"}, nil)
+	mockStorage.EXPECT().
+		UpdateUsersSelectedModel(gomock.Any(), "openai/gpt-4o", "openai/gpt-4o-mini").
+		Return(int64(2), nil)
+
+	err := newLoader(path, 0, updateService.MigrateRetiredModels).Load(t.Context())
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/extraction"
+)
+
+const (
+	// DefaultMaxCharacters is how much of the text of a document the model gets by default, about 25k tokens.
+	DefaultMaxCharacters = 100_000
+	// maxDocumentSize limits the content read from a document, and from each part of an Office document.
+	maxDocumentSize = 50 << 20
+)
+
+// utf8BOM starts text files saved by some Windows editors.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type Extractor struct {
+	maxCharacters int
+}
+
+// New creates an extractor that reads the text of documents in process. Texts longer than maxCharacters
+// are truncated.
+func New(maxCharacters int) extraction.Extraction {
+	return &Extractor{maxCharacters: maxCharacters}
+}
+
+func (e *Extractor) Extract(_ context.Context, file domain.File) (*extraction.Document, error) {
+	if len(file.Data) > maxDocumentSize {
+		return nil, extraction.ErrTooLarge
+	}
+
+	var text string
+	var err error
+	format := domain.DocumentFormatOf(file.MimeType, file.FileName)
+	switch format {
+	case domain.DocumentFormatDOCX:
+		text, err = docxText(file.Data)
+	case domain.DocumentFormatXLSX:
+		text, err = xlsxText(file.Data)
+	case domain.DocumentFormatCSV, domain.DocumentFormatMarkdown, domain.DocumentFormatText, domain.DocumentFormatCode:
+		text, err = plainText(file.Data)
+	default:
+		return nil, extraction.ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	document := e.truncate(strings.TrimSpace(text))
+	// Tables and code keep their layout in code blocks
+	switch format {
+	case domain.DocumentFormatCSV, domain.DocumentFormatCode:
+		document.Text = codeBlock(language(file.FileName, format), document.Text)
+	case domain.DocumentFormatDOCX, domain.DocumentFormatXLSX, domain.DocumentFormatMarkdown,
+		domain.DocumentFormatText:
+	}
+
+	return document, nil
+}
+
+// truncate cuts the text to the character limit, at the end of a line when there is one near it.
+func (e *Extractor) truncate(text string) *extraction.Document {
+	characters := utf8.RuneCountInString(text)
+	document := &extraction.Document{Text: text, Characters: characters}
+	if e.maxCharacters <= 0 || characters <= e.maxCharacters {
+		return document
+	}
+
+	cut := string([]rune(text)[:e.maxCharacters])
+	if i := strings.LastIndexByte(cut, '\n'); i > len(cut)*9/10 {
+		cut = cut[:i]
+	}
+	document.Text = strings.TrimSpace(cut)
+	document.Truncated = true
+	return document
+}
+
+// plainText decodes a text file. Files with NUL bytes are binary and aren't read, invalid UTF-8 is replaced.
+func plainText(data []byte) (string, error) {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", extraction.ErrUnsupported
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	text := strings.ToValidUTF8(string(data), "�")
+	return strings.ReplaceAll(text, "\r\n", "\n"), nil
+}
+
+// language returns the language of the code block a document is shown in, by its file name.
+func language(fileName string, format domain.DocumentFormat) string {
+	if extension := strings.TrimPrefix(strings.ToLower(path.Ext(fileName)), "."); extension != "" {
+		return extension
+	}
+	if format == domain.DocumentFormatCode {
+		return strings.ToLower(path.Base(fileName))
+	}
+	return string(format)
+}
+
+// codeBlock puts the text in a fenced code block, with a fence longer than any backtick run in it.
+func codeBlock(language, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + text + "\n" + fence
+}
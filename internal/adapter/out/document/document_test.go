@@ -0,0 +1,222 @@
+package document_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/internal/adapter/out/document"
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/extraction"
+)
+
+const (
+	docxMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	xlsxMimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	// oversized is more than the content read from a document or a part of it
+	oversized = 50<<20 + 1
+)
+
+const documentXML = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+	<w:p><w:r><w:t>Shopping list</w:t></w:r></w:p>
+	<w:p><w:r><w:t>Milk</w:t><w:tab/><w:t>2 l</w:t></w:r></w:p>
+	<w:tbl><w:tr>
+		<w:tc><w:p><w:r><w:t>Cats</w:t></w:r></w:p></w:tc>
+		<w:tc><w:p><w:r><w:t>3</w:t></w:r></w:p></w:tc>
+	</w:tr></w:tbl>
+	<w:p><w:r><w:t>Line</w:t><w:br/><w:t>break</w:t></w:r></w:p>
+</w:body></w:document>`
+
+// workbookParts are the parts of a workbook with a sheet of text cells and a sheet the package root points to.
+var workbookParts = map[string]string{
+	"xl/workbook.xml": `<workbook><sheets>
+		<sheet name="People" r:id="rId1" xmlns:r="r"/>
+		<sheet name="Numbers" r:id="rId2" xmlns:r="r"/>
+	</sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<Relationships>
+		<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+		<Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/>
+	</Relationships>`,
+	"xl/sharedStrings.xml": `<sst>
+		<si><t>Name</t></si>
+		<si><r><t>Tom</t></r><r><t> Cat</t></r></si>
+	</sst>`,
+	"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+		<row><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>Age</t></is></c></row>
+		<row><c r="A2" t="s"><v>1</v></c><c r="C2"><v>3</v></c><c r="D2" t="b"><v>1</v></c></row>
+	</sheetData></worksheet>`,
+	"xl/worksheets/sheet2.xml": `<worksheet><sheetData><row><c r="A1"><v>42</v></c></row></sheetData></worksheet>`,
+}
+
+// zipArchive packs the parts into an Office document.
+func zipArchive(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range parts {
+		part, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return archive.Bytes()
+}
+
+func withoutPart(parts map[string]string, name string) map[string]string {
+	kept := make(map[string]string, len(parts))
+	for partName, content := range parts {
+		if partName != name {
+			kept[partName] = content
+		}
+	}
+	return kept
+}
+
+func TestExtractor_Extract(t *testing.T) {
+	docx := zipArchive(t, map[string]string{"word/document.xml": documentXML})
+
+	tests := []struct {
+		name          string
+		maxCharacters int
+		file          domain.File
+		expected      *extraction.Document
+		expectedErr   error
+	}{
+		{
+			name:          "word document",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: docx, MimeType: docxMimeType, FileName: "list.docx"},
+			expected: &extraction.Document{
+				Text:       "Shopping list\nMilk\t2 l\nCats | 3 | \nLine\nbreak",
+				Characters: 45,
+			},
+		},
+		{
+			name:          "word document that is truncated at the end of a line",
+			maxCharacters: 24,
+			file:          domain.File{Data: docx, FileName: "list.docx"},
+			expected: &extraction.Document{
+				Text:       "Shopping list\nMilk\t2 l",
+				Characters: 45,
+				Truncated:  true,
+			},
+		},
+		{
+			name:          "word document without its body",
+			maxCharacters: document.DefaultMaxCharacters,
+			file: domain.File{
+				Data:     zipArchive(t, map[string]string{"word/styles.xml": "<w:styles/>"}),
+				FileName: "list.docx",
+			},
+			expectedErr: extraction.ErrUnsupported,
+		},
+		{
+			name:          "word document that was cut off",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: docx[:len(docx)/2], FileName: "list.docx"},
+			expectedErr:   extraction.ErrUnsupported,
+		},
+		{
+			name:          "word document whose body is too large once decompressed",
+			maxCharacters: document.DefaultMaxCharacters,
+			file: domain.File{
+				Data:     zipArchive(t, map[string]string{"word/document.xml": strings.Repeat(" ", oversized)}),
+				FileName: "list.docx",
+			},
+			expectedErr: extraction.ErrTooLarge,
+		},
+		{
+			name:          "workbook",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: zipArchive(t, workbookParts), MimeType: xlsxMimeType},
+			expected: &extraction.Document{
+				Text:       "Sheet: People\nName,Age\nTom Cat,,3,TRUE\n\nSheet: Numbers\n42",
+				Characters: 57,
+			},
+		},
+		{
+			name:          "workbook without shared strings",
+			maxCharacters: document.DefaultMaxCharacters,
+			file: domain.File{
+				Data:     zipArchive(t, withoutPart(workbookParts, "xl/sharedStrings.xml")),
+				FileName: "people.xlsx",
+			},
+			expected: &extraction.Document{
+				// Cells that refer to missing strings keep their index
+				Text:       "Sheet: People\n0,Age\n1,,3,TRUE\n\nSheet: Numbers\n42",
+				Characters: 48,
+			},
+		},
+		{
+			name:          "workbook without a sheet",
+			maxCharacters: document.DefaultMaxCharacters,
+			file: domain.File{
+				Data:     zipArchive(t, withoutPart(workbookParts, "xl/worksheets/sheet2.xml")),
+				FileName: "people.xlsx",
+			},
+			expectedErr: extraction.ErrUnsupported,
+		},
+		{
+			name:          "presentation isn't read",
+			maxCharacters: document.DefaultMaxCharacters,
+			file: domain.File{
+				Data:     zipArchive(t, map[string]string{"ppt/slides/slide1.xml": "<p:sld/>"}),
+				FileName: "slides.pptx",
+			},
+			expectedErr: extraction.ErrUnsupported,
+		},
+		{
+			name:          "CSV is shown in a code block",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: []byte("name,age\r\nTom,3\r\n"), FileName: "cats.csv"},
+			expected:      &extraction.Document{Text: "```csv\nname,age\nTom,3\n```", Characters: 14},
+		},
+		{
+			name:          "source file is shown in a code block with a longer fence than it has",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: []byte("// ```go\npackage main"), FileName: "main.go"},
+			expected: &extraction.Document{
+				Text:       "````go\n// ```go\npackage main\n````",
+				Characters: 21,
+			},
+		},
+		{
+			name:          "text without a line end near the limit is cut at the limit",
+			maxCharacters: 10,
+			file:          domain.File{Data: []byte("\xEF\xBB\xBFA very long line"), FileName: "notes.txt"},
+			expected:      &extraction.Document{Text: "A very lon", Characters: 16, Truncated: true},
+		},
+		{
+			name:          "binary file",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: []byte("MZ\x00\x01"), FileName: "notes.txt"},
+			expectedErr:   extraction.ErrUnsupported,
+		},
+		{
+			name:          "file that is too large",
+			maxCharacters: document.DefaultMaxCharacters,
+			file:          domain.File{Data: make([]byte, oversized), FileName: "notes.txt"},
+			expectedErr:   extraction.ErrTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extracted, err := document.New(tt.maxCharacters).Extract(t.Context(), tt.file)
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, extracted)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vladimish/talk/internal/port/extraction"
+)
+
+// docxText returns the text of a Word document: paragraphs on lines of their own and table rows
+// with their cells separated by "|".
+func docxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", extraction.ErrUnsupported, err)
+	}
+	body, err := readZipFile(archive, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	inRun, inText := false, false
+	cells := 0 // Depth of nested table cells
+	for {
+		token, tokenErr := decoder.Token()
+		if errors.Is(tokenErr, io.EOF) {
+			break
+		}
+		if tokenErr != nil {
+			return "", fmt.Errorf("failed to parse document: %w", tokenErr)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = true
+			case "t":
+				inText = inRun
+			case "tab":
+				if inRun {
+					text.WriteByte('\t')
+				}
+			case "br", "cr":
+				if inRun {
+					text.WriteByte('\n')
+				}
+			case "tc":
+				cells++
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			case "p":
+				if cells > 0 {
+					text.WriteByte(' ')
+				} else {
+					text.WriteByte('\n')
+				}
+			case "tc":
+				cells--
+				text.WriteString("| ")
+			case "tr":
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
+
+type workbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText is a string of a spreadsheet, plain or made of formatted runs.
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richText) String() string {
+	var text strings.Builder
+	text.WriteString(r.Text)
+	for _, run := range r.Runs {
+		text.WriteString(run.Text)
+	}
+	return text.String()
+}
+
+type sharedStrings struct {
+	Items []richText `xml:"si"`
+}
+
+type worksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// xlsxText returns the sheets of an Excel workbook as CSV, each under a line with its name.
+// Cells show their stored values, formulas their last results.
+func xlsxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", extraction.ErrUnsupported, err)
+	}
+
+	var book workbook
+	if err = unmarshalZipFile(archive, "xl/workbook.xml", &book); err != nil {
+		return "", err
+	}
+	var rels relationships
+	if err = unmarshalZipFile(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		targets[rel.ID] = rel.Target
+	}
+
+	// Workbooks without text cells have no shared strings
+	var shared sharedStrings
+	if err = unmarshalZipFile(archive, "xl/sharedStrings.xml", &shared); err != nil &&
+		!errors.Is(err, extraction.ErrUnsupported) {
+		return "", err
+	}
+	strs := make([]string, len(shared.Items))
+	for i, item := range shared.Items {
+		strs[i] = item.String()
+	}
+
+	var text strings.Builder
+	for _, sheet := range book.Sheets {
+		target, ok := targets[sheet.ID]
+		if !ok {
+			continue
+		}
+		// Targets are relative to the workbook unless they start at the root of the package
+		name := "xl/" + target
+		if strings.HasPrefix(target, "/") {
+			name = strings.TrimPrefix(target, "/")
+		}
+
+		var ws worksheet
+		if err = unmarshalZipFile(archive, name, &ws); err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&text, "Sheet: %s\n", sheet.Name)
+		writer := csv.NewWriter(&text)
+		for _, row := range ws.Rows {
+			var record []string
+			for _, cell := range row.Cells {
+				value := cell.Value
+				switch cell.Type {
+				case "s":
+					if i, convErr := strconv.Atoi(value); convErr == nil && i >= 0 && i < len(strs) {
+						value = strs[i]
+					}
+				case "inlineStr":
+					value = cell.Inline.String()
+				case "b":
+					value = strings.ToUpper(strconv.FormatBool(value == "1"))
+				}
+
+				// Empty cells aren't stored, so the cell reference tells the column
+				if column := cellColumn(cell.Ref); column > len(record) {
+					record = append(record, make([]string, column-len(record))...)
+				}
+				record = append(record, value)
+			}
+			if err = writer.Write(record); err != nil {
+				return "", fmt.Errorf("failed to write sheet: %w", err)
+			}
+		}
+		writer.Flush()
+		text.WriteByte('\n')
+	}
+
+	return text.String(), nil
+}
+
+// cellColumn returns the zero-based column of a cell reference like "C12", 0 when it has none.
+func cellColumn(ref string) int {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+	return max(column-1, 0)
+}
+
+// readZipFile reads a part of an Office document. Missing parts make the document unsupported.
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no %s", extraction.ErrUnsupported, name)
+	}
+	defer file.Close()
+
+	// The size in the archive can't be trusted, so the limit is checked on the decompressed content
+	data, err := io.ReadAll(io.LimitReader(file, maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxDocumentSize {
+		return nil, extraction.ErrTooLarge
+	}
+	return data, nil
+}
+
+func unmarshalZipFile(archive *zip.Reader, name string, v any) error {
+	data, err := readZipFile(archive, name)
+	if err != nil {
+		return err
+	}
+	if err = xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
@@ -78,6 +79,21 @@ func (fs *FileStorage) Upload(ctx context.Context, data []byte, mimeType string)
 	return objectName, nil
 }
 
+// Download returns the content of the given object.
+func (fs *FileStorage) Download(ctx context.Context, objectName string) ([]byte, error) {
+	object, err := fs.client.GetObject(ctx, fs.bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, nil
+}
+
 // GetPreSignedURL generates a pre-signed URL for the given object.
 func (fs *FileStorage) GetPreSignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
 	// Generate pre-signed URL
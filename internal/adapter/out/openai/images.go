@@ -0,0 +1,314 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+)
+
+// maxImageSize limits the images downloaded for a generation and the responses that carry them.
+const maxImageSize = 64 << 20
+
+// imageModalities asks OpenRouter for images along with the text of an answer.
+var imageModalities = []string{"image", "text"}
+
+var errNoImagePrompt = errors.New("no prompt to generate images from")
+
+// imageChatResponse is a chat completion of an OpenRouter image model.
+type imageChatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+			Images  []struct {
+				ImageURL struct {
+					URL string `json:"url"`
+				} `json:"image_url"`
+			} `json:"images"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *StreamUsage `json:"usage,omitempty"`
+}
+
+// imageGenerationRequest is a request to the images endpoint of an OpenAI-compatible API.
+type imageGenerationRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	N      int    `json:"n"`
+}
+
+// imagesResponse is the response of the images endpoints. Images come as base64 or as links,
+// depending on the model.
+type imagesResponse struct {
+	Data []struct {
+		B64JSON string `json:"b64_json"`
+		URL     string `json:"url"`
+	} `json:"data"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+}
+
+// GenerateImages generates images for the request. OpenRouter image models answer with chat completions
+// of the image modality, other APIs with their images endpoints: those take the text of the last user message
+// as the prompt and edit its images when it has any.
+func (o *Completion) GenerateImages(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (*imagegen.Result, error) {
+	if o.openRouter {
+		return o.generateChatImages(ctx, req)
+	}
+	return o.generateImages(ctx, req)
+}
+
+func (o *Completion) generateChatImages(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (*imagegen.Result, error) {
+	messages, err := chatMessages(req)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := ChatRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Modalities:  imageModalities,
+		Temperature: req.Parameters.Temperature,
+		Usage:       o.usageAccounting(),
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var resp imageChatResponse
+	if err = o.post(ctx, o.chatCompletionsURL(), "application/json", bytes.NewReader(jsonData), &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no choices in response")
+	}
+
+	message := resp.Choices[0].Message
+	result := &imagegen.Result{Text: message.Content}
+	for _, image := range message.Images {
+		generated, imageErr := o.imageFromURL(ctx, image.ImageURL.URL)
+		if imageErr != nil {
+			return nil, imageErr
+		}
+		result.Images = append(result.Images, generated)
+	}
+	if resp.Usage != nil {
+		result.Usage = resp.Usage.toCompletionUsage()
+	}
+
+	return result, nil
+}
+
+func (o *Completion) generateImages(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (*imagegen.Result, error) {
+	prompt, references := lastUserPrompt(req)
+	if prompt == "" {
+		return nil, errNoImagePrompt
+	}
+
+	var resp imagesResponse
+	if len(references) == 0 {
+		jsonData, err := json.Marshal(imageGenerationRequest{Model: req.Model, Prompt: prompt, N: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		err = o.post(ctx, o.baseURL+"/images/generations", "application/json", bytes.NewReader(jsonData), &resp)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		body, contentType, err := o.imageEditBody(ctx, req.Model, prompt, references)
+		if err != nil {
+			return nil, err
+		}
+		if err = o.post(ctx, o.baseURL+"/images/edits", contentType, body, &resp); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &imagegen.Result{}
+	for _, image := range resp.Data {
+		var generated imagegen.Image
+		if image.B64JSON != "" {
+			data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+			generated = imagegen.Image{Data: data, MimeType: http.DetectContentType(data)}
+		} else {
+			var err error
+			if generated, err = o.imageFromURL(ctx, image.URL); err != nil {
+				return nil, err
+			}
+		}
+		result.Images = append(result.Images, generated)
+	}
+	if resp.Usage != nil {
+		result.Usage = &completion.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+		}
+	}
+
+	return result, nil
+}
+
+// lastUserPrompt returns the text and the images of the last user message of a request.
+func lastUserPrompt(req completion.CompletionRequest) (string, []completion.Part) {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		msg := req.Messages[i]
+		if msg.Role != completion.RoleUser {
+			continue
+		}
+
+		var texts []string
+		var images []completion.Part
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case completion.PartTypeText:
+				texts = append(texts, part.Text)
+			case completion.PartTypeImage:
+				images = append(images, part)
+			case completion.PartTypeFile, completion.PartTypeAudio:
+			}
+		}
+		return strings.TrimSpace(strings.Join(texts, "\n\n")), images
+	}
+	return "", nil
+}
+
+// imageEditBody builds the form of an image edit request and returns it with its content type.
+// A single image goes as "image", several as "image[]", which only some models take.
+func (o *Completion) imageEditBody(
+	ctx context.Context,
+	model string,
+	prompt string,
+	references []completion.Part,
+) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("model", model); err != nil {
+		return nil, "", fmt.Errorf("failed to write model field: %w", err)
+	}
+	if err := writer.WriteField("prompt", prompt); err != nil {
+		return nil, "", fmt.Errorf("failed to write prompt field: %w", err)
+	}
+
+	field := "image"
+	if len(references) > 1 {
+		field = "image[]"
+	}
+	for i, reference := range references {
+		image := imagegen.Image{Data: reference.Data, MimeType: reference.MimeType}
+		if len(image.Data) == 0 {
+			var err error
+			if image, err = o.imageFromURL(ctx, reference.URL); err != nil {
+				return nil, "", err
+			}
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename="image%d"`, field, i+1))
+		header.Set("Content-Type", image.MimeType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create image part: %w", err)
+		}
+		if _, err = part.Write(image.Data); err != nil {
+			return nil, "", fmt.Errorf("failed to write image: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close form: %w", err)
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
+
+// imageFromURL returns the image of a data URL, or downloads the image of a link.
+func (o *Completion) imageFromURL(ctx context.Context, imageURL string) (imagegen.Image, error) {
+	if header, payload, found := strings.Cut(imageURL, ","); found && strings.HasPrefix(header, "data:") {
+		mimeType, isBase64 := strings.CutSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+		if !isBase64 {
+			return imagegen.Image{}, errors.New("image data URL is not base64")
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return imagegen.Image{}, fmt.Errorf("failed to decode image: %w", err)
+		}
+		return imagegen.Image{Data: data, MimeType: mimeType}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return imagegen.Image{}, fmt.Errorf("failed to create image request: %w", err)
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return imagegen.Image{}, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= httpErrorThreshold {
+		return imagegen.Image{}, fmt.Errorf("failed to download image: HTTP error: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return imagegen.Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	return imagegen.Image{Data: data, MimeType: http.DetectContentType(data)}, nil
+}
+
+// post sends a request that isn't streamed and decodes its JSON response into result.
+func (o *Completion) post(ctx context.Context, url, contentType string, body io.Reader, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	o.setHeaders(req)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= httpErrorThreshold {
+		return fmt.Errorf("HTTP error: %d - Response: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if err = json.Unmarshal(bodyBytes, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
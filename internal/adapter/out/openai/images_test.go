@@ -0,0 +1,282 @@
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+// pngData starts like a PNG file, so its content type is detected.
+var pngData = []byte("\x89PNG\r\n\x1a\nkitten")
+
+func newTestClient() *resilience.Client {
+	return resilience.NewClient("images", slog.Default(), resilience.Config{})
+}
+
+func userPrompt(parts ...completion.Part) completion.CompletionRequest {
+	return completion.CompletionRequest{
+		Model:    "gpt-image-1",
+		Messages: []completion.Message{{Role: completion.RoleUser, Parts: parts}},
+	}
+}
+
+func TestCompletion_GenerateImages_OpenRouter(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/cat.png":
+			_, _ = w.Write(pngData)
+		case "/chat/completions":
+			assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+			var body ChatRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "google/gemini-2.5-flash-image-preview", body.Model)
+			assert.Equal(t, []string{"image", "text"}, body.Modalities)
+			assert.Equal(t, map[string]interface{}{"include": true}, body.Usage)
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"choices": [{"message": {
+					"content": "Here is a cat",
+					"images": [
+						{"image_url": {"url": "data:image/webp;base64,` + base64.StdEncoding.EncodeToString([]byte("webp")) + `"}},
+						{"image_url": {"url": "` + server.URL + `/files/cat.png"}}
+					]
+				}}],
+				"usage": {
+					"prompt_tokens": 12,
+					"completion_tokens": 1290,
+					"cost": 0.04,
+					"completion_tokens_details": {"reasoning_tokens": 3}
+				}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOpenAICompletion("key", newTestClient())
+	client.baseURL = server.URL
+
+	req := userPrompt(completion.Part{Type: completion.PartTypeText, Text: "Draw a cat"})
+	req.Model = "google/gemini-2.5-flash-image-preview"
+	result, err := client.GenerateImages(t.Context(), req)
+
+	require.NoError(t, err)
+	cost := 0.04
+	assert.Equal(t, &imagegen.Result{
+		Text: "Here is a cat",
+		Images: []imagegen.Image{
+			{Data: []byte("webp"), MimeType: "image/webp"},
+			{Data: pngData, MimeType: "image/png"},
+		},
+		Usage: &completion.Usage{PromptTokens: 12, CompletionTokens: 1290, ReasoningTokens: 3, Cost: &cost},
+	}, result)
+}
+
+func TestCompletion_GenerateImages_Compatible(t *testing.T) {
+	encodedPNG := base64.StdEncoding.EncodeToString(pngData)
+
+	tests := []struct {
+		name string
+		req  completion.CompletionRequest
+		// expectedPath is the endpoint the request goes to
+		expectedPath string
+		// expectedImages are the names and contents of the image parts of an edit form
+		expectedImages map[string][]string
+		status         int
+		// response is what the server answers, {server} is replaced by its URL
+		response       string
+		expectedResult *imagegen.Result
+		// expectedErr is part of the error, empty when the images are generated
+		expectedErr string
+	}{
+		{
+			name:         "prompt without images is generated",
+			req:          userPrompt(completion.Part{Type: completion.PartTypeText, Text: "Draw a cat"}),
+			expectedPath: "/images/generations",
+			status:       http.StatusOK,
+			response:     `{"data": [{"b64_json": "` + encodedPNG + `"}], "usage": {"input_tokens": 7, "output_tokens": 4160}}`,
+			expectedResult: &imagegen.Result{
+				Images: []imagegen.Image{{Data: pngData, MimeType: "image/png"}},
+				Usage:  &completion.Usage{PromptTokens: 7, CompletionTokens: 4160},
+			},
+		},
+		{
+			name: "prompt with an image is an edit of it",
+			req: userPrompt(
+				completion.Part{Type: completion.PartTypeImage, Data: []byte("cat"), MimeType: "image/jpeg"},
+				completion.Part{Type: completion.PartTypeText, Text: "Add a hat"},
+			),
+			expectedPath:   "/images/edits",
+			expectedImages: map[string][]string{"image": {"cat"}},
+			status:         http.StatusOK,
+			response:       `{"data": [{"url": "{server}/files/cat.png"}]}`,
+			expectedResult: &imagegen.Result{Images: []imagegen.Image{{Data: pngData, MimeType: "image/png"}}},
+		},
+		{
+			name: "prompt with several images sends them as a list",
+			req: userPrompt(
+				completion.Part{Type: completion.PartTypeImage, Data: []byte("cat"), MimeType: "image/jpeg"},
+				completion.Part{
+					Type: completion.PartTypeImage,
+					URL:  "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("hat")),
+				},
+				completion.Part{Type: completion.PartTypeText, Text: "Put the hat on the cat"},
+			),
+			expectedPath:   "/images/edits",
+			expectedImages: map[string][]string{"image[]": {"cat", "hat"}},
+			status:         http.StatusOK,
+			response:       `{"data": [{"b64_json": "` + encodedPNG + `"}]}`,
+			expectedResult: &imagegen.Result{Images: []imagegen.Image{{Data: pngData, MimeType: "image/png"}}},
+		},
+		{
+			name:        "prompt without text",
+			req:         userPrompt(completion.Part{Type: completion.PartTypeImage, Data: []byte("cat")}),
+			expectedErr: errNoImagePrompt.Error(),
+		},
+		{
+			name:         "error status",
+			req:          userPrompt(completion.Part{Type: completion.PartTypeText, Text: "Draw a cat"}),
+			expectedPath: "/images/generations",
+			status:       http.StatusBadRequest,
+			response:     `{"error": {"message": "Your request was rejected by the safety system."}}`,
+			expectedErr:  "HTTP error: 400",
+		},
+		{
+			name:         "image that isn't base64",
+			req:          userPrompt(completion.Part{Type: completion.PartTypeText, Text: "Draw a cat"}),
+			expectedPath: "/images/generations",
+			status:       http.StatusOK,
+			response:     `{"data": [{"b64_json": "not base64!"}]}`,
+			expectedErr:  "failed to decode image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/files/cat.png" {
+					_, _ = w.Write(pngData)
+					return
+				}
+
+				assert.Equal(t, tt.expectedPath, r.URL.Path)
+				assert.Empty(t, r.Header.Get("Authorization"))
+				switch r.URL.Path {
+				case "/images/generations":
+					var body imageGenerationRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Equal(t, imageGenerationRequest{Model: "gpt-image-1", Prompt: "Draw a cat", N: 1}, body)
+				case "/images/edits":
+					assert.NoError(t, r.ParseMultipartForm(1<<20))
+					assert.Equal(t, "gpt-image-1", r.FormValue("model"))
+					assert.NotEmpty(t, r.FormValue("prompt"))
+
+					images := make(map[string][]string)
+					for field, headers := range r.MultipartForm.File {
+						for _, header := range headers {
+							file, err := header.Open()
+							if !assert.NoError(t, err) {
+								continue
+							}
+							data, err := io.ReadAll(file)
+							assert.NoError(t, err)
+							_ = file.Close()
+							images[field] = append(images[field], string(data))
+						}
+					}
+					assert.Equal(t, tt.expectedImages, images)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(strings.ReplaceAll(tt.response, "{server}", server.URL)))
+			}))
+			defer server.Close()
+
+			client := NewCompatibleCompletion("openai", server.URL+"/", "", newTestClient())
+
+			result, err := client.GenerateImages(t.Context(), tt.req)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestCompletion_imageFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cat.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(pngData)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedImage imagegen.Image
+		// expectedErr is part of the error, empty when the image is returned
+		expectedErr string
+	}{
+		{
+			name:          "data URL",
+			url:           "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString([]byte("cat")),
+			expectedImage: imagegen.Image{Data: []byte("cat"), MimeType: "image/jpeg"},
+		},
+		{
+			name:        "data URL that isn't base64",
+			url:         "data:image/svg+xml,<svg></svg>",
+			expectedErr: "image data URL is not base64",
+		},
+		{
+			name:        "data URL with broken base64",
+			url:         "data:image/png;base64,not base64!",
+			expectedErr: "failed to decode image",
+		},
+		{
+			name:          "link is downloaded",
+			url:           server.URL + "/cat.png",
+			expectedImage: imagegen.Image{Data: pngData, MimeType: "image/png"},
+		},
+		{
+			name:        "link that isn't found",
+			url:         server.URL + "/dog.png",
+			expectedErr: "HTTP error: 404",
+		},
+	}
+
+	client := NewCompatibleCompletion("openai", server.URL, "", newTestClient())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := client.imageFromURL(t.Context(), tt.url)
+
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedImage, image)
+		})
+	}
+}
@@ -13,18 +13,20 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/sashabaranov/go-openai"
 	"github.com/vladimish/talk/internal/domain"
 	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/pkg/resilience"
 )
 
 const (
 	httpErrorThreshold = 400
 	maxFileNameLength  = 100
+
+	openRouterURL = "https://openrouter.ai/api/v1"
 )
 
 // sanitizeFilename removes problematic characters from filenames for OpenRouter.
-func sanitizeFilename(filename string) string {
+func sanitizeFilename(filename string, mimeType string) string {
 	// Replace non-ASCII characters, spaces, and special characters with underscores
 	reg := regexp.MustCompile(`[^\w\-.]`)
 	sanitized := reg.ReplaceAllString(filename, "_")
@@ -39,8 +41,8 @@ func sanitizeFilename(filename string) string {
 		sanitized = sanitized[:maxFileNameLength-len(ext)] + ext
 	}
 
-	// Ensure it has a .pdf extension
-	if !strings.HasSuffix(strings.ToLower(sanitized), ".pdf") {
+	// Ensure PDFs have a .pdf extension
+	if mimeType == "application/pdf" && !strings.HasSuffix(strings.ToLower(sanitized), ".pdf") {
 		sanitized += ".pdf"
 	}
 
@@ -48,285 +50,76 @@ func sanitizeFilename(filename string) string {
 }
 
 type Completion struct {
-	client *openai.Client
-	logger *slog.Logger
-	apiKey string
+	logger  *slog.Logger
+	apiKey  string
+	baseURL string
+	// provider is the name the models served by this client are configured with in the catalog.
+	provider string
+	// openRouter enables the OpenRouter extensions: plugins and usage accounting.
+	openRouter bool
+	httpClient *resilience.Client
 }
 
-func NewOpenAICompletion(apiKey string) *Completion {
-	config := openai.DefaultConfig(apiKey)
-	config.BaseURL = "https://openrouter.ai/api/v1"
-
+// NewOpenAICompletion creates a completion client for OpenRouter.
+func NewOpenAICompletion(apiKey string, httpClient *resilience.Client) *Completion {
 	return &Completion{
-		client: openai.NewClientWithConfig(config),
-		logger: slog.Default(),
-		apiKey: apiKey,
+		logger:     slog.Default(),
+		apiKey:     apiKey,
+		baseURL:    openRouterURL,
+		provider:   domain.ProviderOpenRouter,
+		openRouter: true,
+		httpClient: httpClient,
 	}
 }
 
-// supportsReasoning checks if a model supports reasoning tokens.
-func (o *Completion) supportsReasoning(model string) bool {
-	modelInfo := domain.GetModelByID(model)
-	if modelInfo == nil {
-		return false
+// NewCompatibleCompletion creates a completion client for an OpenAI-compatible API: the OpenAI API itself
+// or a self-hosted server like Ollama or vLLM. The API key may be empty for servers that don't check it.
+// Web search isn't available through these APIs and is ignored.
+func NewCompatibleCompletion(
+	provider string,
+	baseURL string,
+	apiKey string,
+	httpClient *resilience.Client,
+) *Completion {
+	return &Completion{
+		logger:     slog.Default(),
+		apiKey:     apiKey,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		provider:   provider,
+		httpClient: httpClient,
 	}
-	return modelInfo.Reasoning
 }
 
-func (o *Completion) CompleteStream(
-	ctx context.Context,
-	model string,
-	systemPrompt string,
-	messages []*domain.Message,
-	currentImageURL string,
-	webSearchEnabled bool,
-) (<-chan completion.StreamToken, error) {
-	// Convert domain messages to OpenAI format
-	openaiMessages := make([]openai.ChatCompletionMessage, 0, len(messages)+1)
-
-	// Add system prompt if provided
-	if systemPrompt != "" {
-		openaiMessages = append(openaiMessages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
-		})
-	}
-
-	// Convert domain messages
-	for _, msg := range messages {
-		role := openai.ChatMessageRoleUser
-		if msg.SentBy == domain.MessageSenderBot {
-			role = openai.ChatMessageRoleAssistant
-		}
-
-		openaiMessages = append(openaiMessages, openai.ChatCompletionMessage{
-			Role:    role,
-			Content: msg.MessageType.Text,
-		})
-	}
-
-	// If there's an image URL, modify the last user message to include it
-	if currentImageURL != "" && len(openaiMessages) > 0 {
-		// Find the last user message and convert it to multipart content
-		for i := len(openaiMessages) - 1; i >= 0; i-- {
-			if openaiMessages[i].Role == openai.ChatMessageRoleUser {
-				// Convert to multipart content
-				openaiMessages[i].MultiContent = []openai.ChatMessagePart{
-					{
-						Type: openai.ChatMessagePartTypeText,
-						Text: openaiMessages[i].Content,
-					},
-					{
-						Type: openai.ChatMessagePartTypeImageURL,
-						ImageURL: &openai.ChatMessageImageURL{
-							URL: currentImageURL,
-						},
-					},
-				}
-				openaiMessages[i].Content = "" // Clear content when using MultiContent
-				break
-			}
-		}
-	}
-
-	req := openai.ChatCompletionRequest{
-		Model:    model,
-		Messages: openaiMessages,
-		Stream:   true,
-	}
-
-	// Handle reasoning models with custom request
-	if o.supportsReasoning(model) {
-		return o.createStreamWithReasoning(ctx, model, openaiMessages, webSearchEnabled)
-	}
-
-	// Handle web search plugin with custom request
-	if webSearchEnabled {
-		return o.createStreamWithPlugins(ctx, openaiMessages, systemPrompt)
-	}
-
-	stream, err := o.client.CreateChatCompletionStream(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create completion stream: %w", err)
-	}
-
-	tokenChan := make(chan completion.StreamToken)
-
-	go func() {
-		defer close(tokenChan)
-		defer stream.Close()
-
-		for {
-			response, recvErr := stream.Recv()
-			if recvErr != nil {
-				if recvErr.Error() == "EOF" {
-					// Stream ended normally
-					return
-				}
-				select {
-				case tokenChan <- completion.StreamToken{Error: fmt.Errorf("stream error: %w", recvErr)}:
-				case <-ctx.Done():
-				}
-				return
-			}
-
-			// Send content if available
-			if len(response.Choices) > 0 && response.Choices[0].Delta.Content != "" {
-				select {
-				case tokenChan <- completion.StreamToken{Content: response.Choices[0].Delta.Content}:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-	}()
-
-	return tokenChan, nil
+// ChatRequest is a chat completion request.
+type ChatRequest struct {
+	Model               string                   `json:"model"`
+	Messages            []ChatMessage            `json:"messages"`
+	Stream              bool                     `json:"stream"`
+	Modalities          []string                 `json:"modalities,omitempty"`
+	MaxTokens           int                      `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int                      `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64                 `json:"temperature,omitempty"`
+	Reasoning           map[string]interface{}   `json:"reasoning,omitempty"`
+	ReasoningEffort     string                   `json:"reasoning_effort,omitempty"`
+	Plugins             []map[string]interface{} `json:"plugins,omitempty"`
+	Usage               map[string]interface{}   `json:"usage,omitempty"`
+	StreamOptions       map[string]interface{}   `json:"stream_options,omitempty"`
 }
 
-func (o *Completion) CompleteStreamWithAttachments(
-	ctx context.Context,
-	model string,
-	systemPrompt string,
-	messages []*domain.Message,
-	imageAttachment *completion.FileAttachment,
-	pdfAttachment *completion.FileAttachment,
-	webSearchEnabled bool,
-) (<-chan completion.StreamToken, error) {
-	// For backwards compatibility, if there's no PDF attachment, use the old method
-	if pdfAttachment == nil {
-		imageURL := ""
-		if imageAttachment != nil {
-			imageURL = imageAttachment.URL
-		}
-		return o.CompleteStream(ctx, model, systemPrompt, messages, imageURL, webSearchEnabled)
-	}
-
-	// Convert domain messages to OpenAI format
-	openaiMessages := make([]openai.ChatCompletionMessage, 0, len(messages)+1)
-
-	// Add system prompt if provided
-	if systemPrompt != "" {
-		openaiMessages = append(openaiMessages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
-		})
-	}
-
-	// Convert domain messages
-	for _, msg := range messages {
-		role := openai.ChatMessageRoleUser
-		if msg.SentBy == domain.MessageSenderBot {
-			role = openai.ChatMessageRoleAssistant
-		}
-
-		openaiMessages = append(openaiMessages, openai.ChatCompletionMessage{
-			Role:    role,
-			Content: msg.MessageType.Text,
-		})
-	}
-
-	// If there's a PDF, we need to use custom request format for OpenRouter
-	if len(openaiMessages) > 0 {
-		// Find the last user message and convert it to multipart content
-		for i := len(openaiMessages) - 1; i >= 0; i-- {
-			if openaiMessages[i].Role == openai.ChatMessageRoleUser {
-				// For PDF, we'll need to use the custom request format
-				return o.createStreamWithPDF(ctx, openaiMessages, pdfAttachment, webSearchEnabled)
-			}
-		}
-	}
-
-	// If no PDF but has image, use standard multipart format
-	if imageAttachment != nil && len(openaiMessages) > 0 {
-		// Find the last user message and convert it to multipart content
-		for i := len(openaiMessages) - 1; i >= 0; i-- {
-			if openaiMessages[i].Role == openai.ChatMessageRoleUser {
-				// Convert to multipart content
-				openaiMessages[i].MultiContent = []openai.ChatMessagePart{
-					{
-						Type: openai.ChatMessagePartTypeText,
-						Text: openaiMessages[i].Content,
-					},
-					{
-						Type: openai.ChatMessagePartTypeImageURL,
-						ImageURL: &openai.ChatMessageImageURL{
-							URL: imageAttachment.URL,
-						},
-					},
-				}
-				openaiMessages[i].Content = "" // Clear content when using MultiContent
-				break
-			}
-		}
-	}
-
-	req := openai.ChatCompletionRequest{
-		Model:    model,
-		Messages: openaiMessages,
-		Stream:   true,
-	}
-
-	// Handle reasoning models with custom request
-	if o.supportsReasoning(model) {
-		return o.createStreamWithReasoning(ctx, model, openaiMessages, webSearchEnabled)
-	}
-
-	// Handle web search plugin with custom request
-	if webSearchEnabled {
-		return o.createStreamWithPlugins(ctx, openaiMessages, systemPrompt)
-	}
-
-	stream, err := o.client.CreateChatCompletionStream(ctx, req)
-	if err != nil {
-		// Log the full error for debugging, especially HTTP errors
-		o.logger.ErrorContext(ctx, "Failed to create completion stream",
-			"error", err.Error(),
-			"model", model,
-			"messages_count", len(openaiMessages))
-		return nil, fmt.Errorf("failed to create completion stream: %w", err)
-	}
-
-	tokenChan := make(chan completion.StreamToken)
-
-	go func() {
-		defer close(tokenChan)
-		defer stream.Close()
-
-		for {
-			response, recvErr := stream.Recv()
-			if recvErr != nil {
-				if recvErr.Error() == "EOF" {
-					// Stream ended normally
-					return
-				}
-				select {
-				case tokenChan <- completion.StreamToken{Error: fmt.Errorf("stream error: %w", recvErr)}:
-				case <-ctx.Done():
-				}
-				return
-			}
-
-			// Send content if available
-			if len(response.Choices) > 0 && response.Choices[0].Delta.Content != "" {
-				select {
-				case tokenChan <- completion.StreamToken{Content: response.Choices[0].Delta.Content}:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-	}()
-
-	return tokenChan, nil
+// ChatMessage is a message of a chat completion request. Its content is a string for plain text messages,
+// which every compatible server understands, and a list of ContentPart otherwise.
+type ChatMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
 }
 
-// CustomRequest represents a request with plugins support.
-type CustomRequest struct {
-	Model    string                         `json:"model"`
-	Messages []openai.ChatCompletionMessage `json:"messages"`
-	Stream   bool                           `json:"stream"`
-	Plugins  []map[string]interface{}       `json:"plugins"`
+// ContentPart is a part of a multipart message.
+type ContentPart struct {
+	Type       string                 `json:"type"`
+	Text       string                 `json:"text,omitempty"`
+	ImageURL   map[string]interface{} `json:"image_url,omitempty"`
+	File       map[string]interface{} `json:"file,omitempty"`
+	InputAudio map[string]interface{} `json:"input_audio,omitempty"`
 }
 
 // CustomStreamResponse represents the streaming response from OpenRouter.
@@ -337,281 +130,223 @@ type CustomStreamResponse struct {
 			Reasoning string `json:"reasoning,omitempty"`
 		} `json:"delta"`
 	} `json:"choices"`
+	Usage *StreamUsage `json:"usage,omitempty"`
 }
 
-// ReasoningRequest represents a request with reasoning support.
-type ReasoningRequest struct {
-	Model     string                         `json:"model"`
-	Messages  []openai.ChatCompletionMessage `json:"messages"`
-	Stream    bool                           `json:"stream"`
-	Reasoning map[string]interface{}         `json:"reasoning,omitempty"`
-	Plugins   []map[string]interface{}       `json:"plugins,omitempty"`
+// StreamUsage is the usage OpenRouter reports in the last chunk of a stream.
+type StreamUsage struct {
+	PromptTokens            int      `json:"prompt_tokens"`
+	CompletionTokens        int      `json:"completion_tokens"`
+	Cost                    *float64 `json:"cost,omitempty"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details,omitempty"`
 }
 
-func (o *Completion) createStreamWithReasoning(
-	ctx context.Context,
-	model string,
-	messages []openai.ChatCompletionMessage,
-	webSearchEnabled bool,
-) (<-chan completion.StreamToken, error) {
-	// Create request with reasoning configuration
-	reqBody := ReasoningRequest{
-		Model:    model,
-		Messages: messages,
-		Stream:   true,
-		Reasoning: map[string]interface{}{
-			"effort": "high", // Use high effort for better quality reasoning
-		},
+func (u *StreamUsage) toCompletionUsage() *completion.Usage {
+	usage := &completion.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		Cost:             u.Cost,
 	}
-
-	// Add plugins if needed
-	if webSearchEnabled {
-		reqBody.Plugins = []map[string]interface{}{
-			{"id": "web"},
-		}
+	if u.CompletionTokensDetails != nil {
+		usage.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
 	}
+	return usage
+}
 
-	jsonData, err := json.Marshal(reqBody)
+// CompleteStream sends the request as a single chat completion for the model the request is for.
+func (o *Completion) CompleteStream(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (<-chan completion.StreamToken, error) {
+	messages, err := chatMessages(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal reasoning request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		"https://openrouter.ai/api/v1/chat/completions",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create reasoning request: %w", err)
+	reqBody := ChatRequest{
+		Model:         req.Model,
+		Messages:      messages,
+		Stream:        true,
+		Temperature:   req.Parameters.Temperature,
+		Plugins:       o.plugins(req.Plugins),
+		Usage:         o.usageAccounting(),
+		StreamOptions: o.streamOptions(),
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req) //nolint:bodyclose // body is closed in the goroutine defer
-	if err != nil {
-		return nil, fmt.Errorf("failed to send reasoning request: %w", err)
+	// OpenRouter takes its own fields, the OpenAI API the ones of its reasoning models
+	if o.openRouter {
+		reqBody.MaxTokens = req.Parameters.MaxTokens
+		if req.Parameters.Reasoning != completion.ReasoningNone {
+			reqBody.Reasoning = map[string]interface{}{"effort": string(req.Parameters.Reasoning)}
+		}
+	} else {
+		reqBody.MaxCompletionTokens = req.Parameters.MaxTokens
+		reqBody.ReasoningEffort = string(req.Parameters.Reasoning)
 	}
 
-	// Check for HTTP errors
-	if resp.StatusCode >= httpErrorThreshold {
-		// Read response body for error details
-		bodyBytes, readErr := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
+	return o.createStream(ctx, reqBody, req.Parameters.Reasoning != completion.ReasoningNone)
+}
 
-		var errorMsg string
-		if readErr != nil {
-			errorMsg = fmt.Sprintf("HTTP error: %d (failed to read response body: %v)", resp.StatusCode, readErr)
-		} else {
-			errorMsg = fmt.Sprintf("HTTP error: %d - Response: %s", resp.StatusCode, string(bodyBytes))
-		}
+// chatMessages converts the system prompt and the messages of a request to the chat completion format.
+func chatMessages(req completion.CompletionRequest) ([]ChatMessage, error) {
+	messages := make([]ChatMessage, 0, len(req.Messages)+1)
 
-		return nil, fmt.Errorf("%s", errorMsg)
+	if req.SystemPrompt != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: req.SystemPrompt})
 	}
 
-	tokenChan := make(chan completion.StreamToken)
+	for _, msg := range req.Messages {
+		if len(msg.Parts) == 1 && msg.Parts[0].Type == completion.PartTypeText {
+			messages = append(messages, ChatMessage{Role: string(msg.Role), Content: msg.Parts[0].Text})
+			continue
+		}
 
-	go func() {
-		defer close(tokenChan)
-		defer func() {
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				o.logger.Error("failed to close response body", "error", closeErr)
+		parts := make([]ContentPart, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			contentPart, err := toContentPart(part)
+			if err != nil {
+				return nil, err
 			}
-		}()
-
-		o.processReasoningStreamResponse(ctx, resp, tokenChan)
-	}()
+			parts = append(parts, contentPart)
+		}
+		messages = append(messages, ChatMessage{Role: string(msg.Role), Content: parts})
+	}
 
-	return tokenChan, nil
+	return messages, nil
 }
 
-func (o *Completion) createStreamWithPlugins(
-	ctx context.Context,
-	messages []openai.ChatCompletionMessage,
-	_ string, // systemPrompt is unused in this implementation
-) (<-chan completion.StreamToken, error) {
-	// Create custom request with plugins
-	reqBody := CustomRequest{
-		Model:    "openrouter/auto",
-		Messages: messages,
-		Stream:   true,
-		Plugins: []map[string]interface{}{
-			{"id": "web"},
-		},
+// toContentPart converts a message part. Files go as data URLs, or as URLs when there is no data.
+func toContentPart(part completion.Part) (ContentPart, error) {
+	switch part.Type {
+	case completion.PartTypeText:
+		return ContentPart{Type: "text", Text: part.Text}, nil
+	case completion.PartTypeImage:
+		return ContentPart{
+			Type:     "image_url",
+			ImageURL: map[string]interface{}{"url": partURL(part)},
+		}, nil
+	case completion.PartTypeFile:
+		return ContentPart{
+			Type: "file",
+			File: map[string]interface{}{
+				"filename":  sanitizeFilename(part.FileName, part.MimeType),
+				"file_data": partURL(part),
+			},
+		}, nil
+	case completion.PartTypeAudio:
+		if len(part.Data) == 0 {
+			return ContentPart{}, fmt.Errorf("audio %q has no data", part.FileName)
+		}
+		return ContentPart{
+			Type: "input_audio",
+			InputAudio: map[string]interface{}{
+				"data":   base64.StdEncoding.EncodeToString(part.Data),
+				"format": audioFormat(part.MimeType),
+			},
+		}, nil
+	default:
+		return ContentPart{}, fmt.Errorf("unsupported message part type %q", part.Type)
 	}
+}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+// partURL returns the data URL of a part with data, otherwise its URL.
+func partURL(part completion.Part) string {
+	if len(part.Data) == 0 {
+		return part.URL
 	}
+	return fmt.Sprintf("data:%s;base64,%s", part.MimeType, base64.StdEncoding.EncodeToString(part.Data))
+}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		"https://openrouter.ai/api/v1/chat/completions",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// audioFormat returns the input audio format of a MIME type, "mp3" or "wav".
+func audioFormat(mimeType string) string {
+	if strings.Contains(mimeType, "wav") {
+		return "wav"
 	}
+	return "mp3"
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req) //nolint:bodyclose // body is closed in the goroutine defer
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+// plugins returns the OpenRouter plugins of the request options.
+func (o *Completion) plugins(options completion.Plugins) []map[string]interface{} {
+	if !o.openRouter {
+		return nil
 	}
 
-	// Check for HTTP errors
-	if resp.StatusCode >= httpErrorThreshold {
-		// Read response body for error details
-		bodyBytes, readErr := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-
-		var errorMsg string
-		if readErr != nil {
-			errorMsg = fmt.Sprintf("HTTP error: %d (failed to read response body: %v)", resp.StatusCode, readErr)
-		} else {
-			errorMsg = fmt.Sprintf("HTTP error: %d - Response: %s", resp.StatusCode, string(bodyBytes))
-		}
-
-		return nil, fmt.Errorf("%s", errorMsg)
+	var plugins []map[string]interface{}
+	if options.WebSearch {
+		plugins = append(plugins, map[string]interface{}{"id": "web"})
 	}
+	if options.PDFEngine != completion.PDFEngineDefault {
+		plugins = append(plugins, map[string]interface{}{
+			"id":  "file-parser",
+			"pdf": map[string]string{"engine": string(options.PDFEngine)},
+		})
+	}
+	return plugins
+}
 
-	tokenChan := make(chan completion.StreamToken)
-
-	go func() {
-		defer close(tokenChan)
-		defer func() {
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				o.logger.Error("failed to close response body", "error", closeErr)
-			}
-		}()
-
-		o.processStreamResponse(ctx, resp, tokenChan)
-	}()
-
-	return tokenChan, nil
+// usageAccounting asks OpenRouter to report the token counts and the cost in the last chunk of a stream.
+func (o *Completion) usageAccounting() map[string]interface{} {
+	if !o.openRouter {
+		return nil
+	}
+	return map[string]interface{}{"include": true}
 }
 
-// CustomMessageContent represents custom message content for PDF support.
-type CustomMessageContent struct {
-	Type string                 `json:"type"`
-	Text string                 `json:"text,omitempty"`
-	File map[string]interface{} `json:"file,omitempty"`
+// streamOptions asks other OpenAI-compatible APIs to report the token counts in the last chunk of a stream.
+func (o *Completion) streamOptions() map[string]interface{} {
+	if o.openRouter {
+		return nil
+	}
+	return map[string]interface{}{"include_usage": true}
 }
 
-// CustomMessage represents a message with custom content format.
-type CustomMessage struct {
-	Role    string                 `json:"role"`
-	Content []CustomMessageContent `json:"content"`
+// chatCompletionsURL returns the chat completions endpoint of the API.
+func (o *Completion) chatCompletionsURL() string {
+	return o.baseURL + "/chat/completions"
 }
 
-// CustomRequestWithPDF represents a request with PDF file support.
-type CustomRequestWithPDF struct {
-	Model    string                   `json:"model"`
-	Messages []CustomMessage          `json:"messages"`
-	Stream   bool                     `json:"stream"`
-	Plugins  []map[string]interface{} `json:"plugins,omitempty"`
+// setHeaders sets the content type and the authorization of a request.
+func (o *Completion) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
 }
 
-func (o *Completion) createStreamWithPDF(
+// createStream sends a streaming chat completion request. Reasoning is passed on only when it was requested.
+func (o *Completion) createStream(
 	ctx context.Context,
-	messages []openai.ChatCompletionMessage,
-	pdfAttachment *completion.FileAttachment,
-	webSearchEnabled bool,
+	reqBody ChatRequest,
+	reasoning bool,
 ) (<-chan completion.StreamToken, error) {
-	// Convert OpenAI messages to custom format with PDF support
-	customMessages := make([]CustomMessage, 0, len(messages))
-
-	for i, msg := range messages {
-		// For the last user message, add PDF attachment
-		if i == len(messages)-1 && msg.Role == openai.ChatMessageRoleUser && pdfAttachment != nil {
-			// Convert PDF data to base64 data URL
-			base64Data := base64.StdEncoding.EncodeToString(pdfAttachment.Data)
-			dataURL := fmt.Sprintf("data:application/pdf;base64,%s", base64Data)
-
-			// Sanitize filename for OpenRouter
-			sanitizedFilename := sanitizeFilename(pdfAttachment.FileName)
-
-			customMessages = append(customMessages, CustomMessage{
-				Role: msg.Role,
-				Content: []CustomMessageContent{
-					{
-						Type: "text",
-						Text: msg.Content,
-					},
-					{
-						Type: "file",
-						File: map[string]interface{}{
-							"filename":  sanitizedFilename,
-							"file_data": dataURL,
-						},
-					},
-				},
-			})
-		} else {
-			// Regular message
-			customMessages = append(customMessages, CustomMessage{
-				Role: msg.Role,
-				Content: []CustomMessageContent{
-					{
-						Type: "text",
-						Text: msg.Content,
-					},
-				},
-			})
-		}
-	}
-
-	// Create request body
-	reqBody := CustomRequestWithPDF{
-		Model:    "openrouter/auto", // Use auto-routing for PDF support
-		Messages: customMessages,
-		Stream:   true,
-	}
-
-	// Add plugins if needed
-	if webSearchEnabled {
-		reqBody.Plugins = []map[string]interface{}{
-			{"id": "web"},
-		}
-	}
-
-	// Add file parser plugin for PDF
-	reqBody.Plugins = append(reqBody.Plugins, map[string]interface{}{
-		"id": "file-parser",
-		"pdf": map[string]string{
-			"engine": "native", // Use text extraction engine
-		},
-	})
-
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal PDF request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
-		"https://openrouter.ai/api/v1/chat/completions",
+		o.chatCompletionsURL(),
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create PDF request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	o.setHeaders(req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req) //nolint:bodyclose // body is closed in the goroutine defer
+	resp, err := o.httpClient.Do(req) //nolint:bodyclose // body is closed in the goroutine defer
 	if err != nil {
-		return nil, fmt.Errorf("failed to send PDF request: %w", err)
+		// Log the full error for debugging, especially HTTP errors
+		o.logger.ErrorContext(ctx, "Failed to create completion stream",
+			"error", err.Error(),
+			"provider", o.provider,
+			"model", reqBody.Model,
+			"messages_count", len(reqBody.Messages))
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	// Check for HTTP errors
@@ -640,7 +375,7 @@ func (o *Completion) createStreamWithPDF(
 			}
 		}()
 
-		o.processStreamResponse(ctx, resp, tokenChan)
+		o.processStreamResponse(ctx, resp, tokenChan, reasoning)
 	}()
 
 	return tokenChan, nil
@@ -650,6 +385,7 @@ func (o *Completion) processStreamResponse(
 	ctx context.Context,
 	resp *http.Response,
 	tokenChan chan<- completion.StreamToken,
+	reasoning bool,
 ) {
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
@@ -669,57 +405,10 @@ func (o *Completion) processStreamResponse(
 		}
 
 		if len(streamResp.Choices) > 0 {
-			content := streamResp.Choices[0].Delta.Content
-			if content != "" {
-				select {
-				case tokenChan <- completion.StreamToken{Content: content}:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-	}
-
-	if scanErr := scanner.Err(); scanErr != nil {
-		select {
-		case tokenChan <- completion.StreamToken{Error: fmt.Errorf("scanner error: %w", scanErr)}:
-		case <-ctx.Done():
-		}
-	}
-}
-
-func (o *Completion) processReasoningStreamResponse(
-	ctx context.Context,
-	resp *http.Response,
-	tokenChan chan<- completion.StreamToken,
-) {
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "data: ") {
-			continue
-		}
-
-		data := strings.TrimPrefix(line, "data: ")
-		if data == "[DONE]" {
-			return
-		}
-
-		var streamResp CustomStreamResponse
-		if unmarshalErr := json.Unmarshal([]byte(data), &streamResp); unmarshalErr != nil {
-			continue // Skip malformed lines
-		}
-
-		if len(streamResp.Choices) > 0 {
-			token := completion.StreamToken{}
-
-			// Handle regular content
-			if streamResp.Choices[0].Delta.Content != "" {
-				token.Content = streamResp.Choices[0].Delta.Content
+			token := completion.StreamToken{
+				Content: streamResp.Choices[0].Delta.Content,
 			}
-
-			// Handle reasoning tokens
-			if streamResp.Choices[0].Delta.Reasoning != "" {
+			if reasoning {
 				token.Reasoning = streamResp.Choices[0].Delta.Reasoning
 			}
 
@@ -732,6 +421,10 @@ func (o *Completion) processReasoningStreamResponse(
 				}
 			}
 		}
+
+		if !o.sendUsage(ctx, streamResp.Usage, tokenChan) {
+			return
+		}
 	}
 
 	if scanErr := scanner.Err(); scanErr != nil {
@@ -741,3 +434,21 @@ func (o *Completion) processReasoningStreamResponse(
 		}
 	}
 }
+
+// sendUsage passes the usage reported in a stream chunk on. It returns false when the context is done.
+func (o *Completion) sendUsage(
+	ctx context.Context,
+	usage *StreamUsage,
+	tokenChan chan<- completion.StreamToken,
+) bool {
+	if usage == nil {
+		return true
+	}
+
+	select {
+	case tokenChan <- completion.StreamToken{Usage: usage.toCompletionUsage()}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,220 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/filestorage"
+)
+
+// MessageFilesMigration is the file name the migration of message files is registered under.
+const MessageFilesMigration = "20250624120000_move_message_file_data.go"
+
+// messageFilesBatch is how many messages the migration reads at once, their files may be large.
+const messageFilesBatch = 50
+
+var errNoFileStorage = errors.New("file storage is required to migrate message files")
+
+type messageFilesMigration struct {
+	fileStorage filestorage.FileStorage
+}
+
+// MoveMessageFileData returns the migration that moves the content of message files from the messages
+// to the file storage, so that messages only reference their uploads. Files that were uploaded when
+// they were received are matched with the attachments of their message by type in order, the others are
+// uploaded and recorded as attachments. Messages are migrated one by one, so a failed migration
+// continues where it stopped when it's run again.
+func MoveMessageFileData(
+	fileStorage filestorage.FileStorage,
+) (func(context.Context, *sql.DB) error, func(context.Context, *sql.DB) error) {
+	m := &messageFilesMigration{fileStorage: fileStorage}
+	return m.up, m.down
+}
+
+type storedAttachment struct {
+	s3Name      string
+	contentType string
+	matched     bool
+}
+
+func (m *messageFilesMigration) up(ctx context.Context, db *sql.DB) error {
+	return forEachMessage(ctx, db, "$.files[*].data", func(messageID int64, messageType domain.MessageType) error {
+		attachments, err := messageAttachments(ctx, db, messageID)
+		if err != nil {
+			return err
+		}
+
+		for i, file := range messageType.Files {
+			if len(file.Data) == 0 {
+				continue
+			}
+			if file.S3Name == "" {
+				file.S3Name = matchAttachment(attachments, file.MimeType)
+			}
+			if file.S3Name == "" {
+				if file.S3Name, err = m.uploadFile(ctx, db, messageID, file); err != nil {
+					return err
+				}
+			}
+			messageType.Files[i] = file.Reference()
+		}
+
+		return updateMessageType(ctx, db, messageID, messageType)
+	})
+}
+
+func (m *messageFilesMigration) down(ctx context.Context, db *sql.DB) error {
+	return forEachMessage(ctx, db, "$.files[*].s3_name", func(messageID int64, messageType domain.MessageType) error {
+		for i, file := range messageType.Files {
+			if file.S3Name == "" || len(file.Data) > 0 {
+				continue
+			}
+			if m.fileStorage == nil {
+				return errNoFileStorage
+			}
+
+			data, err := m.fileStorage.Download(ctx, file.S3Name)
+			if err != nil {
+				return fmt.Errorf("can't download file of message %d: %w", messageID, err)
+			}
+			messageType.Files[i] = domain.File{Data: data, MimeType: file.MimeType, FileName: file.FileName}
+		}
+
+		return updateMessageType(ctx, db, messageID, messageType)
+	})
+}
+
+// uploadFile uploads a file that was never uploaded and records it as an attachment of the message.
+func (m *messageFilesMigration) uploadFile(
+	ctx context.Context,
+	db *sql.DB,
+	messageID int64,
+	file domain.File,
+) (string, error) {
+	if m.fileStorage == nil {
+		return "", errNoFileStorage
+	}
+
+	s3Name, err := m.fileStorage.Upload(ctx, file.Data, file.MimeType)
+	if err != nil {
+		return "", fmt.Errorf("can't upload file of message %d: %w", messageID, err)
+	}
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO attachments (message_id, s3_name, content_type, size) VALUES ($1, $2, $3, $4)`,
+		messageID, s3Name, file.MimeType, len(file.Data))
+	if err != nil {
+		return "", fmt.Errorf("can't record attachment of message %d: %w", messageID, err)
+	}
+
+	return s3Name, nil
+}
+
+// forEachMessage calls fn for every message with a file matching the JSON path, in batches by ID.
+func forEachMessage(
+	ctx context.Context,
+	db *sql.DB,
+	path string,
+	fn func(messageID int64, messageType domain.MessageType) error,
+) error {
+	lastID := int64(0)
+	for {
+		rows, err := db.QueryContext(ctx,
+			`SELECT id, message_type FROM messages
+			WHERE id > $1 AND jsonb_path_exists(message_type, $2::jsonpath)
+			ORDER BY id
+			LIMIT $3`,
+			lastID, path, messageFilesBatch)
+		if err != nil {
+			return fmt.Errorf("can't get messages with files: %w", err)
+		}
+
+		type message struct {
+			id          int64
+			messageType domain.MessageType
+		}
+		var batch []message
+		for rows.Next() {
+			var msg message
+			var raw []byte
+			if err = rows.Scan(&msg.id, &raw); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("can't scan message: %w", err)
+			}
+			if err = json.Unmarshal(raw, &msg.messageType); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("can't unmarshal message type of message %d: %w", msg.id, err)
+			}
+			batch = append(batch, msg)
+		}
+		if err = rows.Close(); err != nil {
+			return fmt.Errorf("can't read messages with files: %w", err)
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("can't read messages with files: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, msg := range batch {
+			if err = fn(msg.id, msg.messageType); err != nil {
+				return err
+			}
+		}
+		lastID = batch[len(batch)-1].id
+	}
+}
+
+// messageAttachments returns the attachments of a message in upload order.
+func messageAttachments(ctx context.Context, db *sql.DB, messageID int64) ([]*storedAttachment, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT s3_name, COALESCE(content_type, '') FROM attachments WHERE message_id = $1 ORDER BY id`,
+		messageID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get attachments of message %d: %w", messageID, err)
+	}
+	defer rows.Close()
+
+	var attachments []*storedAttachment
+	for rows.Next() {
+		attachment := &storedAttachment{}
+		if err = rows.Scan(&attachment.s3Name, &attachment.contentType); err != nil {
+			return nil, fmt.Errorf("can't scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't read attachments of message %d: %w", messageID, err)
+	}
+
+	return attachments, nil
+}
+
+// matchAttachment returns the object of the first attachment of the type that isn't matched yet,
+// empty when there is none.
+func matchAttachment(attachments []*storedAttachment, contentType string) string {
+	for _, attachment := range attachments {
+		if !attachment.matched && attachment.contentType == contentType {
+			attachment.matched = true
+			return attachment.s3Name
+		}
+	}
+	return ""
+}
+
+func updateMessageType(ctx context.Context, db *sql.DB, messageID int64, messageType domain.MessageType) error {
+	raw, err := json.Marshal(messageType)
+	if err != nil {
+		return fmt.Errorf("can't marshal message type of message %d: %w", messageID, err)
+	}
+
+	if _, err = db.ExecContext(ctx, `UPDATE messages SET message_type = $2 WHERE id = $1`, messageID, raw); err != nil {
+		return fmt.Errorf("can't update message %d: %w", messageID, err)
+	}
+	return nil
+}
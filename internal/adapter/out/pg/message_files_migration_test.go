@@ -0,0 +1,287 @@
+package pg_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pgAdapter "github.com/vladimish/talk/internal/adapter/out/pg"
+	"github.com/vladimish/talk/internal/domain"
+)
+
+// fakeFileStorage keeps objects in memory.
+type fakeFileStorage struct {
+	objects map[string][]byte
+	uploads int
+	// failUpload fails the upload with this number, zero for none
+	failUpload int
+}
+
+func (s *fakeFileStorage) Upload(_ context.Context, data []byte, mimeType string) (string, error) {
+	s.uploads++
+	if s.uploads == s.failUpload {
+		return "", errors.New("storage is down")
+	}
+	_, subtype, _ := strings.Cut(mimeType, "/")
+	name := fmt.Sprintf("uploaded-%d.%s", s.uploads, subtype)
+	s.objects[name] = data
+	return name, nil
+}
+
+func (s *fakeFileStorage) Download(_ context.Context, objectName string) ([]byte, error) {
+	data, exists := s.objects[objectName]
+	if !exists {
+		return nil, fmt.Errorf("no object %s", objectName)
+	}
+	return data, nil
+}
+
+func (s *fakeFileStorage) GetPreSignedURL(context.Context, string, time.Duration) (string, error) {
+	return "", errors.New("not supported")
+}
+
+func (s *fakeFileStorage) Delete(_ context.Context, objectName string) error {
+	delete(s.objects, objectName)
+	return nil
+}
+
+type fakeAttachment struct {
+	messageID   int64
+	s3Name      string
+	contentType string
+}
+
+// fakeDatabase answers the queries of the migration from memory.
+type fakeDatabase struct {
+	mu          sync.Mutex
+	messages    map[int64][]byte
+	attachments []fakeAttachment
+}
+
+var (
+	fakeDatabases      sync.Map
+	registerFakeDriver sync.Once
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, exists := fakeDatabases.Load(name)
+	if !exists {
+		return nil, fmt.Errorf("no database %s", name)
+	}
+	return &fakeConn{db: db.(*fakeDatabase)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT id, message_type FROM messages"):
+		lastID, path, limit := args[0].Value.(int64), args[1].Value.(string), args[2].Value.(int64)
+		rows := &fakeRows{columns: []string{"id", "message_type"}}
+		for _, id := range slices.Sorted(maps.Keys(c.db.messages)) {
+			if id > lastID && len(rows.values) < int(limit) && hasFileField(c.db.messages[id], path) {
+				rows.values = append(rows.values, []driver.Value{id, c.db.messages[id]})
+			}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT s3_name, COALESCE(content_type, '') FROM attachments"):
+		rows := &fakeRows{columns: []string{"s3_name", "content_type"}}
+		for _, attachment := range c.db.attachments {
+			if attachment.messageID == args[0].Value.(int64) {
+				rows.values = append(rows.values, []driver.Value{attachment.s3Name, attachment.contentType})
+			}
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO attachments"):
+		c.db.attachments = append(c.db.attachments, fakeAttachment{
+			messageID:   args[0].Value.(int64),
+			s3Name:      args[1].Value.(string),
+			contentType: args[2].Value.(string),
+		})
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "UPDATE messages SET message_type"):
+		c.db.messages[args[0].Value.(int64)] = args[1].Value.([]byte)
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+// hasFileField emulates jsonb_path_exists for the "$.files[*].<field>" paths of the migration.
+func hasFileField(messageType []byte, path string) bool {
+	var message struct {
+		Files []map[string]any `json:"files"`
+	}
+	if err := json.Unmarshal(messageType, &message); err != nil {
+		return false
+	}
+	field := strings.TrimPrefix(path, "$.files[*].")
+	return slices.ContainsFunc(message.Files, func(file map[string]any) bool {
+		_, exists := file[field]
+		return exists
+	})
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func openFakeDatabase(t *testing.T, messages map[int64]domain.MessageType, attachments []fakeAttachment) (
+	*sql.DB,
+	*fakeDatabase,
+) {
+	t.Helper()
+
+	fake := &fakeDatabase{messages: make(map[int64][]byte), attachments: attachments}
+	for id, messageType := range messages {
+		raw, err := json.Marshal(messageType)
+		require.NoError(t, err)
+		fake.messages[id] = raw
+	}
+	registerFakeDriver.Do(func() { sql.Register("fakepg", fakeDriver{}) })
+	fakeDatabases.Store(t.Name(), fake)
+	t.Cleanup(func() { fakeDatabases.Delete(t.Name()) })
+
+	db, err := sql.Open("fakepg", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, fake
+}
+
+func storedMessage(t *testing.T, fake *fakeDatabase, id int64) domain.MessageType {
+	t.Helper()
+
+	var messageType domain.MessageType
+	require.NoError(t, json.Unmarshal(fake.messages[id], &messageType))
+	return messageType
+}
+
+func TestMoveMessageFileData(t *testing.T) {
+	cat, dog, report := []byte("cat"), []byte("dog"), []byte("%PDF report")
+	db, fake := openFakeDatabase(t, map[int64]domain.MessageType{
+		// The cat was uploaded when it was received, the dog never was
+		1: {Text: "Look", Files: []domain.File{
+			{Data: cat, MimeType: "image/jpeg"},
+			{Data: dog, MimeType: "image/png"},
+		}},
+		2: {Text: "Hi"},
+		3: {Text: "Summarize", Files: []domain.File{
+			{Data: report, MimeType: "application/pdf", FileName: "report.pdf", Text: "Report"},
+		}},
+	}, []fakeAttachment{
+		{messageID: 1, s3Name: "cat.jpeg", contentType: "image/jpeg"},
+	})
+	fileStorage := &fakeFileStorage{objects: map[string][]byte{"cat.jpeg": cat}}
+	up, down := pgAdapter.MoveMessageFileData(fileStorage)
+
+	require.NoError(t, up(t.Context(), db))
+
+	assert.Equal(t, domain.MessageType{Text: "Look", Files: []domain.File{
+		{S3Name: "cat.jpeg", MimeType: "image/jpeg"},
+		{S3Name: "uploaded-1.png", MimeType: "image/png"},
+	}}, storedMessage(t, fake, 1))
+	assert.Equal(t, domain.MessageType{Text: "Hi"}, storedMessage(t, fake, 2))
+	assert.Equal(t, domain.MessageType{Text: "Summarize", Files: []domain.File{
+		{S3Name: "uploaded-2.pdf", MimeType: "application/pdf", FileName: "report.pdf"},
+	}}, storedMessage(t, fake, 3))
+	assert.Equal(t, dog, fileStorage.objects["uploaded-1.png"])
+	assert.Equal(t, []fakeAttachment{
+		{messageID: 1, s3Name: "cat.jpeg", contentType: "image/jpeg"},
+		{messageID: 1, s3Name: "uploaded-1.png", contentType: "image/png"},
+		{messageID: 3, s3Name: "uploaded-2.pdf", contentType: "application/pdf"},
+	}, fake.attachments)
+
+	// Running it again changes nothing
+	migrated := maps.Clone(fake.messages)
+	require.NoError(t, up(t.Context(), db))
+	assert.Equal(t, 2, fileStorage.uploads)
+	assert.Equal(t, migrated, fake.messages)
+	assert.Len(t, fake.attachments, 3)
+
+	require.NoError(t, down(t.Context(), db))
+
+	assert.Equal(t, domain.MessageType{Text: "Look", Files: []domain.File{
+		{Data: cat, MimeType: "image/jpeg"},
+		{Data: dog, MimeType: "image/png"},
+	}}, storedMessage(t, fake, 1))
+	assert.Equal(t, domain.MessageType{Text: "Summarize", Files: []domain.File{
+		{Data: report, MimeType: "application/pdf", FileName: "report.pdf"},
+	}}, storedMessage(t, fake, 3))
+
+	// The attachments recorded on the way up are matched again
+	require.NoError(t, up(t.Context(), db))
+	assert.Equal(t, 2, fileStorage.uploads)
+	assert.Equal(t, migrated, fake.messages)
+}
+
+func TestMoveMessageFileData_ContinuesAfterFailure(t *testing.T) {
+	db, fake := openFakeDatabase(t, map[int64]domain.MessageType{
+		1: {Files: []domain.File{{Data: []byte("cat"), MimeType: "image/jpeg"}}},
+		2: {Files: []domain.File{{Data: []byte("dog"), MimeType: "image/png"}}},
+	}, nil)
+	fileStorage := &fakeFileStorage{objects: make(map[string][]byte), failUpload: 2}
+	up, _ := pgAdapter.MoveMessageFileData(fileStorage)
+
+	require.ErrorContains(t, up(t.Context(), db), "can't upload file of message 2")
+	// The first message stays migrated
+	assert.Equal(t, []domain.File{{S3Name: "uploaded-1.jpeg", MimeType: "image/jpeg"}}, storedMessage(t, fake, 1).Files)
+
+	require.NoError(t, up(t.Context(), db))
+
+	assert.Equal(t, []domain.File{{S3Name: "uploaded-3.png", MimeType: "image/png"}}, storedMessage(t, fake, 2).Files)
+	assert.Equal(t, 3, fileStorage.uploads)
+}
+
+func TestMoveMessageFileData_WithoutFileStorage(t *testing.T) {
+	db, _ := openFakeDatabase(t, map[int64]domain.MessageType{
+		1: {Files: []domain.File{{Data: []byte("cat"), MimeType: "image/jpeg"}}},
+	}, nil)
+	up, _ := pgAdapter.MoveMessageFileData(nil)
+
+	require.ErrorContains(t, up(t.Context(), db), "file storage is required")
+}
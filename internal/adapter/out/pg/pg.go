@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/vladimish/talk/db/generated"
 	"github.com/vladimish/talk/internal/domain"
@@ -15,11 +16,36 @@ import (
 )
 
 type PG struct {
-	q *generated.Queries
+	// db starts transactions, it is nil for the storage of a transaction
+	db *sql.DB
+	q  *generated.Queries
 }
 
-func NewPg(q *generated.Queries) *PG {
-	return &PG{q: q}
+func NewPg(db *sql.DB, q *generated.Queries) *PG {
+	return &PG{db: db, q: q}
+}
+
+// InTransaction runs fn with a storage whose changes are committed when fn succeeds and rolled back otherwise.
+// A transaction started inside another one joins it.
+func (p *PG) InTransaction(ctx context.Context, fn func(tx storage.Storage) error) error {
+	if p.db == nil {
+		return fn(p)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("can't begin transaction: %w", err)
+	}
+	if err = fn(&PG{q: p.q.WithTx(tx)}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("can't roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("can't commit transaction: %w", err)
+	}
+	return nil
 }
 
 func (p *PG) GetUserByExternalUserID(ctx context.Context, id string) (*domain.User, error) {
@@ -51,6 +77,9 @@ func (p *PG) GetUserByExternalUserID(ctx context.Context, id string) (*domain.Us
 		CurrentConversationID:  conversationID,
 		ConversationListOffset: int(u.ConversationListOffset),
 		WebSearchEnabled:       u.WebSearchEnabled,
+		DefaultSystemPrompt:    nullStringToPtr(u.DefaultSystemPrompt),
+		ShowUsage:              u.ShowUsage,
+		VoiceReplies:           u.VoiceReplies,
 		CreatedAt:              u.CreatedAt,
 		UpdatedAt:              u.UpdatedAt,
 	}, nil
@@ -77,6 +106,17 @@ func (p *PG) UpdateUserLanguage(ctx context.Context, userID int64, language stri
 	})
 }
 
+func (p *PG) UpdateUserDefaultSystemPrompt(ctx context.Context, userID int64, systemPrompt *string) error {
+	err := p.q.UpdateUserDefaultSystemPrompt(ctx, generated.UpdateUserDefaultSystemPromptParams{
+		ID:                  userID,
+		DefaultSystemPrompt: ptrToNullString(systemPrompt),
+	})
+	if err != nil {
+		return fmt.Errorf("can't update user default system prompt: %w", err)
+	}
+	return nil
+}
+
 func (p *PG) UpdateUserConversationListOffset(ctx context.Context, userID int64, offset int) error {
 	// Clamp offset to int32 range to prevent overflow
 	if offset > math.MaxInt32 {
@@ -99,6 +139,39 @@ func (p *PG) UpdateUserWebSearchEnabled(ctx context.Context, userID int64, enabl
 	})
 }
 
+func (p *PG) UpdateUserShowUsage(ctx context.Context, userID int64, show bool) error {
+	return p.q.UpdateUserShowUsage(ctx, generated.UpdateUserShowUsageParams{
+		ID:        userID,
+		ShowUsage: show,
+	})
+}
+
+func (p *PG) UpdateUserVoiceReplies(ctx context.Context, userID int64, enabled bool) error {
+	return p.q.UpdateUserVoiceReplies(ctx, generated.UpdateUserVoiceRepliesParams{
+		ID:           userID,
+		VoiceReplies: enabled,
+	})
+}
+
+func (p *PG) GetSelectedModels(ctx context.Context) ([]string, error) {
+	models, err := p.q.GetSelectedModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get selected models: %w", err)
+	}
+	return models, nil
+}
+
+func (p *PG) UpdateUsersSelectedModel(ctx context.Context, oldModel string, newModel string) (int64, error) {
+	updated, err := p.q.UpdateUsersSelectedModel(ctx, generated.UpdateUsersSelectedModelParams{
+		NewModel: newModel,
+		OldModel: oldModel,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't update users selected model: %w", err)
+	}
+	return updated, nil
+}
+
 func (p *PG) CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
 	messageType, err := json.Marshal(message.MessageType)
 	if err != nil {
@@ -110,11 +183,23 @@ func (p *PG) CreateMessage(ctx context.Context, message *domain.Message) (*domai
 		conversationID = sql.NullInt64{Int64: *message.ConversationID, Valid: true}
 	}
 
+	// Imported messages keep their original timestamps, new ones are created now
+	createdAt := message.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	updatedAt := message.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
 	m, err := p.q.CreateMessage(ctx, generated.CreateMessageParams{
 		MessageType:    json.RawMessage(messageType),
 		UserID:         message.UserID,
 		SentBy:         generated.MessageSender(message.SentBy),
 		ConversationID: conversationID,
+		CreatedAt:      sql.NullTime{Time: createdAt, Valid: true},
+		UpdatedAt:      sql.NullTime{Time: updatedAt, Valid: true},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("can't create message: %w", err)
@@ -235,24 +320,259 @@ func (p *PG) GetLatestMessageByConversationID(ctx context.Context, conversationI
 	}, nil
 }
 
+func (p *PG) GetMessageByID(ctx context.Context, messageID int64) (*domain.Message, error) {
+	m, err := p.q.GetMessageByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("can't get message: %w", err)
+	}
+
+	var msgType domain.MessageType
+	if unmarshalErr := json.Unmarshal(m.MessageType, &msgType); unmarshalErr != nil {
+		return nil, fmt.Errorf("can't unmarshal message type: %w", unmarshalErr)
+	}
+
+	var messageConversationID *int64
+	if m.ConversationID.Valid {
+		messageConversationID = &m.ConversationID.Int64
+	}
+
+	return &domain.Message{
+		ID:             m.ID,
+		UserID:         m.UserID,
+		MessageType:    msgType,
+		SentBy:         domain.MessageSender(m.SentBy),
+		ConversationID: messageConversationID,
+		CreatedAt:      m.CreatedAt.Time,
+		UpdatedAt:      m.UpdatedAt.Time,
+	}, nil
+}
+
+func (p *PG) GetMessageByForeignID(
+	ctx context.Context,
+	userID int64,
+	foreignMessageID int32,
+) (*domain.Message, error) {
+	m, err := p.q.GetMessageByForeignID(ctx, generated.GetMessageByForeignIDParams{
+		ForeignMessageID: foreignMessageID,
+		UserID:           userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("can't get message by foreign id: %w", err)
+	}
+
+	var msgType domain.MessageType
+	if unmarshalErr := json.Unmarshal(m.MessageType, &msgType); unmarshalErr != nil {
+		return nil, fmt.Errorf("can't unmarshal message type: %w", unmarshalErr)
+	}
+
+	var messageConversationID *int64
+	if m.ConversationID.Valid {
+		messageConversationID = &m.ConversationID.Int64
+	}
+
+	return &domain.Message{
+		ID:             m.ID,
+		UserID:         m.UserID,
+		MessageType:    msgType,
+		SentBy:         domain.MessageSender(m.SentBy),
+		ConversationID: messageConversationID,
+		CreatedAt:      m.CreatedAt.Time,
+		UpdatedAt:      m.UpdatedAt.Time,
+	}, nil
+}
+
+// DeleteMessage deletes a message together with its attachments and foreign message mappings.
+func (p *PG) DeleteMessage(ctx context.Context, messageID int64) error {
+	if err := p.q.DeleteMessage(ctx, messageID); err != nil {
+		return fmt.Errorf("can't delete message: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessagesAfter deletes all messages of a conversation that were created after the given message.
+func (p *PG) DeleteMessagesAfter(ctx context.Context, conversationID int64, messageID int64) error {
+	err := p.q.DeleteMessagesAfterID(ctx, generated.DeleteMessagesAfterIDParams{
+		ConversationID: sql.NullInt64{Int64: conversationID, Valid: true},
+		ID:             messageID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete messages: %w", err)
+	}
+	return nil
+}
+
+func (p *PG) UpdateMessageType(ctx context.Context, messageID int64, messageType domain.MessageType) error {
+	rawMessageType, err := json.Marshal(messageType)
+	if err != nil {
+		return fmt.Errorf("can't marshal message type: %w", err)
+	}
+
+	err = p.q.UpdateMessageType(ctx, generated.UpdateMessageTypeParams{
+		ID:          messageID,
+		MessageType: json.RawMessage(rawMessageType),
+	})
+	if err != nil {
+		return fmt.Errorf("can't update message type: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PG) CreateMessageVersion(
+	ctx context.Context,
+	version *domain.MessageVersion,
+) (*domain.MessageVersion, error) {
+	v, err := p.q.CreateMessageVersion(ctx, generated.CreateMessageVersionParams{
+		MessageID: version.MessageID,
+		Text:      version.Text,
+		Model:     version.Model,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create message version: %w", err)
+	}
+
+	return convertMessageVersion(v), nil
+}
+
+func (p *PG) GetMessageVersions(ctx context.Context, messageID int64) ([]*domain.MessageVersion, error) {
+	versions, err := p.q.GetMessageVersionsByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get message versions: %w", err)
+	}
+
+	result := make([]*domain.MessageVersion, len(versions))
+	for i, v := range versions {
+		result[i] = convertMessageVersion(v)
+	}
+
+	return result, nil
+}
+
+func (p *PG) DeleteMessageVersions(ctx context.Context, messageID int64) error {
+	err := p.q.DeleteMessageVersionsByMessageID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("can't delete message versions: %w", err)
+	}
+	return nil
+}
+
+// SearchMessages finds the user's conversation messages matching the query, best matches first.
+// The text search configuration follows the user's language, so that words match in any of their forms.
+func (p *PG) SearchMessages(
+	ctx context.Context,
+	userID int64,
+	language string,
+	query string,
+	limit int,
+) ([]*domain.MessageSearchResult, error) {
+	if limit > int(^uint32(0)>>1) {
+		limit = int(^uint32(0) >> 1)
+	}
+	resultLimit := int32(limit) //nolint:gosec // bounded above
+
+	params := searchParams{Query: query, UserID: userID, ResultLimit: resultLimit}
+	var rows []generated.SearchMessagesEnglishRow
+	var err error
+	switch language {
+	case "en":
+		rows, err = searchWith(ctx, p.q.SearchMessagesEnglish, params)
+	case "ru":
+		rows, err = searchWith(ctx, p.q.SearchMessagesRussian, params)
+	case "de":
+		rows, err = searchWith(ctx, p.q.SearchMessagesGerman, params)
+	case "fr":
+		rows, err = searchWith(ctx, p.q.SearchMessagesFrench, params)
+	case "es":
+		rows, err = searchWith(ctx, p.q.SearchMessagesSpanish, params)
+	case "it":
+		rows, err = searchWith(ctx, p.q.SearchMessagesItalian, params)
+	case "pt":
+		rows, err = searchWith(ctx, p.q.SearchMessagesPortuguese, params)
+	case "ar":
+		rows, err = searchWith(ctx, p.q.SearchMessagesArabic, params)
+	case "hi":
+		rows, err = searchWith(ctx, p.q.SearchMessagesHindi, params)
+	case "hy":
+		rows, err = searchWith(ctx, p.q.SearchMessagesArmenian, params)
+	default:
+		// Languages without a stemmer in PostgreSQL match words as they are written
+		rows, err = searchWith(ctx, p.q.SearchMessagesSimple, params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't search messages: %w", err)
+	}
+
+	results := make([]*domain.MessageSearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = &domain.MessageSearchResult{
+			MessageID:        row.ID,
+			ConversationID:   row.ConversationID.Int64,
+			ConversationName: row.ConversationName,
+			SentBy:           domain.MessageSender(row.SentBy),
+			Snippet:          row.Snippet,
+			Rank:             row.Rank,
+			CreatedAt:        row.CreatedAt.Time,
+		}
+	}
+
+	return results, nil
+}
+
+// searchParams and searchRow are the parameters and rows the search queries of every language share.
+type searchParams = struct {
+	Query       string
+	UserID      int64
+	ResultLimit int32
+}
+
+type searchRow = struct {
+	ID               int64
+	ConversationID   sql.NullInt64
+	ConversationName string
+	SentBy           generated.MessageSender
+	CreatedAt        sql.NullTime
+	Snippet          string
+	Rank             float32
+}
+
+// searchWith runs the search query of a language, the queries only differ in their text search configuration.
+func searchWith[P ~searchParams, R ~searchRow](
+	ctx context.Context,
+	search func(context.Context, P) ([]R, error),
+	params searchParams,
+) ([]generated.SearchMessagesEnglishRow, error) {
+	found, err := search(ctx, P(params))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]generated.SearchMessagesEnglishRow, len(found))
+	for i, row := range found {
+		rows[i] = generated.SearchMessagesEnglishRow(row)
+	}
+	return rows, nil
+}
+
 func (p *PG) CreateConversation(ctx context.Context, conversation *domain.Conversation) (*domain.Conversation, error) {
 	c, err := p.q.CreateConversation(ctx, generated.CreateConversationParams{
-		Name:      conversation.Name,
-		UserID:    conversation.UserID,
-		CreatedAt: conversation.CreatedAt,
-		UpdatedAt: conversation.UpdatedAt,
+		Name:                 conversation.Name,
+		UserID:               conversation.UserID,
+		CreatedAt:            conversation.CreatedAt,
+		UpdatedAt:            conversation.UpdatedAt,
+		SystemPrompt:         ptrToNullString(conversation.SystemPrompt),
+		ParentConversationID: ptrToNullInt64(conversation.ParentConversationID),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("can't create conversation: %w", err)
 	}
 
-	return &domain.Conversation{
-		ID:        c.ID,
-		Name:      c.Name,
-		UserID:    c.UserID,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
-	}, nil
+	return convertConversation(c), nil
 }
 
 func (p *PG) GetConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error) {
@@ -263,13 +583,21 @@ func (p *PG) GetConversationsByUserID(ctx context.Context, userID int64) ([]*dom
 
 	result := make([]*domain.Conversation, len(conversations))
 	for i, c := range conversations {
-		result[i] = &domain.Conversation{
-			ID:        c.ID,
-			Name:      c.Name,
-			UserID:    c.UserID,
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
-		}
+		result[i] = convertConversation(c)
+	}
+
+	return result, nil
+}
+
+func (p *PG) GetArchivedConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error) {
+	conversations, err := p.q.GetArchivedConversationsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get archived conversations by user id: %w", err)
+	}
+
+	result := make([]*domain.Conversation, len(conversations))
+	for i, c := range conversations {
+		result[i] = convertConversation(c)
 	}
 
 	return result, nil
@@ -284,13 +612,7 @@ func (p *PG) GetConversationByID(ctx context.Context, conversationID int64) (*do
 		return nil, fmt.Errorf("can't get conversation by id: %w", err)
 	}
 
-	return &domain.Conversation{
-		ID:        c.ID,
-		Name:      c.Name,
-		UserID:    c.UserID,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
-	}, nil
+	return convertConversation(c), nil
 }
 
 func (p *PG) UpdateConversationName(ctx context.Context, conversationID int64, name string) error {
@@ -300,10 +622,98 @@ func (p *PG) UpdateConversationName(ctx context.Context, conversationID int64, n
 	})
 }
 
+func (p *PG) UpdateConversationSystemPrompt(ctx context.Context, conversationID int64, systemPrompt *string) error {
+	err := p.q.UpdateConversationSystemPrompt(ctx, generated.UpdateConversationSystemPromptParams{
+		ID:           conversationID,
+		SystemPrompt: ptrToNullString(systemPrompt),
+	})
+	if err != nil {
+		return fmt.Errorf("can't update conversation system prompt: %w", err)
+	}
+	return nil
+}
+
+func (p *PG) UpdateConversationPinned(ctx context.Context, conversationID int64, pinned bool) error {
+	err := p.q.UpdateConversationPinned(ctx, generated.UpdateConversationPinnedParams{
+		Pinned: pinned,
+		ID:     conversationID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't update conversation pinned: %w", err)
+	}
+	return nil
+}
+
+func (p *PG) UpdateConversationArchived(ctx context.Context, conversationID int64, archived bool) error {
+	err := p.q.UpdateConversationArchived(ctx, generated.UpdateConversationArchivedParams{
+		Archived: archived,
+		ID:       conversationID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't update conversation archived: %w", err)
+	}
+	return nil
+}
+
+// DeleteConversation marks the conversation as deleted, it can be brought back with RestoreConversation.
+func (p *PG) DeleteConversation(ctx context.Context, conversationID int64) error {
+	if err := p.q.SoftDeleteConversation(ctx, conversationID); err != nil {
+		return fmt.Errorf("can't delete conversation: %w", err)
+	}
+	return nil
+}
+
+func (p *PG) RestoreConversation(ctx context.Context, conversationID int64) error {
+	if err := p.q.RestoreConversation(ctx, conversationID); err != nil {
+		return fmt.Errorf("can't restore conversation: %w", err)
+	}
+	return nil
+}
+
 func (p *PG) UpdateConversationTimestamp(ctx context.Context, conversationID int64) error {
 	return p.q.UpdateConversationTimestamp(ctx, conversationID)
 }
 
+func (p *PG) GetConversationSummary(
+	ctx context.Context,
+	conversationID int64,
+) (*domain.ConversationSummary, error) {
+	cs, err := p.q.GetConversationSummary(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("can't get conversation summary: %w", err)
+	}
+
+	return &domain.ConversationSummary{
+		ConversationID: cs.ConversationID,
+		Summary:        cs.Summary,
+		LastMessageID:  cs.LastMessageID,
+		UpdatedAt:      cs.UpdatedAt,
+	}, nil
+}
+
+func (p *PG) UpsertConversationSummary(ctx context.Context, summary *domain.ConversationSummary) error {
+	err := p.q.UpsertConversationSummary(ctx, generated.UpsertConversationSummaryParams{
+		ConversationID: summary.ConversationID,
+		Summary:        summary.Summary,
+		LastMessageID:  summary.LastMessageID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't upsert conversation summary: %w", err)
+	}
+	return nil
+}
+
+func (p *PG) DeleteConversationSummary(ctx context.Context, conversationID int64) error {
+	err := p.q.DeleteConversationSummary(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("can't delete conversation summary: %w", err)
+	}
+	return nil
+}
+
 func (p *PG) UpdateUserCurrentConversationID(ctx context.Context, userID int64, conversationID *int64) error {
 	var conversation sql.NullInt64
 	if conversationID != nil {
@@ -357,6 +767,10 @@ func (p *PG) CreateUser(ctx context.Context, user *domain.User) (*domain.User, e
 		SelectedModel:          u.SelectedModel,
 		CurrentConversationID:  currentConversationID,
 		ConversationListOffset: int(u.ConversationListOffset),
+		WebSearchEnabled:       u.WebSearchEnabled,
+		DefaultSystemPrompt:    nullStringToPtr(u.DefaultSystemPrompt),
+		ShowUsage:              u.ShowUsage,
+		VoiceReplies:           u.VoiceReplies,
 		CreatedAt:              u.CreatedAt,
 		UpdatedAt:              u.UpdatedAt,
 	}, nil
@@ -389,6 +803,28 @@ func (p *PG) GetForeignMessageByMessageID(ctx context.Context, messageID int32)
 	return fm.ForeignMessageID, nil
 }
 
+func (p *PG) GetForeignMessagesByMessageID(ctx context.Context, messageID int32) ([]int32, error) {
+	fms, err := p.q.GetForeignMessagesByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get foreign messages: %w", err)
+	}
+
+	result := make([]int32, len(fms))
+	for i, fm := range fms {
+		result[i] = fm.ForeignMessageID
+	}
+
+	return result, nil
+}
+
+func (p *PG) DeleteForeignMessages(ctx context.Context, messageID int32) error {
+	err := p.q.DeleteForeignMessage(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("can't delete foreign messages: %w", err)
+	}
+	return nil
+}
+
 // CreateTransaction creates a new transaction record in the database.
 func (p *PG) CreateTransaction(ctx context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
 	var modelUsed sql.NullString
@@ -466,6 +902,60 @@ func (p *PG) GetUserTokenBalanceByType(ctx context.Context, userID int64, tokenT
 	return balanceValue, nil
 }
 
+// CreateMessageUsage stores what generating an answer took.
+func (p *PG) CreateMessageUsage(ctx context.Context, usage *domain.MessageUsage) (*domain.MessageUsage, error) {
+	cost := sql.NullFloat64{}
+	if usage.Cost != nil {
+		cost = sql.NullFloat64{Float64: *usage.Cost, Valid: true}
+	}
+
+	u, err := p.q.CreateMessageUsage(ctx, generated.CreateMessageUsageParams{
+		UserID:           usage.UserID,
+		MessageID:        ptrToNullInt64(usage.MessageID),
+		Model:            usage.Model,
+		PromptTokens:     clampInt32(usage.PromptTokens),
+		CompletionTokens: clampInt32(usage.CompletionTokens),
+		ReasoningTokens:  clampInt32(usage.ReasoningTokens),
+		Cost:             cost,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create message usage: %w", err)
+	}
+
+	var storedCost *float64
+	if u.Cost.Valid {
+		storedCost = &u.Cost.Float64
+	}
+
+	return &domain.MessageUsage{
+		ID:               u.ID,
+		UserID:           u.UserID,
+		MessageID:        nullInt64ToPtr(u.MessageID),
+		Model:            u.Model,
+		PromptTokens:     int(u.PromptTokens),
+		CompletionTokens: int(u.CompletionTokens),
+		ReasoningTokens:  int(u.ReasoningTokens),
+		Cost:             storedCost,
+		CreatedAt:        u.CreatedAt,
+	}, nil
+}
+
+// GetUserUsageTotals sums up the usage of all answers of the user.
+func (p *PG) GetUserUsageTotals(ctx context.Context, userID int64) (*domain.UsageTotals, error) {
+	totals, err := p.q.GetUserUsageTotals(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get user usage totals: %w", err)
+	}
+
+	return &domain.UsageTotals{
+		Answers:          totals.Answers,
+		PromptTokens:     totals.PromptTokens,
+		CompletionTokens: totals.CompletionTokens,
+		ReasoningTokens:  totals.ReasoningTokens,
+		Cost:             totals.Cost,
+	}, nil
+}
+
 // CreatePayment creates a new payment record in the database.
 func (p *PG) CreatePayment(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
 	var invoicePayloadNullable sql.NullString
@@ -764,6 +1254,28 @@ func (p *PG) CreateAttachment(ctx context.Context, attachment *domain.Attachment
 	}, nil
 }
 
+// GetAttachmentsByMessageID returns all attachments of a message in upload order.
+func (p *PG) GetAttachmentsByMessageID(ctx context.Context, messageID int64) ([]*domain.Attachment, error) {
+	dbAttachments, err := p.q.GetAttachmentsByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get attachments: %w", err)
+	}
+
+	result := make([]*domain.Attachment, len(dbAttachments))
+	for i, a := range dbAttachments {
+		result[i] = &domain.Attachment{
+			ID:          a.ID,
+			MessageID:   a.MessageID,
+			S3Name:      a.S3Name,
+			ContentType: a.ContentType.String,
+			Size:        a.Size.Int64,
+			CreatedAt:   a.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
 // convertToInt64 converts various numeric types to int64.
 func (p *PG) convertToInt64(value interface{}) int64 {
 	switch v := value.(type) {
@@ -786,3 +1298,67 @@ func (p *PG) convertToInt64(value interface{}) int64 {
 		return 0
 	}
 }
+
+func convertConversation(c generated.Conversation) *domain.Conversation {
+	return &domain.Conversation{
+		ID:                   c.ID,
+		Name:                 c.Name,
+		UserID:               c.UserID,
+		SystemPrompt:         nullStringToPtr(c.SystemPrompt),
+		ParentConversationID: nullInt64ToPtr(c.ParentConversationID),
+		PinnedAt:             nullTimeToPtr(c.PinnedAt),
+		ArchivedAt:           nullTimeToPtr(c.ArchivedAt),
+		DeletedAt:            nullTimeToPtr(c.DeletedAt),
+		CreatedAt:            c.CreatedAt,
+		UpdatedAt:            c.UpdatedAt,
+	}
+}
+
+func convertMessageVersion(v generated.MessageVersion) *domain.MessageVersion {
+	return &domain.MessageVersion{
+		ID:        v.ID,
+		MessageID: v.MessageID,
+		Text:      v.Text,
+		Model:     v.Model,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func nullTimeToPtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullStringToPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func ptrToNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func nullInt64ToPtr(i sql.NullInt64) *int64 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int64
+}
+
+func ptrToNullInt64(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+func clampInt32(i int) int32 {
+	return int32(min(max(i, math.MinInt32), math.MaxInt32)) //nolint:gosec // clamped to the int32 range
+}
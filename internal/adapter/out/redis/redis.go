@@ -17,6 +17,17 @@ const (
 	queueExpiration   = 24 * time.Hour
 )
 
+// takeAlbumScript returns and deletes the album list when its length is the expected one, in one step so
+// a message can't be added between the check and the removal.
+var takeAlbumScript = redis.NewScript(`
+if redis.call("LLEN", KEYS[1]) ~= tonumber(ARGV[1]) then
+	return {}
+end
+local items = redis.call("LRANGE", KEYS[1], 0, -1)
+redis.call("DEL", KEYS[1])
+return items
+`)
+
 type Queue struct {
 	client *redis.Client
 }
@@ -188,6 +199,50 @@ func (r *Queue) ClearPendingMessages(ctx context.Context, userID string) error {
 	return nil
 }
 
+// AddAlbumMessage stores a message of a media group and returns how many messages the group has so far.
+func (r *Queue) AddAlbumMessage(
+	ctx context.Context,
+	userID, albumID string,
+	update domain.Update,
+	ttl time.Duration,
+) (int, error) {
+	albumKey := r.albumKey(userID, albumID)
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal album message: %w", err)
+	}
+
+	pipe := r.client.TxPipeline()
+	length := pipe.RPush(ctx, albumKey, data)
+	pipe.Expire(ctx, albumKey, ttl)
+	if _, execErr := pipe.Exec(ctx); execErr != nil {
+		return 0, fmt.Errorf("failed to add album message: %w", execErr)
+	}
+
+	return int(length.Val()), nil
+}
+
+// TakeAlbum atomically retrieves and removes the stored messages of a media group when it has exactly length
+// of them, otherwise returns none: a message added meanwhile takes the album itself.
+func (r *Queue) TakeAlbum(ctx context.Context, userID, albumID string, length int) ([]domain.Update, error) {
+	items, err := takeAlbumScript.Run(ctx, r.client, []string{r.albumKey(userID, albumID)}, length).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take album: %w", err)
+	}
+
+	updates := make([]domain.Update, 0, len(items))
+	for _, item := range items {
+		var update domain.Update
+		if err := json.Unmarshal([]byte(item), &update); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal album message: %w", err)
+		}
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
+
 // SetGenerationLock marks a user as having an active AI generation (for cancellation).
 func (r *Queue) SetGenerationLock(ctx context.Context, userID string, ttl time.Duration) error {
 	genKey := r.generationKey(userID)
@@ -223,6 +278,37 @@ func (r *Queue) ClearGenerationLock(ctx context.Context, userID string) error {
 	return nil
 }
 
+// PublishCancel asks the replica generating an answer for a user to stop it.
+func (r *Queue) PublishCancel(ctx context.Context, userID string) (bool, error) {
+	receivers, err := r.client.Publish(ctx, r.cancelChannel(userID), "1").Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to publish generation cancel: %w", err)
+	}
+
+	return receivers > 0, nil
+}
+
+// SubscribeCancel listens for cancel requests of a user's generation.
+func (r *Queue) SubscribeCancel(ctx context.Context, userID string) (<-chan struct{}, func(), error) {
+	pubsub := r.client.Subscribe(ctx, r.cancelChannel(userID))
+
+	// Wait for the confirmation so that a request published right after isn't missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe to generation cancel: %w", err)
+	}
+
+	messages := pubsub.Channel()
+	cancelled := make(chan struct{})
+	go func() {
+		if _, ok := <-messages; ok {
+			close(cancelled)
+		}
+	}()
+
+	return cancelled, func() { _ = pubsub.Close() }, nil
+}
+
 func (r *Queue) Close() error {
 	return r.client.Close()
 }
@@ -240,6 +326,14 @@ func (r *Queue) pendingKey(userID string) string {
 	return fmt.Sprintf("pending:user:%s", userID)
 }
 
+func (r *Queue) albumKey(userID, albumID string) string {
+	return fmt.Sprintf("album:user:%s:%s", userID, albumID)
+}
+
 func (r *Queue) generationKey(userID string) string {
 	return fmt.Sprintf("generation:user:%s", userID)
 }
+
+func (r *Queue) cancelChannel(userID string) string {
+	return fmt.Sprintf("cancel:user:%s", userID)
+}
@@ -0,0 +1,98 @@
+package redis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	redisAdapter "github.com/vladimish/talk/internal/adapter/out/redis"
+	"github.com/vladimish/talk/internal/domain"
+)
+
+func newQueue(t *testing.T) (*redisAdapter.Queue, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	q, err := redisAdapter.NewRedisQueue("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.Close() })
+	return q, server
+}
+
+func TestQueue_TakeAlbum(t *testing.T) {
+	q, server := newQueue(t)
+	ctx := t.Context()
+
+	first := domain.Update{ExternalUserID: "12345", MessageText: "Cats", MediaGroupID: "album1"}
+	second := domain.Update{ExternalUserID: "12345", MediaGroupID: "album1"}
+
+	length, err := q.AddAlbumMessage(ctx, "12345", "album1", first, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, length)
+	length, err = q.AddAlbumMessage(ctx, "12345", "album1", second, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, length)
+	assert.Equal(t, time.Minute, server.TTL("album:user:12345:album1"))
+
+	// The handler of the first message saw one message, the album has grown since
+	updates, err := q.TakeAlbum(ctx, "12345", "album1", 1)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+
+	updates, err = q.TakeAlbum(ctx, "12345", "album1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Update{first, second}, updates)
+	assert.False(t, server.Exists("album:user:12345:album1"), "a taken album is removed")
+
+	updates, err = q.TakeAlbum(ctx, "12345", "album1", 2)
+	require.NoError(t, err)
+	assert.Empty(t, updates, "an album is taken once")
+}
+
+func TestQueue_TakeAlbum_Expired(t *testing.T) {
+	q, server := newQueue(t)
+	ctx := t.Context()
+
+	_, err := q.AddAlbumMessage(ctx, "12345", "album1", domain.Update{MediaGroupID: "album1"}, time.Minute)
+	require.NoError(t, err)
+	server.FastForward(2 * time.Minute)
+
+	updates, err := q.TakeAlbum(ctx, "12345", "album1", 1)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	q, _ := newQueue(t)
+	ctx := t.Context()
+
+	delivered, err := q.PublishCancel(ctx, "12345")
+	require.NoError(t, err)
+	assert.False(t, delivered, "nobody generates an answer")
+
+	cancelled, unsubscribe, err := q.SubscribeCancel(ctx, "12345")
+	require.NoError(t, err)
+	defer unsubscribe()
+	other, unsubscribeOther, err := q.SubscribeCancel(ctx, "67890")
+	require.NoError(t, err)
+	defer unsubscribeOther()
+
+	// Published right after subscribing, it isn't missed
+	delivered, err = q.PublishCancel(ctx, "12345")
+	require.NoError(t, err)
+	assert.True(t, delivered)
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancel wasn't delivered")
+	}
+	select {
+	case <-other:
+		t.Fatal("cancel was delivered to another user")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
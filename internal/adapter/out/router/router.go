@@ -0,0 +1,83 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+)
+
+// Router sends every completion to the provider its model is served by.
+type Router struct {
+	logger    *slog.Logger
+	fallback  completion.Completion
+	providers map[string]completion.Completion
+}
+
+// NewRouter creates a completion router. Models of providers that aren't configured go to the fallback,
+// which serves models by their catalog ID.
+func NewRouter(
+	logger *slog.Logger,
+	fallback completion.Completion,
+	providers map[string]completion.Completion,
+) *Router {
+	return &Router{
+		logger:    logger,
+		fallback:  fallback,
+		providers: providers,
+	}
+}
+
+// route picks the provider of a model and the name of the model at the provider. Catalog models are routed by
+// their provider, other models by the "provider/" prefix of the model name when that provider is configured.
+func (r *Router) route(ctx context.Context, model string) (completion.Completion, string) {
+	if modelInfo := domain.GetModelByID(model); modelInfo != nil {
+		provider := modelInfo.CompletionProvider()
+		if provider == domain.ProviderOpenRouter {
+			return r.fallback, model
+		}
+		if client, exists := r.providers[provider]; exists {
+			return client, modelInfo.CompletionModel()
+		}
+
+		r.logger.WarnContext(ctx, "model provider is not configured, using the fallback",
+			slog.String("model", model),
+			slog.String("provider", provider))
+		return r.fallback, model
+	}
+
+	if provider, name, found := strings.Cut(model, "/"); found {
+		if client, exists := r.providers[provider]; exists {
+			return client, name
+		}
+	}
+
+	return r.fallback, model
+}
+
+func (r *Router) CompleteStream(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (<-chan completion.StreamToken, error) {
+	client, providerModel := r.route(ctx, req.Model)
+	req.Model = providerModel
+	return client.CompleteStream(ctx, req)
+}
+
+// GenerateImages sends an image generation to the provider of its model, which has to be able to generate images.
+func (r *Router) GenerateImages(
+	ctx context.Context,
+	req completion.CompletionRequest,
+) (*imagegen.Result, error) {
+	client, providerModel := r.route(ctx, req.Model)
+	generator, ok := client.(imagegen.ImageGeneration)
+	if !ok {
+		return nil, fmt.Errorf("provider of model %s can't generate images", req.Model)
+	}
+	req.Model = providerModel
+	return generator.GenerateImages(ctx, req)
+}
@@ -0,0 +1,160 @@
+package router_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/adapter/out/router"
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+	"github.com/vladimish/talk/mocks"
+)
+
+// imageProvider is a provider that generates images too.
+type imageProvider struct {
+	*mocks.MockCompletion
+	*mocks.MockImageGeneration
+}
+
+func setProviderModels(t *testing.T) {
+	t.Helper()
+
+	names := map[string]string{"en": "Test"}
+	catalog := domain.DefaultModelCatalog()
+	catalog.Models = append(catalog.Models,
+		domain.ModelInfo{
+			ID:            "anthropic/claude-sonnet-4",
+			Provider:      domain.ProviderAnthropic,
+			ProviderModel: "claude-sonnet-4-0",
+			Names:         names,
+			TokenType:     domain.TokenTypePremium,
+		},
+		domain.ModelInfo{ID: "gpt-4.1", Provider: domain.ProviderOpenAI, Names: names, TokenType: domain.TokenTypeRegular},
+		domain.ModelInfo{ID: "llama-3.3", Provider: domain.ProviderLocal, Names: names, TokenType: domain.TokenTypeRegular},
+		domain.ModelInfo{
+			ID:        "anthropic/claude-2",
+			Provider:  domain.ProviderAnthropic,
+			TokenType: domain.TokenTypeRegular,
+			Retired:   true,
+		},
+	)
+	require.NoError(t, catalog.Validate())
+	domain.SetModelCatalog(catalog)
+	t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+}
+
+func TestRouter_CompleteStream(t *testing.T) {
+	setProviderModels(t)
+
+	tests := []struct {
+		name  string
+		model string
+		// expectedProvider is the provider the request goes to, empty for the fallback
+		expectedProvider string
+		expectedModel    string
+	}{
+		{
+			name:          "OpenRouter model goes to the fallback by its ID",
+			model:         "google/gemini-2.5-flash",
+			expectedModel: "google/gemini-2.5-flash",
+		},
+		{
+			name:             "catalog model goes to its provider by its provider name",
+			model:            "anthropic/claude-sonnet-4",
+			expectedProvider: domain.ProviderAnthropic,
+			expectedModel:    "claude-sonnet-4-0",
+		},
+		{
+			name:             "provider name defaults to the ID",
+			model:            "gpt-4.1",
+			expectedProvider: domain.ProviderOpenAI,
+			expectedModel:    "gpt-4.1",
+		},
+		{
+			name:          "model of a provider that isn't configured goes to the fallback",
+			model:         "llama-3.3",
+			expectedModel: "llama-3.3",
+		},
+		{
+			name:             "retired model goes to the provider of its prefix",
+			model:            "anthropic/claude-2",
+			expectedProvider: domain.ProviderAnthropic,
+			expectedModel:    "claude-2",
+		},
+		{
+			name:             "unknown model goes to the provider of its prefix",
+			model:            "openai/o3",
+			expectedProvider: domain.ProviderOpenAI,
+			expectedModel:    "o3",
+		},
+		{
+			name:          "unknown model of an unknown provider goes to the fallback",
+			model:         "mistral/mistral-large",
+			expectedModel: "mistral/mistral-large",
+		},
+		{
+			name:          "unknown model without a provider goes to the fallback",
+			model:         "mystery",
+			expectedModel: "mystery",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fallback := mocks.NewMockCompletion(ctrl)
+			providers := map[string]*mocks.MockCompletion{
+				domain.ProviderOpenAI:    mocks.NewMockCompletion(ctrl),
+				domain.ProviderAnthropic: mocks.NewMockCompletion(ctrl),
+			}
+			clients := make(map[string]completion.Completion, len(providers))
+			for provider, client := range providers {
+				clients[provider] = client
+			}
+
+			expected := fallback
+			if tt.expectedProvider != "" {
+				expected = providers[tt.expectedProvider]
+			}
+			// The other providers get no request
+			expected.EXPECT().
+				CompleteStream(gomock.Any(), completion.CompletionRequest{Model: tt.expectedModel}).
+				Return(nil, nil)
+
+			_, err := router.NewRouter(slog.Default(), fallback, clients).
+				CompleteStream(t.Context(), completion.CompletionRequest{Model: tt.model})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRouter_GenerateImages(t *testing.T) {
+	setProviderModels(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fallback := imageProvider{mocks.NewMockCompletion(ctrl), mocks.NewMockImageGeneration(ctrl)}
+	r := router.NewRouter(slog.Default(), fallback, map[string]completion.Completion{
+		domain.ProviderAnthropic: mocks.NewMockCompletion(ctrl),
+	})
+
+	fallback.MockImageGeneration.EXPECT().
+		GenerateImages(gomock.Any(), completion.CompletionRequest{Model: "google/gemini-2.5-flash-image-preview"}).
+		Return(&imagegen.Result{Text: "A cat"}, nil)
+	result, err := r.GenerateImages(t.Context(), completion.CompletionRequest{
+		Model: "google/gemini-2.5-flash-image-preview",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "A cat", result.Text)
+
+	_, err = r.GenerateImages(t.Context(), completion.CompletionRequest{Model: "anthropic/claude-sonnet-4"})
+	require.ErrorContains(t, err, "can't generate images")
+}
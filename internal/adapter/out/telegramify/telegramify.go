@@ -6,24 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/vladimish/talk/internal/port/formatter"
+	"github.com/vladimish/talk/pkg/resilience"
 )
 
 type Client struct {
 	baseURL    string
-	httpClient *http.Client
+	httpClient *resilience.Client
 }
 
-const defaultTimeout = 30 * time.Second
-
-func New(baseURL string) formatter.Formatter {
+// New creates a telegramify client. Formatting has no side effects, so httpClient may retry its POST requests.
+func New(baseURL string, httpClient *resilience.Client) formatter.Formatter {
 	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		baseURL:    baseURL,
+		httpClient: httpClient,
 	}
 }
 
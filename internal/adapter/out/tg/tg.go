@@ -1,6 +1,7 @@
 package tg
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
@@ -354,6 +355,67 @@ func (u *Sender) UpdateMessages(
 	return resultMessageIDs, nil
 }
 
+func (u *Sender) SendDocument(ctx context.Context, externalUserID string, document domain.Document) (string, error) {
+	msg, err := u.bot.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: externalUserID,
+		Document: &models.InputFileUpload{
+			Filename: document.FileName,
+			Data:     bytes.NewReader(document.Data),
+		},
+		Caption: document.Caption,
+	})
+	if err != nil {
+		return "", fmt.Errorf("can't send document: %w", err)
+	}
+
+	return strconv.Itoa(msg.ID), nil
+}
+
+func (u *Sender) SendVoice(ctx context.Context, externalUserID string, voice domain.VoiceMessage) (string, error) {
+	params := &bot.SendVoiceParams{
+		ChatID: externalUserID,
+		Voice: &models.InputFileUpload{
+			Filename: "voice.ogg",
+			Data:     bytes.NewReader(voice.Data),
+		},
+	}
+	if voice.ReplyToMessageID != nil {
+		params.ReplyParameters = &models.ReplyParameters{
+			MessageID: int(*voice.ReplyToMessageID),
+		}
+	}
+
+	msg, err := u.bot.SendVoice(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("can't send voice: %w", err)
+	}
+
+	return strconv.Itoa(msg.ID), nil
+}
+
+func (u *Sender) SendPhoto(ctx context.Context, externalUserID string, photo domain.Photo) (string, error) {
+	params := &bot.SendPhotoParams{
+		ChatID: externalUserID,
+		Photo: &models.InputFileUpload{
+			Filename: "image.png",
+			Data:     bytes.NewReader(photo.Data),
+		},
+		Caption: photo.Caption,
+	}
+	if photo.ReplyToMessageID != nil {
+		params.ReplyParameters = &models.ReplyParameters{
+			MessageID: int(*photo.ReplyToMessageID),
+		}
+	}
+
+	msg, err := u.bot.SendPhoto(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("can't send photo: %w", err)
+	}
+
+	return strconv.Itoa(msg.ID), nil
+}
+
 func (u *Sender) DeleteMessage(ctx context.Context, externalUserID string, messageID string) error {
 	msgID, err := strconv.Atoi(messageID)
 	if err != nil {
@@ -373,6 +435,49 @@ func (u *Sender) DeleteMessage(ctx context.Context, externalUserID string, messa
 	return nil
 }
 
+func (u *Sender) EditMessageKeyboard(
+	ctx context.Context,
+	externalUserID string,
+	messageID string,
+	keyboard *domain.InlineKeyboard,
+) error {
+	msgID, err := strconv.Atoi(messageID)
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	params := &bot.EditMessageReplyMarkupParams{
+		ChatID:    externalUserID,
+		MessageID: msgID,
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = u.buildInlineKeyboard(keyboard)
+	} else {
+		params.ReplyMarkup = &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{}}
+	}
+
+	_, err = u.bot.EditMessageReplyMarkup(ctx, params)
+	if err != nil {
+		if isMessageNotModified(err) {
+			return nil
+		}
+		return fmt.Errorf("can't edit message keyboard: %w", err)
+	}
+
+	return nil
+}
+
+func (u *Sender) AnswerCallbackQuery(ctx context.Context, callbackQueryID string, text string) error {
+	_, err := u.bot.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQueryID,
+		Text:            text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to answer callback query: %w", err)
+	}
+	return nil
+}
+
 func splitText(text string, maxLength int) []string {
 	if len(text) <= maxLength {
 		return []string{text}
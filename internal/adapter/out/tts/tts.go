@@ -0,0 +1,92 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vladimish/talk/internal/port/speech"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+const (
+	// DefaultModel is the speech model of the OpenAI API.
+	DefaultModel = "tts-1"
+	// DefaultVoice is the voice answers are spoken with.
+	DefaultVoice = "alloy"
+)
+
+// maxErrorBody limits how much of an error response ends up in the error.
+const maxErrorBody = 1024
+
+type Client struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	voice      string
+	httpClient *resilience.Client
+}
+
+// New creates a client for an OpenAI-compatible speech API: the OpenAI API itself or a self-hosted
+// server. The API key may be empty for servers that don't check it.
+func New(baseURL, apiKey, model, voice string, httpClient *resilience.Client) speech.Speech {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		voice:      voice,
+		httpClient: httpClient,
+	}
+}
+
+type speechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+func (c *Client) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	jsonData, err := json.Marshal(speechRequest{
+		Model: c.model,
+		Input: text,
+		Voice: c.voice,
+		// Opus comes in an OGG container, which Telegram plays as a voice message
+		ResponseFormat: "opus",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/audio/speech", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("unexpected status code: %d - Response: %s", resp.StatusCode, string(errorBody))
+	}
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	return audio, nil
+}
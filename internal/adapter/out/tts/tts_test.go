@@ -0,0 +1,100 @@
+package tts_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/internal/adapter/out/tts"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+func TestClient_Synthesize(t *testing.T) {
+	audio := []byte("OggS opus")
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		status        int
+		response      []byte
+		expectedAudio []byte
+		// expectedErr is part of the error, empty when the speech is returned
+		expectedErr string
+	}{
+		{
+			name:          "speech is returned",
+			apiKey:        "key",
+			status:        http.StatusOK,
+			response:      audio,
+			expectedAudio: audio,
+		},
+		{
+			name:          "server without an API key",
+			status:        http.StatusOK,
+			response:      audio,
+			expectedAudio: audio,
+		},
+		{
+			name:        "error status",
+			apiKey:      "key",
+			status:      http.StatusBadRequest,
+			response:    []byte(`{"error": {"message": "Input is too long."}}`),
+			expectedErr: "unexpected status code: 400 - Response: {\"error\": {\"message\": \"Input is too long.\"}}",
+		},
+		{
+			name:        "long error response is cut",
+			apiKey:      "key",
+			status:      http.StatusUnauthorized,
+			response:    []byte(strings.Repeat("x", 4096)),
+			expectedErr: "unexpected status code: 401 - Response: " + strings.Repeat("x", 1024),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v1/audio/speech", r.URL.Path)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				if tt.apiKey != "" {
+					assert.Equal(t, "Bearer "+tt.apiKey, r.Header.Get("Authorization"))
+				} else {
+					assert.Empty(t, r.Header.Get("Authorization"))
+				}
+
+				var body map[string]string
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, map[string]string{
+					"model":           "tts-1",
+					"input":           "Ahoy, the treasure is buried under the palm",
+					"voice":           "alloy",
+					"response_format": "opus",
+				}, body)
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write(tt.response)
+			}))
+			defer server.Close()
+
+			client := tts.New(
+				server.URL+"/v1/", tt.apiKey, tts.DefaultModel, tts.DefaultVoice,
+				resilience.NewClient("speech", slog.Default(), resilience.Config{}),
+			)
+
+			speech, err := client.Synthesize(t.Context(), "Ahoy, the treasure is buried under the palm")
+
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAudio, speech)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/vladimish/talk/internal/port/transcription"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+// DefaultModel is the transcription model of the OpenAI API.
+const DefaultModel = "whisper-1"
+
+// maxErrorBody limits how much of an error response ends up in the error.
+const maxErrorBody = 1024
+
+type Client struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *resilience.Client
+}
+
+// New creates a client for an OpenAI-compatible transcription API: the OpenAI API itself or a self-hosted
+// Whisper server. The API key may be empty for servers that don't check it.
+func New(baseURL, apiKey, model string, httpClient *resilience.Client) transcription.Transcription {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+type transcriptionResponse struct {
+	Text string `json:"text"`
+}
+
+func (c *Client) Transcribe(ctx context.Context, audio []byte, mimeType, fileName string) (string, error) {
+	body, contentType, err := c.multipartBody(audio, mimeType, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/audio/transcriptions", body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("unexpected status code: %d - Response: %s", resp.StatusCode, string(errorBody))
+	}
+
+	var result transcriptionResponse
+	if decodeErr := json.NewDecoder(resp.Body).Decode(&result); decodeErr != nil {
+		return "", fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
+
+// multipartBody builds the form of a transcription request and returns it with its content type.
+func (c *Client) multipartBody(audio []byte, mimeType, fileName string) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("model", c.model); err != nil {
+		return nil, "", fmt.Errorf("failed to write model field: %w", err)
+	}
+	if err := writer.WriteField("response_format", "json"); err != nil {
+		return nil, "", fmt.Errorf("failed to write response format field: %w", err)
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file part: %w", err)
+	}
+	if _, err = part.Write(audio); err != nil {
+		return nil, "", fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close form: %w", err)
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
@@ -0,0 +1,103 @@
+package whisper_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/internal/adapter/out/whisper"
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+func TestClient_Transcribe(t *testing.T) {
+	audio := []byte("OggS voice")
+
+	tests := []struct {
+		name         string
+		apiKey       string
+		status       int
+		response     string
+		expectedText string
+		// expectedErr is part of the error, empty when the audio is transcribed
+		expectedErr string
+	}{
+		{
+			name:         "transcription is returned without surrounding spaces",
+			apiKey:       "key",
+			status:       http.StatusOK,
+			response:     `{"text": " Ahoy, where is the treasure? \n"}`,
+			expectedText: "Ahoy, where is the treasure?",
+		},
+		{
+			name:         "server without an API key",
+			status:       http.StatusOK,
+			response:     `{"text": "Ahoy"}`,
+			expectedText: "Ahoy",
+		},
+		{
+			name:        "error status",
+			apiKey:      "key",
+			status:      http.StatusBadRequest,
+			response:    `{"error": {"message": "Invalid file format."}}`,
+			expectedErr: "unexpected status code: 400 - Response: {\"error\": {\"message\": \"Invalid file format.\"}}",
+		},
+		{
+			name:        "response that isn't JSON",
+			apiKey:      "key",
+			status:      http.StatusOK,
+			response:    "Ahoy",
+			expectedErr: "failed to decode response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+				if tt.apiKey != "" {
+					assert.Equal(t, "Bearer "+tt.apiKey, r.Header.Get("Authorization"))
+				} else {
+					assert.Empty(t, r.Header.Get("Authorization"))
+				}
+
+				assert.NoError(t, r.ParseMultipartForm(1<<20))
+				assert.Equal(t, "whisper-1", r.FormValue("model"))
+				assert.Equal(t, "json", r.FormValue("response_format"))
+
+				file, header, err := r.FormFile("file")
+				if assert.NoError(t, err) {
+					defer file.Close()
+					assert.Equal(t, "voice.ogg", header.Filename)
+					assert.Equal(t, "audio/ogg", header.Header.Get("Content-Type"))
+					data, readErr := io.ReadAll(file)
+					assert.NoError(t, readErr)
+					assert.Equal(t, audio, data)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := whisper.New(
+				server.URL+"/v1/", tt.apiKey, whisper.DefaultModel,
+				resilience.NewClient("transcription", slog.Default(), resilience.Config{}),
+			)
+
+			text, err := client.Transcribe(t.Context(), audio, "audio/ogg", "voice.ogg")
+
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedText, text)
+		})
+	}
+}
@@ -3,9 +3,24 @@ package domain
 import "time"
 
 type Conversation struct {
-	ID        int64
-	Name      string
-	UserID    int64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                   int64
+	Name                 string
+	UserID               int64
+	SystemPrompt         *string    // Overrides the user's default system prompt when set
+	ParentConversationID *int64     // Set when the conversation was forked from another one
+	PinnedAt             *time.Time // Pinned conversations are listed first
+	ArchivedAt           *time.Time // Archived conversations are hidden from the conversation list
+	DeletedAt            *time.Time // Deleted conversations are kept until the deletion can't be undone
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// ConversationSummary is a rolling summary of the oldest turns of a conversation.
+// Messages with IDs up to and including LastMessageID are represented by Summary
+// and are no longer sent to the model verbatim.
+type ConversationSummary struct {
+	ConversationID int64
+	Summary        string
+	LastMessageID  int64
+	UpdatedAt      time.Time
 }
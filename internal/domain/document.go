@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// DocumentFormat is a kind of document whose text is extracted and given to the model instead of the file.
+type DocumentFormat string
+
+const (
+	DocumentFormatDOCX     DocumentFormat = "docx"
+	DocumentFormatXLSX     DocumentFormat = "xlsx"
+	DocumentFormatCSV      DocumentFormat = "csv"
+	DocumentFormatMarkdown DocumentFormat = "markdown"
+	DocumentFormatText     DocumentFormat = "text"
+	DocumentFormatCode     DocumentFormat = "code"
+)
+
+// documentExtensions are the formats of documents by file extension, which Telegram often knows better
+// than the MIME type: source files mostly come as application/octet-stream.
+var documentExtensions = map[string]DocumentFormat{
+	".docx": DocumentFormatDOCX,
+	".xlsx": DocumentFormatXLSX,
+	".csv":  DocumentFormatCSV,
+	".tsv":  DocumentFormatCSV,
+	".md":   DocumentFormatMarkdown,
+	".txt":  DocumentFormatText,
+	".log":  DocumentFormatText,
+}
+
+// codeExtensions are the extensions of source and configuration files.
+var codeExtensions = []string{
+	".go", ".py", ".js", ".ts", ".jsx", ".tsx", ".java", ".kt", ".c", ".h", ".cpp", ".hpp", ".cs", ".rb", ".php",
+	".rs", ".swift", ".scala", ".sh", ".sql", ".yaml", ".yml", ".toml", ".xml", ".html", ".css", ".proto", ".lua",
+	".dart", ".vue", ".ex", ".hs", ".json",
+}
+
+// documentMimeTypes are the formats of documents by MIME type, for files without a known extension.
+var documentMimeTypes = map[string]DocumentFormat{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": DocumentFormatDOCX,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       DocumentFormatXLSX,
+	"text/csv":                  DocumentFormatCSV,
+	"text/tab-separated-values": DocumentFormatCSV,
+	"text/markdown":             DocumentFormatMarkdown,
+	"text/x-markdown":           DocumentFormatMarkdown,
+	"application/json":          DocumentFormatCode,
+}
+
+// documentFileNames are source files that are known by their whole name.
+var documentFileNames = map[string]DocumentFormat{
+	"dockerfile": DocumentFormatCode,
+	"makefile":   DocumentFormatCode,
+}
+
+// DocumentFormatOf returns the format of a document by its file name and MIME type, empty when its text
+// can't be extracted. PDFs aren't documents in this sense, models read them as files.
+func DocumentFormatOf(mimeType, fileName string) DocumentFormat {
+	extension := strings.ToLower(path.Ext(fileName))
+	if format, ok := documentExtensions[extension]; ok {
+		return format
+	}
+	if slices.Contains(codeExtensions, extension) {
+		return DocumentFormatCode
+	}
+	if format, ok := documentFileNames[strings.ToLower(path.Base(fileName))]; ok {
+		return format
+	}
+
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	if format, ok := documentMimeTypes[mimeType]; ok {
+		return format
+	}
+	if strings.HasPrefix(mimeType, "text/") {
+		return DocumentFormatText
+	}
+	return ""
+}
+
+// IsDocument reports whether the file is a document whose text is extracted for the model.
+func (f File) IsDocument() bool {
+	return DocumentFormatOf(f.MimeType, f.FileName) != ""
+}
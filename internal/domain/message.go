@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type MessageSender string
 
@@ -20,10 +23,101 @@ type Message struct {
 }
 
 type MessageType struct {
-	Text          string `json:"text"`
-	ImageData     []byte `json:"image_data"`
-	ImageMimeType string `json:"image_mime_type"`
-	PDFData       []byte `json:"pdf_data"`
-	PDFMimeType   string `json:"pdf_mime_type"`
-	PDFFileName   string `json:"pdf_filename"`
+	Text  string `json:"text"`
+	Files []File `json:"files,omitempty"` // Images and documents sent with the message, in order
+	Quote *Quote `json:"quote,omitempty"` // Part of an earlier message the user replied to
+}
+
+// File is an image or a document sent with a message. Stored messages reference the uploaded file only,
+// its content is loaded from the file storage when it's needed.
+type File struct {
+	Data     []byte `json:"data,omitempty"`    // Content of a received file
+	S3Name   string `json:"s3_name,omitempty"` // Object of the uploaded file in the file storage
+	MimeType string `json:"mime_type"`
+	FileName string `json:"file_name,omitempty"` // Original file name of a document
+	// Text extracted from a document for the model, and the object it is kept in
+	Text       string `json:"text,omitempty"`
+	TextS3Name string `json:"text_s3_name,omitempty"`
+}
+
+// Reference returns the file without its content and extracted text, as it's stored with a message.
+func (f File) Reference() File {
+	f.Data = nil
+	f.Text = ""
+	return f
+}
+
+// IsImage reports whether the file is an image.
+func (f File) IsImage() bool {
+	return strings.HasPrefix(f.MimeType, "image/")
+}
+
+// IsPDF reports whether the file is a PDF document.
+func (f File) IsPDF() bool {
+	return f.MimeType == "application/pdf"
+}
+
+// Images returns the images of the files in order.
+func Images(files []File) []File {
+	var images []File
+	for _, file := range files {
+		if file.IsImage() {
+			images = append(images, file)
+		}
+	}
+	return images
+}
+
+// PDFs returns the PDF documents of the files in order.
+func PDFs(files []File) []File {
+	var pdfs []File
+	for _, file := range files {
+		if file.IsPDF() {
+			pdfs = append(pdfs, file)
+		}
+	}
+	return pdfs
+}
+
+// Quote is the part of an earlier message that a user message replies to.
+type Quote struct {
+	MessageID int64  `json:"message_id,omitempty"` // Quoted stored message, 0 if it isn't stored
+	Text      string `json:"text"`
+}
+
+// PromptText returns the text as it is sent to the model, with the quoted part in front of it.
+func (m MessageType) PromptText() string {
+	if m.Quote == nil || m.Quote.Text == "" {
+		return m.Text
+	}
+
+	var builder strings.Builder
+	for line := range strings.SplitSeq(m.Quote.Text, "\n") {
+		builder.WriteString("> ")
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+	builder.WriteString(m.Text)
+	return builder.String()
+}
+
+// MessageVersion is one of the alternative answers generated for a bot message.
+type MessageVersion struct {
+	ID        int64
+	MessageID int64
+	Text      string
+	Model     string
+	CreatedAt time.Time
+}
+
+// MessageSearchResult is a message matching a full-text search, with the matched words highlighted.
+type MessageSearchResult struct {
+	MessageID        int64
+	ConversationID   int64
+	ConversationName string
+	SentBy           MessageSender
+	Snippet          string // Fragments of the message text, matched words are wrapped in **
+	Rank             float32
+	CreatedAt        time.Time
 }
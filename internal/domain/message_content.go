@@ -28,3 +28,23 @@ type MessageContent struct {
 	ReplyToMessageID *int64
 	IsPersistent     bool
 }
+
+// Document is a file sent to the user as a Telegram document.
+type Document struct {
+	FileName string
+	Data     []byte
+	Caption  string
+}
+
+// VoiceMessage is audio sent to the user as a Telegram voice message, OGG with Opus.
+type VoiceMessage struct {
+	Data             []byte
+	ReplyToMessageID *int64
+}
+
+// Photo is an image sent to the user as a Telegram photo.
+type Photo struct {
+	Data             []byte
+	Caption          string
+	ReplyToMessageID *int64
+}
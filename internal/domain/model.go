@@ -0,0 +1,304 @@
+package domain
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ModelInfo represents the complete information for an AI model.
+type ModelInfo struct {
+	ID              string            `json:"id"`                          // Internal model identifier
+	Provider        string            `json:"provider,omitempty"`          // Completion provider, OpenRouter when empty
+	ProviderModel   string            `json:"provider_model,omitempty"`    // Model name at the provider, the ID when empty
+	Names           map[string]string `json:"names"`                       // Display names by language code, "en" is the fallback
+	Cost            int64             `json:"cost"`                        // Token cost per message, the least an answer costs with rates
+	PromptRate      int64             `json:"prompt_rate"`                 // Tokens per million prompt tokens, zero keeps the flat cost
+	CompletionRate  int64             `json:"completion_rate"`             // Tokens per million completion tokens, reasoning included
+	TokenType       TokenType         `json:"token_type"`                  // Type of tokens required
+	ImageSupport    bool              `json:"image_support"`               // Whether the model supports image inputs
+	PDFSupport      bool              `json:"pdf_support"`                 // Whether the model supports PDF inputs
+	MaxImages       int               `json:"max_images,omitempty"`        // Most images in one prompt, no limit when zero
+	MaxPDFs         int               `json:"max_pdfs,omitempty"`          // Most PDF files in one prompt, no limit when zero
+	Reasoning       bool              `json:"reasoning"`                   // Whether the model has reasoning capabilities
+	WebSearch       bool              `json:"web_search"`                  // Whether the model has web search capabilities
+	NoSubscription  bool              `json:"no_subscription"`             // If true, requires active subscription to use
+	SearchCost      *int64            `json:"search_cost,omitempty"`       // Additional cost when using web search (optional)
+	SearchTokenType *TokenType        `json:"search_token_type,omitempty"` // Token type for search cost (optional)
+	FileCost        *int64            `json:"file_cost,omitempty"`         // Additional cost per PDF for the file parser (optional)
+	ImageOutput     bool              `json:"image_output,omitempty"`      // Whether the model answers with generated images
+	ImageCost       *int64            `json:"image_cost,omitempty"`        // Additional cost per generated image (optional)
+	ContextBudget   int               `json:"context_budget"`              // Max prompt tokens per request, older turns get summarized
+	Fallbacks       []string          `json:"fallbacks,omitempty"`         // Models that answer in turn when the provider fails
+	Retired         bool              `json:"retired,omitempty"`           // Retired models are hidden and their users are moved on
+	ReplacedBy      string            `json:"replaced_by,omitempty"`       // Model that users of a retired model are moved to (optional)
+}
+
+// Completion providers a model can be served by.
+const (
+	ProviderOpenRouter = "openrouter"
+	ProviderOpenAI     = "openai"
+	ProviderAnthropic  = "anthropic"
+	ProviderLocal      = "local" // Self-hosted OpenAI-compatible server, e.g. Ollama or vLLM
+)
+
+// Context budget constants (in tokens).
+const (
+	ContextBudgetSmall  = 16000
+	ContextBudgetMedium = 32000
+	ContextBudgetLarge  = 64000
+)
+
+// ModelCatalog is the set of models users can choose from, in the order they are offered.
+type ModelCatalog struct {
+	// DefaultModel is selected for new users and replaces retired models without a replacement.
+	DefaultModel string `json:"default_model"`
+	// TokensPerUSD converts provider prices into model rates when the catalog is synced with the provider.
+	TokensPerUSD map[TokenType]float64 `json:"tokens_per_usd,omitempty"`
+	Models       []ModelInfo           `json:"models"`
+}
+
+// defaultModelCatalog is the catalog the bot starts with until a configured one is loaded.
+//
+//go:embed models.json
+var defaultModelCatalog []byte
+
+// modelCatalog is replaced as a whole on reload, so readers always see a consistent catalog.
+var modelCatalog atomic.Pointer[ModelCatalog]
+
+// builtinModelCatalog is the catalog in use until one is set.
+var builtinModelCatalog = sync.OnceValue(func() *ModelCatalog {
+	catalog, err := ParseModelCatalog(defaultModelCatalog)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default model catalog: %v", err))
+	}
+	return catalog
+})
+
+// currentModelCatalog returns the catalog in use.
+func currentModelCatalog() *ModelCatalog {
+	if catalog := modelCatalog.Load(); catalog != nil {
+		return catalog
+	}
+	return builtinModelCatalog()
+}
+
+// DefaultModelCatalog returns a copy of the built-in catalog.
+func DefaultModelCatalog() *ModelCatalog {
+	catalog, _ := ParseModelCatalog(defaultModelCatalog)
+	return catalog
+}
+
+// ParseModelCatalog decodes a catalog and checks that it can be used.
+func ParseModelCatalog(data []byte) (*ModelCatalog, error) {
+	var catalog ModelCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("can't decode model catalog: %w", err)
+	}
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Validate checks that model IDs are unique, replacements and the default model can be selected
+// and at least one model is available.
+func (c *ModelCatalog) Validate() error {
+	models := make(map[string]*ModelInfo, len(c.Models))
+	for i := range c.Models {
+		model := &c.Models[i]
+		if model.ID == "" {
+			return errors.New("model without an ID")
+		}
+		if _, exists := models[model.ID]; exists {
+			return fmt.Errorf("duplicate model %s", model.ID)
+		}
+		if model.TokenType != TokenTypeRegular && model.TokenType != TokenTypePremium {
+			return fmt.Errorf("model %s has unknown token type %q", model.ID, model.TokenType)
+		}
+		switch model.Provider {
+		case "", ProviderOpenRouter, ProviderOpenAI, ProviderAnthropic, ProviderLocal:
+		default:
+			return fmt.Errorf("model %s has unknown provider %q", model.ID, model.Provider)
+		}
+		if model.ImageCost != nil && !model.ImageOutput {
+			return fmt.Errorf("model %s has an image cost but doesn't generate images", model.ID)
+		}
+		if model.MaxImages < 0 || model.MaxPDFs < 0 {
+			return fmt.Errorf("model %s has a negative attachment limit", model.ID)
+		}
+		if !model.Retired && model.Names["en"] == "" {
+			return fmt.Errorf("model %s has no English name", model.ID)
+		}
+		models[model.ID] = model
+	}
+
+	defaultModel, exists := models[c.DefaultModel]
+	if !exists || defaultModel.Retired {
+		return fmt.Errorf("default model %q is not available", c.DefaultModel)
+	}
+
+	for _, model := range c.Models {
+		// Retired fallbacks are skipped, so only unknown ones are a mistake
+		for _, fallback := range model.Fallbacks {
+			if _, fallbackExists := models[fallback]; !fallbackExists || fallback == model.ID {
+				return fmt.Errorf("model %s falls back to %q, which is not a different model", model.ID, fallback)
+			}
+		}
+
+		if model.ReplacedBy == "" {
+			continue
+		}
+		replacement, replacementExists := models[model.ReplacedBy]
+		if !replacementExists || replacement.Retired {
+			return fmt.Errorf("model %s is replaced by %q, which is not available", model.ID, model.ReplacedBy)
+		}
+	}
+
+	return nil
+}
+
+// SetModelCatalog replaces the catalog used by the bot.
+func SetModelCatalog(catalog *ModelCatalog) {
+	modelCatalog.Store(catalog)
+}
+
+// AvailableModels returns the models users can choose from.
+func AvailableModels() []ModelInfo {
+	catalog := currentModelCatalog()
+
+	models := make([]ModelInfo, 0, len(catalog.Models))
+	for _, model := range catalog.Models {
+		if !model.Retired {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// GetModelByID returns the model info for a given model ID, or nil when the model is unknown or retired.
+func GetModelByID(modelID string) *ModelInfo {
+	for _, model := range currentModelCatalog().Models {
+		if model.ID == modelID && !model.Retired {
+			return &model
+		}
+	}
+	return nil
+}
+
+// DefaultModelID returns the model selected for new users.
+func DefaultModelID() string {
+	return currentModelCatalog().DefaultModel
+}
+
+// ReplacementModel returns the model that users of an unavailable model are moved to: the replacement
+// of a retired model, or the default model when there is none or the model is unknown.
+func ReplacementModel(modelID string) *ModelInfo {
+	catalog := currentModelCatalog()
+	for _, model := range catalog.Models {
+		if model.ID == modelID && model.ReplacedBy != "" {
+			if replacement := GetModelByID(model.ReplacedBy); replacement != nil {
+				return replacement
+			}
+		}
+	}
+	return GetModelByID(catalog.DefaultModel)
+}
+
+// CompletionProvider returns the provider the model is served by.
+func (m *ModelInfo) CompletionProvider() string {
+	if m.Provider == "" {
+		return ProviderOpenRouter
+	}
+	return m.Provider
+}
+
+// CompletionModel returns the name of the model at its provider.
+func (m *ModelInfo) CompletionModel() string {
+	if m.ProviderModel == "" {
+		return m.ID
+	}
+	return m.ProviderModel
+}
+
+// TakesImages reports whether the model can take that many images in one prompt.
+func (m *ModelInfo) TakesImages(count int) bool {
+	return count == 0 || (m.ImageSupport && (m.MaxImages == 0 || count <= m.MaxImages))
+}
+
+// TakesPDFs reports whether the model can take that many PDF files in one prompt.
+func (m *ModelInfo) TakesPDFs(count int) bool {
+	return count == 0 || (m.PDFSupport && (m.MaxPDFs == 0 || count <= m.MaxPDFs))
+}
+
+// GetDisplayName returns the model name in the given language, falling back to English and the model ID.
+func (m *ModelInfo) GetDisplayName(language string) string {
+	if name := m.Names[language]; name != "" {
+		return name
+	}
+	if name := m.Names["en"]; name != "" {
+		return name
+	}
+	return m.ID
+}
+
+// GetDisplayNameWithEmojis returns the model display name with appropriate capability emojis.
+func (m *ModelInfo) GetDisplayNameWithEmojis(language string) string {
+	name := m.GetDisplayName(language)
+
+	// Add capability emojis
+	if m.ImageSupport {
+		name += " 👁️"
+	}
+	if m.PDFSupport {
+		name += " 📄" // Page emoji indicates PDF document support
+	}
+	if m.Reasoning {
+		name += " 🧠"
+	}
+	if m.WebSearch {
+		name += " 🌐"
+	}
+	if m.ImageOutput {
+		name += " 🎨"
+	}
+
+	return name
+}
+
+// CanUserUseModel checks if a user can use a specific model based on subscription and token balance.
+func CanUserUseModel(model *ModelInfo, hasActiveSubscription bool, tokenBalance TokenBalance) bool {
+	// Check subscription requirement
+	if model.NoSubscription && !hasActiveSubscription {
+		return false
+	}
+
+	// Check token balance requirement
+	switch model.TokenType {
+	case TokenTypePremium:
+		return tokenBalance.PremiumBalance >= model.Cost
+	case TokenTypeRegular:
+		return tokenBalance.RegularBalance >= model.Cost
+	}
+
+	return false
+}
+
+// GetDisplayNameForUser returns the model display name with availability indicator for a specific user.
+func (m *ModelInfo) GetDisplayNameForUser(
+	language string,
+	hasActiveSubscription bool,
+	tokenBalance TokenBalance,
+) string {
+	displayName := m.GetDisplayNameWithEmojis(language)
+
+	// Add red cross if user can't use the model
+	if !CanUserUseModel(m, hasActiveSubscription, tokenBalance) {
+		displayName = "❌ " + displayName
+	}
+
+	return displayName
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "github.com/vladimish/talk/pkg/i18n"
+
+// DefaultSystemPrompt is used when neither the conversation nor the user has a custom prompt.
+const DefaultSystemPrompt = "You are a helpful assistant."
+
+// MaxSystemPromptLength limits custom system prompts (in characters).
+const MaxSystemPromptLength = 4000
+
+// Persona is a preset system prompt that users can pick instead of writing their own.
+type Persona struct {
+	ID      string `json:"id"`
+	I18nKey string `json:"i18n_key"` // Internationalization key for the button label
+	Prompt  string `json:"prompt"`
+}
+
+// AvailablePersonas defines preset personas shown in the persona selection keyboard.
+var AvailablePersonas = []Persona{
+	{
+		ID:      "assistant",
+		I18nKey: i18n.PersonaAssistant,
+		Prompt:  DefaultSystemPrompt,
+	},
+	{
+		ID:      "programmer",
+		I18nKey: i18n.PersonaProgrammer,
+		Prompt: "You are a senior software engineer. Give precise, idiomatic code with short explanations. " +
+			"Point out bugs, edge cases and security issues. Prefer concrete examples over theory.",
+	},
+	{
+		ID:      "translator",
+		I18nKey: i18n.PersonaTranslator,
+		Prompt: "You are a professional translator. Translate the user's text into the language they ask for " +
+			"(English by default). Preserve meaning, tone and formatting. Reply with the translation only.",
+	},
+	{
+		ID:      "editor",
+		I18nKey: i18n.PersonaEditor,
+		Prompt: "You are a careful editor. Fix grammar, spelling and style in the user's text while keeping " +
+			"their voice. Return the improved text first, then a short list of the most important changes.",
+	},
+	{
+		ID:      "teacher",
+		I18nKey: i18n.PersonaTeacher,
+		Prompt: "You are a patient teacher. Explain concepts step by step with simple examples, " +
+			"check understanding with a short question at the end and avoid jargon unless you define it.",
+	},
+	{
+		ID:      "concise",
+		I18nKey: i18n.PersonaConcise,
+		Prompt:  "You are a helpful assistant. Answer as briefly as possible, without preambles or repetition.",
+	},
+}
+
+// GetPersonaByLabel returns the persona whose localized label matches text.
+func GetPersonaByLabel(language, text string) *Persona {
+	for _, persona := range AvailablePersonas {
+		if i18n.GetString(language, persona.I18nKey) == text {
+			return &persona
+		}
+	}
+	return nil
+}
+
+// ResolveSystemPrompt returns the effective system prompt for a conversation.
+// The conversation prompt wins over the user's default, which wins over DefaultSystemPrompt.
+func ResolveSystemPrompt(user *User, conversation *Conversation) string {
+	if conversation != nil && conversation.SystemPrompt != nil && *conversation.SystemPrompt != "" {
+		return *conversation.SystemPrompt
+	}
+	if user != nil && user.DefaultSystemPrompt != nil && *user.DefaultSystemPrompt != "" {
+		return *user.DefaultSystemPrompt
+	}
+	return DefaultSystemPrompt
+}
@@ -0,0 +1,128 @@
+package domain
+
+import "fmt"
+
+// tokensPerRate is the number of model tokens that ModelInfo.PromptRate and CompletionRate are given for.
+const tokensPerRate = 1_000_000
+
+// AnswerUsage is what generating an answer took, as reported by the provider or estimated before the call.
+type AnswerUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	WebSearch        bool
+	Files            int
+	Images           int // Generated images
+}
+
+// TokenCharge is a single item of the bill for an answer.
+type TokenCharge struct {
+	TokenType   TokenType
+	Amount      int64 // positive, the transaction debits it
+	Description string
+}
+
+// PriceAnswer converts the usage of an answer into the tokens charged for it, one item per billed resource.
+// Models without rates charge their flat Cost, and with rates an answer never costs less than it.
+func (m *ModelInfo) PriceAnswer(usage AnswerUsage) []TokenCharge {
+	charges := m.priceTokens(usage.PromptTokens, usage.CompletionTokens)
+
+	if usage.WebSearch && m.SearchCost != nil && m.SearchTokenType != nil {
+		charges = append(charges, TokenCharge{
+			TokenType:   *m.SearchTokenType,
+			Amount:      *m.SearchCost,
+			Description: "Web search cost",
+		})
+	}
+
+	if usage.Files > 0 && m.FileCost != nil {
+		charges = append(charges, TokenCharge{
+			TokenType:   m.TokenType,
+			Amount:      *m.FileCost * int64(usage.Files),
+			Description: fmt.Sprintf("File parser cost: %d files", usage.Files),
+		})
+	}
+
+	if usage.Images > 0 && m.ImageCost != nil {
+		charges = append(charges, TokenCharge{
+			TokenType:   m.TokenType,
+			Amount:      *m.ImageCost * int64(usage.Images),
+			Description: fmt.Sprintf("Image generation cost: %d images", usage.Images),
+		})
+	}
+
+	return charges
+}
+
+func (m *ModelInfo) priceTokens(promptTokens, completionTokens int) []TokenCharge {
+	if m.PromptRate == 0 && m.CompletionRate == 0 {
+		return []TokenCharge{{TokenType: m.TokenType, Amount: m.Cost}}
+	}
+
+	prompt := int64(promptTokens) * m.PromptRate
+	completion := int64(completionTokens) * m.CompletionRate
+
+	// Rounding the items separately would charge a short answer twice the minimum
+	total := (prompt + completion + tokensPerRate - 1) / tokensPerRate
+	if total <= m.Cost {
+		return []TokenCharge{{TokenType: m.TokenType, Amount: m.Cost}}
+	}
+
+	promptAmount := min((prompt+tokensPerRate/2)/tokensPerRate, total)
+
+	var charges []TokenCharge
+	if promptAmount > 0 {
+		charges = append(charges, TokenCharge{
+			TokenType:   m.TokenType,
+			Amount:      promptAmount,
+			Description: fmt.Sprintf("Prompt cost: %d tokens", promptTokens),
+		})
+	}
+	if total > promptAmount {
+		charges = append(charges, TokenCharge{
+			TokenType:   m.TokenType,
+			Amount:      total - promptAmount,
+			Description: fmt.Sprintf("Completion cost: %d tokens", completionTokens),
+		})
+	}
+
+	return charges
+}
+
+// ChargeTotals sums the charges per token type, keeping the order in which the types first appear.
+func ChargeTotals(charges []TokenCharge) []TokenCharge {
+	var totals []TokenCharge
+	for _, charge := range charges {
+		found := false
+		for i := range totals {
+			if totals[i].TokenType == charge.TokenType {
+				totals[i].Amount += charge.Amount
+				found = true
+				break
+			}
+		}
+		if !found {
+			totals = append(totals, TokenCharge{TokenType: charge.TokenType, Amount: charge.Amount})
+		}
+	}
+	return totals
+}
+
+// charactersPerRate is the number of characters that SpeechPrice.Rate is given for.
+const charactersPerRate = 1_000_000
+
+// SpeechPrice is what synthesizing spoken replies costs.
+type SpeechPrice struct {
+	Model     string // Speech model the charges are recorded with
+	TokenType TokenType
+	Rate      int64 // Tokens per million characters
+}
+
+// PriceSpeech converts the characters of a spoken reply into the tokens charged for them, at least one token.
+func (p SpeechPrice) PriceSpeech(characters int) TokenCharge {
+	amount := (int64(characters)*p.Rate + charactersPerRate - 1) / charactersPerRate
+	return TokenCharge{
+		TokenType:   p.TokenType,
+		Amount:      max(amount, 1),
+		Description: fmt.Sprintf("Speech cost: %d characters", characters),
+	}
+}
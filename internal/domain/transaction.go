@@ -1,12 +1,6 @@
 package domain
 
-import (
-	"time"
-
-	"github.com/vladimish/talk/pkg/pointer"
-
-	"github.com/vladimish/talk/pkg/i18n"
-)
+import "time"
 
 // TokenType represents the type of token.
 type TokenType string
@@ -24,6 +18,10 @@ const (
 	TransactionTypeMessageCost   TransactionType = "message_cost"
 	TransactionTypeAdminCredit   TransactionType = "admin_credit"
 	TransactionTypeAdminDebit    TransactionType = "admin_debit"
+	// TransactionTypeReservation holds the estimated cost of an answer while it is generated.
+	TransactionTypeReservation TransactionType = "reservation"
+	// TransactionTypeReservationRelease returns a reservation once the answer is settled or abandoned.
+	TransactionTypeReservationRelease TransactionType = "reservation_release"
 )
 
 // Transaction represents a token transaction.
@@ -43,228 +41,3 @@ type TokenBalance struct {
 	PremiumBalance int64 `json:"premium_balance"`
 	RegularBalance int64 `json:"regular_balance"`
 }
-
-// ModelInfo represents the complete information for an AI model.
-type ModelInfo struct {
-	ID              string     `json:"id"`                          // Internal model identifier
-	DisplayName     string     `json:"display_name"`                // User-friendly name for buttons (deprecated - use I18nKey)
-	I18nKey         string     `json:"i18n_key"`                    // Internationalization key for display name
-	Cost            int64      `json:"cost"`                        // Token cost per message
-	TokenType       TokenType  `json:"token_type"`                  // Type of tokens required
-	ImageSupport    bool       `json:"image_support"`               // Whether the model supports image inputs
-	PDFSupport      bool       `json:"pdf_support"`                 // Whether the model supports PDF inputs
-	Reasoning       bool       `json:"reasoning"`                   // Whether the model has reasoning capabilities
-	WebSearch       bool       `json:"web_search"`                  // Whether the model has web search capabilities
-	NoSubscription  bool       `json:"no_subscription"`             // If true, requires active subscription to use
-	SearchCost      *int64     `json:"search_cost,omitempty"`       // Additional cost when using web search (optional)
-	SearchTokenType *TokenType `json:"search_token_type,omitempty"` // Token type for search cost (optional)
-}
-
-// Token cost constants.
-const (
-	TokenCostLow    int64 = 1
-	TokenCostMedium int64 = 3
-	TokenCostHigh   int64 = 5
-)
-
-// AvailableModels defines all available AI models with their complete information.
-var AvailableModels = []ModelInfo{
-	{
-		ID:              "google/gemini-2.5-flash",
-		I18nKey:         i18n.ModelGeminiFlash,
-		Cost:            TokenCostLow,
-		TokenType:       TokenTypeRegular,
-		ImageSupport:    true,
-		PDFSupport:      true,
-		Reasoning:       false,
-		WebSearch:       true,
-		NoSubscription:  false,
-		SearchCost:      pointer.To(int64(1)),
-		SearchTokenType: pointer.To(TokenTypePremium),
-	},
-	{
-		ID:             "openai/gpt-4o",
-		I18nKey:        i18n.ModelGPT4o,
-		Cost:           TokenCostLow,
-		TokenType:      TokenTypePremium,
-		ImageSupport:   true,
-		PDFSupport:     true,
-		Reasoning:      false,
-		WebSearch:      false,
-		NoSubscription: false,
-	},
-	{
-		ID:             "openai/gpt-4o-mini",
-		I18nKey:        i18n.ModelGPT4oMini,
-		Cost:           TokenCostLow,
-		TokenType:      TokenTypeRegular,
-		ImageSupport:   true,
-		PDFSupport:     true,
-		Reasoning:      false,
-		WebSearch:      false,
-		NoSubscription: false,
-	},
-	{
-		ID:             "anthropic/claude-4-sonnet-20250522",
-		I18nKey:        i18n.ModelClaude4Sonnet,
-		Cost:           TokenCostLow,
-		TokenType:      TokenTypePremium,
-		ImageSupport:   true,
-		PDFSupport:     true,
-		Reasoning:      false,
-		WebSearch:      false,
-		NoSubscription: false,
-	},
-	{
-		ID:              "google/gemini-2.5-pro-preview",
-		I18nKey:         i18n.ModelGeminiPro,
-		Cost:            TokenCostLow,
-		TokenType:       TokenTypePremium,
-		ImageSupport:    true,
-		PDFSupport:      true,
-		Reasoning:       false,
-		WebSearch:       true,
-		NoSubscription:  true,
-		SearchCost:      pointer.To(int64(1)),
-		SearchTokenType: pointer.To(TokenTypePremium),
-	},
-	{
-		ID:             "openai/o3-mini",
-		I18nKey:        i18n.ModelO3Mini,
-		Cost:           TokenCostMedium,
-		TokenType:      TokenTypePremium,
-		ImageSupport:   false,
-		PDFSupport:     false,
-		Reasoning:      true,
-		WebSearch:      false,
-		NoSubscription: true,
-	},
-	{
-		ID:             "deepseek/deepseek-chat-v3-0324:free",
-		I18nKey:        i18n.ModelDeepSeekChat,
-		Cost:           TokenCostLow,
-		TokenType:      TokenTypeRegular,
-		ImageSupport:   false,
-		PDFSupport:     false,
-		Reasoning:      false,
-		WebSearch:      false,
-		NoSubscription: false,
-	},
-	{
-		ID:             "deepseek/deepseek-r1:free",
-		I18nKey:        i18n.ModelDeepSeekR1,
-		Cost:           TokenCostLow,
-		TokenType:      TokenTypeRegular,
-		ImageSupport:   false,
-		PDFSupport:     false,
-		Reasoning:      true,
-		WebSearch:      false,
-		NoSubscription: false,
-	},
-}
-
-// GetModelByID returns the model info for a given model ID.
-func GetModelByID(modelID string) *ModelInfo {
-	for _, model := range AvailableModels {
-		if model.ID == modelID {
-			return &model
-		}
-	}
-	return nil
-}
-
-// GetDisplayNameWithEmojis returns the model display name with appropriate capability emojis.
-func (m *ModelInfo) GetDisplayNameWithEmojis(language string) string {
-	// Get localized name or fallback to DisplayName
-	name := i18n.GetString(language, m.I18nKey)
-	if name == m.I18nKey && m.DisplayName != "" {
-		// Fallback to DisplayName if i18n key not found
-		name = m.DisplayName
-	}
-
-	// Add capability emojis
-	if m.ImageSupport {
-		name += " 👁️"
-	}
-	if m.PDFSupport {
-		name += " 📄" // Page emoji indicates PDF document support
-	}
-	if m.Reasoning {
-		name += " 🧠"
-	}
-	if m.WebSearch {
-		name += " 🌐"
-	}
-
-	return name
-}
-
-// CanUserUseModel checks if a user can use a specific model based on subscription and token balance.
-func CanUserUseModel(model *ModelInfo, hasActiveSubscription bool, tokenBalance TokenBalance) bool {
-	// Check subscription requirement
-	if model.NoSubscription && !hasActiveSubscription {
-		return false
-	}
-
-	// Check token balance requirement
-	switch model.TokenType {
-	case TokenTypePremium:
-		return tokenBalance.PremiumBalance >= model.Cost
-	case TokenTypeRegular:
-		return tokenBalance.RegularBalance >= model.Cost
-	}
-
-	return false
-}
-
-// GetDisplayNameForUser returns the model display name with availability indicator for a specific user.
-func (m *ModelInfo) GetDisplayNameForUser(
-	language string,
-	hasActiveSubscription bool,
-	tokenBalance TokenBalance,
-) string {
-	displayName := m.GetDisplayNameWithEmojis(language)
-
-	// Add red cross if user can't use the model
-	if !CanUserUseModel(m, hasActiveSubscription, tokenBalance) {
-		displayName = "❌ " + displayName
-	}
-
-	return displayName
-}
-
-// ModelCosts defines the token costs for each AI model (for backward compatibility).
-var ModelCosts = map[string]ModelCost{
-	"google/gemini-2.5-flash": {
-		ModelName: "google/gemini-2.5-flash",
-		Cost:      TokenCostLow,
-		TokenType: TokenTypeRegular,
-	},
-	"openai/gpt-4o": {
-		ModelName: "openai/gpt-4o",
-		Cost:      TokenCostHigh,
-		TokenType: TokenTypePremium,
-	},
-	"openai/gpt-4o-mini": {
-		ModelName: "openai/gpt-4o-mini",
-		Cost:      TokenCostLow,
-		TokenType: TokenTypeRegular,
-	},
-	"anthropic/claude-3.5-sonnet": {
-		ModelName: "anthropic/claude-3.5-sonnet",
-		Cost:      TokenCostMedium,
-		TokenType: TokenTypePremium,
-	},
-	"anthropic/claude-3.5-haiku": {
-		ModelName: "anthropic/claude-3.5-haiku",
-		Cost:      TokenCostLow,
-		TokenType: TokenTypeRegular,
-	},
-}
-
-// ModelCost represents the cost configuration for AI models (for backward compatibility).
-type ModelCost struct {
-	ModelName string    `json:"model_name"`
-	Cost      int64     `json:"cost"`
-	TokenType TokenType `json:"token_type"`
-}
@@ -11,6 +11,14 @@ type CallbackQuery struct {
 	Data           string `json:"data"`
 }
 
+// EditedMessage is a change of the text of a message the user sent earlier.
+type EditedMessage struct {
+	ExternalUserID    string `json:"external_user_id"`
+	UserLanguage      string `json:"user_language"`
+	ExternalMessageID int    `json:"external_message_id"` // Telegram message ID
+	MessageText       string `json:"message_text"`
+}
+
 type PreCheckoutQuery struct {
 	ID               string `json:"id"`
 	ExternalUserID   string `json:"external_user_id"`
@@ -35,14 +43,21 @@ type Update struct {
 	ExternalUserID    string             `json:"external_user_id"`
 	UserLanguage      string             `json:"user_language"`
 	MessageText       string             `json:"message_text"`
-	ImageData         []byte             `json:"image_data,omitempty"`      // Base64 encoded image data
-	ImageMimeType     string             `json:"image_mime_type,omitempty"` // MIME type of the image
-	PDFData           []byte             `json:"pdf_data,omitempty"`        // Base64 encoded PDF data
-	PDFMimeType       string             `json:"pdf_mime_type,omitempty"`   // MIME type of the PDF
-	PDFFileName       string             `json:"pdf_filename,omitempty"`    // Original file name
-	ExternalMessageID int                `json:"external_message_id"`       // Telegram message ID
-	ReceivedAt        time.Time          `json:"received_at"`               // Timestamp when message was received
+	Files             []File             `json:"files,omitempty"`               // Images and PDFs, in the order they were sent
+	MediaGroupID      string             `json:"media_group_id,omitempty"`      // Telegram album the message belongs to
+	Voice             *File              `json:"voice,omitempty"`               // Voice message or audio file to transcribe
+	DocumentData      []byte             `json:"document_data,omitempty"`       // Other supported documents, e.g. data exports
+	DocumentFileName  string             `json:"document_filename,omitempty"`   // Original file name of the document
+	ExternalMessageID int                `json:"external_message_id"`           // Telegram message ID
+	ReplyToMessageID  int                `json:"reply_to_message_id,omitempty"` // Telegram ID of the replied message
+	QuoteText         string             `json:"quote_text,omitempty"`          // Part of the replied message the user quoted
+	ReceivedAt        time.Time          `json:"received_at"`                   // Timestamp when message was received
 	CallbackQuery     *CallbackQuery     `json:"callback_query,omitempty"`
 	PreCheckoutQuery  *PreCheckoutQuery  `json:"pre_checkout_query,omitempty"`
 	SuccessfulPayment *SuccessfulPayment `json:"successful_payment,omitempty"`
 }
+
+// HasPrompt reports whether the update carries something to answer: text or files.
+func (u Update) HasPrompt() bool {
+	return u.MessageText != "" || len(u.Files) > 0
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// MessageUsage is what generating a bot answer took, as reported by the provider.
+type MessageUsage struct {
+	ID     int64
+	UserID int64
+	// MessageID is nil once the answer is deleted, the usage is kept for the totals.
+	MessageID        *int64
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// ReasoningTokens are the part of CompletionTokens the model spent on thinking.
+	ReasoningTokens int
+	// Cost is in USD, nil when the provider didn't report it.
+	Cost      *float64
+	CreatedAt time.Time
+}
+
+// UsageTotals sums up the usage of all answers of a user.
+type UsageTotals struct {
+	Answers          int64
+	PromptTokens     int64
+	CompletionTokens int64
+	ReasoningTokens  int64
+	Cost             float64
+}
@@ -3,13 +3,16 @@ package domain
 import "time"
 
 const (
-	UserStateMenu             = "menu"
-	UserStateConversation     = "conversation"
-	UserStateModelSelect      = "model_select"
-	UserStateConversationList = "conversation_list"
-	UserStateSettings         = "settings"
-	UserStateLanguageSelect   = "language_select"
-	UserStateProfile          = "profile"
+	UserStateMenu                      = "menu"
+	UserStateConversation              = "conversation"
+	UserStateModelSelect               = "model_select"
+	UserStateConversationList          = "conversation_list"
+	UserStateSettings                  = "settings"
+	UserStateLanguageSelect            = "language_select"
+	UserStateProfile                   = "profile"
+	UserStateDefaultPersonaSelect      = "default_persona_select"
+	UserStateConversationPersonaSelect = "conversation_persona_select"
+	UserStateConversationRename        = "conversation_rename"
 )
 
 type User struct {
@@ -21,6 +24,11 @@ type User struct {
 	CurrentConversationID  *int64
 	ConversationListOffset int
 	WebSearchEnabled       bool
-	CreatedAt              time.Time
-	UpdatedAt              time.Time
+	DefaultSystemPrompt    *string
+	// ShowUsage adds the tokens and cost an answer took below it.
+	ShowUsage bool
+	// VoiceReplies sends answers as voice messages too.
+	VoiceReplies bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
@@ -1,43 +1,100 @@
 package completion
 
-import (
-	"context"
-
-	"github.com/vladimish/talk/internal/domain"
-)
+import "context"
 
 //go:generate go tool mockgen -source=completion.go -destination=../../../mocks/mock_completion.go -package=mocks
 
 type StreamToken struct {
 	Content   string
 	Reasoning string // Reasoning tokens from models that support them
+	Usage     *Usage // Set on the last token when the provider reports usage
 	Error     error
 }
 
-type FileAttachment struct {
+// Usage is what a completion took, as reported by the provider at the end of the stream.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	ReasoningTokens  int      // Part of CompletionTokens spent on reasoning
+	Cost             *float64 // In USD, nil when the provider didn't report it
+}
+
+// PartType is the kind of content a message part carries.
+type PartType string
+
+const (
+	PartTypeText  PartType = "text"
+	PartTypeImage PartType = "image"
+	PartTypeFile  PartType = "file"
+	PartTypeAudio PartType = "audio"
+)
+
+// Part is a piece of message content. Images, files and audio are sent as their data when it is set,
+// otherwise the provider downloads them from the URL.
+type Part struct {
+	Type     PartType
+	Text     string // Text of a text part
 	URL      string
 	MimeType string
 	FileName string
-	Data     []byte // Raw file data for direct processing
+	Data     []byte
+}
+
+// Role is who a message is from.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+type Message struct {
+	Role  Role
+	Parts []Part
+}
+
+// ReasoningEffort is how long a reasoning model thinks before it answers.
+type ReasoningEffort string
+
+const (
+	ReasoningNone   ReasoningEffort = ""
+	ReasoningLow    ReasoningEffort = "low"
+	ReasoningMedium ReasoningEffort = "medium"
+	ReasoningHigh   ReasoningEffort = "high"
+)
+
+// Parameters tune the generation, zero values leave the provider defaults.
+type Parameters struct {
+	MaxTokens   int
+	Temperature *float64
+	Reasoning   ReasoningEffort
+}
+
+// PDFEngine is how OpenRouter turns PDFs into something the model reads.
+type PDFEngine string
+
+const (
+	PDFEngineDefault PDFEngine = ""
+	PDFEngineNative  PDFEngine = "native"      // The model reads the file itself
+	PDFEngineText    PDFEngine = "pdf-text"    // Text extraction, free
+	PDFEngineOCR     PDFEngine = "mistral-ocr" // OCR for scans, paid
+)
+
+// Plugins are the tools the provider may use while answering. Providers without a tool ignore it.
+type Plugins struct {
+	WebSearch bool
+	PDFEngine PDFEngine
+}
+
+// CompletionRequest is everything a completion is generated from.
+type CompletionRequest struct {
+	Model        string
+	SystemPrompt string
+	Messages     []Message
+	Parameters   Parameters
+	Plugins      Plugins
 }
 
 type Completion interface {
-	CompleteStream(
-		ctx context.Context,
-		model string,
-		systemPrompt string,
-		messages []*domain.Message,
-		currentImageURL string, // Empty string if no image
-		webSearchEnabled bool, // Whether to enable web search plugin
-	) (<-chan StreamToken, error)
-
-	CompleteStreamWithAttachments(
-		ctx context.Context,
-		model string,
-		systemPrompt string,
-		messages []*domain.Message,
-		imageAttachment *FileAttachment, // nil if no image
-		pdfAttachment *FileAttachment, // nil if no PDF
-		webSearchEnabled bool, // Whether to enable web search plugin
-	) (<-chan StreamToken, error)
+	CompleteStream(ctx context.Context, req CompletionRequest) (<-chan StreamToken, error)
 }
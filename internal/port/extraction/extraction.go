@@ -0,0 +1,30 @@
+package extraction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vladimish/talk/internal/domain"
+)
+
+//go:generate go tool mockgen -source=extraction.go -destination=../../../mocks/mock_extraction.go -package=mocks
+
+var (
+	// ErrUnsupported is returned for files that aren't documents of a supported format, or are binary.
+	ErrUnsupported = errors.New("unsupported document")
+	// ErrTooLarge is returned for documents whose content exceeds the size limit.
+	ErrTooLarge = errors.New("document is too large")
+)
+
+// Document is the text of a document, ready to be given to the model.
+type Document struct {
+	Text string
+	// Characters is the length of the whole text, more than Text has when it was truncated.
+	Characters int
+	Truncated  bool
+}
+
+// Extraction turns documents into text.
+type Extraction interface {
+	Extract(ctx context.Context, file domain.File) (*Document, error)
+}
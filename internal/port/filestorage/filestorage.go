@@ -12,6 +12,9 @@ type FileStorage interface {
 	// Upload uploads a file to the storage and returns the object name.
 	Upload(ctx context.Context, data []byte, mimeType string) (string, error)
 
+	// Download returns the content of the given object.
+	Download(ctx context.Context, objectName string) ([]byte, error)
+
 	// GetPreSignedURL generates a pre-signed URL for the given object.
 	// The URL will be valid for the specified duration.
 	GetPreSignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
@@ -0,0 +1,28 @@
+package imagegen
+
+import (
+	"context"
+
+	"github.com/vladimish/talk/internal/port/completion"
+)
+
+//go:generate go tool mockgen -source=imagegen.go -destination=../../../mocks/mock_imagegen.go -package=mocks
+
+// Image is a generated image.
+type Image struct {
+	Data     []byte
+	MimeType string
+}
+
+// Result is what an image model answered: the images and the text that came with them, if any.
+type Result struct {
+	Text   string
+	Images []Image
+	Usage  *completion.Usage // Nil when the provider didn't report it
+}
+
+// ImageGeneration generates images from the prompt of a completion request. The images of the request
+// are the references the model draws from.
+type ImageGeneration interface {
+	GenerateImages(ctx context.Context, req completion.CompletionRequest) (*Result, error)
+}
@@ -59,6 +59,14 @@ type Queue interface {
 	// ClearPendingMessages removes pending messages for a user
 	ClearPendingMessages(ctx context.Context, userID string) error
 
+	// Album methods
+	// AddAlbumMessage stores a message of a media group and returns how many messages the group has so far
+	AddAlbumMessage(ctx context.Context, userID, albumID string, update domain.Update, ttl time.Duration) (int, error)
+
+	// TakeAlbum atomically retrieves and removes the stored messages of a media group when it has exactly
+	// length of them, otherwise returns none
+	TakeAlbum(ctx context.Context, userID, albumID string, length int) ([]domain.Update, error)
+
 	// SetGenerationLock marks a user as having an active AI generation (for cancellation)
 	SetGenerationLock(ctx context.Context, userID string, ttl time.Duration) error
 
@@ -67,4 +75,12 @@ type Queue interface {
 
 	// ClearGenerationLock removes the generation lock for a user
 	ClearGenerationLock(ctx context.Context, userID string) error
+
+	// PublishCancel asks the replica generating an answer for a user to stop it.
+	// It reports whether a generation received the request
+	PublishCancel(ctx context.Context, userID string) (bool, error)
+
+	// SubscribeCancel listens for cancel requests of a user's generation.
+	// The channel is closed on the first request, the returned function stops listening
+	SubscribeCancel(ctx context.Context, userID string) (<-chan struct{}, func(), error)
 }
@@ -18,8 +18,19 @@ type Sender interface {
 		messageIDs []string,
 		previousText, currentText string,
 	) ([]string, error)
+	SendDocument(ctx context.Context, externalUserID string, document domain.Document) (string, error)
+	SendVoice(ctx context.Context, externalUserID string, voice domain.VoiceMessage) (string, error)
+	SendPhoto(ctx context.Context, externalUserID string, photo domain.Photo) (string, error)
 	SendTyping(ctx context.Context, externalUserID string) error
 	DeleteMessage(ctx context.Context, externalUserID string, messageID string) error
+	// EditMessageKeyboard replaces the inline keyboard of a message. A nil keyboard removes it.
+	EditMessageKeyboard(
+		ctx context.Context,
+		externalUserID string,
+		messageID string,
+		keyboard *domain.InlineKeyboard,
+	) error
+	AnswerCallbackQuery(ctx context.Context, callbackQueryID string, text string) error
 	CreateInvoiceLink(ctx context.Context, params domain.CreateInvoiceLinkParams) (string, error)
 	AnswerPreCheckoutQuery(ctx context.Context, preCheckoutQueryID string, ok bool, errorMessage string) error
 }
@@ -0,0 +1,11 @@
+package speech
+
+import "context"
+
+//go:generate go tool mockgen -source=speech.go -destination=../../../mocks/mock_speech.go -package=mocks
+
+// Speech converts text to speech.
+type Speech interface {
+	// Synthesize returns the text spoken as OGG with Opus, the format of Telegram voice messages.
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
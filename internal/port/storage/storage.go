@@ -17,27 +17,62 @@ type Storage interface {
 	UpdateUserLanguage(ctx context.Context, userID int64, language string) error
 	UpdateUserConversationListOffset(ctx context.Context, userID int64, offset int) error
 	UpdateUserWebSearchEnabled(ctx context.Context, userID int64, enabled bool) error
+	UpdateUserDefaultSystemPrompt(ctx context.Context, userID int64, systemPrompt *string) error
+	UpdateUserShowUsage(ctx context.Context, userID int64, show bool) error
+	UpdateUserVoiceReplies(ctx context.Context, userID int64, enabled bool) error
+	GetSelectedModels(ctx context.Context) ([]string, error)
+	UpdateUsersSelectedModel(ctx context.Context, oldModel string, newModel string) (int64, error)
 
 	CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
 	GetMessagesByUserID(ctx context.Context, userID int64) ([]*domain.Message, error)
 	GetMessagesByConversationID(ctx context.Context, conversationID int64) ([]*domain.Message, error)
 	GetLatestMessageByConversationID(ctx context.Context, conversationID int64) (*domain.Message, error)
+	GetMessageByID(ctx context.Context, messageID int64) (*domain.Message, error)
+	GetMessageByForeignID(ctx context.Context, userID int64, foreignMessageID int32) (*domain.Message, error)
+	DeleteMessage(ctx context.Context, messageID int64) error
+	DeleteMessagesAfter(ctx context.Context, conversationID int64, messageID int64) error
+	UpdateMessageType(ctx context.Context, messageID int64, messageType domain.MessageType) error
+	CreateMessageVersion(ctx context.Context, version *domain.MessageVersion) (*domain.MessageVersion, error)
+	GetMessageVersions(ctx context.Context, messageID int64) ([]*domain.MessageVersion, error)
+	DeleteMessageVersions(ctx context.Context, messageID int64) error
+	SearchMessages(
+		ctx context.Context,
+		userID int64,
+		language string,
+		query string,
+		limit int,
+	) ([]*domain.MessageSearchResult, error)
 
 	CreateConversation(ctx context.Context, conversation *domain.Conversation) (*domain.Conversation, error)
 	GetConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error)
+	GetArchivedConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error)
 	GetConversationByID(ctx context.Context, conversationID int64) (*domain.Conversation, error)
 	UpdateConversationName(ctx context.Context, conversationID int64, name string) error
 	UpdateConversationTimestamp(ctx context.Context, conversationID int64) error
+	UpdateConversationSystemPrompt(ctx context.Context, conversationID int64, systemPrompt *string) error
+	UpdateConversationPinned(ctx context.Context, conversationID int64, pinned bool) error
+	UpdateConversationArchived(ctx context.Context, conversationID int64, archived bool) error
+	DeleteConversation(ctx context.Context, conversationID int64) error
+	RestoreConversation(ctx context.Context, conversationID int64) error
+	GetConversationSummary(ctx context.Context, conversationID int64) (*domain.ConversationSummary, error)
+	UpsertConversationSummary(ctx context.Context, summary *domain.ConversationSummary) error
+	DeleteConversationSummary(ctx context.Context, conversationID int64) error
 
 	UpdateUserCurrentConversationID(ctx context.Context, userID int64, conversationID *int64) error
 	CreateForeignMessage(ctx context.Context, messageID int32, foreignMessageID int32) error
 	GetForeignMessageByMessageID(ctx context.Context, messageID int32) (int32, error)
+	GetForeignMessagesByMessageID(ctx context.Context, messageID int32) ([]int32, error)
+	DeleteForeignMessages(ctx context.Context, messageID int32) error
 
 	// Transaction methods
 	CreateTransaction(ctx context.Context, transaction *domain.Transaction) (*domain.Transaction, error)
 	GetUserTokenBalance(ctx context.Context, userID int64) (*domain.TokenBalance, error)
 	GetUserTokenBalanceByType(ctx context.Context, userID int64, tokenType domain.TokenType) (int64, error)
 
+	// Usage methods
+	CreateMessageUsage(ctx context.Context, usage *domain.MessageUsage) (*domain.MessageUsage, error)
+	GetUserUsageTotals(ctx context.Context, userID int64) (*domain.UsageTotals, error)
+
 	// Payment methods
 	CreatePayment(ctx context.Context, payment *domain.Payment) (*domain.Payment, error)
 	GetPaymentByInvoicePayload(ctx context.Context, invoicePayload string) (*domain.Payment, error)
@@ -59,6 +94,10 @@ type Storage interface {
 
 	// Attachment methods
 	CreateAttachment(ctx context.Context, attachment *domain.Attachment) (*domain.Attachment, error)
+	GetAttachmentsByMessageID(ctx context.Context, messageID int64) ([]*domain.Attachment, error)
+
+	// InTransaction runs fn with a storage whose changes are kept only when fn succeeds.
+	InTransaction(ctx context.Context, fn func(tx Storage) error) error
 }
 
 var ErrNotFound = errors.New("not found")
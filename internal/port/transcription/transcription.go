@@ -0,0 +1,12 @@
+package transcription
+
+import "context"
+
+//go:generate go tool mockgen -source=transcription.go -destination=../../../mocks/mock_transcription.go -package=mocks
+
+// Transcription converts speech to text.
+type Transcription interface {
+	// Transcribe returns the text spoken in the audio. The file name tells the format of the audio
+	// to servers that don't look at the MIME type.
+	Transcribe(ctx context.Context, audio []byte, mimeType, fileName string) (string, error)
+}
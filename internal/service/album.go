@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// collectAlbum stores a message of a media group. The group is answered as a single prompt by the message
+// that is followed by no other one within the concatenation window.
+func (s *UpdateService) collectAlbum(ctx context.Context, user *domain.User, update domain.Update) error {
+	position, err := s.queue.AddAlbumMessage(
+		ctx, user.ExternalID, update.MediaGroupID, update, messageConcatenationWindow*messageConcatenationTTLFactor,
+	)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to add album message",
+			slog.String("error", err.Error()))
+		// Answer the message on its own
+		return s.queueOrProcessUpdate(ctx, user, update)
+	}
+
+	s.logger.InfoContext(ctx, "added message to album",
+		slog.String("user_id", user.ExternalID),
+		slog.String("media_group_id", update.MediaGroupID),
+		slog.Int("position", position))
+
+	go func() {
+		time.Sleep(messageConcatenationWindow)
+		s.processAlbum(context.Background(), user.ExternalID, update.MediaGroupID, position)
+	}()
+
+	return nil
+}
+
+// processAlbum answers the album when the message at the position is still its last one.
+func (s *UpdateService) processAlbum(ctx context.Context, externalUserID string, albumID string, position int) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Panic occurred while processing album",
+				"panic", r,
+				"stack_trace", string(debug.Stack()),
+				"user_id", externalUserID,
+				"media_group_id", albumID)
+		}
+	}()
+
+	// A message received later answers the album, then nothing is taken
+	messages, err := s.queue.TakeAlbum(ctx, externalUserID, albumID, position)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to take album",
+			slog.String("error", err.Error()))
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	// Re-fetch user, the state might have changed while the album was being received
+	user, err := s.storage.GetUserByExternalUserID(ctx, externalUserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get user for album",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	s.logger.InfoContext(ctx, "processing album",
+		slog.String("user_id", externalUserID),
+		slog.String("media_group_id", albumID),
+		slog.Int("message_count", len(messages)))
+
+	update := s.combineMessages(messages)
+	update.MediaGroupID = ""
+	if processErr := s.queueOrProcessUpdate(ctx, user, update); processErr != nil {
+		s.logger.ErrorContext(ctx, "failed to process album",
+			slog.String("error", processErr.Error()),
+			slog.String("user_id", externalUserID))
+	}
+}
+
+// unsupportedFilesNotice returns the notice for files the model can't take in one prompt, empty when it takes them.
+func unsupportedFilesNotice(model *domain.ModelInfo, language string, files []domain.File) string {
+	images, pdfs := len(domain.Images(files)), len(domain.PDFs(files))
+	switch {
+	case images > 0 && !model.ImageSupport:
+		return i18n.GetString(language, i18n.ModelImageNotSupported)
+	case !model.TakesImages(images):
+		return fmt.Sprintf(i18n.GetString(language, i18n.ModelImageLimit), model.GetDisplayName(language), model.MaxImages)
+	case pdfs > 0 && !model.PDFSupport:
+		return i18n.GetString(language, i18n.ModelPDFNotSupported)
+	case !model.TakesPDFs(pdfs):
+		return fmt.Sprintf(i18n.GetString(language, i18n.ModelPDFLimit), model.GetDisplayName(language), model.MaxPDFs)
+	default:
+		return ""
+	}
+}
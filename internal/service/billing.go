@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+	"github.com/vladimish/talk/pkg/tokens"
+)
+
+const (
+	// reservedCompletionTokens is the answer length reserved before the completion request.
+	reservedCompletionTokens = 1000
+	// reservedReasoningTokens is reserved instead for reasoning models, which think before answering.
+	reservedReasoningTokens = 4000
+	// pdfPageTokens is a rough prompt size of a single PDF page.
+	pdfPageTokens = 800
+)
+
+// errInsufficientBalance is returned when the balance can't cover the estimated cost and the user was notified.
+var errInsufficientBalance = errors.New("balance doesn't cover the estimated answer cost")
+
+// pdfPagePattern matches the page objects of a PDF, but not the /Pages tree nodes.
+var pdfPagePattern = regexp.MustCompile(`/Type\s*/Page\b`)
+
+// answerBill is the estimated cost of an answer that is held on the user's balance until the answer is settled.
+type answerBill struct {
+	user     *domain.User
+	model    *domain.ModelInfo
+	estimate domain.AnswerUsage
+	reserved []domain.TokenCharge
+	// reservedFor is the model the reservation was made for, model is the one that answers.
+	reservedFor string
+	closed      bool
+}
+
+// answeredBy makes the bill charge the answer by the fallback model that answered instead of the reserved one.
+func (b *answerBill) answeredBy(model *domain.ModelInfo) {
+	b.model = model
+	b.estimate.WebSearch = b.estimate.WebSearch && model.WebSearch
+}
+
+// reserveAnswer estimates what the answer to llmContext will cost and reserves it on the user's balance.
+// It returns errInsufficientBalance when the balance can't cover the estimate and the user was notified.
+func (s *UpdateService) reserveAnswer(
+	ctx context.Context,
+	user *domain.User,
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) (*answerBill, error) {
+	bill := &answerBill{
+		user:        user,
+		model:       model,
+		estimate:    estimateAnswerUsage(model, llmContext, attachments, webSearchEnabled),
+		reservedFor: model.ID,
+	}
+
+	totals := domain.ChargeTotals(model.PriceAnswer(bill.estimate))
+	for _, total := range totals {
+		balance, err := s.storage.GetUserTokenBalanceByType(ctx, user.ID, total.TokenType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user token balance: %w", err)
+		}
+		if balance >= total.Amount {
+			continue
+		}
+
+		insufficientTokensMsg := fmt.Sprintf(
+			i18n.GetString(user.Language, i18n.ProfileInsufficientTokens),
+			total.Amount,
+			string(total.TokenType),
+		)
+		if _, err = s.sender.SendMessage(ctx, user.ExternalID, insufficientTokensMsg); err != nil {
+			return nil, err
+		}
+		return nil, errInsufficientBalance
+	}
+
+	for _, total := range totals {
+		_, err := s.storage.CreateTransaction(ctx, &domain.Transaction{
+			UserID:          user.ID,
+			TokenType:       total.TokenType,
+			Amount:          -total.Amount,
+			TransactionType: domain.TransactionTypeReservation,
+			ModelUsed:       &model.ID,
+			Description:     pointer.To("Reserved for an answer"),
+			CreatedAt:       time.Now(),
+		})
+		if err != nil {
+			// Log error but don't fail the entire operation, the answer is still settled afterwards
+			s.logger.ErrorContext(ctx, "failed to create token reservation transaction",
+				slog.String("error", err.Error()),
+				slog.String("model", model.ID),
+				slog.Int64("amount", total.Amount))
+			continue
+		}
+		bill.reserved = append(bill.reserved, total)
+	}
+
+	return bill, nil
+}
+
+// settleAnswer replaces the reservation with itemized charges for what the answer actually took.
+// Without the usage reported by the provider the answer is charged by the estimated token counts.
+func (s *UpdateService) settleAnswer(ctx context.Context, bill *answerBill, answer *shownAnswer) {
+	if bill.closed {
+		return
+	}
+	s.releaseAnswer(ctx, bill)
+
+	usage := domain.AnswerUsage{
+		PromptTokens:     bill.estimate.PromptTokens,
+		CompletionTokens: tokens.Estimate(answer.text),
+		WebSearch:        bill.estimate.WebSearch,
+		Files:            bill.estimate.Files,
+		Images:           answer.generatedImages,
+	}
+	if answer.usage != nil {
+		usage.PromptTokens = answer.usage.PromptTokens
+		usage.CompletionTokens = answer.usage.CompletionTokens
+	}
+
+	for _, charge := range bill.model.PriceAnswer(usage) {
+		var description *string
+		if charge.Description != "" {
+			description = pointer.To(charge.Description)
+		}
+
+		_, err := s.storage.CreateTransaction(ctx, &domain.Transaction{
+			UserID:          bill.user.ID,
+			TokenType:       charge.TokenType,
+			Amount:          -charge.Amount, // Negative for debit
+			TransactionType: domain.TransactionTypeMessageCost,
+			ModelUsed:       &bill.model.ID,
+			Description:     description,
+			CreatedAt:       time.Now(),
+		})
+		if err != nil {
+			// Log error but don't fail the entire operation
+			s.logger.ErrorContext(ctx, "failed to create token deduction transaction",
+				slog.String("error", err.Error()),
+				slog.String("model", bill.model.ID),
+				slog.Int64("amount", charge.Amount))
+		}
+	}
+}
+
+// releaseAnswer returns the reservation of an answer that wasn't generated. It does nothing once the bill is closed.
+func (s *UpdateService) releaseAnswer(ctx context.Context, bill *answerBill) {
+	if bill.closed {
+		return
+	}
+	bill.closed = true
+
+	for _, reserved := range bill.reserved {
+		_, err := s.storage.CreateTransaction(ctx, &domain.Transaction{
+			UserID:          bill.user.ID,
+			TokenType:       reserved.TokenType,
+			Amount:          reserved.Amount,
+			TransactionType: domain.TransactionTypeReservationRelease,
+			ModelUsed:       &bill.reservedFor,
+			Description:     pointer.To("Reservation released"),
+			CreatedAt:       time.Now(),
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to release token reservation",
+				slog.String("error", err.Error()),
+				slog.String("model", bill.reservedFor),
+				slog.Int64("amount", reserved.Amount))
+		}
+	}
+}
+
+// estimateAnswerUsage estimates the usage of an answer before the request. It rather overestimates,
+// so that the reservation covers the answer.
+func estimateAnswerUsage(
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) domain.AnswerUsage {
+	usage := domain.AnswerUsage{
+		PromptTokens:     tokens.Estimate(llmContext.systemPrompt),
+		CompletionTokens: reservedCompletionTokens,
+		WebSearch:        webSearchEnabled,
+	}
+	for _, msg := range llmContext.messages {
+		usage.PromptTokens += estimateMessageTokens(msg)
+	}
+	if model.Reasoning {
+		usage.CompletionTokens = reservedReasoningTokens
+	}
+	if model.ImageOutput {
+		usage.Images = 1
+	}
+
+	for _, attachment := range attachments {
+		switch attachment.Type {
+		case completion.PartTypeFile:
+			usage.Files++
+			usage.PromptTokens += max(len(pdfPagePattern.FindAllIndex(attachment.Data, -1)), 1) * pdfPageTokens
+		case completion.PartTypeText:
+			usage.PromptTokens += tokens.Estimate(attachment.Text)
+		case completion.PartTypeImage, completion.PartTypeAudio:
+		}
+	}
+	// The tokens of earlier files are counted with their messages, except for the text of documents
+	for _, parts := range attachedEarlierFiles(model, llmContext, attachments) {
+		usage.Files += countParts(parts, completion.PartTypeFile)
+		for _, part := range parts {
+			if part.Type == completion.PartTypeText {
+				usage.PromptTokens += tokens.Estimate(part.Text)
+			}
+		}
+	}
+
+	return usage
+}
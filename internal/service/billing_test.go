@@ -0,0 +1,314 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleCallbackQuery_RegenerateBilling(t *testing.T) {
+	conversationID := int64(7)
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "First summary"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+
+	type charge struct {
+		transactionType domain.TransactionType
+		amount          int64
+		description     string
+	}
+
+	tests := []struct {
+		name        string
+		userMessage *domain.Message
+		// storedFiles are the contents of the uploaded files of the user message by object
+		storedFiles map[string][]byte
+		balance     int64
+		setupMocks  func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockCompletion, *mocks.MockQueue)
+		charges     []charge
+	}{
+		{
+			name: "answer is charged by the reported usage",
+			userMessage: &domain.Message{
+				ID:             41,
+				UserID:         1,
+				MessageType:    domain.MessageType{Text: "Summarize the book"},
+				SentBy:         domain.MessageSenderUser,
+				ConversationID: &conversationID,
+			},
+			balance: 100,
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockQueue.EXPECT().
+					SubscribeCancel(gomock.Any(), "12345").
+					Return(make(chan struct{}), func() {}, nil)
+				mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+				mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(func(_ context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+						tokens := make(chan completion.StreamToken, 2)
+						tokens <- completion.StreamToken{Content: "Second summary"}
+						tokens <- completion.StreamToken{Usage: &completion.Usage{
+							PromptTokens:     30000,
+							CompletionTokens: 2000,
+							Cost:             pointer.To(0.014),
+						}}
+						close(tokens)
+						return tokens, nil
+					})
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First summary", "Second summary").
+					Return([]string{"100"}, nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second summary"}).
+					Return(nil)
+				mockStorage.EXPECT().CreateMessageUsage(gomock.Any(), gomock.Any()).Return(&domain.MessageUsage{ID: 1}, nil)
+				mockStorage.EXPECT().
+					GetMessageVersions(gomock.Any(), int64(42)).
+					Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First summary"}}, nil)
+				mockStorage.EXPECT().CreateMessageVersion(gomock.Any(), gomock.Any()).Return(&domain.MessageVersion{ID: 2}, nil)
+				mockSender.EXPECT().EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).Return(nil)
+			},
+			charges: []charge{
+				{transactionType: domain.TransactionTypeReservation, amount: -1, description: "Reserved for an answer"},
+				{transactionType: domain.TransactionTypeReservationRelease, amount: 1, description: "Reservation released"},
+				{transactionType: domain.TransactionTypeMessageCost, amount: -3, description: "Prompt cost: 30000 tokens"},
+				{transactionType: domain.TransactionTypeMessageCost, amount: -1, description: "Completion cost: 2000 tokens"},
+			},
+		},
+		{
+			name: "balance below the estimate of a long document",
+			userMessage: &domain.Message{
+				ID:     41,
+				UserID: 1,
+				SentBy: domain.MessageSenderUser,
+				MessageType: domain.MessageType{
+					Text:  "Summarize the book",
+					Files: []domain.File{{S3Name: "book", MimeType: "application/pdf", FileName: "book.pdf"}},
+				},
+				ConversationID: &conversationID,
+			},
+			storedFiles: map[string][]byte{"book": []byte(strings.Repeat("<< /Type /Page >>\n", 50))},
+			balance:     3,
+			setupMocks: func(
+				_ *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				_ *mocks.MockQueue,
+			) {
+				// 50 pages and the file parser cost more than a short question
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", fmt.Sprintf(
+						i18n.GetString("en", i18n.ProfileInsufficientTokens), 6, "regular",
+					)).
+					Return("msg1", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+			mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalance(gomock.Any(), int64(1)).
+				Return(&domain.TokenBalance{RegularBalance: tt.balance}, nil)
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return([]*domain.Message{tt.userMessage, botMessage}, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(tt.balance, nil)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockSender.EXPECT().
+				AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+			for objectName, data := range tt.storedFiles {
+				mockFileStorage.EXPECT().Download(gomock.Any(), objectName).Return(data, nil)
+			}
+			tt.setupMocks(mockStorage, mockSender, mockCompletion, mockQueue)
+
+			var charges []charge
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					assert.Equal(t, domain.TokenTypeRegular, transaction.TokenType)
+					require.NotNil(t, transaction.Description)
+					charges = append(charges, charge{
+						transactionType: transaction.TransactionType,
+						amount:          transaction.Amount,
+						description:     *transaction.Description,
+					})
+					return transaction, nil
+				}).
+				Times(len(tt.charges))
+
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           "regen:42",
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.charges, charges)
+		})
+	}
+}
+
+func TestUpdateService_HandleConversationState_InsufficientBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockSender := mocks.NewMockSender(ctrl)
+	mockCompletion := mocks.NewMockCompletion(ctrl)
+	mockQueue := mocks.NewMockQueue(ctrl)
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	logger := slog.Default()
+
+	updateService := service.NewUpdateService(
+		logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+	)
+
+	conversationID := int64(7)
+	history := []*domain.Message{
+		{ID: 1, UserID: 1, MessageType: domain.MessageType{Text: "Hi"}, SentBy: domain.MessageSenderUser},
+		{ID: 2, UserID: 1, MessageType: domain.MessageType{Text: "Ahoy"}, SentBy: domain.MessageSenderBot},
+		{ID: 5, UserID: 1, MessageType: domain.MessageType{Text: "Where is the treasure?"}, SentBy: domain.MessageSenderUser},
+	}
+
+	// Failing to batch the message makes it answered right away
+	mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+	mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+	mockQueue.EXPECT().
+		SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+		Return(errors.New("redis is down"))
+	mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+	mockQueue.EXPECT().
+		SubscribeCancel(gomock.Any(), "12345").
+		Return(make(chan struct{}), func() {}, nil).
+		AnyTimes()
+	mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+	mockQueue.EXPECT().
+		DequeueWithMetadata(gomock.Any(), "12345").
+		Return(nil, queue.ErrEmptyQueue).
+		AnyTimes()
+
+	mockStorage.EXPECT().
+		GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+		Return(nil, storage.ErrNotFound).
+		AnyTimes()
+	mockStorage.EXPECT().
+		CreateMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+			message.ID = 5
+			return message, nil
+		})
+	mockStorage.EXPECT().
+		GetMessagesByConversationID(gomock.Any(), conversationID).
+		Return(history, nil).
+		Times(2)
+	mockStorage.EXPECT().
+		GetConversationByID(gomock.Any(), conversationID).
+		Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetConversationSummary(gomock.Any(), conversationID).
+		Return(nil, storage.ErrNotFound).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+		Return(int64(0), nil)
+	mockSender.EXPECT().SendTyping(gomock.Any(), "12345").Return(nil).AnyTimes()
+	mockSender.EXPECT().
+		SendMessage(gomock.Any(), "12345", fmt.Sprintf(
+			i18n.GetString("en", i18n.ProfileInsufficientTokens), 1, "regular",
+		)).
+		Return("100", nil)
+	// The unanswered message is dropped, so it doesn't turn up in the history without an answer
+	mockStorage.EXPECT().DeleteMessage(gomock.Any(), int64(5)).Return(nil)
+
+	user := &domain.User{
+		ID:                    1,
+		ExternalID:            "12345",
+		Language:              "en",
+		CurrentStep:           domain.UserStateConversation,
+		SelectedModel:         "google/gemini-2.5-flash",
+		CurrentConversationID: &conversationID,
+	}
+
+	err := updateService.HandleConversationState(t.Context(), user, domain.Update{
+		ExternalUserID:    "12345",
+		ExternalMessageID: 50,
+		UserLanguage:      "en",
+		MessageText:       "Where is the treasure?",
+	})
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+)
+
+// importedConversation is a conversation read from a data export of another chat service.
+type importedConversation struct {
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Messages  []importedMessage
+}
+
+type importedMessage struct {
+	SentBy    domain.MessageSender
+	Text      string
+	CreatedAt time.Time
+}
+
+// The subset of the ChatGPT data export format (conversations.json) needed to restore the chats.
+// Every conversation is a tree of message nodes, edited prompts and regenerated answers are branches.
+type chatGPTConversation struct {
+	Title       string                 `json:"title"`
+	CreateTime  *float64               `json:"create_time"`
+	UpdateTime  *float64               `json:"update_time"`
+	Mapping     map[string]chatGPTNode `json:"mapping"`
+	CurrentNode string                 `json:"current_node"`
+}
+
+type chatGPTNode struct {
+	Message  *chatGPTMessage `json:"message"`
+	Parent   *string         `json:"parent"`
+	Children []string        `json:"children"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime *float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	Metadata struct {
+		IsVisuallyHidden bool `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+// isConversationImport reports whether the update carries a data export to import conversations from.
+// Other JSON files are read as documents.
+func isConversationImport(update domain.Update) bool {
+	return len(update.DocumentData) > 0 && isChatGPTExport(update.DocumentData)
+}
+
+// isChatGPTExport tells a ChatGPT export by its shape: an array of conversations with a message mapping.
+// Only the first conversation is decoded, the whole export is parsed once it's imported.
+func isChatGPTExport(data []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') || !decoder.More() {
+		return false
+	}
+
+	var conversation struct {
+		Mapping map[string]json.RawMessage `json:"mapping"`
+	}
+	return decoder.Decode(&conversation) == nil && conversation.Mapping != nil
+}
+
+// parseChatGPTExport reads conversations.json of a ChatGPT data export. Only the branch that was
+// shown last in each conversation is kept, and conversations without text messages are skipped.
+func parseChatGPTExport(data []byte) ([]importedConversation, error) {
+	var export []chatGPTConversation
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("can't parse ChatGPT export: %w", err)
+	}
+
+	conversations := make([]importedConversation, 0, len(export))
+	for _, conversation := range export {
+		if conversation.Mapping == nil {
+			return nil, fmt.Errorf("conversation %q has no messages mapping", conversation.Title)
+		}
+
+		createdAt := chatGPTTime(conversation.CreateTime, time.Time{})
+		imported := importedConversation{
+			Title:     strings.TrimSpace(conversation.Title),
+			CreatedAt: createdAt,
+			UpdatedAt: chatGPTTime(conversation.UpdateTime, createdAt),
+		}
+
+		previousTime := createdAt
+		for _, node := range conversation.thread() {
+			message, ok := node.importedMessage(previousTime)
+			if !ok {
+				continue
+			}
+			imported.Messages = append(imported.Messages, message)
+			previousTime = message.CreatedAt
+		}
+
+		if len(imported.Messages) > 0 {
+			conversations = append(conversations, imported)
+		}
+	}
+
+	return conversations, nil
+}
+
+// thread returns the nodes from the root of the tree to the current node.
+func (c chatGPTConversation) thread() []chatGPTNode {
+	nodeID := c.CurrentNode
+	if _, ok := c.Mapping[nodeID]; !ok {
+		nodeID = c.lastLeaf()
+	}
+
+	var thread []chatGPTNode
+	visited := make(map[string]bool)
+	for nodeID != "" && !visited[nodeID] {
+		node, ok := c.Mapping[nodeID]
+		if !ok {
+			break
+		}
+		visited[nodeID] = true
+		thread = append(thread, node)
+
+		nodeID = ""
+		if node.Parent != nil {
+			nodeID = *node.Parent
+		}
+	}
+
+	slices.Reverse(thread)
+	return thread
+}
+
+// lastLeaf follows the latest branch from the root when the export doesn't name the current node.
+func (c chatGPTConversation) lastLeaf() string {
+	var nodeID string
+	for id, node := range c.Mapping {
+		if node.Parent == nil {
+			nodeID = id
+			break
+		}
+	}
+
+	for range len(c.Mapping) {
+		node, ok := c.Mapping[nodeID]
+		if !ok || len(node.Children) == 0 {
+			break
+		}
+		nodeID = node.Children[len(node.Children)-1]
+	}
+
+	return nodeID
+}
+
+// importedMessage converts a visible user or assistant text message of the node. Messages without a
+// timestamp, and ones that are out of order, get the previous timestamp to keep the order of the thread.
+func (n chatGPTNode) importedMessage(previousTime time.Time) (importedMessage, bool) {
+	message := n.Message
+	if message == nil || message.Metadata.IsVisuallyHidden {
+		return importedMessage{}, false
+	}
+
+	var sentBy domain.MessageSender
+	switch message.Author.Role {
+	case "user":
+		sentBy = domain.MessageSenderUser
+	case "assistant":
+		sentBy = domain.MessageSenderBot
+	default:
+		return importedMessage{}, false
+	}
+
+	if message.Content.ContentType != "text" && message.Content.ContentType != "multimodal_text" {
+		return importedMessage{}, false
+	}
+
+	// Images and other non-text parts are objects, only the text parts are kept
+	var parts []string
+	for _, rawPart := range message.Content.Parts {
+		var part string
+		if json.Unmarshal(rawPart, &part) == nil && strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return importedMessage{}, false
+	}
+
+	createdAt := chatGPTTime(message.CreateTime, previousTime)
+	if createdAt.Before(previousTime) {
+		createdAt = previousTime
+	}
+
+	return importedMessage{
+		SentBy:    sentBy,
+		Text:      strings.Join(parts, "\n"),
+		CreatedAt: createdAt,
+	}, true
+}
+
+// chatGPTTime converts a Unix timestamp with a fractional part, falling back when it is missing.
+func chatGPTTime(timestamp *float64, fallback time.Time) time.Time {
+	if timestamp == nil || *timestamp <= 0 {
+		return fallback
+	}
+
+	seconds, fraction := math.Modf(*timestamp)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second))).UTC()
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+)
+
+// answerRequest builds the completion request of an answer by the model. The attachments go to the last user
+// message of the context in order, the earlier files the model takes go to their messages.
+func answerRequest(
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) completion.CompletionRequest {
+	req := completion.CompletionRequest{
+		Model:        model.ID,
+		SystemPrompt: llmContext.systemPrompt,
+		Messages:     completionMessages(llmContext.messages),
+		Plugins:      completion.Plugins{WebSearch: webSearchEnabled},
+	}
+	if model.Reasoning {
+		req.Parameters.Reasoning = completion.ReasoningHigh
+	}
+
+	pdfs := countParts(attachments, completion.PartTypeFile)
+	earlierFiles := attachedEarlierFiles(model, llmContext, attachments)
+	for i, msg := range llmContext.messages {
+		req.Messages[i].Parts = append(req.Messages[i].Parts, earlierFiles[msg.ID]...)
+		pdfs += countParts(earlierFiles[msg.ID], completion.PartTypeFile)
+	}
+	if pdfs > 0 {
+		req.Plugins.PDFEngine = completion.PDFEngineNative
+	}
+	if len(attachments) == 0 {
+		return req
+	}
+
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == completion.RoleUser {
+			req.Messages[i].Parts = append(req.Messages[i].Parts, attachments...)
+			break
+		}
+	}
+
+	return req
+}
+
+// countParts returns how many of the parts have the type.
+func countParts(parts []completion.Part, partType completion.PartType) int {
+	count := 0
+	for _, part := range parts {
+		if part.Type == partType {
+			count++
+		}
+	}
+	return count
+}
+
+// textRequest builds a completion request for a housekeeping call on a single text prompt.
+func textRequest(model string, systemPrompt string, prompt string) completion.CompletionRequest {
+	return completion.CompletionRequest{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		Messages: []completion.Message{
+			{
+				Role:  completion.RoleUser,
+				Parts: []completion.Part{{Type: completion.PartTypeText, Text: prompt}},
+			},
+		},
+	}
+}
+
+// completionMessages converts conversation messages to completion messages with their prompt text.
+func completionMessages(messages []*domain.Message) []completion.Message {
+	converted := make([]completion.Message, 0, len(messages))
+	for _, msg := range messages {
+		role := completion.RoleUser
+		if msg.SentBy == domain.MessageSenderBot {
+			role = completion.RoleAssistant
+		}
+
+		converted = append(converted, completion.Message{
+			Role:  role,
+			Parts: []completion.Part{{Type: completion.PartTypeText, Text: msg.MessageType.PromptText()}},
+		})
+	}
+	return converted
+}
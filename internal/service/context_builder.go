@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/pointer"
+	"github.com/vladimish/talk/pkg/tokens"
+)
+
+const (
+	// utilityModel is a cheap model used for housekeeping calls such as naming and summarization.
+	utilityModel = "google/gemini-2.5-flash"
+
+	// attachmentTokenEstimate is a rough token cost of a single image or document in a message.
+	attachmentTokenEstimate = 1000
+	// summaryKeepDivisor controls how much of the budget recent turns may use after folding,
+	// so that a summary is not regenerated on every single turn.
+	summaryKeepDivisor = 2
+	// maxSummarizedMessageRunes limits how much of a single message goes into the summarization transcript.
+	maxSummarizedMessageRunes = 4000
+)
+
+const summarizationPrompt = `You maintain a running summary of a chat between a user and an AI assistant.
+You will receive the previous summary (if any) and the next part of the conversation transcript.
+Produce an updated summary that preserves facts, decisions, names, numbers, code identifiers,
+open questions and user preferences that may matter later. Drop greetings and filler.
+Write the summary in the language of the conversation. Return ONLY the summary text.`
+
+// conversationContext is what actually gets sent to the model for a single request.
+type conversationContext struct {
+	systemPrompt string
+	messages     []*domain.Message
+	// earlierFiles are the uploaded images and PDFs of earlier user messages by message ID, newest messages
+	// within the history attachment limit
+	earlierFiles map[int64][]completion.Part
+}
+
+// buildConversationContext fits the conversation history into the model's context budget and restores
+// the files of earlier turns. Summarizing the history is charged to the user.
+func (s *UpdateService) buildConversationContext(
+	ctx context.Context,
+	user *domain.User,
+	conversationID *int64,
+	model *domain.ModelInfo,
+	systemPrompt string,
+	messages []*domain.Message,
+) conversationContext {
+	llmContext := s.fitConversationContext(ctx, user, conversationID, model, systemPrompt, messages)
+	llmContext.earlierFiles = s.earlierFiles(ctx, llmContext.messages)
+	return llmContext
+}
+
+// fitConversationContext fits the conversation history into the model's context budget.
+// Turns that were already summarized are replaced by the stored summary, and if the remaining
+// history still does not fit, the oldest turns are folded into an updated rolling summary.
+func (s *UpdateService) fitConversationContext(
+	ctx context.Context,
+	user *domain.User,
+	conversationID *int64,
+	model *domain.ModelInfo,
+	systemPrompt string,
+	messages []*domain.Message,
+) conversationContext {
+	budget := model.ContextBudget
+	if budget <= 0 {
+		budget = domain.ContextBudgetMedium
+	}
+
+	var summary *domain.ConversationSummary
+	if conversationID != nil {
+		storedSummary, err := s.storage.GetConversationSummary(ctx, *conversationID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			s.logger.WarnContext(ctx, "failed to get conversation summary",
+				slog.String("error", err.Error()),
+				slog.Int64("conversation_id", *conversationID))
+		}
+		summary = storedSummary
+	}
+
+	if summary != nil && len(messages) > 0 && summary.LastMessageID >= messages[len(messages)-1].ID {
+		// The history ends before the summarized part (e.g. an older answer is regenerated), so the stored
+		// summary describes turns that are not part of this request and must not be overwritten either
+		split := splitForSummary(messages, budget-tokens.Estimate(systemPrompt))
+		return conversationContext{systemPrompt: systemPrompt, messages: messages[split:]}
+	}
+
+	messages = messagesAfterSummary(messages, summary)
+
+	used := tokens.Estimate(withSummary(systemPrompt, summary))
+	for _, msg := range messages {
+		used += estimateMessageTokens(msg)
+	}
+	if used <= budget || conversationID == nil {
+		return conversationContext{systemPrompt: withSummary(systemPrompt, summary), messages: messages}
+	}
+
+	split := splitForSummary(messages, budget/summaryKeepDivisor-tokens.Estimate(systemPrompt))
+	if split == 0 {
+		// Only the latest message is left and there is nothing older to fold
+		return conversationContext{systemPrompt: withSummary(systemPrompt, summary), messages: messages}
+	}
+
+	folded, kept := messages[:split], messages[split:]
+
+	previousSummary := ""
+	if summary != nil {
+		previousSummary = summary.Summary
+	}
+
+	summaryText, err := s.summarizeMessages(ctx, user, previousSummary, folded)
+	if err != nil {
+		// Better to lose the oldest turns than to fail the whole request on the context limit
+		s.logger.WarnContext(ctx, "failed to summarize conversation, dropping oldest turns",
+			slog.String("error", err.Error()),
+			slog.Int64("conversation_id", *conversationID),
+			slog.Int("dropped_messages", len(folded)))
+		return conversationContext{systemPrompt: withSummary(systemPrompt, summary), messages: kept}
+	}
+
+	summary = &domain.ConversationSummary{
+		ConversationID: *conversationID,
+		Summary:        summaryText,
+		LastMessageID:  folded[len(folded)-1].ID,
+	}
+	if upsertErr := s.storage.UpsertConversationSummary(ctx, summary); upsertErr != nil {
+		s.logger.WarnContext(ctx, "failed to save conversation summary",
+			slog.String("error", upsertErr.Error()),
+			slog.Int64("conversation_id", *conversationID))
+	}
+
+	s.logger.InfoContext(ctx, "conversation history summarized",
+		slog.Int64("conversation_id", *conversationID),
+		slog.Int("folded_messages", len(folded)),
+		slog.Int("kept_messages", len(kept)))
+
+	return conversationContext{systemPrompt: withSummary(systemPrompt, summary), messages: kept}
+}
+
+// summarizeMessages folds messages into the previous summary using the utility model and charges
+// the user for it. The summary is stored and saves the tokens of the folded turns on later answers,
+// so it's charged even when the answer itself can't be paid for.
+func (s *UpdateService) summarizeMessages(
+	ctx context.Context,
+	user *domain.User,
+	previousSummary string,
+	messages []*domain.Message,
+) (string, error) {
+	var transcript strings.Builder
+	if previousSummary != "" {
+		transcript.WriteString("Previous summary:\n")
+		transcript.WriteString(previousSummary)
+		transcript.WriteString("\n\n")
+	}
+	transcript.WriteString("Conversation:\n")
+	for _, msg := range messages {
+		role := "User"
+		if msg.SentBy == domain.MessageSenderBot {
+			role = "Assistant"
+		}
+		transcript.WriteString(role)
+		transcript.WriteString(": ")
+		transcript.WriteString(truncateRunes(msg.MessageType.PromptText(), maxSummarizedMessageRunes))
+		transcript.WriteString("\n")
+	}
+
+	tokenStream, err := s.completion.CompleteStream(
+		ctx, textRequest(utilityModel, summarizationPrompt, transcript.String()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("can't start summarization: %w", err)
+	}
+
+	var summaryBuilder strings.Builder
+	var usage *completion.Usage
+	for token := range tokenStream {
+		if token.Error != nil {
+			return "", fmt.Errorf("summarization stream error: %w", token.Error)
+		}
+		if token.Usage != nil {
+			usage = token.Usage
+		}
+		summaryBuilder.WriteString(token.Content)
+	}
+
+	summaryText := strings.TrimSpace(summaryBuilder.String())
+	if summaryText == "" {
+		return "", errors.New("empty summary")
+	}
+
+	// Without the usage reported by the provider the summary is charged by the estimated token counts
+	summaryUsage := domain.AnswerUsage{
+		PromptTokens:     tokens.Estimate(summarizationPrompt) + tokens.Estimate(transcript.String()),
+		CompletionTokens: tokens.Estimate(summaryText),
+	}
+	if usage != nil {
+		summaryUsage.PromptTokens = usage.PromptTokens
+		summaryUsage.CompletionTokens = usage.CompletionTokens
+		s.saveSummaryUsage(ctx, user, usage)
+	}
+	s.chargeSummary(ctx, user, summaryUsage)
+
+	return summaryText, nil
+}
+
+// saveSummaryUsage stores what a summary took, without a message it belongs to.
+func (s *UpdateService) saveSummaryUsage(ctx context.Context, user *domain.User, usage *completion.Usage) {
+	_, err := s.storage.CreateMessageUsage(ctx, &domain.MessageUsage{
+		UserID:           user.ID,
+		Model:            utilityModel,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		ReasoningTokens:  usage.ReasoningTokens,
+		Cost:             usage.Cost,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to save summary usage", slog.String("error", err.Error()))
+	}
+}
+
+// chargeSummary charges a summary at the price of the utility model.
+func (s *UpdateService) chargeSummary(ctx context.Context, user *domain.User, usage domain.AnswerUsage) {
+	model := domain.GetModelByID(utilityModel)
+	if model == nil {
+		s.logger.WarnContext(ctx, "utility model isn't in the catalog, summary is not charged",
+			slog.String("model", utilityModel))
+		return
+	}
+
+	for _, charge := range model.PriceAnswer(usage) {
+		description := "Conversation summary"
+		if charge.Description != "" {
+			description += ": " + charge.Description
+		}
+
+		_, err := s.storage.CreateTransaction(ctx, &domain.Transaction{
+			UserID:          user.ID,
+			TokenType:       charge.TokenType,
+			Amount:          -charge.Amount, // Negative for debit
+			TransactionType: domain.TransactionTypeMessageCost,
+			ModelUsed:       &model.ID,
+			Description:     pointer.To(description),
+			CreatedAt:       time.Now(),
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to create summary cost transaction",
+				slog.String("error", err.Error()),
+				slog.String("model", model.ID),
+				slog.Int64("amount", charge.Amount))
+		}
+	}
+}
+
+// messagesAfterSummary drops messages that are already represented by the summary.
+func messagesAfterSummary(messages []*domain.Message, summary *domain.ConversationSummary) []*domain.Message {
+	if summary == nil {
+		return messages
+	}
+
+	for i, msg := range messages {
+		if msg.ID > summary.LastMessageID {
+			return messages[i:]
+		}
+	}
+
+	return nil
+}
+
+// splitForSummary returns the index of the first message to keep verbatim so that the kept tail
+// fits into keepBudget. The latest message is always kept.
+func splitForSummary(messages []*domain.Message, keepBudget int) int {
+	if len(messages) == 0 {
+		return 0
+	}
+
+	split := len(messages) - 1
+	used := estimateMessageTokens(messages[split])
+	for split > 0 {
+		next := estimateMessageTokens(messages[split-1])
+		if used+next > keepBudget {
+			break
+		}
+		used += next
+		split--
+	}
+
+	return split
+}
+
+// estimateMessageTokens estimates how many tokens a stored message takes in the prompt.
+func estimateMessageTokens(msg *domain.Message) int {
+	return tokens.EstimateMessage(msg.MessageType.PromptText()) + len(msg.MessageType.Files)*attachmentTokenEstimate
+}
+
+func withSummary(systemPrompt string, summary *domain.ConversationSummary) string {
+	if summary == nil || summary.Summary == "" {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\nSummary of the earlier part of this conversation:\n" + summary.Summary
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+)
+
+func TestUpdateService_HandleConversationState_ContextBudget(t *testing.T) {
+	conversationID := int64(7)
+	systemPrompt := "You are a pirate"
+	// A long message takes 25000 of the 64000 tokens Gemini 2.5 Flash is given
+	long := strings.Repeat("a", 100_000)
+
+	message := func(id int64, sentBy domain.MessageSender, text string) *domain.Message {
+		return &domain.Message{
+			ID:             id,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: text},
+			SentBy:         sentBy,
+			ConversationID: &conversationID,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		history []*domain.Message
+		text    string
+		summary *domain.ConversationSummary
+		// summaryErr fails the summarization, nil when the history isn't summarized
+		summaryErr error
+		// expectedSummary is the summary stored for the request, nil when it isn't updated
+		expectedSummary      *domain.ConversationSummary
+		expectedSystemPrompt string
+		expectedTexts        []string
+		expectedCharges      []string
+	}{
+		{
+			name: "history within the budget is sent whole",
+			history: []*domain.Message{
+				message(1, domain.MessageSenderUser, "Hi"),
+				message(2, domain.MessageSenderBot, "Ahoy"),
+			},
+			text:                 "Where is the treasure?",
+			expectedSystemPrompt: systemPrompt,
+			expectedTexts:        []string{"Hi", "Ahoy", "Where is the treasure?"},
+			expectedCharges:      []string{"Reserved for an answer", "Reservation released", ""},
+		},
+		{
+			name: "oldest turns over the budget are summarized",
+			history: []*domain.Message{
+				message(1, domain.MessageSenderUser, long),
+				message(2, domain.MessageSenderBot, long),
+				message(3, domain.MessageSenderUser, "Hi"),
+				message(4, domain.MessageSenderBot, long),
+			},
+			text: "Where is the treasure?",
+			expectedSummary: &domain.ConversationSummary{
+				ConversationID: conversationID,
+				Summary:        "The user asked about ships",
+				LastMessageID:  2,
+			},
+			expectedSystemPrompt: systemPrompt +
+				"\n\nSummary of the earlier part of this conversation:\nThe user asked about ships",
+			expectedTexts: []string{"Hi", long, "Where is the treasure?"},
+			expectedCharges: []string{
+				"Conversation summary: Prompt cost: 50000 tokens",
+				"Conversation summary: Completion cost: 200 tokens",
+				"Reserved for an answer",
+				"Reservation released",
+				"Prompt cost: 25043 tokens",
+			},
+		},
+		{
+			name: "stored summary replaces the turns it covers",
+			history: []*domain.Message{
+				message(1, domain.MessageSenderUser, long),
+				message(2, domain.MessageSenderBot, long),
+				message(3, domain.MessageSenderUser, "Hi"),
+				message(4, domain.MessageSenderBot, "Ahoy"),
+			},
+			text: "Where is the treasure?",
+			summary: &domain.ConversationSummary{
+				ConversationID: conversationID,
+				Summary:        "The user asked about ships",
+				LastMessageID:  2,
+			},
+			expectedSystemPrompt: systemPrompt +
+				"\n\nSummary of the earlier part of this conversation:\nThe user asked about ships",
+			expectedTexts:   []string{"Hi", "Ahoy", "Where is the treasure?"},
+			expectedCharges: []string{"Reserved for an answer", "Reservation released", ""},
+		},
+		{
+			name: "system prompt and the new message are kept when summarizing fails",
+			history: []*domain.Message{
+				message(1, domain.MessageSenderUser, "Hi"),
+				message(2, domain.MessageSenderBot, "Ahoy"),
+			},
+			text:                 strings.Repeat("b", 300_000),
+			summaryErr:           errors.New("provider is down"),
+			expectedSystemPrompt: systemPrompt,
+			expectedTexts:        []string{strings.Repeat("b", 300_000)},
+			expectedCharges: []string{
+				"Reserved for an answer",
+				"Reservation released",
+				"Prompt cost: 75008 tokens",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			// Failing to batch the message makes it answered right away
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), gomock.Any()).
+				Return(int64(1000), nil).
+				AnyTimes()
+			history := append(tt.history, message(5, domain.MessageSenderUser, tt.text))
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(history, nil).
+				Times(2)
+			mockStorage.EXPECT().
+				CreateMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+					message.ID = 5
+					if message.SentBy == domain.MessageSenderBot {
+						message.ID = 6
+					}
+					return message, nil
+				}).
+				Times(2)
+			mockStorage.EXPECT().UpdateConversationTimestamp(gomock.Any(), conversationID).Return(nil).Times(2)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1, SystemPrompt: &systemPrompt}, nil).
+				AnyTimes()
+			if tt.summary == nil {
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(nil, storage.ErrNotFound)
+			} else {
+				mockStorage.EXPECT().GetConversationSummary(gomock.Any(), conversationID).Return(tt.summary, nil)
+			}
+			if tt.expectedSummary != nil {
+				mockStorage.EXPECT().UpsertConversationSummary(gomock.Any(), tt.expectedSummary).Return(nil)
+				mockStorage.EXPECT().
+					CreateMessageUsage(gomock.Any(), &domain.MessageUsage{
+						UserID:           1,
+						Model:            "google/gemini-2.5-flash",
+						PromptTokens:     50000,
+						CompletionTokens: 200,
+					}).
+					Return(&domain.MessageUsage{ID: 1}, nil)
+			}
+			mockStorage.EXPECT().CreateForeignMessage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockStorage.EXPECT().CreateMessageVersion(gomock.Any(), gomock.Any()).Return(&domain.MessageVersion{}, nil)
+
+			var answerRequest completion.CompletionRequest
+			mockCompletion.EXPECT().
+				CompleteStream(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+					stream := make(chan completion.StreamToken, 2)
+					if strings.HasPrefix(req.SystemPrompt, "You maintain a running summary") {
+						if tt.summaryErr != nil {
+							return nil, tt.summaryErr
+						}
+						require.NotNil(t, tt.expectedSummary, "history within the budget is not summarized")
+						stream <- completion.StreamToken{Content: tt.expectedSummary.Summary}
+						stream <- completion.StreamToken{Usage: &completion.Usage{PromptTokens: 50000, CompletionTokens: 200}}
+						close(stream)
+						return stream, nil
+					}
+
+					answerRequest = req
+					stream <- completion.StreamToken{Content: "Arr"}
+					close(stream)
+					return stream, nil
+				}).
+				MinTimes(1)
+
+			mockSender.EXPECT().SendTyping(gomock.Any(), "12345").Return(nil).AnyTimes()
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				Return("100", nil).
+				AnyTimes()
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", gomock.Any()).Return(nil).AnyTimes()
+			mockSender.EXPECT().
+				EditMessageKeyboard(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			var charges []string
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					description := ""
+					if transaction.Description != nil {
+						description = *transaction.Description
+					}
+					charges = append(charges, description)
+					return transaction, nil
+				}).
+				AnyTimes()
+
+			user := &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateConversation,
+				SelectedModel:         "google/gemini-2.5-flash",
+				CurrentConversationID: &conversationID,
+			}
+
+			err := updateService.HandleConversationState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    tt.text,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSystemPrompt, answerRequest.SystemPrompt)
+			var texts []string
+			for _, message := range answerRequest.Messages {
+				require.NotEmpty(t, message.Parts)
+				texts = append(texts, message.Parts[0].Text)
+			}
+			assert.Equal(t, tt.expectedTexts, texts)
+			assert.Equal(t, tt.expectedCharges, charges)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vladimish/talk/internal/domain"
+)
+
+// Export formats offered for a conversation.
+const (
+	exportFormatMarkdown = "md"
+	exportFormatJSON     = "json"
+	exportFormatHTML     = "html"
+)
+
+const (
+	// exportLinkExpiry is how long attachment links in an export stay valid, the longest S3 allows.
+	exportLinkExpiry = 7 * 24 * time.Hour
+	// maxExportFileNameRunes limits the part of the file name taken from the conversation name.
+	maxExportFileNameRunes = 50
+
+	exportTimeLayout = "2006-01-02 15:04 UTC"
+)
+
+// exportedConversation is a conversation with everything needed to render it outside of Telegram.
+// It is also the schema of the JSON export.
+type exportedConversation struct {
+	ID                   int64             `json:"id"`
+	Name                 string            `json:"name"`
+	ParentConversationID *int64            `json:"parent_conversation_id,omitempty"`
+	SystemPrompt         *string           `json:"system_prompt,omitempty"`
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+	ExportedAt           time.Time         `json:"exported_at"`
+	Messages             []exportedMessage `json:"messages"`
+}
+
+type exportedMessage struct {
+	ID          int64                `json:"id"`
+	Role        string               `json:"role"`
+	Text        string               `json:"text"`
+	Quote       string               `json:"quote,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Attachments []exportedAttachment `json:"attachments,omitempty"`
+}
+
+type exportedAttachment struct {
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	URL          string    `json:"url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+}
+
+// exportRole names the author of a message the way chat exports usually do.
+func exportRole(sentBy domain.MessageSender) string {
+	if sentBy == domain.MessageSenderBot {
+		return "assistant"
+	}
+	return "user"
+}
+
+// renderConversationExport renders the conversation as a document in the given format.
+func renderConversationExport(conversation *exportedConversation, format string) (domain.Document, error) {
+	var data []byte
+	var err error
+	switch format {
+	case exportFormatMarkdown:
+		data = renderMarkdownExport(conversation)
+	case exportFormatJSON:
+		data, err = json.MarshalIndent(conversation, "", "  ")
+	case exportFormatHTML:
+		data, err = renderHTMLExport(conversation)
+	default:
+		return domain.Document{}, fmt.Errorf("unknown export format: %s", format)
+	}
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("can't render %s export: %w", format, err)
+	}
+
+	return domain.Document{
+		FileName: exportFileName(conversation.Name) + "." + format,
+		Data:     data,
+	}, nil
+}
+
+func renderMarkdownExport(conversation *exportedConversation) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# %s\n\n", conversation.Name)
+	fmt.Fprintf(&builder, "Exported %s, %d messages\n", conversation.ExportedAt.UTC().Format(exportTimeLayout),
+		len(conversation.Messages))
+	if conversation.SystemPrompt != nil {
+		fmt.Fprintf(&builder, "\nSystem prompt:\n\n%s\n", quoteMarkdown(*conversation.SystemPrompt))
+	}
+
+	for _, message := range conversation.Messages {
+		author := "User"
+		if message.Role == exportRole(domain.MessageSenderBot) {
+			author = "Assistant"
+		}
+		fmt.Fprintf(&builder, "\n---\n\n**%s** · %s\n\n", author, message.CreatedAt.UTC().Format(exportTimeLayout))
+		if message.Quote != "" {
+			builder.WriteString(quoteMarkdown(message.Quote))
+			builder.WriteString("\n\n")
+		}
+		builder.WriteString(message.Text)
+		builder.WriteString("\n")
+
+		if len(message.Attachments) > 0 {
+			builder.WriteString("\nAttachments:\n")
+			for _, attachment := range message.Attachments {
+				fmt.Fprintf(&builder, "- [%s, %s](%s)\n", attachment.ContentType, formatFileSize(attachment.Size),
+					attachment.URL)
+			}
+		}
+	}
+
+	return []byte(builder.String())
+}
+
+func quoteMarkdown(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+var htmlExportTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.UTC().Format(exportTimeLayout) },
+	"size": formatFileSize,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; max-width: 800px; margin: 0 auto; padding: 16px; background: #f4f4f5; color: #18181b; }
+header { margin-bottom: 24px; }
+header p, .meta { color: #71717a; font-size: 13px; }
+.message { border-radius: 12px; padding: 12px 16px; margin: 12px 0; background: #fff; }
+.message.user { background: #dbeafe; margin-left: 48px; }
+.message.assistant { margin-right: 48px; }
+.text { white-space: pre-wrap; word-wrap: break-word; }
+blockquote { margin: 0 0 8px; padding-left: 10px; border-left: 3px solid #a1a1aa; color: #52525b; white-space: pre-wrap; }
+ul { margin: 8px 0 0; padding-left: 20px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Name}}</h1>
+<p>Exported {{time .ExportedAt}}, {{len .Messages}} messages</p>
+{{- if .SystemPrompt}}
+<blockquote>{{.SystemPrompt}}</blockquote>
+{{- end}}
+</header>
+{{- range .Messages}}
+<div class="message {{.Role}}">
+<div class="meta">{{.Role}} · {{time .CreatedAt}}</div>
+{{- if .Quote}}
+<blockquote>{{.Quote}}</blockquote>
+{{- end}}
+<div class="text">{{.Text}}</div>
+{{- if .Attachments}}
+<ul>
+{{- range .Attachments}}
+<li><a href="{{.URL}}">{{.ContentType}}, {{size .Size}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+func renderHTMLExport(conversation *exportedConversation) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := htmlExportTemplate.Execute(&buffer, conversation); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// exportFileName turns a conversation name into a file name without an extension.
+func exportFileName(name string) string {
+	var builder strings.Builder
+	count := 0
+	lastUnderscore := false
+	for _, r := range name {
+		if count == maxExportFileNameRunes {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			builder.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore {
+			builder.WriteRune('_')
+			lastUnderscore = true
+		}
+		count++
+	}
+
+	fileName := strings.Trim(builder.String(), "_")
+	if fileName == "" {
+		return "conversation"
+	}
+	return fileName
+}
+
+func formatFileSize(size int64) string {
+	const kilobyte = 1024
+	switch {
+	case size <= 0:
+		return "unknown size"
+	case size < kilobyte:
+		return fmt.Sprintf("%d B", size)
+	case size < kilobyte*kilobyte:
+		return fmt.Sprintf("%.1f KB", float64(size)/kilobyte)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(kilobyte*kilobyte))
+	}
+}
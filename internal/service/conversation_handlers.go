@@ -9,13 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/vladimish/talk/pkg/pointer"
-
 	"github.com/vladimish/talk/internal/domain"
 	"github.com/vladimish/talk/internal/port/completion"
 	"github.com/vladimish/talk/internal/port/queue"
 	"github.com/vladimish/talk/internal/port/storage"
-	"github.com/vladimish/talk/pkg/i18n"
 )
 
 const (
@@ -37,31 +34,27 @@ func (s *UpdateService) handleConversationMessageWithReply(
 	update domain.Update,
 	replyToMessageID *int64,
 ) error {
-	// Check if an image is provided and validate model support
-	if len(update.ImageData) > 0 || update.ImageMimeType != "" {
-		modelInfo := domain.GetModelByID(user.SelectedModel)
-		if modelInfo == nil || !modelInfo.ImageSupport {
-			errorMsg := i18n.GetString(user.Language, i18n.ModelImageNotSupported)
-			_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, errorMsg)
-			if sendErr != nil {
-				s.logger.WarnContext(
-					ctx,
-					"failed to send image not supported message",
-					slog.String("error", sendErr.Error()),
-				)
-			}
-			return nil // Don't process the message further
-		}
-	}
-
 	// Get model info for cost calculation
-	currentModel := domain.GetModelByID(user.SelectedModel)
+	currentModel := s.currentModel(ctx, user)
 	if currentModel == nil {
-		fmt.Println(user.SelectedModel)
-		fmt.Println(domain.AvailableModels)
 		return fmt.Errorf("model not found: %s", user.SelectedModel)
 	}
 
+	// Documents are given to the model as their text
+	update.Files = s.extractDocuments(ctx, user, update.Files)
+	if !update.HasPrompt() {
+		return nil
+	}
+
+	// Check that the model takes the attached images and files
+	if notice := unsupportedFilesNotice(currentModel, user.Language, update.Files); notice != "" {
+		if _, sendErr := s.sender.SendMessage(ctx, user.ExternalID, notice); sendErr != nil {
+			s.logger.WarnContext(ctx, "failed to send unsupported files message",
+				slog.String("error", sendErr.Error()))
+		}
+		return nil // Don't process the message further
+	}
+
 	// Check if user has active subscription (required for web search)
 	activeSubscription, err := s.storage.GetActiveSubscriptionByUserID(ctx, user.ID)
 	if err != nil && !errors.Is(err, storage.ErrNotFound) {
@@ -72,48 +65,6 @@ func (s *UpdateService) handleConversationMessageWithReply(
 	// Check if web search is enabled and user has subscription
 	webSearchEnabled := currentModel.WebSearch && user.WebSearchEnabled && hasActiveSubscription
 
-	// Check base model token balance
-	baseBalance, err := s.storage.GetUserTokenBalanceByType(ctx, user.ID, currentModel.TokenType)
-	if err != nil {
-		return fmt.Errorf("failed to get user base token balance: %w", err)
-	}
-
-	if baseBalance < currentModel.Cost {
-		tokenTypeName := "regular"
-		if currentModel.TokenType == domain.TokenTypePremium {
-			tokenTypeName = "premium"
-		}
-		insufficientTokensMsg := fmt.Sprintf(
-			i18n.GetString(user.Language, i18n.ProfileInsufficientTokens),
-			currentModel.Cost,
-			tokenTypeName,
-		)
-		_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, insufficientTokensMsg)
-		return sendErr
-	}
-
-	// Check search token balance if web search is enabled
-	if webSearchEnabled && currentModel.SearchCost != nil && currentModel.SearchTokenType != nil {
-		searchBalance, searchErr := s.storage.GetUserTokenBalanceByType(ctx, user.ID, *currentModel.SearchTokenType)
-		if searchErr != nil {
-			return fmt.Errorf("failed to get user search token balance: %w", searchErr)
-		}
-
-		if searchBalance < *currentModel.SearchCost {
-			searchTokenTypeName := "regular"
-			if *currentModel.SearchTokenType == domain.TokenTypePremium {
-				searchTokenTypeName = "premium"
-			}
-			insufficientSearchTokensMsg := fmt.Sprintf(
-				i18n.GetString(user.Language, i18n.ProfileInsufficientTokens),
-				*currentModel.SearchCost,
-				searchTokenTypeName,
-			)
-			_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, insufficientSearchTokensMsg)
-			return sendErr
-		}
-	}
-
 	// Set processing lock with 5 minute timeout
 	if lockErr := s.queue.SetProcessing(ctx, user.ExternalID, processingLockTimeout); lockErr != nil {
 		if errors.Is(lockErr, queue.ErrAlreadyProcessing) {
@@ -137,24 +88,26 @@ func (s *UpdateService) handleConversationMessageWithReply(
 	}()
 	// Check if this is the first message in a new conversation
 	var isFirstMessage bool
+	var history []*domain.Message
 	if user.CurrentConversationID != nil {
 		messages, msgErr := s.storage.GetMessagesByConversationID(ctx, *user.CurrentConversationID)
 		if msgErr != nil {
 			return fmt.Errorf("can't check existing messages: %w", msgErr)
 		}
 		isFirstMessage = len(messages) == 0
+		history = messages
 	}
 
+	// Upload the images and files, the message keeps references to them and the model gets them in order
+	files, attachments := s.uploadFiles(ctx, update.Files)
+
 	// Save incoming message from user
 	userMessage, err := s.storage.CreateMessage(ctx, &domain.Message{
 		UserID: user.ID,
 		MessageType: domain.MessageType{
-			Text:          update.MessageText,
-			ImageData:     update.ImageData,
-			ImageMimeType: update.ImageMimeType,
-			PDFData:       update.PDFData,
-			PDFMimeType:   update.PDFMimeType,
-			PDFFileName:   update.PDFFileName,
+			Text:  update.MessageText,
+			Files: fileReferences(files),
+			Quote: s.resolveQuote(ctx, user, update, history),
 		},
 		SentBy:         domain.MessageSenderUser,
 		ConversationID: user.CurrentConversationID,
@@ -165,6 +118,47 @@ func (s *UpdateService) handleConversationMessageWithReply(
 		return fmt.Errorf("can't save user message: %w", err)
 	}
 
+	// Get conversation history
+	var messages []*domain.Message
+	if user.CurrentConversationID != nil {
+		messages, err = s.storage.GetMessagesByConversationID(ctx, *user.CurrentConversationID)
+		if err != nil {
+			s.discardMessage(ctx, userMessage)
+			return fmt.Errorf("can't get messages: %w", err)
+		}
+	}
+
+	// Send typing indicator and start periodic typing
+	err = s.sender.SendTyping(ctx, user.ExternalID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to send typing indicator", slog.String("error", err.Error()))
+	}
+
+	// Start periodic typing indicator for the entire duration
+	typingDone := make(chan struct{})
+	defer close(typingDone)
+
+	go s.sendPeriodicTyping(ctx, user.ExternalID, typingDone)
+
+	systemPrompt := s.resolveConversationSystemPrompt(ctx, user)
+
+	// Web search is already calculated above with subscription check
+	// Use the webSearchEnabled variable from the cost calculation
+
+	llmContext := s.buildConversationContext(ctx, user, user.CurrentConversationID, currentModel, systemPrompt, messages)
+
+	bill, err := s.reserveAnswer(ctx, user, currentModel, llmContext, attachments, webSearchEnabled)
+	if err != nil {
+		// The message can't be answered, so it doesn't stay in the conversation as a turn without an answer
+		s.discardMessage(ctx, userMessage)
+		if errors.Is(err, errInsufficientBalance) {
+			return nil
+		}
+		return err
+	}
+	// Returns the reservation unless the answer is settled
+	defer s.releaseAnswer(ctx, bill)
+
 	// Update conversation timestamp when user message is created
 	if user.CurrentConversationID != nil {
 		if timestampErr := s.storage.UpdateConversationTimestamp(ctx, *user.CurrentConversationID); timestampErr != nil {
@@ -175,7 +169,7 @@ func (s *UpdateService) handleConversationMessageWithReply(
 	}
 
 	// Generate conversation name if this is the first message
-	if isFirstMessage && user.CurrentConversationID != nil {
+	if isFirstMessage && user.CurrentConversationID != nil && update.MessageText != "" {
 		go s.generateAndUpdateConversationName(context.Background(), *user.CurrentConversationID, update.MessageText)
 	}
 
@@ -190,126 +184,114 @@ func (s *UpdateService) handleConversationMessageWithReply(
 		}
 	}
 
-	// Get conversation history
-	var messages []*domain.Message
-	if user.CurrentConversationID != nil {
-		messages, err = s.storage.GetMessagesByConversationID(ctx, *user.CurrentConversationID)
+	s.recordAttachments(ctx, userMessage, files)
+
+	gen := s.startGeneration(ctx, user)
+	defer gen.finish()
+
+	var answer *shownAnswer
+	answeringModel := currentModel
+	if currentModel.ImageOutput {
+		answer, err = s.generateImages(ctx, gen, user, currentModel, llmContext, attachments, replyToMessageID)
+	} else {
+		var tokenStream <-chan completion.StreamToken
+		tokenStream, answeringModel, err = s.completeAnswer(
+			ctx, gen, user, currentModel, llmContext, attachments, webSearchEnabled,
+		)
 		if err != nil {
-			return fmt.Errorf("can't get messages: %w", err)
+			return err
 		}
-	}
+		bill.answeredBy(answeringModel)
 
-	// Send typing indicator and start periodic typing
-	err = s.sender.SendTyping(ctx, user.ExternalID)
+		answer, err = s.streamAnswer(ctx, user, tokenStream, gen, nil, replyToMessageID)
+	}
+	gen.finish()
 	if err != nil {
-		s.logger.WarnContext(ctx, "failed to send typing indicator", slog.String("error", err.Error()))
+		return err
 	}
-
-	// Start periodic typing indicator for the entire duration
-	typingDone := make(chan struct{})
-	defer close(typingDone)
-
-	go s.sendPeriodicTyping(ctx, user.ExternalID, typingDone)
-
-	// Handle image upload if present
-	uploadResult := s.handleImageUpload(ctx, update.ImageData, update.ImageMimeType)
-	currentImageURL := ""
-	if uploadResult != nil {
-		currentImageURL = uploadResult.imageURL
+	if answer.stopped && answer.text == "" {
+		// Nothing was generated, so there is no answer to keep or to pay for
+		return nil
 	}
 
-	// Handle PDF upload if present
-	pdfUploadResult := s.handlePDFUpload(ctx, update.PDFData, update.PDFMimeType, update.PDFFileName)
-	currentPDFURL := ""
-	currentPDFFileName := ""
-	if pdfUploadResult != nil {
-		currentPDFURL = pdfUploadResult.pdfURL
-		currentPDFFileName = update.PDFFileName
+	botMessage, err := s.storage.CreateMessage(ctx, &domain.Message{
+		UserID: user.ID,
+		MessageType: domain.MessageType{
+			Text:  answer.text,
+			Files: fileReferences(answer.images),
+		},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: user.CurrentConversationID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't save bot message: %w", err)
 	}
 
-	// Create attachment record if image was uploaded
-	if uploadResult != nil {
-		_, attachmentErr := s.storage.CreateAttachment(ctx, &domain.Attachment{
-			MessageID:   userMessage.ID,
-			S3Name:      uploadResult.objectName,
-			ContentType: uploadResult.contentType,
-			Size:        uploadResult.size,
-		})
-		if attachmentErr != nil {
-			s.logger.ErrorContext(
-				ctx,
-				"failed to create image attachment record",
-				slog.String("error", attachmentErr.Error()),
-			)
+	// Update conversation timestamp when bot message is created
+	if user.CurrentConversationID != nil {
+		if timestampErr := s.storage.UpdateConversationTimestamp(ctx, *user.CurrentConversationID); timestampErr != nil {
+			s.logger.WarnContext(ctx, "failed to update conversation timestamp",
+				slog.String("error", timestampErr.Error()),
+				slog.Int64("conversation_id", *user.CurrentConversationID))
 		}
 	}
 
-	// Create attachment record if PDF was uploaded
-	if pdfUploadResult != nil {
-		_, attachmentErr := s.storage.CreateAttachment(ctx, &domain.Attachment{
-			MessageID:   userMessage.ID,
-			S3Name:      pdfUploadResult.objectName,
-			ContentType: pdfUploadResult.contentType,
-			Size:        pdfUploadResult.size,
-		})
-		if attachmentErr != nil {
-			s.logger.ErrorContext(
-				ctx,
-				"failed to create PDF attachment record",
-				slog.String("error", attachmentErr.Error()),
-			)
-		}
-	}
+	// Save foreign message mappings for all bot messages
+	s.saveForeignMessages(ctx, botMessage.ID, answer.messageIDs)
+	s.saveUsage(ctx, user, botMessage.ID, answeringModel.ID, answer.usage)
 
-	// Check if PDF is provided but model doesn't support it
-	if pdfUploadResult != nil && !currentModel.PDFSupport {
-		notSupportedMsg := i18n.GetString(user.Language, i18n.ModelPDFNotSupported)
-		_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, notSupportedMsg)
-		if sendErr != nil {
-			s.logger.WarnContext(ctx, "failed to send PDF not supported message",
-				slog.String("error", sendErr.Error()))
-		}
-		return nil
+	s.recordAttachments(ctx, botMessage, answer.images)
+
+	// Keep the first version of the answer and offer to regenerate it, images can't be regenerated in place
+	if answer.generatedImages == 0 {
+		s.saveAnswerVersion(ctx, user, botMessage.ID, answeringModel.ID, answer)
 	}
 
-	systemPrompt := "You are a helpful assistant."
+	// Charge for what the answer took, a stopped answer is paid only when it has text
+	s.settleAnswer(ctx, bill, answer)
 
-	// Web search is already calculated above with subscription check
-	// Use the webSearchEnabled variable from the cost calculation
+	s.speakAnswer(ctx, user, answer)
 
-	// Prepare file attachments if any
-	var imageAttachment *completion.FileAttachment
-	if currentImageURL != "" {
-		imageAttachment = &completion.FileAttachment{
-			URL:      currentImageURL,
-			MimeType: "image/jpeg",
-		}
-	}
+	return nil
+}
 
-	var pdfAttachment *completion.FileAttachment
-	if len(update.PDFData) > 0 {
-		pdfAttachment = &completion.FileAttachment{
-			URL:      currentPDFURL, // Keep the MinIO URL for other purposes if needed
-			MimeType: "application/pdf",
-			FileName: currentPDFFileName,
-			Data:     update.PDFData, // Pass the raw PDF data for OpenRouter
-		}
+// discardMessage deletes a user message that won't be answered, e.g. when the answer can't be paid for.
+func (s *UpdateService) discardMessage(ctx context.Context, message *domain.Message) {
+	if err := s.storage.DeleteMessage(ctx, message.ID); err != nil {
+		s.logger.ErrorContext(ctx, "failed to delete unanswered message",
+			slog.String("error", err.Error()),
+			slog.Int64("message_id", message.ID))
 	}
+}
 
-	tokenStream, err := s.completion.CompleteStreamWithAttachments(
-		ctx,
-		user.SelectedModel,
-		systemPrompt,
-		messages,
-		imageAttachment,
-		pdfAttachment,
-		webSearchEnabled,
-	)
-	if err != nil {
-		return fmt.Errorf("can't get completion: %w", err)
-	}
+// shownAnswer is a bot answer as it is currently displayed in the chat, possibly split into several messages.
+type shownAnswer struct {
+	messageIDs []string
+	text       string
+	// stopped is set when the user stopped the generation and text is only the part generated before that.
+	stopped bool
+	// usage is what generating the answer took, when the provider reported it.
+	usage *completion.Usage
+	// model is the model that generated a new answer, a fallback of the requested one when that failed.
+	model *domain.ModelInfo
+	// generatedImages is how many images an image model answered with, images are the ones that were uploaded.
+	generatedImages int
+	images          []domain.File
+}
 
-	// Stream response tokens and handle messaging
+// streamAnswer streams completion tokens into the chat. When shown is not nil the tokens are streamed
+// into the messages that already display an answer, otherwise new messages are sent.
+// When the user stops gen, the text generated so far is shown as the answer.
+func (s *UpdateService) streamAnswer(
+	ctx context.Context,
+	user *domain.User,
+	tokenStream <-chan completion.StreamToken,
+	gen *generation,
+	shown *shownAnswer,
+	replyToMessageID *int64,
+) (*shownAnswer, error) {
 	var responseBuilder strings.Builder
 	var reasoningBuilder strings.Builder
 	var messageIDs []string
@@ -318,11 +300,21 @@ func (s *UpdateService) handleConversationMessageWithReply(
 	var previousReasoningContent string
 	var hasReasoningMessage bool
 	var hasMainMessage bool
+	var usage *completion.Usage
+	if shown != nil {
+		// Stream into the messages that already display an answer instead of sending new ones
+		messageIDs = shown.messageIDs
+		previousContent = shown.text
+		hasMainMessage = len(shown.messageIDs) > 0
+	}
 	lastUpdate := time.Now()
 
 	for token := range tokenStream {
 		if token.Error != nil {
-			return fmt.Errorf("completion stream error: %w", token.Error)
+			if gen.isStopped() {
+				break
+			}
+			return nil, fmt.Errorf("completion stream error: %w", token.Error)
 		}
 
 		// Handle reasoning tokens - accumulate in reasoning builder
@@ -335,6 +327,10 @@ func (s *UpdateService) handleConversationMessageWithReply(
 			responseBuilder.WriteString(token.Content)
 		}
 
+		if token.Usage != nil {
+			usage = token.Usage
+		}
+
 		// Update messages at most once per second
 		if time.Since(lastUpdate) >= time.Second {
 			// Handle periodic updates
@@ -352,7 +348,7 @@ func (s *UpdateService) handleConversationMessageWithReply(
 				replyToMessageID,
 			)
 			if updateErr != nil {
-				return updateErr
+				return nil, updateErr
 			}
 			if shouldContinue {
 				continue
@@ -362,8 +358,21 @@ func (s *UpdateService) handleConversationMessageWithReply(
 		}
 	}
 
+	stopped := gen.isStopped()
+	if stopped {
+		s.logger.InfoContext(ctx, "generation stopped by user",
+			slog.String("user_id", user.ExternalID),
+			slog.Int("answer_length", responseBuilder.Len()))
+
+		if responseBuilder.Len() == 0 {
+			// Nothing was generated, so the chat keeps displaying what it did before
+			return &shownAnswer{messageIDs: messageIDs, stopped: true}, nil
+		}
+	}
+
 	// Send final updates if needed
 	if time.Since(lastUpdate) > 0 {
+		var err error
 		messageIDs, err = s.sendFinalUpdates(
 			ctx,
 			user,
@@ -378,36 +387,20 @@ func (s *UpdateService) handleConversationMessageWithReply(
 			replyToMessageID,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	responseText := responseBuilder.String()
-
-	botMessage, err := s.storage.CreateMessage(ctx, &domain.Message{
-		UserID: user.ID,
-		MessageType: domain.MessageType{
-			Text: responseText,
-		},
-		SentBy:         domain.MessageSenderBot,
-		ConversationID: user.CurrentConversationID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	})
-	if err != nil {
-		return fmt.Errorf("can't save bot message: %w", err)
-	}
-
-	// Update conversation timestamp when bot message is created
-	if user.CurrentConversationID != nil {
-		if timestampErr := s.storage.UpdateConversationTimestamp(ctx, *user.CurrentConversationID); timestampErr != nil {
-			s.logger.WarnContext(ctx, "failed to update conversation timestamp",
-				slog.String("error", timestampErr.Error()),
-				slog.Int64("conversation_id", *user.CurrentConversationID))
-		}
-	}
+	return &shownAnswer{
+		messageIDs: messageIDs,
+		text:       responseBuilder.String(),
+		stopped:    stopped,
+		usage:      usage,
+	}, nil
+}
 
-	// Save foreign message mappings for all bot messages
+// saveForeignMessages maps the Telegram messages that display a bot answer to the stored message.
+func (s *UpdateService) saveForeignMessages(ctx context.Context, botMessageID int64, messageIDs []string) {
 	for _, msgIDStr := range messageIDs {
 		if msgIDStr == "" {
 			continue
@@ -423,66 +416,22 @@ func (s *UpdateService) handleConversationMessageWithReply(
 		}
 
 		// Create foreign message mapping
-		if botMessage.ID > int64(^uint32(0)>>1) || msgID > int64(^uint32(0)>>1) {
+		if botMessageID > int64(^uint32(0)>>1) || msgID > int64(^uint32(0)>>1) {
 			s.logger.WarnContext(ctx, "message ID too large for foreign message mapping")
 			continue
 		}
-		err = s.storage.CreateForeignMessage(ctx, int32(botMessage.ID), int32(msgID)) //nolint:gosec
+		err := s.storage.CreateForeignMessage(ctx, int32(botMessageID), int32(msgID)) //nolint:gosec
 		if err != nil {
 			s.logger.WarnContext(ctx, "failed to save foreign message mapping for bot",
 				slog.String("telegram_message_id", msgIDStr),
-				slog.Int64("db_message_id", botMessage.ID),
+				slog.Int64("db_message_id", botMessageID),
 				slog.String("error", err.Error()))
 		} else {
 			s.logger.DebugContext(ctx, "saved foreign message mapping",
 				slog.String("telegram_message_id", msgIDStr),
-				slog.Int64("db_message_id", botMessage.ID))
-		}
-	}
-
-	// Deduct base model tokens after successful completion
-	baseTransaction := &domain.Transaction{
-		UserID:          user.ID,
-		TokenType:       currentModel.TokenType,
-		Amount:          -currentModel.Cost, // Negative for debit
-		TransactionType: domain.TransactionTypeMessageCost,
-		ModelUsed:       &currentModel.ID,
-		Description:     nil,
-		CreatedAt:       time.Now(),
-	}
-
-	_, err = s.storage.CreateTransaction(ctx, baseTransaction)
-	if err != nil {
-		// Log error but don't fail the entire operation
-		s.logger.ErrorContext(ctx, "failed to create base token deduction transaction",
-			slog.String("error", err.Error()),
-			slog.String("model", currentModel.ID),
-			slog.Int("cost", int(currentModel.Cost)))
-	}
-
-	// Deduct search tokens if web search was used
-	if webSearchEnabled && currentModel.SearchCost != nil && currentModel.SearchTokenType != nil {
-		searchTransaction := &domain.Transaction{
-			UserID:          user.ID,
-			TokenType:       *currentModel.SearchTokenType,
-			Amount:          -*currentModel.SearchCost, // Negative for debit
-			TransactionType: domain.TransactionTypeMessageCost,
-			ModelUsed:       &currentModel.ID,
-			Description:     pointer.To("Web search cost"),
-			CreatedAt:       time.Now(),
-		}
-
-		_, err = s.storage.CreateTransaction(ctx, searchTransaction)
-		if err != nil {
-			// Log error but don't fail the entire operation
-			s.logger.ErrorContext(ctx, "failed to create search token deduction transaction",
-				slog.String("error", err.Error()),
-				slog.String("model", currentModel.ID),
-				slog.Int("search_cost", int(*currentModel.SearchCost)))
+				slog.Int64("db_message_id", botMessageID))
 		}
 	}
-
-	return nil
 }
 
 // handleReasoningUpdate manages reasoning message creation and updates.
@@ -807,6 +756,12 @@ func (s *UpdateService) generateAndUpdateConversationName(
 	conversationID int64,
 	firstMessage string,
 ) {
+	// The user may have named a new conversation before writing to it
+	conversation, err := s.storage.GetConversationByID(ctx, conversationID)
+	if err == nil && conversation.Name != defaultConversationName {
+		return
+	}
+
 	const systemPrompt = `Generate a short, descriptive name for a chat conversation based on the user's first message. 
 The name should be 2-5 words max and capture the main topic or intent.
 Return ONLY the conversation name, nothing else.
@@ -815,22 +770,8 @@ Examples:
 - User: "Tell me about quantum physics" -> "Quantum Physics Discussion"
 - User: "I need help with Python code" -> "Python Code Help"`
 
-	messages := []*domain.Message{
-		{
-			MessageType: domain.MessageType{Text: firstMessage},
-			SentBy:      domain.MessageSenderUser,
-		},
-	}
-
-	// Use Gemini model for generating conversation name
-	tokenStream, err := s.completion.CompleteStream(
-		ctx,
-		"google/gemini-2.5-flash",
-		systemPrompt,
-		messages,
-		"",    // no image for conversation name generation
-		false, // no web search for conversation name generation
-	)
+	// Use the utility model for generating conversation name
+	tokenStream, err := s.completion.CompleteStream(ctx, textRequest(utilityModel, systemPrompt, firstMessage))
 	if err != nil {
 		s.logger.WarnContext(ctx, "failed to generate conversation name with LLM, using fallback",
 			slog.String("error", err.Error()))
@@ -918,7 +859,7 @@ func (s *UpdateService) processQueuedMessages(ctx context.Context, user *domain.
 
 		// Process the update with reply to the original message
 		// We need to handle conversation messages specially to add reply_to_message_id
-		if freshUser.CurrentStep == domain.UserStateConversation && queuedItem.Update.MessageText != "" {
+		if freshUser.CurrentStep == domain.UserStateConversation && queuedItem.Update.HasPrompt() {
 			// Convert external message ID to int64 for reply
 			var replyToMessageID *int64
 			if queuedItem.Update.ExternalMessageID > 0 {
@@ -944,6 +885,90 @@ func (s *UpdateService) processQueuedMessages(ctx context.Context, user *domain.
 	}
 }
 
+// uploadFiles uploads the files of a user message. It returns the files with their objects and the files
+// as completion parts in order. Images are passed by links to their uploads, PDFs by their data
+// and other documents by their extracted text.
+func (s *UpdateService) uploadFiles(ctx context.Context, files []domain.File) ([]domain.File, []completion.Part) {
+	uploaded := make([]domain.File, 0, len(files))
+	var attachments []completion.Part
+	for _, file := range files {
+		switch {
+		case file.IsImage():
+			uploadResult := s.handleImageUpload(ctx, file.Data, file.MimeType)
+			if uploadResult == nil {
+				continue
+			}
+			file.S3Name = uploadResult.objectName
+			attachments = append(attachments, completion.Part{
+				Type:     completion.PartTypeImage,
+				URL:      uploadResult.imageURL,
+				MimeType: file.MimeType,
+			})
+		case file.IsPDF():
+			if pdfUploadResult := s.handlePDFUpload(ctx, file.Data, file.MimeType, file.FileName); pdfUploadResult != nil {
+				file.S3Name = pdfUploadResult.objectName
+			}
+			attachments = append(attachments, completion.Part{
+				Type:     completion.PartTypeFile,
+				MimeType: file.MimeType,
+				FileName: file.FileName,
+				Data:     file.Data, // Pass the raw PDF data for OpenRouter
+			})
+		case file.IsDocument():
+			file.S3Name, file.TextS3Name = s.uploadDocument(ctx, file)
+			attachments = append(attachments, documentPart(file))
+		}
+		uploaded = append(uploaded, file)
+	}
+	return uploaded, attachments
+}
+
+// fileReferences returns the files without their content.
+func fileReferences(files []domain.File) []domain.File {
+	if len(files) == 0 {
+		return nil
+	}
+
+	references := make([]domain.File, len(files))
+	for i, file := range files {
+		references[i] = file.Reference()
+	}
+	return references
+}
+
+// recordAttachments creates the attachment records of the uploaded files of a message,
+// and of the extracted text of its documents.
+func (s *UpdateService) recordAttachments(ctx context.Context, message *domain.Message, files []domain.File) {
+	for _, file := range files {
+		s.recordAttachment(ctx, message, file.S3Name, file.MimeType, len(file.Data))
+		s.recordAttachment(ctx, message, file.TextS3Name, documentTextMimeType, len(file.Text))
+	}
+}
+
+func (s *UpdateService) recordAttachment(
+	ctx context.Context,
+	message *domain.Message,
+	s3Name string,
+	contentType string,
+	size int,
+) {
+	if s3Name == "" {
+		return
+	}
+
+	_, err := s.storage.CreateAttachment(ctx, &domain.Attachment{
+		MessageID:   message.ID,
+		S3Name:      s3Name,
+		ContentType: contentType,
+		Size:        int64(size),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create attachment record",
+			slog.String("error", err.Error()),
+			slog.String("content_type", contentType))
+	}
+}
+
 type imageUploadResult struct {
 	imageURL    string
 	objectName  string
@@ -2,7 +2,10 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +13,8 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
 	"github.com/vladimish/talk/internal/service"
 	"github.com/vladimish/talk/mocks"
 	"github.com/vladimish/talk/pkg/i18n"
@@ -98,3 +103,119 @@ func TestUpdateService_HandleConversationState(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateService_HandleConversationState_Files(t *testing.T) {
+	conversationID := int64(7)
+	errStop := errors.New("stop after saving the user message")
+	image := domain.File{Data: []byte("jpeg"), MimeType: "image/jpeg"}
+	pdf := domain.File{Data: []byte("%PDF"), MimeType: "application/pdf", FileName: "report.pdf"}
+	gpt4oMini := domain.GetModelByID("openai/gpt-4o-mini")
+
+	tests := []struct {
+		name  string
+		files []domain.File
+		// expectedNotice is sent instead of answering, empty when the message is answered
+		expectedNotice string
+	}{
+		{
+			name:  "album within the model limits is saved in order",
+			files: []domain.File{image, pdf, image},
+		},
+		{
+			name:  "more images than the model takes",
+			files: slices.Repeat([]domain.File{image}, gpt4oMini.MaxImages+1),
+			expectedNotice: fmt.Sprintf(
+				i18n.GetString("en", i18n.ModelImageLimit), gpt4oMini.GetDisplayName("en"), gpt4oMini.MaxImages,
+			),
+		},
+		{
+			name:  "more PDF files than the model takes",
+			files: slices.Repeat([]domain.File{pdf}, gpt4oMini.MaxPDFs+1),
+			expectedNotice: fmt.Sprintf(
+				i18n.GetString("en", i18n.ModelPDFLimit), gpt4oMini.GetDisplayName("en"), gpt4oMini.MaxPDFs,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			// Failing to batch the message makes it processed right away
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+
+			if tt.expectedNotice != "" {
+				mockSender.EXPECT().SendMessage(gomock.Any(), "12345", tt.expectedNotice).Return("1", nil)
+			} else {
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(nil, nil)
+				// The message references the uploaded files without their content
+				var expectedFiles []domain.File
+				for i, file := range tt.files {
+					objectName := fmt.Sprintf("file%d", i)
+					mockFileStorage.EXPECT().Upload(gomock.Any(), file.Data, file.MimeType).Return(objectName, nil)
+					mockFileStorage.EXPECT().
+						GetPreSignedURL(gomock.Any(), objectName, gomock.Any()).
+						Return("https://files.example.com/"+objectName, nil)
+					expectedFiles = append(expectedFiles, domain.File{
+						S3Name:   objectName,
+						MimeType: file.MimeType,
+						FileName: file.FileName,
+					})
+				}
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						assert.Equal(t, expectedFiles, message.MessageType.Files)
+						return nil, errStop
+					})
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			}
+
+			user := &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateConversation,
+				SelectedModel:         gpt4oMini.ID,
+				CurrentConversationID: &conversationID,
+			}
+
+			err := updateService.HandleConversationState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Files:          tt.files,
+			})
+			if tt.expectedNotice != "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errStop)
+		})
+	}
+}
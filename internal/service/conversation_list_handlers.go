@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vladimish/talk/internal/domain"
@@ -14,6 +16,12 @@ import (
 
 const (
 	defaultConversationName = "New conversation"
+
+	// conversationListPageSize is how many conversations one page of the conversation list shows.
+	conversationListPageSize = 5
+
+	// callbackOpenConversation is the callback data of the inline buttons opening a conversation: conv_open:<id>.
+	callbackOpenConversation = "conv_open"
 )
 
 // Conversation list handlers.
@@ -51,6 +59,14 @@ func (s *UpdateService) HandleConversationListState(
 		return s.createNewConversation(ctx, user)
 	}
 
+	// Check conversation management buttons
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonManageConversations) {
+		return s.showManageConversations(ctx, user)
+	}
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonArchivedConversations) {
+		return s.showArchivedConversations(ctx, user)
+	}
+
 	// Check navigation buttons
 	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonNextPage) {
 		return s.handleNextPage(ctx, user)
@@ -66,8 +82,7 @@ func (s *UpdateService) HandleConversationListState(
 	}
 
 	for _, conversation := range conversations {
-		lastUpdate := s.formatDateTime(conversation.UpdatedAt)
-		if update.MessageText == fmt.Sprintf("💬 %s, %s", conversation.Name, lastUpdate) {
+		if update.MessageText == s.conversationButtonText(conversation) {
 			return s.selectConversation(ctx, user, conversation.ID)
 		}
 	}
@@ -87,17 +102,8 @@ func (s *UpdateService) showConversationList(ctx context.Context, user *domain.U
 		return fmt.Errorf("can't get conversations: %w", err)
 	}
 
-	const pageSize = 5
 	totalConversations := len(conversations)
-	offset := user.ConversationListOffset
-
-	// Validate and adjust offset
-	if offset < 0 {
-		offset = 0
-	}
-	if offset >= totalConversations && totalConversations > 0 {
-		offset = ((totalConversations - 1) / pageSize) * pageSize
-	}
+	offset, end := conversationListPage(totalConversations, user.ConversationListOffset)
 
 	var buttons [][]domain.KeyboardButton
 
@@ -107,23 +113,14 @@ func (s *UpdateService) showConversationList(ctx context.Context, user *domain.U
 	})
 
 	// Add conversations for current page
-	start := offset
-	end := offset + pageSize
-	if end > totalConversations {
-		end = totalConversations
-	}
-
-	for i := start; i < end; i++ {
-		conversation := conversations[i]
-		// Format the last update time according to user's language
-		lastUpdate := s.formatDateTime(conversation.UpdatedAt)
+	for i := offset; i < end; i++ {
 		buttons = append(buttons, []domain.KeyboardButton{
-			{Text: fmt.Sprintf("💬 %s, %s", conversation.Name, lastUpdate)},
+			{Text: s.conversationButtonText(conversations[i])},
 		})
 	}
 
 	// Add navigation buttons if needed
-	if totalConversations > pageSize {
+	if totalConversations > conversationListPageSize {
 		var navButtons []domain.KeyboardButton
 
 		// Add previous button if not on first page
@@ -143,6 +140,17 @@ func (s *UpdateService) showConversationList(ctx context.Context, user *domain.U
 		}
 	}
 
+	// Add conversation management buttons
+	managementButtons := []domain.KeyboardButton{
+		{Text: i18n.GetString(user.Language, i18n.ButtonArchivedConversations)},
+	}
+	if totalConversations > 0 {
+		managementButtons = append([]domain.KeyboardButton{
+			{Text: i18n.GetString(user.Language, i18n.ButtonManageConversations)},
+		}, managementButtons...)
+	}
+	buttons = append(buttons, managementButtons)
+
 	// Add back button
 	buttons = append(buttons, []domain.KeyboardButton{
 		{Text: i18n.GetString(user.Language, i18n.ButtonBackToMenu)},
@@ -157,9 +165,9 @@ func (s *UpdateService) showConversationList(ctx context.Context, user *domain.U
 	text := i18n.GetString(user.Language, i18n.ConversationListSelect)
 	if totalConversations == 0 {
 		text = i18n.GetString(user.Language, i18n.ConversationListEmpty)
-	} else if totalConversations > pageSize {
-		currentPage := (offset / pageSize) + 1
-		totalPages := ((totalConversations - 1) / pageSize) + 1
+	} else if totalConversations > conversationListPageSize {
+		currentPage := (offset / conversationListPageSize) + 1
+		totalPages := ((totalConversations - 1) / conversationListPageSize) + 1
 		text = fmt.Sprintf(i18n.GetString(user.Language, i18n.ConversationListPageInfo), currentPage, totalPages)
 	}
 
@@ -243,9 +251,8 @@ func (s *UpdateService) handleNextPage(ctx context.Context, user *domain.User) e
 		return fmt.Errorf("can't get conversations: %w", err)
 	}
 
-	const pageSize = 5
 	totalConversations := len(conversations)
-	newOffset := user.ConversationListOffset + pageSize
+	newOffset := user.ConversationListOffset + conversationListPageSize
 
 	// Don't go past the last page
 	if newOffset >= totalConversations {
@@ -262,8 +269,7 @@ func (s *UpdateService) handleNextPage(ctx context.Context, user *domain.User) e
 }
 
 func (s *UpdateService) handlePrevPage(ctx context.Context, user *domain.User) error {
-	const pageSize = 5
-	newOffset := user.ConversationListOffset - pageSize
+	newOffset := user.ConversationListOffset - conversationListPageSize
 
 	// Don't go before the first page
 	if newOffset < 0 {
@@ -279,6 +285,32 @@ func (s *UpdateService) handlePrevPage(ctx context.Context, user *domain.User) e
 	return s.showConversationList(ctx, user)
 }
 
+// conversationListPage returns the bounds of the conversation list page starting at the offset,
+// moving the offset to the last page when it is past the end of the list.
+func conversationListPage(totalConversations, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= totalConversations && totalConversations > 0 {
+		offset = ((totalConversations - 1) / conversationListPageSize) * conversationListPageSize
+	}
+
+	return offset, min(offset+conversationListPageSize, totalConversations)
+}
+
+// conversationButtonText returns the conversation list button of a conversation.
+// Pinned conversations are marked with a pin and forks as branches.
+func (s *UpdateService) conversationButtonText(conversation *domain.Conversation) string {
+	marker := "💬"
+	switch {
+	case conversation.PinnedAt != nil:
+		marker = "📌"
+	case conversation.ParentConversationID != nil:
+		marker = "🌿"
+	}
+	return fmt.Sprintf("%s %s, %s", marker, conversation.Name, s.formatDateTime(conversation.UpdatedAt))
+}
+
 // formatDateTime formats a timestamp in a unified dd.mm hh:mm format.
 func (s *UpdateService) formatDateTime(t time.Time) string {
 	return t.Format("02.01 15:04")
@@ -317,3 +349,36 @@ func (s *UpdateService) createNewConversationFromList(
 	// Process the message in the new conversation
 	return s.handleConversationMessage(ctx, user, update)
 }
+
+// isOpenConversationCallback reports whether callback data belongs to a button opening a conversation.
+func isOpenConversationCallback(data string) bool {
+	action, _, _ := strings.Cut(data, ":")
+	return action == callbackOpenConversation
+}
+
+// handleOpenConversationCallback makes the conversation from the callback data the current one.
+func (s *UpdateService) handleOpenConversationCallback(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+) error {
+	_, rawID, _ := strings.Cut(callbackQuery.Data, ":")
+	conversationID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		s.logger.WarnContext(ctx, "invalid open conversation callback data", slog.String("data", callbackQuery.Data))
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ConversationUnavailable))
+		return nil
+	}
+
+	conversation, err := s.storage.GetConversationByID(ctx, conversationID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("can't get conversation to open: %w", err)
+	}
+	if conversation == nil || conversation.UserID != user.ID || conversation.DeletedAt != nil {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ConversationUnavailable))
+		return nil
+	}
+
+	s.answerCallback(ctx, callbackQuery.ID, "")
+	return s.selectConversation(ctx, user, conversation.ID)
+}
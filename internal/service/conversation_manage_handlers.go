@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// Callback data of the conversation management buttons: <action>:<conversationID>.
+const (
+	callbackManageConversation    = "conv_manage"
+	callbackRenameConversation    = "conv_rename"
+	callbackPinConversation       = "conv_pin"
+	callbackUnpinConversation     = "conv_unpin"
+	callbackArchiveConversation   = "conv_archive"
+	callbackUnarchiveConversation = "conv_unarchive"
+	callbackDeleteConversation    = "conv_delete"
+	callbackRestoreConversation   = "conv_restore"
+)
+
+// isConversationActionCallback reports whether callback data belongs to the conversation management buttons.
+func isConversationActionCallback(data string) bool {
+	action, _, _ := strings.Cut(data, ":")
+	switch action {
+	case callbackManageConversation,
+		callbackRenameConversation,
+		callbackPinConversation,
+		callbackUnpinConversation,
+		callbackArchiveConversation,
+		callbackUnarchiveConversation,
+		callbackDeleteConversation,
+		callbackRestoreConversation:
+		return true
+	default:
+		return false
+	}
+}
+
+// showManageConversations offers the conversations of the current list page to manage.
+func (s *UpdateService) showManageConversations(ctx context.Context, user *domain.User) error {
+	conversations, err := s.storage.GetConversationsByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("can't get conversations: %w", err)
+	}
+	if len(conversations) == 0 {
+		return s.showConversationList(ctx, user)
+	}
+
+	start, end := conversationListPage(len(conversations), user.ConversationListOffset)
+	_, err = s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text:           i18n.GetString(user.Language, i18n.ManageChooseConversation),
+		InlineKeyboard: s.manageConversationsKeyboard(conversations[start:end]),
+	})
+	return err
+}
+
+// showArchivedConversations offers the archived conversations to open or unarchive.
+func (s *UpdateService) showArchivedConversations(ctx context.Context, user *domain.User) error {
+	conversations, err := s.storage.GetArchivedConversationsByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("can't get archived conversations: %w", err)
+	}
+	if len(conversations) == 0 {
+		_, err = s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ManageNoArchived))
+		return err
+	}
+
+	_, err = s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text:           i18n.GetString(user.Language, i18n.ManageArchivedList),
+		InlineKeyboard: s.manageConversationsKeyboard(conversations),
+	})
+	return err
+}
+
+func (s *UpdateService) manageConversationsKeyboard(conversations []*domain.Conversation) *domain.InlineKeyboard {
+	keyboard := &domain.InlineKeyboard{}
+	for _, conversation := range conversations {
+		keyboard.Buttons = append(keyboard.Buttons, []domain.InlineKeyboardButton{{
+			Text:         s.conversationButtonText(conversation),
+			CallbackData: conversationCallbackData(callbackManageConversation, conversation.ID),
+		}})
+	}
+	return keyboard
+}
+
+func conversationCallbackData(action string, conversationID int64) string {
+	return fmt.Sprintf("%s:%d", action, conversationID)
+}
+
+// handleConversationActionCallback applies a conversation management button to its conversation.
+func (s *UpdateService) handleConversationActionCallback(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+) error {
+	action, rawID, _ := strings.Cut(callbackQuery.Data, ":")
+	conversationID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		s.logger.WarnContext(ctx, "invalid conversation action callback data", slog.String("data", callbackQuery.Data))
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ConversationUnavailable))
+		return nil
+	}
+
+	conversation, err := s.storage.GetConversationByID(ctx, conversationID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("can't get conversation to manage: %w", err)
+	}
+	// Deleted conversations can only be brought back
+	if conversation == nil || conversation.UserID != user.ID ||
+		(conversation.DeletedAt != nil && action != callbackRestoreConversation) {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ConversationUnavailable))
+		return nil
+	}
+
+	switch action {
+	case callbackManageConversation:
+		s.answerCallback(ctx, callbackQuery.ID, "")
+		return s.showConversationActions(ctx, user, conversation)
+	case callbackRenameConversation:
+		s.answerCallback(ctx, callbackQuery.ID, "")
+		return s.transitionToConversationRename(ctx, user, conversation)
+	case callbackPinConversation, callbackUnpinConversation:
+		pinned := action == callbackPinConversation
+		if err = s.storage.UpdateConversationPinned(ctx, conversation.ID, pinned); err != nil {
+			return fmt.Errorf("can't pin conversation: %w", err)
+		}
+		resultKey := i18n.ManageUnpinned
+		if pinned {
+			resultKey = i18n.ManagePinned
+		}
+		s.answerCallback(ctx, callbackQuery.ID, fmt.Sprintf(i18n.GetString(user.Language, resultKey), conversation.Name))
+	case callbackArchiveConversation, callbackUnarchiveConversation:
+		archived := action == callbackArchiveConversation
+		if err = s.storage.UpdateConversationArchived(ctx, conversation.ID, archived); err != nil {
+			return fmt.Errorf("can't archive conversation: %w", err)
+		}
+		resultKey := i18n.ManageUnarchived
+		if archived {
+			resultKey = i18n.ManageArchived
+		}
+		s.answerCallback(ctx, callbackQuery.ID, fmt.Sprintf(i18n.GetString(user.Language, resultKey), conversation.Name))
+	case callbackDeleteConversation:
+		s.answerCallback(ctx, callbackQuery.ID, "")
+		return s.deleteConversation(ctx, user, conversation)
+	case callbackRestoreConversation:
+		if err = s.storage.RestoreConversation(ctx, conversation.ID); err != nil {
+			return fmt.Errorf("can't restore conversation: %w", err)
+		}
+		s.answerCallback(ctx, callbackQuery.ID,
+			fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageRestored), conversation.Name))
+	}
+
+	return s.refreshConversationList(ctx, user)
+}
+
+// showConversationActions sends the management buttons of a conversation.
+func (s *UpdateService) showConversationActions(
+	ctx context.Context,
+	user *domain.User,
+	conversation *domain.Conversation,
+) error {
+	pinButton := domain.InlineKeyboardButton{
+		Text:         i18n.GetString(user.Language, i18n.ButtonPin),
+		CallbackData: conversationCallbackData(callbackPinConversation, conversation.ID),
+	}
+	if conversation.PinnedAt != nil {
+		pinButton = domain.InlineKeyboardButton{
+			Text:         i18n.GetString(user.Language, i18n.ButtonUnpin),
+			CallbackData: conversationCallbackData(callbackUnpinConversation, conversation.ID),
+		}
+	}
+
+	archiveButton := domain.InlineKeyboardButton{
+		Text:         i18n.GetString(user.Language, i18n.ButtonArchive),
+		CallbackData: conversationCallbackData(callbackArchiveConversation, conversation.ID),
+	}
+	if conversation.ArchivedAt != nil {
+		archiveButton = domain.InlineKeyboardButton{
+			Text:         i18n.GetString(user.Language, i18n.ButtonUnarchive),
+			CallbackData: conversationCallbackData(callbackUnarchiveConversation, conversation.ID),
+		}
+	}
+
+	_, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text: fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageConversation), conversation.Name),
+		InlineKeyboard: &domain.InlineKeyboard{Buttons: [][]domain.InlineKeyboardButton{
+			{
+				{
+					Text:         i18n.GetString(user.Language, i18n.ButtonOpen),
+					CallbackData: conversationCallbackData(callbackOpenConversation, conversation.ID),
+				},
+				{
+					Text:         i18n.GetString(user.Language, i18n.ButtonRename),
+					CallbackData: conversationCallbackData(callbackRenameConversation, conversation.ID),
+				},
+			},
+			{pinButton, archiveButton},
+			{
+				{
+					Text:         i18n.GetString(user.Language, i18n.ButtonDelete),
+					CallbackData: conversationCallbackData(callbackDeleteConversation, conversation.ID),
+				},
+			},
+		}},
+	})
+	return err
+}
+
+// deleteConversation hides the conversation and offers to undo the deletion.
+func (s *UpdateService) deleteConversation(
+	ctx context.Context,
+	user *domain.User,
+	conversation *domain.Conversation,
+) error {
+	if err := s.storage.DeleteConversation(ctx, conversation.ID); err != nil {
+		return fmt.Errorf("can't delete conversation: %w", err)
+	}
+
+	if user.CurrentConversationID != nil && *user.CurrentConversationID == conversation.ID {
+		user.CurrentConversationID = nil
+		if err := s.storage.UpdateUserCurrentConversationID(ctx, user.ID, nil); err != nil {
+			return fmt.Errorf("can't update user conversation: %w", err)
+		}
+	}
+
+	_, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text: fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageDeleted), conversation.Name),
+		InlineKeyboard: &domain.InlineKeyboard{Buttons: [][]domain.InlineKeyboardButton{{{
+			Text:         i18n.GetString(user.Language, i18n.ButtonUndo),
+			CallbackData: conversationCallbackData(callbackRestoreConversation, conversation.ID),
+		}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("can't send deletion notice: %w", err)
+	}
+
+	// The deleted conversation can't be continued, so the user goes back to the list
+	if user.CurrentStep == domain.UserStateConversation && user.CurrentConversationID == nil {
+		return s.transitionToConversationList(ctx, user)
+	}
+	return s.refreshConversationList(ctx, user)
+}
+
+// refreshConversationList shows the conversation list again when the user is looking at it,
+// so that its keyboard reflects a change made with an inline button.
+func (s *UpdateService) refreshConversationList(ctx context.Context, user *domain.User) error {
+	if user.CurrentStep != domain.UserStateConversationList {
+		return nil
+	}
+	return s.showConversationList(ctx, user)
+}
+
+// transitionToConversationRename asks for a new name of the conversation, which becomes the current one.
+func (s *UpdateService) transitionToConversationRename(
+	ctx context.Context,
+	user *domain.User,
+	conversation *domain.Conversation,
+) error {
+	user.CurrentConversationID = &conversation.ID
+	if err := s.storage.UpdateUserCurrentConversationID(ctx, user.ID, &conversation.ID); err != nil {
+		return fmt.Errorf("can't update user conversation: %w", err)
+	}
+
+	renameState := domain.UserStateConversationRename
+	user.CurrentStep = renameState
+	if err := s.storage.UpdateUserCurrentStep(ctx, user.ID, renameState); err != nil {
+		return fmt.Errorf("can't update user state: %w", err)
+	}
+
+	return s.sendRenamePrompt(ctx, user, conversation)
+}
+
+func (s *UpdateService) sendRenamePrompt(ctx context.Context, user *domain.User, conversation *domain.Conversation) error {
+	_, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text: fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageRenamePrompt), conversation.Name),
+		ReplyKeyboard: &domain.ReplyKeyboard{
+			Buttons: [][]domain.KeyboardButton{
+				{{Text: i18n.GetString(user.Language, i18n.ButtonBackToMenu)}},
+			},
+			Resize:  true,
+			OneTime: false,
+		},
+	})
+	return err
+}
+
+// HandleConversationRenameState renames the current conversation to the name the user sent.
+func (s *UpdateService) HandleConversationRenameState(
+	ctx context.Context,
+	user *domain.User,
+	update domain.Update,
+) error {
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonBackToMenu) {
+		return s.transitionToConversationList(ctx, user)
+	}
+
+	conversation, err := s.getCurrentConversation(ctx, user)
+	if err != nil {
+		return err
+	}
+	if conversation == nil || conversation.DeletedAt != nil {
+		return s.transitionToConversationList(ctx, user)
+	}
+
+	if update.MessageText == "" {
+		return s.sendRenamePrompt(ctx, user, conversation)
+	}
+
+	// Names are shown on a single line of the list, so line breaks and repeated spaces are dropped
+	name := strings.Join(strings.Fields(update.MessageText), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxConversationNameLength {
+		_, err = s.sender.SendMessage(ctx, user.ExternalID,
+			fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageNameInvalid), maxConversationNameLength))
+		return err
+	}
+
+	if err = s.storage.UpdateConversationName(ctx, conversation.ID, name); err != nil {
+		return fmt.Errorf("can't rename conversation: %w", err)
+	}
+
+	_, err = s.sender.SendMessage(ctx, user.ExternalID,
+		fmt.Sprintf(i18n.GetString(user.Language, i18n.ManageRenamed), name))
+	if err != nil {
+		return err
+	}
+
+	return s.transitionToConversationList(ctx, user)
+}
@@ -0,0 +1,237 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleCallbackQuery_ManageConversation(t *testing.T) {
+	conversationID := int64(7)
+	updatedAt := time.Date(2025, 6, 21, 10, 30, 0, 0, time.UTC)
+	conversation := &domain.Conversation{
+		ID:        conversationID,
+		Name:      "Trip",
+		UserID:    1,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		user       domain.User
+		setupMocks func(*mocks.MockStorage, *mocks.MockSender)
+	}{
+		{
+			name: "pinning refreshes the conversation list",
+			data: "conv_pin:7",
+			user: domain.User{ID: 1, ExternalID: "12345", Language: "en", CurrentStep: domain.UserStateConversationList},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().UpdateConversationPinned(gomock.Any(), conversationID, true).Return(nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", "📌 «Trip» is pinned to the top of the list.").
+					Return(nil)
+
+				pinned := *conversation
+				pinned.PinnedAt = pointer.To(updatedAt)
+				mockStorage.EXPECT().
+					GetConversationsByUserID(gomock.Any(), int64(1)).
+					Return([]*domain.Conversation{&pinned}, nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						require.NotNil(t, content.ReplyKeyboard)
+						buttons := content.ReplyKeyboard.Buttons
+						require.Len(t, buttons, 4)
+						assert.True(t, strings.HasPrefix(buttons[1][0].Text, "📌 Trip"))
+						assert.Equal(t, i18n.GetString("en", i18n.ButtonManageConversations), buttons[2][0].Text)
+						assert.Equal(t, i18n.GetString("en", i18n.ButtonArchivedConversations), buttons[2][1].Text)
+						return "msg1", nil
+					})
+			},
+		},
+		{
+			name: "deleting the current conversation offers undo",
+			data: "conv_delete:7",
+			user: domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateMenu,
+				CurrentConversationID: &conversationID,
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+				mockStorage.EXPECT().DeleteConversation(gomock.Any(), conversationID).Return(nil)
+				mockStorage.EXPECT().UpdateUserCurrentConversationID(gomock.Any(), int64(1), nil).Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						assert.Equal(t, "🗑 «Trip» is deleted.", content.Text)
+						require.NotNil(t, content.InlineKeyboard)
+						assert.Equal(t, "conv_restore:7", content.InlineKeyboard.Buttons[0][0].CallbackData)
+						return "msg1", nil
+					})
+			},
+		},
+		{
+			name: "deleted conversation can be restored",
+			data: "conv_restore:7",
+			user: domain.User{ID: 1, ExternalID: "12345", Language: "en", CurrentStep: domain.UserStateMenu},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				deleted := *conversation
+				deleted.DeletedAt = pointer.To(updatedAt)
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(&deleted, nil)
+				mockStorage.EXPECT().RestoreConversation(gomock.Any(), conversationID).Return(nil)
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "↩️ «Trip» is restored.").Return(nil)
+			},
+		},
+		{
+			name: "deleted conversation can't be archived",
+			data: "conv_archive:7",
+			user: domain.User{ID: 1, ExternalID: "12345", Language: "en", CurrentStep: domain.UserStateMenu},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				deleted := *conversation
+				deleted.DeletedAt = pointer.To(updatedAt)
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(&deleted, nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ConversationUnavailable)).
+					Return(nil)
+			},
+		},
+		{
+			name: "conversation of another user is rejected",
+			data: "conv_delete:7",
+			user: domain.User{ID: 2, ExternalID: "12345", Language: "en", CurrentStep: domain.UserStateMenu},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ConversationUnavailable)).
+					Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			user := tt.user
+			mockStorage.EXPECT().GetUserByExternalUserID(gomock.Any(), "12345").Return(&user, nil)
+			tt.setupMocks(mockStorage, mockSender)
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           tt.data,
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateService_HandleUpdate_RenameConversation(t *testing.T) {
+	conversationID := int64(7)
+	conversation := &domain.Conversation{ID: conversationID, Name: "Trip", UserID: 1}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func(*mocks.MockStorage, *mocks.MockSender)
+	}{
+		{
+			name: "name is stored on a single line",
+			text: "  Trip   to\nRome ",
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().UpdateConversationName(gomock.Any(), conversationID, "Trip to Rome").Return(nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", "✏️ The conversation is renamed to «Trip to Rome».").
+					Return("msg1", nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateConversationList).
+					Return(nil)
+				mockStorage.EXPECT().UpdateUserConversationListOffset(gomock.Any(), int64(1), 0).Return(nil)
+				mockStorage.EXPECT().GetConversationsByUserID(gomock.Any(), int64(1)).Return(nil, nil)
+				mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("msg2", nil)
+			},
+		},
+		{
+			name: "too long name is rejected",
+			text: strings.Repeat("a", 51),
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", fmt.Sprintf(i18n.GetString("en", i18n.ManageNameInvalid), 50)).
+					Return("msg1", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					CurrentStep:           domain.UserStateConversationRename,
+					CurrentConversationID: &conversationID,
+				}, nil)
+			tt.setupMocks(mockStorage, mockSender)
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    tt.text,
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
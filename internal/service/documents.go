@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/extraction"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const (
+	// documentTextMimeType is the content type the extracted text of a document is stored with.
+	documentTextMimeType = "text/plain; charset=utf-8"
+	// jsonMimeType is the content type of JSON documents, which Telegram doesn't always report.
+	jsonMimeType = "application/json"
+)
+
+// SetDocumentExtraction sets the service that reads the text of documents, which the model gets instead
+// of the files. Without it documents are left out of messages.
+func (s *UpdateService) SetDocumentExtraction(extraction extraction.Extraction) {
+	s.extraction = extraction
+}
+
+// extractDocuments reads the text of the documents among the files. Documents that can't be read are left out
+// and the user is told so, as well as about documents that were truncated to the size limit.
+func (s *UpdateService) extractDocuments(ctx context.Context, user *domain.User, files []domain.File) []domain.File {
+	extracted := make([]domain.File, 0, len(files))
+	for _, file := range files {
+		if file.IsPDF() || !file.IsDocument() {
+			extracted = append(extracted, file)
+			continue
+		}
+
+		var document *extraction.Document
+		err := extraction.ErrUnsupported
+		if s.extraction != nil {
+			document, err = s.extraction.Extract(ctx, file)
+		}
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to extract document text",
+				slog.String("error", err.Error()),
+				slog.String("file_name", file.FileName),
+				slog.String("mime_type", file.MimeType))
+			s.sendDocumentNotice(ctx, user, fmt.Sprintf(
+				i18n.GetString(user.Language, i18n.DocumentUnreadable), documentName(file),
+			))
+			continue
+		}
+
+		file.Text = document.Text
+		if document.Truncated {
+			read := utf8.RuneCountInString(document.Text)
+			// The model is told too, so it doesn't take the beginning for the whole document
+			file.Text += fmt.Sprintf("\n\n[The document was truncated here: %d of %d characters were read]",
+				read, document.Characters)
+			s.sendDocumentNotice(ctx, user, fmt.Sprintf(
+				i18n.GetString(user.Language, i18n.DocumentTruncated), documentName(file), read, document.Characters,
+			))
+		}
+		extracted = append(extracted, file)
+	}
+	return extracted
+}
+
+// withJSONDocument adds a JSON file that isn't a data export to the files of the update, so its text is read
+// like that of any other document.
+func withJSONDocument(update domain.Update) domain.Update {
+	if len(update.DocumentData) == 0 {
+		return update
+	}
+
+	update.Files = append(slices.Clone(update.Files), domain.File{
+		Data:     update.DocumentData,
+		MimeType: jsonMimeType,
+		FileName: update.DocumentFileName,
+	})
+	update.DocumentData = nil
+	update.DocumentFileName = ""
+	return update
+}
+
+func (s *UpdateService) sendDocumentNotice(ctx context.Context, user *domain.User, notice string) {
+	if _, err := s.sender.SendMessage(ctx, user.ExternalID, notice); err != nil {
+		s.logger.WarnContext(ctx, "failed to send document notice", slog.String("error", err.Error()))
+	}
+}
+
+// uploadDocument uploads a document and its extracted text. It returns their objects, empty when an upload failed.
+func (s *UpdateService) uploadDocument(ctx context.Context, file domain.File) (string, string) {
+	if s.fileStorage == nil {
+		return "", ""
+	}
+
+	mimeType := file.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	documentObject, err := s.fileStorage.Upload(ctx, file.Data, mimeType)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to upload document", slog.String("error", err.Error()))
+		documentObject = ""
+	}
+
+	textObject, err := s.fileStorage.Upload(ctx, []byte(file.Text), documentTextMimeType)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to upload document text", slog.String("error", err.Error()))
+		textObject = ""
+	}
+
+	return documentObject, textObject
+}
+
+// storedDocumentPart restores the extracted text of a document of an earlier message.
+func (s *UpdateService) storedDocumentPart(ctx context.Context, file domain.File) (completion.Part, bool) {
+	if file.TextS3Name == "" {
+		return completion.Part{}, false
+	}
+
+	text, err := s.fileStorage.Download(ctx, file.TextS3Name)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to download document text", slog.String("error", err.Error()))
+		return completion.Part{}, false
+	}
+
+	file.Text = string(text)
+	return documentPart(file), true
+}
+
+// documentPart returns the extracted text of a document as a message part, under the name of the document.
+func documentPart(file domain.File) completion.Part {
+	return completion.Part{
+		Type:     completion.PartTypeText,
+		Text:     fmt.Sprintf("Document %s:\n\n%s", documentName(file), file.Text),
+		MimeType: file.MimeType,
+		FileName: file.FileName,
+	}
+}
+
+// documentName returns how a document is called in notices and prompts.
+func documentName(file domain.File) string {
+	if file.FileName == "" {
+		return "document"
+	}
+	return fmt.Sprintf("%q", file.FileName)
+}
@@ -0,0 +1,274 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/extraction"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleUpdate_Documents(t *testing.T) {
+	conversationID := int64(7)
+	errStop := errors.New("stop after saving the user message")
+	docx := domain.File{
+		Data:     []byte("PK"),
+		MimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		FileName: "report.docx",
+	}
+
+	tests := []struct {
+		name     string
+		caption  string
+		document *extraction.Document
+		// extractErr fails the extraction of the document
+		extractErr error
+		// expectedText is the stored extracted text, empty when the document is left out
+		expectedText    string
+		expectedNotices []string
+	}{
+		{
+			name:         "text of the document is stored with it",
+			caption:      "Summarize it",
+			document:     &extraction.Document{Text: "Quarterly results", Characters: 17},
+			expectedText: "Quarterly results",
+		},
+		{
+			name:         "truncated document is reported",
+			document:     &extraction.Document{Text: "Quarterly", Characters: 17, Truncated: true},
+			expectedText: "Quarterly\n\n[The document was truncated here: 9 of 17 characters were read]",
+			expectedNotices: []string{
+				fmt.Sprintf(i18n.GetString("en", i18n.DocumentTruncated), `"report.docx"`, 9, 17),
+			},
+		},
+		{
+			name:       "unreadable document is left out of the message",
+			caption:    "Summarize it",
+			extractErr: extraction.ErrUnsupported,
+			expectedNotices: []string{
+				fmt.Sprintf(i18n.GetString("en", i18n.DocumentUnreadable), `"report.docx"`),
+			},
+		},
+		{
+			name:       "message of only an unreadable document isn't answered",
+			extractErr: extraction.ErrTooLarge,
+			expectedNotices: []string{
+				fmt.Sprintf(i18n.GetString("en", i18n.DocumentUnreadable), `"report.docx"`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			mockExtraction := mocks.NewMockExtraction(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetDocumentExtraction(mockExtraction)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					CurrentStep:           domain.UserStateConversation,
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+
+			// Failing to batch the message makes it answered right away
+			mockQueue.EXPECT().IsProcessing(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+
+			mockExtraction.EXPECT().Extract(gomock.Any(), docx).Return(tt.document, tt.extractErr)
+			for _, notice := range tt.expectedNotices {
+				mockSender.EXPECT().SendMessage(gomock.Any(), "12345", notice).Return("msg1", nil)
+			}
+
+			if tt.caption != "" || tt.extractErr == nil {
+				var expectedFiles []domain.File
+				if tt.extractErr == nil {
+					mockFileStorage.EXPECT().Upload(gomock.Any(), docx.Data, docx.MimeType).Return("document", nil)
+					mockFileStorage.EXPECT().
+						Upload(gomock.Any(), []byte(tt.expectedText), "text/plain; charset=utf-8").
+						Return("document-text", nil)
+					expectedFiles = []domain.File{{
+						MimeType:   docx.MimeType,
+						FileName:   docx.FileName,
+						S3Name:     "document",
+						TextS3Name: "document-text",
+					}}
+				}
+
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(nil, nil)
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						assert.Equal(t, tt.caption, message.MessageType.Text)
+						assert.Equal(t, expectedFiles, message.MessageType.Files)
+						return nil, errStop
+					})
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ErrorResponseGeneration)).
+					Return("msg2", nil)
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			}
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				MessageText:       tt.caption,
+				Files:             []domain.File{docx},
+				ExternalMessageID: 100,
+			})
+
+			if tt.caption == "" && tt.extractErr != nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errStop)
+		})
+	}
+}
+
+func TestUpdateService_HandleUpdate_JSONDocument(t *testing.T) {
+	conversationID := int64(7)
+	errStop := errors.New("stop after saving the user message")
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "object is read as a document",
+			data: `{"name": "settings"}`,
+		},
+		{
+			name: "array of something else than conversations is read as a document",
+			data: `[{"title": "Cats"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			mockExtraction := mocks.NewMockExtraction(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetDocumentExtraction(mockExtraction)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					CurrentStep:           domain.UserStateConversation,
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+
+			// Failing to batch the message makes it answered right away
+			mockQueue.EXPECT().IsProcessing(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+
+			file := domain.File{Data: []byte(tt.data), MimeType: "application/json", FileName: "settings.json"}
+			mockExtraction.EXPECT().
+				Extract(gomock.Any(), file).
+				Return(&extraction.Document{Text: tt.data, Characters: len(tt.data)}, nil)
+			mockFileStorage.EXPECT().Upload(gomock.Any(), file.Data, file.MimeType).Return("document", nil)
+			mockFileStorage.EXPECT().
+				Upload(gomock.Any(), []byte(tt.data), "text/plain; charset=utf-8").
+				Return("document-text", nil)
+
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(nil, nil)
+			mockStorage.EXPECT().
+				CreateMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+					assert.Equal(t, "What is set here?", message.MessageType.Text)
+					assert.Equal(t, []domain.File{{
+						MimeType:   file.MimeType,
+						FileName:   file.FileName,
+						S3Name:     "document",
+						TextS3Name: "document-text",
+					}}, message.MessageType.Files)
+					return nil, errStop
+				})
+			mockSender.EXPECT().
+				SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ErrorResponseGeneration)).
+				Return("msg1", nil)
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				MessageText:       "What is set here?",
+				DocumentData:      []byte(tt.data),
+				DocumentFileName:  "settings.json",
+				ExternalMessageID: 100,
+			})
+			require.ErrorIs(t, err, errStop)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+)
+
+// DefaultHistoryFileLimit is how many files of earlier turns are attached to a request again by default.
+const DefaultHistoryFileLimit = 5
+
+// SetHistoryFileLimit sets how many images, PDFs and documents of earlier turns are attached to a request again.
+// With zero earlier turns are sent as text only.
+func (s *UpdateService) SetHistoryFileLimit(limit int) {
+	s.historyFileLimit = max(limit, 0)
+}
+
+// earlierFiles restores the uploaded files of the user messages before the latest one, newest messages first,
+// until the history file limit is reached. The links are generated anew, the ones of the original request
+// have expired by now.
+func (s *UpdateService) earlierFiles(ctx context.Context, messages []*domain.Message) map[int64][]completion.Part {
+	if s.fileStorage == nil || s.historyFileLimit <= 0 {
+		return nil
+	}
+
+	var files map[int64][]completion.Part
+	budget := s.historyFileLimit
+	// The latest message is the one being answered, its files are attached by the caller
+	for i := len(messages) - 2; i >= 0 && budget > 0; i-- {
+		msg := messages[i]
+		if msg.SentBy != domain.MessageSenderUser || len(msg.MessageType.Files) == 0 {
+			continue
+		}
+
+		parts := s.uploadedFiles(ctx, msg, budget)
+		if len(parts) == 0 {
+			continue
+		}
+		if files == nil {
+			files = make(map[int64][]completion.Part)
+		}
+		files[msg.ID] = parts
+		budget -= len(parts)
+	}
+
+	return files
+}
+
+// uploadedFiles returns up to limit uploaded images and PDFs of a message as links, and documents as their text,
+// in order.
+func (s *UpdateService) uploadedFiles(ctx context.Context, msg *domain.Message, limit int) []completion.Part {
+	var parts []completion.Part
+	for _, file := range msg.MessageType.Files {
+		if len(parts) == limit {
+			break
+		}
+		if !file.IsPDF() && file.IsDocument() {
+			if part, ok := s.storedDocumentPart(ctx, file); ok {
+				parts = append(parts, part)
+			}
+			continue
+		}
+		if file.S3Name == "" {
+			continue
+		}
+
+		part := completion.Part{MimeType: file.MimeType, FileName: file.FileName}
+		switch {
+		case file.IsImage():
+			part.Type = completion.PartTypeImage
+		case file.IsPDF():
+			part.Type = completion.PartTypeFile
+		default:
+			continue
+		}
+
+		fileURL, err := s.fileStorage.GetPreSignedURL(ctx, file.S3Name, time.Hour)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to generate pre-signed URL", slog.String("error", err.Error()))
+			continue
+		}
+		part.URL = fileURL
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// attachedEarlierFiles returns the earlier files the model can take besides the attachments of the request.
+// When there are more than the model takes, the files of the oldest messages are left out.
+func attachedEarlierFiles(
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+) map[int64][]completion.Part {
+	if len(llmContext.earlierFiles) == 0 {
+		return nil
+	}
+
+	images := countParts(attachments, completion.PartTypeImage)
+	pdfs := countParts(attachments, completion.PartTypeFile)
+	attached := make(map[int64][]completion.Part)
+	for i := len(llmContext.messages) - 1; i >= 0; i-- {
+		msg := llmContext.messages[i]
+		for _, part := range llmContext.earlierFiles[msg.ID] {
+			switch part.Type {
+			case completion.PartTypeImage:
+				if !model.TakesImages(images + 1) {
+					continue
+				}
+				images++
+			case completion.PartTypeFile:
+				if !model.TakesPDFs(pdfs + 1) {
+					continue
+				}
+				pdfs++
+			case completion.PartTypeText:
+				// Any model reads the text of documents
+			case completion.PartTypeAudio:
+				continue
+			}
+			attached[msg.ID] = append(attached[msg.ID], part)
+		}
+	}
+
+	return attached
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleCallbackQuery_RegenerateEarlierFiles(t *testing.T) {
+	conversationID := int64(7)
+	history := []*domain.Message{
+		{
+			ID:     39,
+			UserID: 1,
+			MessageType: domain.MessageType{
+				Text:  "Here is the report",
+				Files: []domain.File{{S3Name: "report", MimeType: "application/pdf", FileName: "report.pdf"}},
+			},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             40,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Got it"},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "What is on page two?"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             42,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "First answer"},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+		},
+	}
+
+	tests := []struct {
+		name             string
+		historyFileLimit int
+		// expectedParts are the parts of the first message in the request
+		expectedParts []completion.Part
+	}{
+		{
+			name:             "earlier PDF is attached again by a fresh link",
+			historyFileLimit: service.DefaultHistoryFileLimit,
+			expectedParts: []completion.Part{
+				{Type: completion.PartTypeText, Text: "Here is the report"},
+				{
+					Type:     completion.PartTypeFile,
+					URL:      "https://files.example.com/report",
+					MimeType: "application/pdf",
+					FileName: "report.pdf",
+				},
+			},
+		},
+		{
+			name:             "earlier turns are text only without a limit",
+			historyFileLimit: 0,
+			expectedParts:    []completion.Part{{Type: completion.PartTypeText, Text: "Here is the report"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetHistoryFileLimit(tt.historyFileLimit)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+			mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(history[3], nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalance(gomock.Any(), int64(1)).
+				Return(&domain.TokenBalance{RegularBalance: 100}, nil)
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(history, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(int64(100), nil).
+				AnyTimes()
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockSender.EXPECT().
+				AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+			if tt.historyFileLimit > 0 {
+				mockFileStorage.EXPECT().
+					GetPreSignedURL(gomock.Any(), "report", gomock.Any()).
+					Return("https://files.example.com/report", nil)
+			}
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+			mockCompletion.EXPECT().
+				CompleteStream(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+					require.Len(t, req.Messages, 3)
+					assert.Equal(t, tt.expectedParts, req.Messages[0].Parts)
+					assert.Len(t, req.Messages[2].Parts, 1)
+
+					tokens := make(chan completion.StreamToken, 1)
+					tokens <- completion.StreamToken{Content: "Second answer"}
+					close(tokens)
+					return tokens, nil
+				})
+			mockStorage.EXPECT().
+				GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+				Return([]int32{100}, nil)
+			mockSender.EXPECT().
+				UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First answer", "Second answer").
+				Return([]string{"100"}, nil)
+			mockStorage.EXPECT().
+				UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second answer"}).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetMessageVersions(gomock.Any(), int64(42)).
+				Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First answer"}}, nil)
+			mockStorage.EXPECT().CreateMessageVersion(gomock.Any(), gomock.Any()).Return(&domain.MessageVersion{ID: 2}, nil)
+			mockSender.EXPECT().EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).Return(nil)
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					return transaction, nil
+				}).
+				AnyTimes()
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           "regen:42",
+			})
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// rerunFromEditedMessage continues the conversation from an edited user message: the answer is regenerated
+// in place, then the stored prompt gets the new text and turns after its answer are dropped.
+func (s *UpdateService) rerunFromEditedMessage(
+	ctx context.Context,
+	user *domain.User,
+	message *domain.Message,
+	text string,
+) error {
+	model := s.currentModel(ctx, user)
+	if model == nil {
+		return fmt.Errorf("model not found: %s", user.SelectedModel)
+	}
+
+	activeSubscription, err := s.storage.GetActiveSubscriptionByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to check subscription status: %w", err)
+	}
+	webSearchEnabled := model.WebSearch && user.WebSearchEnabled && activeSubscription != nil
+
+	release, locked := s.lockAnswer(ctx, user)
+	if !locked {
+		_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.EditBusy))
+		return sendErr
+	}
+	defer release()
+
+	history, err := s.storage.GetMessagesByConversationID(ctx, *message.ConversationID)
+	if err != nil {
+		return fmt.Errorf("can't get messages: %w", err)
+	}
+
+	index := slices.IndexFunc(history, func(m *domain.Message) bool { return m.ID == message.ID })
+	if index < 0 {
+		return nil
+	}
+	message.MessageType.Text = text
+	history[index] = message
+	prompt := history[:index+1]
+
+	var answer *domain.Message
+	if index+1 < len(history) && history[index+1].SentBy == domain.MessageSenderBot {
+		answer = history[index+1]
+	}
+
+	if answer != nil {
+		// Photos can't be edited into another answer, nor can images be streamed into the text of one
+		if model.ImageOutput || len(answer.MessageType.Files) > 0 {
+			_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.EditImagesAnswer))
+			return sendErr
+		}
+
+		if notice := unsupportedFilesNotice(model, user.Language, message.MessageType.Files); notice != "" {
+			_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, notice)
+			return sendErr
+		}
+	}
+
+	// The new answer is reserved first, the edit isn't applied when it can't be paid for
+	var request *rerunRequest
+	if answer != nil {
+		request, err = s.prepareRerun(ctx, user, message.ConversationID, prompt, model, webSearchEnabled)
+		if errors.Is(err, errInsufficientBalance) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer s.releaseAnswer(ctx, request.bill)
+	}
+
+	if answer == nil {
+		// The prompt was never answered, so there is nothing to regenerate
+		return s.applyEdit(ctx, user, message, nil, history)
+	}
+
+	// The answer is generated from the edited prompt before the edit is stored, so a failed generation
+	// leaves the conversation as it was
+	newAnswer, err := s.rerunAnswer(ctx, user, answer, request, model, webSearchEnabled)
+	if errors.Is(err, errGenerationStopped) {
+		return nil
+	}
+	if err != nil {
+		if _, sendErr := s.sender.SendMessage(ctx, user.ExternalID,
+			i18n.GetString(user.Language, i18n.EditFailed)); sendErr != nil {
+			s.logger.WarnContext(ctx, "failed to send edit failed notice", slog.String("error", sendErr.Error()))
+		}
+		return err
+	}
+
+	if err = s.applyEdit(ctx, user, message, answer, history); err != nil {
+		return err
+	}
+
+	// Earlier versions answered the old prompt, so the pager starts over
+	if err = s.storage.DeleteMessageVersions(ctx, answer.ID); err != nil {
+		s.logger.WarnContext(ctx, "failed to delete message versions", slog.String("error", err.Error()))
+	}
+	s.saveAnswerVersion(ctx, user, answer.ID, newAnswer.model.ID, newAnswer)
+
+	return nil
+}
+
+// applyEdit stores the edited message and drops the turns that followed it.
+func (s *UpdateService) applyEdit(
+	ctx context.Context,
+	user *domain.User,
+	message *domain.Message,
+	answer *domain.Message,
+	history []*domain.Message,
+) error {
+	if err := s.storage.UpdateMessageType(ctx, message.ID, message.MessageType); err != nil {
+		return fmt.Errorf("can't update edited message: %w", err)
+	}
+
+	return s.dropTurnsAfter(ctx, user, message, answer, history)
+}
+
+// dropTurnsAfter removes the turns that followed the edited message and its answer from the conversation
+// and forgets a summary that already covers the edited message.
+func (s *UpdateService) dropTurnsAfter(
+	ctx context.Context,
+	user *domain.User,
+	message *domain.Message,
+	answer *domain.Message,
+	history []*domain.Message,
+) error {
+	conversationID := *message.ConversationID
+
+	lastKeptID := message.ID
+	if answer != nil {
+		lastKeptID = answer.ID
+	}
+
+	dropped := 0
+	for _, historyMessage := range history {
+		if historyMessage.ID > lastKeptID {
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		if err := s.storage.DeleteMessagesAfter(ctx, conversationID, lastKeptID); err != nil {
+			return fmt.Errorf("can't drop messages after edited message: %w", err)
+		}
+	}
+
+	summary, err := s.storage.GetConversationSummary(ctx, conversationID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.logger.WarnContext(ctx, "failed to get conversation summary", slog.String("error", err.Error()))
+	}
+	if summary != nil && summary.LastMessageID >= message.ID {
+		if err = s.storage.DeleteConversationSummary(ctx, conversationID); err != nil {
+			return fmt.Errorf("can't reset conversation summary: %w", err)
+		}
+	}
+
+	if dropped == 0 {
+		return nil
+	}
+
+	notice := fmt.Sprintf(i18n.GetString(user.Language, i18n.EditHistoryTrimmed), dropped)
+	if _, err = s.sender.SendMessage(ctx, user.ExternalID, notice); err != nil {
+		s.logger.WarnContext(ctx, "failed to send history trimmed notice", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,366 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleEditedMessage(t *testing.T) {
+	conversationID := int64(7)
+	user := &domain.User{
+		ID:                    1,
+		ExternalID:            "12345",
+		Language:              "en",
+		SelectedModel:         "google/gemini-2.5-flash",
+		CurrentConversationID: &conversationID,
+	}
+	newUserMessage := func() *domain.Message {
+		return &domain.Message{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Tell me a joke"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		}
+	}
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "A joke"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+	laterMessages := []*domain.Message{
+		{
+			ID:             43,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Another one"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             44,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Another joke"},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		edit           domain.EditedMessage
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockCompletion, *mocks.MockQueue)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "edit regenerates the answer and drops later turns",
+			edit: domain.EditedMessage{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				ExternalMessageID: 99,
+				MessageText:       "Tell me a pun",
+			},
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(99)).
+					Return(newUserMessage(), nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(append([]*domain.Message{newUserMessage(), botMessage}, laterMessages...), nil)
+				mockStorage.EXPECT().
+					GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+					Return(int64(100), nil)
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(nil, storage.ErrNotFound).
+					Times(2)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, text string) (string, error) {
+						assert.Contains(t, text, "2 later messages")
+						return "msg1", nil
+					})
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+				mockQueue.EXPECT().
+					SubscribeCancel(gomock.Any(), "12345").
+					Return(make(chan struct{}), func() {}, nil)
+				mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+				mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+				generate := mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(func(_ context.Context, req completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+						require.Len(t, req.Messages, 1)
+						assert.Equal(t, "Tell me a pun", req.Messages[0].Parts[0].Text)
+
+						tokens := make(chan completion.StreamToken, 1)
+						tokens <- completion.StreamToken{Content: "A pun"}
+						close(tokens)
+						return tokens, nil
+					})
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100"}, "A joke", "A pun").
+					Return([]string{"100"}, nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "A pun"}).
+					Return(nil)
+				// The edit is stored once its answer is generated
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(41), domain.MessageType{Text: "Tell me a pun"}).
+					Return(nil).
+					After(generate)
+				mockStorage.EXPECT().DeleteMessagesAfter(gomock.Any(), conversationID, int64(42)).Return(nil)
+				mockStorage.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+						return transaction, nil
+					}).
+					Times(3) // Reservation, its release and the charge
+				mockStorage.EXPECT().DeleteMessageVersions(gomock.Any(), int64(42)).Return(nil)
+				mockStorage.EXPECT().
+					CreateMessageVersion(gomock.Any(), &domain.MessageVersion{
+						MessageID: 42,
+						Text:      "A pun",
+						Model:     "google/gemini-2.5-flash",
+					}).
+					Return(&domain.MessageVersion{ID: 1}, nil)
+				mockSender.EXPECT().
+					EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).
+					Return(nil)
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "edit that can't be paid for isn't applied",
+			edit: domain.EditedMessage{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				ExternalMessageID: 99,
+				MessageText:       "Tell me a pun",
+			},
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(99)).
+					Return(newUserMessage(), nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(append([]*domain.Message{newUserMessage(), botMessage}, laterMessages...), nil)
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(nil, storage.ErrNotFound)
+				mockStorage.EXPECT().
+					GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+					Return(int64(0), nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", fmt.Sprintf(
+						i18n.GetString("en", i18n.ProfileInsufficientTokens), 1, "regular",
+					)).
+					Return("msg1", nil)
+				// Neither the edit nor the dropped turns are stored
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "edit whose answer can't be generated isn't applied",
+			edit: domain.EditedMessage{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				ExternalMessageID: 99,
+				MessageText:       "Tell me a pun",
+			},
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(99)).
+					Return(newUserMessage(), nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(append([]*domain.Message{newUserMessage(), botMessage}, laterMessages...), nil)
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(nil, storage.ErrNotFound)
+				mockStorage.EXPECT().
+					GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+					Return(int64(100), nil).
+					AnyTimes() // The fallbacks are tried too
+				mockStorage.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+						return transaction, nil
+					}).
+					Times(2) // Reservation and its release
+				mockQueue.EXPECT().
+					SubscribeCancel(gomock.Any(), "12345").
+					Return(make(chan struct{}), func() {}, nil)
+				mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+				mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("provider is down")).
+					AnyTimes()
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.EditFailed)).
+					Return("msg1", nil)
+				// Neither the edit nor the dropped turns are stored
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "provider is down")
+			},
+		},
+		{
+			name: "edit of an unknown message is ignored",
+			edit: domain.EditedMessage{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				ExternalMessageID: 98,
+				MessageText:       "/start",
+			},
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				_ *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				_ *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(98)).
+					Return(nil, storage.ErrNotFound)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "edit while another answer is generated",
+			edit: domain.EditedMessage{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				ExternalMessageID: 99,
+				MessageText:       "Tell me a pun",
+			},
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(99)).
+					Return(newUserMessage(), nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().
+					SetProcessing(gomock.Any(), "12345", gomock.Any()).
+					Return(queue.ErrAlreadyProcessing)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.EditBusy)).
+					Return("msg1", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			tt.setupMocks(mockStorage, mockSender, mockCompletion, mockQueue)
+
+			ctx := t.Context()
+			err := updateService.HandleEditedMessage(ctx, tt.edit)
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// callbackExport is the callback data of the export format buttons: export:<conversationID>:<format>.
+const callbackExport = "export"
+
+// isExportCallback reports whether callback data belongs to the export format buttons.
+func isExportCallback(data string) bool {
+	action, _, _ := strings.Cut(data, ":")
+	return action == callbackExport
+}
+
+// showExportFormats offers the formats the current conversation can be exported in.
+func (s *UpdateService) showExportFormats(ctx context.Context, user *domain.User) error {
+	if user.CurrentConversationID == nil {
+		_, err := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ExportEmpty))
+		return err
+	}
+
+	conversation, err := s.storage.GetConversationByID(ctx, *user.CurrentConversationID)
+	if err != nil {
+		return fmt.Errorf("can't get conversation to export: %w", err)
+	}
+
+	buttons := make([]domain.InlineKeyboardButton, 0, 3)
+	for _, format := range []struct{ name, id string }{
+		{"Markdown", exportFormatMarkdown},
+		{"JSON", exportFormatJSON},
+		{"HTML", exportFormatHTML},
+	} {
+		buttons = append(buttons, domain.InlineKeyboardButton{
+			Text:         format.name,
+			CallbackData: fmt.Sprintf("%s:%d:%s", callbackExport, conversation.ID, format.id),
+		})
+	}
+
+	_, err = s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text:           fmt.Sprintf(i18n.GetString(user.Language, i18n.ExportChooseFormat), conversation.Name),
+		InlineKeyboard: &domain.InlineKeyboard{Buttons: [][]domain.InlineKeyboardButton{buttons}},
+	})
+	return err
+}
+
+// handleExportCallback renders the conversation from the callback data and sends it as a document.
+func (s *UpdateService) handleExportCallback(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+) error {
+	_, args, _ := strings.Cut(callbackQuery.Data, ":")
+	rawID, format, _ := strings.Cut(args, ":")
+	conversationID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		s.logger.WarnContext(ctx, "invalid export callback data", slog.String("data", callbackQuery.Data))
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ExportUnavailable))
+		return nil
+	}
+
+	conversation, err := s.storage.GetConversationByID(ctx, conversationID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("can't get conversation to export: %w", err)
+	}
+	if conversation == nil || conversation.UserID != user.ID {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ExportUnavailable))
+		return nil
+	}
+
+	messages, err := s.storage.GetMessagesByConversationID(ctx, conversation.ID)
+	if err != nil {
+		return fmt.Errorf("can't get messages to export: %w", err)
+	}
+	if len(messages) == 0 {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ExportEmpty))
+		return nil
+	}
+
+	exported, err := s.exportConversation(ctx, conversation, messages)
+	if err != nil {
+		return err
+	}
+
+	// The button keeps spinning until the callback is answered, so failures are answered too
+	document, err := renderConversationExport(exported, format)
+	if err != nil {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ExportUnavailable))
+		return err
+	}
+	document.Caption = fmt.Sprintf(i18n.GetString(user.Language, i18n.ExportCaption), conversation.Name, len(messages))
+
+	if _, err = s.sender.SendDocument(ctx, user.ExternalID, document); err != nil {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.ExportUnavailable))
+		return fmt.Errorf("can't send export: %w", err)
+	}
+
+	s.answerCallback(ctx, callbackQuery.ID, "")
+	return nil
+}
+
+// exportConversation collects the conversation, its messages and links to their attachments.
+func (s *UpdateService) exportConversation(
+	ctx context.Context,
+	conversation *domain.Conversation,
+	messages []*domain.Message,
+) (*exportedConversation, error) {
+	now := time.Now()
+	exported := &exportedConversation{
+		ID:                   conversation.ID,
+		Name:                 conversation.Name,
+		ParentConversationID: conversation.ParentConversationID,
+		SystemPrompt:         conversation.SystemPrompt,
+		CreatedAt:            conversation.CreatedAt,
+		UpdatedAt:            conversation.UpdatedAt,
+		ExportedAt:           now,
+		Messages:             make([]exportedMessage, 0, len(messages)),
+	}
+
+	for _, message := range messages {
+		exportedMsg := exportedMessage{
+			ID:        message.ID,
+			Role:      exportRole(message.SentBy),
+			Text:      message.MessageType.Text,
+			CreatedAt: message.CreatedAt,
+		}
+		if message.MessageType.Quote != nil {
+			exportedMsg.Quote = message.MessageType.Quote.Text
+		}
+
+		attachments, err := s.exportAttachments(ctx, message.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		exportedMsg.Attachments = attachments
+
+		exported.Messages = append(exported.Messages, exportedMsg)
+	}
+
+	return exported, nil
+}
+
+// exportAttachments links the attachments of a message, there are no links without the file storage.
+func (s *UpdateService) exportAttachments(
+	ctx context.Context,
+	messageID int64,
+	now time.Time,
+) ([]exportedAttachment, error) {
+	if s.fileStorage == nil {
+		return nil, nil
+	}
+
+	attachments, err := s.storage.GetAttachmentsByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get attachments to export: %w", err)
+	}
+
+	var exported []exportedAttachment
+	for _, attachment := range attachments {
+		url, urlErr := s.fileStorage.GetPreSignedURL(ctx, attachment.S3Name, exportLinkExpiry)
+		if urlErr != nil {
+			// A missing link shouldn't cost the user the whole export
+			s.logger.WarnContext(ctx, "failed to get attachment link for export",
+				slog.String("error", urlErr.Error()),
+				slog.Int64("attachment_id", attachment.ID))
+			continue
+		}
+		exported = append(exported, exportedAttachment{
+			ContentType:  attachment.ContentType,
+			Size:         attachment.Size,
+			URL:          url,
+			URLExpiresAt: now.Add(exportLinkExpiry),
+		})
+	}
+	return exported, nil
+}
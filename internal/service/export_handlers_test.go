@@ -0,0 +1,291 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleCallbackQuery_Export(t *testing.T) {
+	conversationID := int64(7)
+	createdAt := time.Date(2025, 6, 19, 10, 30, 0, 0, time.UTC)
+	conversation := &domain.Conversation{
+		ID:        conversationID,
+		Name:      "Cats & <dogs>",
+		UserID:    1,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	messages := []*domain.Message{
+		{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "What is on this picture?"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+			CreatedAt:      createdAt,
+		},
+		{
+			ID:             42,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "A <b>cat</b>"},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+			CreatedAt:      createdAt.Add(time.Minute),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		data           string
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockFileStorage)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "markdown export with attachment link",
+			data: "export:7:md",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockFileStorage *mocks.MockFileStorage,
+			) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().GetMessagesByConversationID(gomock.Any(), conversationID).Return(messages, nil)
+				mockStorage.EXPECT().
+					GetAttachmentsByMessageID(gomock.Any(), int64(41)).
+					Return([]*domain.Attachment{{ID: 3, S3Name: "cat.jpg", ContentType: "image/jpeg", Size: 2048}}, nil)
+				mockStorage.EXPECT().GetAttachmentsByMessageID(gomock.Any(), int64(42)).Return(nil, nil)
+				mockFileStorage.EXPECT().
+					GetPreSignedURL(gomock.Any(), "cat.jpg", 7*24*time.Hour).
+					Return("https://files.example.com/cat.jpg", nil)
+				mockSender.EXPECT().
+					SendDocument(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document domain.Document) (string, error) {
+						assert.Equal(t, "Cats_dogs.md", document.FileName)
+						assert.Contains(t, document.Caption, "2 messages")
+
+						text := string(document.Data)
+						assert.Contains(t, text, "# Cats & <dogs>")
+						assert.Contains(t, text, "**User** · 2025-06-19 10:30 UTC")
+						assert.Contains(t, text, "**Assistant** · 2025-06-19 10:31 UTC")
+						assert.Contains(t, text, "- [image/jpeg, 2.0 KB](https://files.example.com/cat.jpg)")
+						return "doc1", nil
+					})
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "html export escapes message text",
+			data: "export:7:html",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockFileStorage,
+			) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().GetMessagesByConversationID(gomock.Any(), conversationID).Return(messages, nil)
+				mockStorage.EXPECT().GetAttachmentsByMessageID(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+				mockSender.EXPECT().
+					SendDocument(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document domain.Document) (string, error) {
+						assert.Equal(t, "Cats_dogs.html", document.FileName)
+
+						text := string(document.Data)
+						assert.Contains(t, text, "A &lt;b&gt;cat&lt;/b&gt;")
+						assert.NotContains(t, text, "<b>cat</b>")
+						return "doc1", nil
+					})
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "json export keeps roles and timestamps",
+			data: "export:7:json",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockFileStorage,
+			) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().GetMessagesByConversationID(gomock.Any(), conversationID).Return(messages, nil)
+				mockStorage.EXPECT().GetAttachmentsByMessageID(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+				mockSender.EXPECT().
+					SendDocument(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document domain.Document) (string, error) {
+						var exported struct {
+							Name     string `json:"name"`
+							Messages []struct {
+								Role      string    `json:"role"`
+								Text      string    `json:"text"`
+								CreatedAt time.Time `json:"created_at"`
+							} `json:"messages"`
+						}
+						require.NoError(t, json.Unmarshal(document.Data, &exported))
+						assert.Equal(t, "Cats & <dogs>", exported.Name)
+						require.Len(t, exported.Messages, 2)
+						assert.Equal(t, "user", exported.Messages[0].Role)
+						assert.Equal(t, "assistant", exported.Messages[1].Role)
+						assert.True(t, createdAt.Equal(exported.Messages[0].CreatedAt))
+						return "doc1", nil
+					})
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "unknown format is answered",
+			data: "export:7:pdf",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockFileStorage,
+			) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().GetMessagesByConversationID(gomock.Any(), conversationID).Return(messages, nil)
+				mockStorage.EXPECT().GetAttachmentsByMessageID(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ExportUnavailable)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "unknown export format")
+			},
+		},
+		{
+			name: "failed send is answered",
+			data: "export:7:md",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockFileStorage,
+			) {
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(conversation, nil)
+				mockStorage.EXPECT().GetMessagesByConversationID(gomock.Any(), conversationID).Return(messages, nil)
+				mockStorage.EXPECT().GetAttachmentsByMessageID(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+				mockSender.EXPECT().SendDocument(gomock.Any(), "12345", gomock.Any()).Return("", errors.New("too big"))
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ExportUnavailable)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "can't send export")
+			},
+		},
+		{
+			name: "conversation of another user is rejected",
+			data: "export:7:md",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockFileStorage,
+			) {
+				foreignConversation := *conversation
+				foreignConversation.UserID = 2
+				mockStorage.EXPECT().GetConversationByID(gomock.Any(), conversationID).Return(&foreignConversation, nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ExportUnavailable)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{ID: 1, ExternalID: "12345", Language: "en"}, nil)
+			tt.setupMocks(mockStorage, mockSender, mockFileStorage)
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           tt.data,
+			})
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
+
+func TestUpdateService_HandleCallbackQuery_ExportWithoutFileStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockSender := mocks.NewMockSender(ctrl)
+	updateService := service.NewUpdateService(
+		slog.Default(), mockStorage, mockSender, mocks.NewMockCompletion(ctrl), mocks.NewMockQueue(ctrl), nil,
+	)
+
+	conversationID := int64(7)
+	mockStorage.EXPECT().
+		GetUserByExternalUserID(gomock.Any(), "12345").
+		Return(&domain.User{ID: 1, ExternalID: "12345", Language: "en"}, nil)
+	mockStorage.EXPECT().
+		GetConversationByID(gomock.Any(), conversationID).
+		Return(&domain.Conversation{ID: conversationID, Name: "Cats", UserID: 1}, nil)
+	mockStorage.EXPECT().
+		GetMessagesByConversationID(gomock.Any(), conversationID).
+		Return([]*domain.Message{{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "What is on this picture?"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		}}, nil)
+	// The attachments have no links to export, so they aren't looked up
+	mockSender.EXPECT().
+		SendDocument(gomock.Any(), "12345", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document domain.Document) (string, error) {
+			assert.Contains(t, string(document.Data), "What is on this picture?")
+			return "doc1", nil
+		})
+	mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+
+	err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+		ID:             "cb1",
+		ExternalUserID: "12345",
+		UserLanguage:   "en",
+		Data:           "export:7:md",
+	})
+
+	require.NoError(t, err)
+}
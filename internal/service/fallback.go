@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// completeAnswer starts the completion of an answer for gen. When the provider fails before any content
+// streamed, the fallback models of the model answer in turn and the user is told which one does.
+// It returns the completion stream and the model that answers.
+func (s *UpdateService) completeAnswer(
+	ctx context.Context,
+	gen *generation,
+	user *domain.User,
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) (<-chan completion.StreamToken, *domain.ModelInfo, error) {
+	candidate := model
+	fallbacks := model.Fallbacks
+
+	for {
+		tokenStream, err := s.startCompletion(
+			gen, candidate, llmContext, attachments, webSearchEnabled && candidate.WebSearch,
+		)
+		if err == nil {
+			if candidate != model {
+				s.sendFallbackNotice(ctx, user, model, candidate)
+			}
+			return tokenStream, candidate, nil
+		}
+		// A stopped generation isn't a provider failure
+		if gen.ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("can't get completion: %w", err)
+		}
+
+		var next *domain.ModelInfo
+		next, fallbacks = s.nextFallback(
+			ctx, user, fallbacks, llmContext, attachments, webSearchEnabled,
+		)
+		if next == nil {
+			return nil, nil, fmt.Errorf("can't get completion: %w", err)
+		}
+
+		s.logger.WarnContext(ctx, "model failed, falling back",
+			slog.String("error", err.Error()),
+			slog.String("model", candidate.ID),
+			slog.String("fallback", next.ID))
+		candidate = next
+	}
+}
+
+// startCompletion requests a completion from the model and waits until it either streams or fails.
+func (s *UpdateService) startCompletion(
+	gen *generation,
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) (<-chan completion.StreamToken, error) {
+	tokenStream, err := s.completion.CompleteStream(
+		gen.ctx, answerRequest(model, llmContext, attachments, webSearchEnabled),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return peekStream(gen, tokenStream)
+}
+
+// sendFallbackNotice tells the user that the fallback answers instead of the model they selected.
+func (s *UpdateService) sendFallbackNotice(
+	ctx context.Context,
+	user *domain.User,
+	model *domain.ModelInfo,
+	fallback *domain.ModelInfo,
+) {
+	notice := fmt.Sprintf(
+		i18n.GetString(user.Language, i18n.ModelFallbackUsed),
+		model.GetDisplayName(user.Language),
+		fallback.GetDisplayName(user.Language),
+	)
+	if _, err := s.sender.SendMessage(ctx, user.ExternalID, notice); err != nil {
+		s.logger.WarnContext(ctx, "failed to send model fallback notice", slog.String("error", err.Error()))
+	}
+}
+
+// nextFallback returns the first of the fallbacks that can answer the request and the user can pay for,
+// and the fallbacks that remain after it. It returns nil when none of them can.
+func (s *UpdateService) nextFallback(
+	ctx context.Context,
+	user *domain.User,
+	fallbacks []string,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) (*domain.ModelInfo, []string) {
+	for i, fallbackID := range fallbacks {
+		fallback := domain.GetModelByID(fallbackID)
+		if fallback == nil ||
+			!fallback.TakesImages(countParts(attachments, completion.PartTypeImage)) ||
+			!fallback.TakesPDFs(countParts(attachments, completion.PartTypeFile)) {
+			continue
+		}
+
+		canPay, err := s.canPayForAnswer(
+			ctx, user, fallback, llmContext, attachments, webSearchEnabled && fallback.WebSearch,
+		)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to check fallback model balance",
+				slog.String("error", err.Error()),
+				slog.String("model", fallback.ID))
+			continue
+		}
+		if canPay {
+			return fallback, fallbacks[i+1:]
+		}
+	}
+
+	return nil, nil
+}
+
+// canPayForAnswer reports whether the user can use the model and the balance covers the estimated answer cost.
+func (s *UpdateService) canPayForAnswer(
+	ctx context.Context,
+	user *domain.User,
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	webSearchEnabled bool,
+) (bool, error) {
+	if model.NoSubscription {
+		subscription, err := s.storage.GetActiveSubscriptionByUserID(ctx, user.ID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return false, fmt.Errorf("failed to check subscription status: %w", err)
+		}
+		if subscription == nil {
+			return false, nil
+		}
+	}
+
+	estimate := estimateAnswerUsage(model, llmContext, attachments, webSearchEnabled)
+	for _, total := range domain.ChargeTotals(model.PriceAnswer(estimate)) {
+		balance, err := s.storage.GetUserTokenBalanceByType(ctx, user.ID, total.TokenType)
+		if err != nil {
+			return false, fmt.Errorf("failed to get user token balance: %w", err)
+		}
+		if balance < total.Amount {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// peekStream waits for the first token of a completion stream. It returns the error of a stream that fails
+// before any content, otherwise a stream of all its tokens. The tokens of a stopped generation are passed
+// on as they are, so that what was generated before the stop is kept.
+func peekStream(
+	gen *generation,
+	tokenStream <-chan completion.StreamToken,
+) (<-chan completion.StreamToken, error) {
+	var first completion.StreamToken
+	var ok bool
+	select {
+	case first, ok = <-tokenStream:
+	case <-gen.ctx.Done():
+		return tokenStream, nil
+	}
+	if !ok {
+		return tokenStream, nil
+	}
+	if first.Error != nil && gen.ctx.Err() == nil {
+		return nil, first.Error
+	}
+
+	peeked := make(chan completion.StreamToken)
+	go func() {
+		defer close(peeked)
+		token := first
+		for {
+			select {
+			case peeked <- token:
+			case <-gen.finished:
+				return
+			}
+			if token, ok = <-tokenStream; !ok {
+				return
+			}
+		}
+	}()
+
+	return peeked, nil
+}
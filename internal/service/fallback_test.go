@@ -0,0 +1,240 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleCallbackQuery_RegenerateFallback(t *testing.T) {
+	conversationID := int64(7)
+	userMessage := &domain.Message{
+		ID:             41,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "Tell me a joke"},
+		SentBy:         domain.MessageSenderUser,
+		ConversationID: &conversationID,
+	}
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "First joke"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+
+	answer := func(text string) func(
+		context.Context, completion.CompletionRequest,
+	) (<-chan completion.StreamToken, error) {
+		return func(_ context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+			tokens := make(chan completion.StreamToken, 1)
+			tokens <- completion.StreamToken{Content: text}
+			close(tokens)
+			return tokens, nil
+		}
+	}
+	failedStream := func(_ context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+		tokens := make(chan completion.StreamToken, 1)
+		tokens <- completion.StreamToken{Error: errors.New("provider is overloaded")}
+		close(tokens)
+		return tokens, nil
+	}
+
+	tests := []struct {
+		name       string
+		setupMocks func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockCompletion)
+		// answeredBy is the model the answer is charged by, empty when no model answers
+		answeredBy    string
+		expectedError bool
+	}{
+		{
+			name: "fallback answers when the model fails before any content",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+			) {
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(failedStream)
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("openai/gpt-4o-mini")).
+					DoAndReturn(answer("Second joke"))
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", fmt.Sprintf(
+						i18n.GetString("en", i18n.ModelFallbackUsed),
+						domain.GetModelByID("google/gemini-2.5-flash").GetDisplayName("en"),
+						domain.GetModelByID("openai/gpt-4o-mini").GetDisplayName("en"),
+					)).
+					Return("notice1", nil)
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First joke", "Second joke").
+					Return([]string{"100"}, nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second joke"}).
+					Return(nil)
+				mockStorage.EXPECT().
+					GetMessageVersions(gomock.Any(), int64(42)).
+					Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First joke"}}, nil)
+				mockStorage.EXPECT().
+					CreateMessageVersion(gomock.Any(), &domain.MessageVersion{
+						MessageID: 42,
+						Text:      "Second joke",
+						Model:     "openai/gpt-4o-mini",
+					}).
+					Return(&domain.MessageVersion{ID: 2}, nil)
+				mockSender.EXPECT().EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).Return(nil)
+			},
+			answeredBy: "openai/gpt-4o-mini",
+		},
+		{
+			name: "error when the fallbacks fail too",
+			setupMocks: func(
+				_ *mocks.MockStorage,
+				_ *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+			) {
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(failedStream)
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("openai/gpt-4o-mini")).
+					Return(nil, errors.New("HTTP error: 503"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+			mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalance(gomock.Any(), int64(1)).
+				Return(&domain.TokenBalance{RegularBalance: 100}, nil)
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return([]*domain.Message{userMessage, botMessage}, nil)
+			// The reservation and the balance check of the fallback
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(int64(100), nil).
+				Times(2)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockSender.EXPECT().
+				AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+			tt.setupMocks(mockStorage, mockSender, mockCompletion)
+
+			var charged []string
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					require.NotNil(t, transaction.ModelUsed)
+					if transaction.TransactionType == domain.TransactionTypeMessageCost {
+						charged = append(charged, *transaction.ModelUsed)
+					} else {
+						// The reservation is made and released for the selected model
+						assert.Equal(t, "google/gemini-2.5-flash", *transaction.ModelUsed)
+					}
+					return transaction, nil
+				}).
+				MinTimes(2)
+
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           "regen:42",
+			})
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Empty(t, charged)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, charged)
+			for _, model := range charged {
+				assert.Equal(t, tt.answeredBy, model)
+			}
+		})
+	}
+}
+
+// textOnlyRequest matches a completion request to the model without attachments and web search.
+func textOnlyRequest(model string) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		req, ok := x.(completion.CompletionRequest)
+		if !ok || req.Model != model || req.Plugins.WebSearch {
+			return false
+		}
+		for _, message := range req.Messages {
+			for _, part := range message.Parts {
+				if part.Type != completion.PartTypeText {
+					return false
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const forkCommand = "/fork"
+
+// forkConversation copies the conversation of the replied message up to and including that message
+// into a new conversation and makes it the current one.
+func (s *UpdateService) forkConversation(ctx context.Context, user *domain.User, update domain.Update) error {
+	if update.ReplyToMessageID <= 0 {
+		_, err := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ForkUsage))
+		return err
+	}
+
+	forkPoint, err := s.getForkPoint(ctx, user, update.ReplyToMessageID)
+	if err != nil {
+		return err
+	}
+	if forkPoint == nil {
+		_, err = s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ForkUnavailable))
+		return err
+	}
+
+	parent, err := s.storage.GetConversationByID(ctx, *forkPoint.ConversationID)
+	if err != nil {
+		return fmt.Errorf("can't get conversation to fork: %w", err)
+	}
+
+	history, err := s.storage.GetMessagesByConversationID(ctx, parent.ID)
+	if err != nil {
+		return fmt.Errorf("can't get messages to fork: %w", err)
+	}
+
+	fork, err := s.storage.CreateConversation(ctx, &domain.Conversation{
+		Name:                 parent.Name,
+		UserID:               user.ID,
+		SystemPrompt:         parent.SystemPrompt,
+		ParentConversationID: &parent.ID,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't create forked conversation: %w", err)
+	}
+
+	copiedIDs, err := s.copyMessages(ctx, fork.ID, history, forkPoint.ID)
+	if err != nil {
+		return err
+	}
+	s.copyConversationSummary(ctx, parent.ID, fork.ID, copiedIDs)
+
+	user.CurrentConversationID = &fork.ID
+	if err = s.storage.UpdateUserCurrentConversationID(ctx, user.ID, &fork.ID); err != nil {
+		return fmt.Errorf("can't update user conversation: %w", err)
+	}
+
+	notice := fmt.Sprintf(i18n.GetString(user.Language, i18n.ForkCreated), parent.Name, len(copiedIDs))
+	if _, err = s.sender.SendMessage(ctx, user.ExternalID, notice); err != nil {
+		s.logger.WarnContext(ctx, "failed to send fork notice", slog.String("error", err.Error()))
+	}
+
+	// Reply to the fork point so it's clear where the new conversation continues from
+	replyToMessageID := int64(update.ReplyToMessageID)
+	return s.transitionToConversation(ctx, user, &replyToMessageID)
+}
+
+// getForkPoint returns the stored conversation message displayed by the given Telegram message
+// or nil if the message doesn't belong to one of the user's conversations.
+func (s *UpdateService) getForkPoint(
+	ctx context.Context,
+	user *domain.User,
+	externalMessageID int,
+) (*domain.Message, error) {
+	if int64(externalMessageID) > int64(^uint32(0)>>1) {
+		return nil, nil //nolint:nilnil // Telegram IDs out of range are never mapped
+	}
+
+	message, err := s.storage.GetMessageByForeignID(ctx, user.ID, int32(externalMessageID)) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil //nolint:nilnil // Menu and service messages are not part of a conversation
+		}
+		return nil, fmt.Errorf("can't get message to fork from: %w", err)
+	}
+	if message.ConversationID == nil {
+		return nil, nil //nolint:nilnil // Messages sent before conversations existed can't be forked
+	}
+
+	return message, nil
+}
+
+// copyMessages copies the messages of history up to and including lastMessageID into the conversation
+// together with their attachments. It returns the IDs of the copies keyed by the IDs of the originals.
+func (s *UpdateService) copyMessages(
+	ctx context.Context,
+	conversationID int64,
+	history []*domain.Message,
+	lastMessageID int64,
+) (map[int64]int64, error) {
+	copiedIDs := make(map[int64]int64)
+	for _, message := range history {
+		if message.ID > lastMessageID {
+			break
+		}
+
+		// The copies keep the timestamps of the originals
+		copied, err := s.storage.CreateMessage(ctx, &domain.Message{
+			UserID:         message.UserID,
+			MessageType:    message.MessageType,
+			SentBy:         message.SentBy,
+			ConversationID: &conversationID,
+			CreatedAt:      message.CreatedAt,
+			UpdatedAt:      message.UpdatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't copy message: %w", err)
+		}
+		copiedIDs[message.ID] = copied.ID
+
+		attachments, err := s.storage.GetAttachmentsByMessageID(ctx, message.ID)
+		if err != nil {
+			return nil, fmt.Errorf("can't get attachments to copy: %w", err)
+		}
+		for _, attachment := range attachments {
+			// The copy points to the same stored file
+			_, err = s.storage.CreateAttachment(ctx, &domain.Attachment{
+				MessageID:   copied.ID,
+				S3Name:      attachment.S3Name,
+				ContentType: attachment.ContentType,
+				Size:        attachment.Size,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("can't copy attachment: %w", err)
+			}
+		}
+	}
+
+	return copiedIDs, nil
+}
+
+// copyConversationSummary carries the rolling summary of the parent conversation over to the fork
+// when it only covers copied messages, so the fork doesn't have to summarize the same turns again.
+func (s *UpdateService) copyConversationSummary(
+	ctx context.Context,
+	parentID int64,
+	forkID int64,
+	copiedIDs map[int64]int64,
+) {
+	summary, err := s.storage.GetConversationSummary(ctx, parentID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			s.logger.WarnContext(ctx, "failed to get conversation summary", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	lastMessageID, ok := copiedIDs[summary.LastMessageID]
+	if !ok {
+		return
+	}
+
+	err = s.storage.UpsertConversationSummary(ctx, &domain.ConversationSummary{
+		ConversationID: forkID,
+		Summary:        summary.Summary,
+		LastMessageID:  lastMessageID,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to copy conversation summary", slog.String("error", err.Error()))
+	}
+}
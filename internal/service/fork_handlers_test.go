@@ -0,0 +1,246 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleUpdate_Fork(t *testing.T) {
+	conversationID := int64(7)
+	forkID := int64(8)
+	askedAt := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	user := &domain.User{
+		ID:                    1,
+		ExternalID:            "12345",
+		Language:              "en",
+		CurrentStep:           domain.UserStateConversation,
+		SelectedModel:         "google/gemini-2.5-flash",
+		CurrentConversationID: &conversationID,
+	}
+	history := []*domain.Message{
+		{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "What is on this picture?"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+			CreatedAt:      askedAt,
+			UpdatedAt:      askedAt,
+		},
+		{
+			ID:             42,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "A cat"},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+			CreatedAt:      askedAt.Add(time.Second),
+			UpdatedAt:      askedAt.Add(time.Hour),
+		},
+		{
+			ID:             43,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "And its color?"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+			CreatedAt:      askedAt.Add(time.Minute),
+			UpdatedAt:      askedAt.Add(time.Minute),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		update         domain.Update
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "reply copies history up to the replied message",
+			update: domain.Update{
+				ExternalUserID:   "12345",
+				UserLanguage:     "en",
+				MessageText:      "/fork",
+				ReplyToMessageID: 100,
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(100)).
+					Return(history[1], nil)
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{
+						ID:           conversationID,
+						Name:         "Cats",
+						UserID:       1,
+						SystemPrompt: pointer.To("Be brief."),
+					}, nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(history, nil)
+				mockStorage.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, conversation *domain.Conversation) (*domain.Conversation, error) {
+						assert.Equal(t, "Cats", conversation.Name)
+						assert.Equal(t, pointer.To("Be brief."), conversation.SystemPrompt)
+						assert.Equal(t, &conversationID, conversation.ParentConversationID)
+						forked := *conversation
+						forked.ID = forkID
+						return &forked, nil
+					})
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						assert.Equal(t, &forkID, message.ConversationID)
+						assert.Equal(t, "What is on this picture?", message.MessageType.Text)
+						assert.Equal(t, askedAt, message.CreatedAt)
+						copied := *message
+						copied.ID = 51
+						return &copied, nil
+					})
+				mockStorage.EXPECT().
+					GetAttachmentsByMessageID(gomock.Any(), int64(41)).
+					Return([]*domain.Attachment{{ID: 3, MessageID: 41, S3Name: "cat.jpg", ContentType: "image/jpeg"}}, nil)
+				mockStorage.EXPECT().
+					CreateAttachment(gomock.Any(), &domain.Attachment{
+						MessageID:   51,
+						S3Name:      "cat.jpg",
+						ContentType: "image/jpeg",
+					}).
+					Return(&domain.Attachment{ID: 4}, nil)
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						assert.Equal(t, "A cat", message.MessageType.Text)
+						assert.Equal(t, askedAt.Add(time.Second), message.CreatedAt)
+						assert.Equal(t, askedAt.Add(time.Hour), message.UpdatedAt)
+						copied := *message
+						copied.ID = 52
+						return &copied, nil
+					})
+				mockStorage.EXPECT().
+					GetAttachmentsByMessageID(gomock.Any(), int64(42)).
+					Return(nil, nil)
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(&domain.ConversationSummary{
+						ConversationID: conversationID,
+						Summary:        "The user asked about a picture.",
+						LastMessageID:  41,
+					}, nil)
+				mockStorage.EXPECT().
+					UpsertConversationSummary(gomock.Any(), &domain.ConversationSummary{
+						ConversationID: forkID,
+						Summary:        "The user asked about a picture.",
+						LastMessageID:  51,
+					}).
+					Return(nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentConversationID(gomock.Any(), int64(1), &forkID).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, text string) (string, error) {
+						assert.Contains(t, text, "Cats")
+						assert.Contains(t, text, "2 messages")
+						return "msg1", nil
+					})
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateConversation).
+					Return(nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound).
+					AnyTimes()
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						require.NotNil(t, content.ReplyToMessageID)
+						assert.Equal(t, int64(100), *content.ReplyToMessageID)
+						return "msg2", nil
+					})
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "command without reply explains usage",
+			update: domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    "/fork",
+			},
+			setupMocks: func(_ *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ForkUsage)).
+					Return("msg1", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "reply to a message outside conversations is rejected",
+			update: domain.Update{
+				ExternalUserID:   "12345",
+				UserLanguage:     "en",
+				MessageText:      "/fork",
+				ReplyToMessageID: 5,
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					GetMessageByForeignID(gomock.Any(), int64(1), int32(5)).
+					Return(nil, storage.ErrNotFound)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ForkUnavailable)).
+					Return("msg1", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			tt.setupMocks(mockStorage, mockSender)
+
+			ctx := t.Context()
+			err := updateService.HandleUpdate(ctx, tt.update)
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
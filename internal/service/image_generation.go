@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+)
+
+var errNothingGenerated = errors.New("model generated neither images nor text")
+
+// SetImageGeneration sets the service that models with image output answer with.
+// Without it these models can't answer.
+func (s *UpdateService) SetImageGeneration(imageGeneration imagegen.ImageGeneration) {
+	s.imageGeneration = imageGeneration
+}
+
+// generateImages answers with the images the model generates from the conversation, the images of the request
+// are its references. The images are uploaded and sent as photos, followed by the text that came with them.
+// Images aren't streamed, so a generation stopped by the user leaves nothing to show.
+func (s *UpdateService) generateImages(
+	ctx context.Context,
+	gen *generation,
+	user *domain.User,
+	model *domain.ModelInfo,
+	llmContext conversationContext,
+	attachments []completion.Part,
+	replyToMessageID *int64,
+) (*shownAnswer, error) {
+	if s.imageGeneration == nil {
+		return nil, fmt.Errorf("image generation is not configured for model %s", model.ID)
+	}
+
+	result, err := s.imageGeneration.GenerateImages(gen.ctx, answerRequest(model, llmContext, attachments, false))
+	if gen.isStopped() {
+		return &shownAnswer{stopped: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't generate images: %w", err)
+	}
+	text := strings.TrimSpace(result.Text)
+	if len(result.Images) == 0 && text == "" {
+		return nil, errNothingGenerated
+	}
+
+	answer := &shownAnswer{text: text, usage: result.Usage, model: model, generatedImages: len(result.Images)}
+	for _, image := range result.Images {
+		messageID, sendErr := s.sender.SendPhoto(ctx, user.ExternalID, domain.Photo{
+			Data:             image.Data,
+			ReplyToMessageID: replyToMessageID,
+		})
+		if sendErr != nil {
+			return s.partialImageAnswer(ctx, answer, fmt.Errorf("can't send generated image: %w", sendErr))
+		}
+		answer.messageIDs = append(answer.messageIDs, messageID)
+
+		if file, uploaded := s.uploadGeneratedImage(ctx, image); uploaded {
+			answer.images = append(answer.images, file)
+		}
+	}
+
+	if text != "" {
+		messageID, sendErr := s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+			Text:             text,
+			ReplyToMessageID: replyToMessageID,
+		})
+		if sendErr != nil {
+			return s.partialImageAnswer(ctx, answer, fmt.Errorf("can't send image answer text: %w", sendErr))
+		}
+		answer.messageIDs = append(answer.messageIDs, messageID)
+	}
+
+	return answer, nil
+}
+
+// partialImageAnswer returns the answer when some of it was sent before sending failed: the user has seen
+// those images and the generation is charged. Nothing sent fails the answer with err.
+func (s *UpdateService) partialImageAnswer(
+	ctx context.Context,
+	answer *shownAnswer,
+	err error,
+) (*shownAnswer, error) {
+	if len(answer.messageIDs) == 0 {
+		return nil, err
+	}
+
+	s.logger.WarnContext(ctx, "image answer was sent in part", slog.String("error", err.Error()))
+	return answer, nil
+}
+
+// uploadGeneratedImage keeps a generated image in the file storage. It returns false when the image
+// couldn't be stored, the user has seen it anyway.
+func (s *UpdateService) uploadGeneratedImage(ctx context.Context, image imagegen.Image) (domain.File, bool) {
+	if s.fileStorage == nil {
+		return domain.File{}, false
+	}
+
+	objectName, err := s.fileStorage.Upload(ctx, image.Data, image.MimeType)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to upload generated image",
+			slog.String("error", err.Error()),
+			slog.String("content_type", image.MimeType))
+		return domain.File{}, false
+	}
+
+	return domain.File{Data: image.Data, MimeType: image.MimeType, S3Name: objectName}, true
+}
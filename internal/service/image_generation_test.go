@@ -0,0 +1,243 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/imagegen"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+)
+
+func TestUpdateService_HandleConversationState_ImageGeneration(t *testing.T) {
+	conversationID := int64(7)
+	imageModel := "google/gemini-2.5-flash-image-preview"
+	images := []imagegen.Image{
+		{Data: []byte("png1"), MimeType: "image/png"},
+		{Data: []byte("png2"), MimeType: "image/png"},
+	}
+
+	tests := []struct {
+		name   string
+		result *imagegen.Result
+		// uploadErr fails the upload of the generated images
+		uploadErr error
+		// failedPhoto is the number of the photo that can't be sent, zero for none
+		failedPhoto int
+		// expectedFiles are the images the bot message references
+		expectedFiles   []domain.File
+		expectedCharges []domain.TokenCharge
+	}{
+		{
+			name:   "images are sent as photos with the text and stored",
+			result: &imagegen.Result{Text: "Here is your cat", Images: images},
+			expectedFiles: []domain.File{
+				{MimeType: "image/png", S3Name: "generated-0"},
+				{MimeType: "image/png", S3Name: "generated-1"},
+			},
+			expectedCharges: []domain.TokenCharge{
+				{TokenType: domain.TokenTypeRegular, Amount: 1},
+				{TokenType: domain.TokenTypeRegular, Amount: 16, Description: "Image generation cost: 2 images"},
+			},
+		},
+		{
+			name:          "images are charged when they can't be stored",
+			result:        &imagegen.Result{Images: images[:1]},
+			uploadErr:     errors.New("minio is down"),
+			expectedFiles: nil,
+			expectedCharges: []domain.TokenCharge{
+				{TokenType: domain.TokenTypeRegular, Amount: 1},
+				{TokenType: domain.TokenTypeRegular, Amount: 8, Description: "Image generation cost: 1 images"},
+			},
+		},
+		{
+			name:          "images are charged when only some of them are sent",
+			result:        &imagegen.Result{Text: "Here is your cat", Images: images},
+			failedPhoto:   2,
+			expectedFiles: []domain.File{{MimeType: "image/png", S3Name: "generated-0"}},
+			expectedCharges: []domain.TokenCharge{
+				{TokenType: domain.TokenTypeRegular, Amount: 1},
+				{TokenType: domain.TokenTypeRegular, Amount: 16, Description: "Image generation cost: 2 images"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			mockImageGeneration := mocks.NewMockImageGeneration(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetImageGeneration(mockImageGeneration)
+
+			// Failing to batch the message makes it answered right away
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(int64(100), nil).
+				AnyTimes()
+			history := []*domain.Message{{
+				ID:             42,
+				UserID:         1,
+				MessageType:    domain.MessageType{Text: "Draw a cat"},
+				SentBy:         domain.MessageSenderUser,
+				ConversationID: &conversationID,
+			}}
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(history, nil).
+				Times(2)
+			var botMessage *domain.Message
+			mockStorage.EXPECT().
+				CreateMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+					message.ID = 42
+					if message.SentBy == domain.MessageSenderBot {
+						message.ID = 43
+						botMessage = message
+					}
+					return message, nil
+				}).
+				Times(2)
+			mockStorage.EXPECT().UpdateConversationTimestamp(gomock.Any(), conversationID).Return(nil).Times(2)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil).
+				AnyTimes()
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound).
+				AnyTimes()
+			mockStorage.EXPECT().CreateForeignMessage(gomock.Any(), int32(43), gomock.Any()).Return(nil).AnyTimes()
+
+			mockImageGeneration.EXPECT().
+				GenerateImages(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req completion.CompletionRequest) (*imagegen.Result, error) {
+					assert.Equal(t, imageModel, req.Model)
+					require.NotEmpty(t, req.Messages)
+					assert.Equal(t, "Draw a cat", req.Messages[len(req.Messages)-1].Parts[0].Text)
+					return tt.result, nil
+				})
+
+			for i, image := range tt.result.Images {
+				if i+1 == tt.failedPhoto {
+					// The photos after it and the text aren't sent
+					mockSender.EXPECT().
+						SendPhoto(gomock.Any(), "12345", domain.Photo{Data: image.Data}).
+						Return("", errors.New("Too Many Requests"))
+					break
+				}
+				if tt.uploadErr != nil {
+					mockFileStorage.EXPECT().Upload(gomock.Any(), image.Data, image.MimeType).Return("", tt.uploadErr)
+				} else {
+					s3Name := tt.expectedFiles[i].S3Name
+					mockFileStorage.EXPECT().Upload(gomock.Any(), image.Data, image.MimeType).Return(s3Name, nil)
+					mockStorage.EXPECT().
+						CreateAttachment(gomock.Any(), &domain.Attachment{
+							MessageID:   43,
+							S3Name:      s3Name,
+							ContentType: image.MimeType,
+							Size:        int64(len(image.Data)),
+						}).
+						Return(&domain.Attachment{}, nil)
+				}
+				mockSender.EXPECT().
+					SendPhoto(gomock.Any(), "12345", domain.Photo{Data: image.Data}).
+					Return(fmt.Sprintf("20%d", i), nil)
+			}
+
+			mockSender.EXPECT().SendTyping(gomock.Any(), "12345").Return(nil).AnyTimes()
+			var sentText []string
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+					if content.InlineKeyboard != nil {
+						return "stop1", nil
+					}
+					sentText = append(sentText, content.Text)
+					return "300", nil
+				}).
+				AnyTimes()
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+
+			var charges []domain.TokenCharge
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					if transaction.TransactionType == domain.TransactionTypeMessageCost {
+						charge := domain.TokenCharge{TokenType: transaction.TokenType, Amount: -transaction.Amount}
+						if transaction.Description != nil {
+							charge.Description = *transaction.Description
+						}
+						charges = append(charges, charge)
+					}
+					return transaction, nil
+				}).
+				AnyTimes()
+
+			user := &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateConversation,
+				SelectedModel:         imageModel,
+				CurrentConversationID: &conversationID,
+			}
+
+			err := updateService.HandleConversationState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    "Draw a cat",
+			})
+
+			require.NoError(t, err)
+			require.NotNil(t, botMessage)
+			assert.Equal(t, tt.result.Text, botMessage.MessageType.Text)
+			assert.Equal(t, tt.expectedFiles, botMessage.MessageType.Files)
+			if tt.result.Text != "" && tt.failedPhoto == 0 {
+				assert.Equal(t, []string{tt.result.Text}, sentText)
+			} else {
+				assert.Empty(t, sentText)
+			}
+			assert.Equal(t, tt.expectedCharges, charges)
+		})
+	}
+}
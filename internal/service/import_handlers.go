@@ -0,0 +1,205 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const (
+	// importProgressInterval is how often the import status message is updated.
+	importProgressInterval = 3 * time.Second
+	// maxImportedConversationButtons limits how many imported conversations are offered to continue.
+	maxImportedConversationButtons = 10
+)
+
+// startConversationImport parses an uploaded data export and imports its conversations in the
+// background, reporting the progress in a status message.
+func (s *UpdateService) startConversationImport(ctx context.Context, user *domain.User, update domain.Update) error {
+	conversations, err := parseChatGPTExport(update.DocumentData)
+	if err != nil {
+		s.logger.InfoContext(ctx, "rejected conversation import",
+			slog.String("error", err.Error()),
+			slog.String("file_name", update.DocumentFileName))
+		_, sendErr := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ImportInvalid))
+		return sendErr
+	}
+	if len(conversations) == 0 {
+		_, err = s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.ImportEmpty))
+		return err
+	}
+
+	statusMessageID, err := s.sender.SendMessage(ctx, user.ExternalID,
+		fmt.Sprintf(i18n.GetString(user.Language, i18n.ImportProgress), 0, len(conversations)))
+	if err != nil {
+		return fmt.Errorf("can't send import status: %w", err)
+	}
+
+	go s.importConversations(context.Background(), user, conversations, statusMessageID)
+
+	return nil
+}
+
+// importConversations stores the parsed conversations and offers to continue the imported ones.
+func (s *UpdateService) importConversations(
+	ctx context.Context,
+	user *domain.User,
+	conversations []importedConversation,
+	statusMessageID string,
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Panic occurred while importing conversations",
+				"panic", r,
+				"stack_trace", string(debug.Stack()),
+				"user_id", user.ExternalID)
+		}
+	}()
+
+	imported := make([]*domain.Conversation, 0, len(conversations))
+	messageCount := 0
+	lastProgress := time.Now()
+	for i, conversation := range conversations {
+		stored, err := s.importConversation(ctx, user, conversation)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to import conversation",
+				slog.String("error", err.Error()),
+				slog.String("title", conversation.Title))
+		} else {
+			imported = append(imported, stored)
+			messageCount += len(conversation.Messages)
+		}
+
+		if time.Since(lastProgress) >= importProgressInterval || i == len(conversations)-1 {
+			s.updateImportStatus(ctx, user, statusMessageID, i+1, len(conversations))
+			lastProgress = time.Now()
+		}
+	}
+
+	s.logger.InfoContext(ctx, "conversations imported",
+		slog.String("user_id", user.ExternalID),
+		slog.Int("conversations", len(imported)),
+		slog.Int("messages", messageCount),
+		slog.Int("failed", len(conversations)-len(imported)))
+
+	text := fmt.Sprintf(i18n.GetString(user.Language, i18n.ImportFinished), len(imported), messageCount)
+	if failed := len(conversations) - len(imported); failed > 0 {
+		text += "\n\n" + fmt.Sprintf(i18n.GetString(user.Language, i18n.ImportSkipped), failed)
+	}
+
+	content := domain.MessageContent{Text: text}
+	if keyboard := importedConversationsKeyboard(imported); keyboard != nil {
+		content.InlineKeyboard = keyboard
+	}
+	if _, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, content); err != nil {
+		s.logger.WarnContext(ctx, "failed to send import result", slog.String("error", err.Error()))
+	}
+}
+
+// importConversation stores a single imported conversation with its messages.
+func (s *UpdateService) importConversation(
+	ctx context.Context,
+	user *domain.User,
+	imported importedConversation,
+) (*domain.Conversation, error) {
+	name := imported.Title
+	if name == "" {
+		name = defaultConversationName
+	}
+	createdAt := imported.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = imported.Messages[0].CreatedAt
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	updatedAt := imported.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
+	// A conversation is imported whole or not at all
+	var conversation *domain.Conversation
+	err := s.storage.InTransaction(ctx, func(tx storage.Storage) error {
+		var createErr error
+		conversation, createErr = tx.CreateConversation(ctx, &domain.Conversation{
+			Name:      name,
+			UserID:    user.ID,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		})
+		if createErr != nil {
+			return fmt.Errorf("can't create imported conversation: %w", createErr)
+		}
+
+		for _, message := range imported.Messages {
+			messageTime := message.CreatedAt
+			if messageTime.IsZero() {
+				messageTime = createdAt
+			}
+
+			_, createErr = tx.CreateMessage(ctx, &domain.Message{
+				UserID:         user.ID,
+				MessageType:    domain.MessageType{Text: message.Text},
+				SentBy:         message.SentBy,
+				ConversationID: &conversation.ID,
+				CreatedAt:      messageTime,
+				UpdatedAt:      messageTime,
+			})
+			if createErr != nil {
+				return fmt.Errorf("can't create imported message: %w", createErr)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+func (s *UpdateService) updateImportStatus(
+	ctx context.Context,
+	user *domain.User,
+	statusMessageID string,
+	done, total int,
+) {
+	text := fmt.Sprintf(i18n.GetString(user.Language, i18n.ImportProgress), done, total)
+	if _, err := s.sender.UpdateMessage(ctx, user.ExternalID, statusMessageID, text); err != nil {
+		s.logger.WarnContext(ctx, "failed to update import status", slog.String("error", err.Error()))
+	}
+}
+
+// importedConversationsKeyboard offers the most recently updated imported conversations to continue.
+func importedConversationsKeyboard(conversations []*domain.Conversation) *domain.InlineKeyboard {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	recent := slices.Clone(conversations)
+	slices.SortStableFunc(recent, func(a, b *domain.Conversation) int {
+		return cmp.Compare(b.UpdatedAt.UnixNano(), a.UpdatedAt.UnixNano())
+	})
+	if len(recent) > maxImportedConversationButtons {
+		recent = recent[:maxImportedConversationButtons]
+	}
+
+	keyboard := &domain.InlineKeyboard{}
+	for _, conversation := range recent {
+		keyboard.Buttons = append(keyboard.Buttons, []domain.InlineKeyboardButton{{
+			Text:         "💬 " + conversation.Name,
+			CallbackData: fmt.Sprintf("%s:%d", callbackOpenConversation, conversation.ID),
+		}})
+	}
+
+	return keyboard
+}
@@ -0,0 +1,262 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// chatGPTExport has a regenerated answer (an abandoned branch) and a hidden system message.
+const chatGPTExport = `[{
+	"title": "Cats",
+	"create_time": 1718791800.5,
+	"update_time": 1718792100,
+	"current_node": "answer-2",
+	"mapping": {
+		"root": {"message": null, "parent": null, "children": ["system"]},
+		"system": {
+			"message": {
+				"author": {"role": "system"},
+				"content": {"content_type": "text", "parts": [""]},
+				"metadata": {"is_visually_hidden_from_conversation": true}
+			},
+			"parent": "root",
+			"children": ["question"]
+		},
+		"question": {
+			"message": {
+				"author": {"role": "user"},
+				"create_time": 1718791860,
+				"content": {"content_type": "text", "parts": ["What do cats eat?"]}
+			},
+			"parent": "system",
+			"children": ["answer-1", "answer-2"]
+		},
+		"answer-1": {
+			"message": {
+				"author": {"role": "assistant"},
+				"create_time": 1718791870,
+				"content": {"content_type": "text", "parts": ["Abandoned answer"]}
+			},
+			"parent": "question",
+			"children": []
+		},
+		"answer-2": {
+			"message": {
+				"author": {"role": "assistant"},
+				"create_time": 1718791920,
+				"content": {"content_type": "text", "parts": ["Mostly meat."]}
+			},
+			"parent": "question",
+			"children": []
+		}
+	}
+}]`
+
+// expectTransaction runs a transaction on the storage mock itself.
+func expectTransaction(mockStorage *mocks.MockStorage) {
+	mockStorage.EXPECT().
+		InTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(storage.Storage) error) error {
+			return fn(mockStorage)
+		})
+}
+
+func TestUpdateService_HandleUpdate_Import(t *testing.T) {
+	user := &domain.User{
+		ID:          1,
+		ExternalID:  "12345",
+		Language:    "en",
+		CurrentStep: domain.UserStateMenu,
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		setupMocks func(*testing.T, *mocks.MockStorage, *mocks.MockSender, chan struct{})
+	}{
+		{
+			name: "current branch of the conversation is imported",
+			data: chatGPTExport,
+			setupMocks: func(t *testing.T, mockStorage *mocks.MockStorage, mockSender *mocks.MockSender, done chan struct{}) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", "📥 Importing conversations: 0 of 1…").
+					Return("status1", nil)
+				expectTransaction(mockStorage)
+				mockStorage.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, conversation *domain.Conversation) (*domain.Conversation, error) {
+						assert.Equal(t, "Cats", conversation.Name)
+						assert.Equal(t, int64(1), conversation.UserID)
+						assert.True(t, time.Unix(1718791800, 500000000).Equal(conversation.CreatedAt))
+						assert.True(t, time.Unix(1718792100, 0).Equal(conversation.UpdatedAt))
+						conversation.ID = 7
+						return conversation, nil
+					})
+
+				var messages []*domain.Message
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						messages = append(messages, message)
+						return message, nil
+					}).
+					Times(2)
+				mockSender.EXPECT().
+					UpdateMessage(gomock.Any(), "12345", "status1", "📥 Importing conversations: 1 of 1…").
+					Return([]string{"status1"}, nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						defer close(done)
+
+						require.Len(t, messages, 2)
+						assert.Equal(t, domain.MessageSenderUser, messages[0].SentBy)
+						assert.Equal(t, "What do cats eat?", messages[0].MessageType.Text)
+						assert.True(t, time.Unix(1718791860, 0).Equal(messages[0].CreatedAt))
+						assert.Equal(t, domain.MessageSenderBot, messages[1].SentBy)
+						assert.Equal(t, "Mostly meat.", messages[1].MessageType.Text)
+						assert.Equal(t, int64(7), *messages[1].ConversationID)
+
+						assert.Contains(t, content.Text, "Imported 1 conversations with 2 messages")
+						require.NotNil(t, content.InlineKeyboard)
+						require.Len(t, content.InlineKeyboard.Buttons, 1)
+						assert.Equal(t, "conv_open:7", content.InlineKeyboard.Buttons[0][0].CallbackData)
+						return "msg2", nil
+					})
+			},
+		},
+		{
+			name: "conversation whose messages can't be stored isn't imported",
+			data: chatGPTExport,
+			setupMocks: func(t *testing.T, mockStorage *mocks.MockStorage, mockSender *mocks.MockSender, done chan struct{}) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", "📥 Importing conversations: 0 of 1…").
+					Return("status1", nil)
+				expectTransaction(mockStorage)
+				mockStorage.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, conversation *domain.Conversation) (*domain.Conversation, error) {
+						conversation.ID = 7
+						return conversation, nil
+					})
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("connection reset"))
+				mockSender.EXPECT().
+					UpdateMessage(gomock.Any(), "12345", "status1", "📥 Importing conversations: 1 of 1…").
+					Return([]string{"status1"}, nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						defer close(done)
+
+						assert.Contains(t, content.Text, "Imported 0 conversations with 0 messages")
+						assert.Contains(t, content.Text, "1 conversations couldn't be imported")
+						assert.Nil(t, content.InlineKeyboard)
+						return "msg2", nil
+					})
+			},
+		},
+		{
+			name: "export that can't be parsed is rejected",
+			data: `[{"title": "Cats", "create_time": "yesterday", "mapping": {}}]`,
+			setupMocks: func(_ *testing.T, _ *mocks.MockStorage, mockSender *mocks.MockSender, done chan struct{}) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ImportInvalid)).
+					DoAndReturn(func(_ context.Context, _, _ string) (string, error) {
+						close(done)
+						return "msg1", nil
+					})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			done := make(chan struct{})
+			tt.setupMocks(t, mockStorage, mockSender, done)
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID:   "12345",
+				UserLanguage:     "en",
+				DocumentData:     []byte(tt.data),
+				DocumentFileName: "conversations.json",
+			})
+			require.NoError(t, err)
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("import didn't finish")
+			}
+		})
+	}
+}
+
+func TestUpdateService_HandleCallbackQuery_OpenConversation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockSender := mocks.NewMockSender(ctrl)
+	mockCompletion := mocks.NewMockCompletion(ctrl)
+	mockQueue := mocks.NewMockQueue(ctrl)
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	logger := slog.Default()
+
+	updateService := service.NewUpdateService(
+		logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+	)
+
+	mockStorage.EXPECT().
+		GetUserByExternalUserID(gomock.Any(), "12345").
+		Return(&domain.User{ID: 1, ExternalID: "12345", Language: "en"}, nil)
+	mockStorage.EXPECT().
+		GetConversationByID(gomock.Any(), int64(7)).
+		Return(&domain.Conversation{ID: 7, Name: "Cats", UserID: 2}, nil)
+	mockSender.EXPECT().
+		AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.ConversationUnavailable)).
+		Return(nil)
+
+	err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+		ID:             "cb1",
+		ExternalUserID: "12345",
+		UserLanguage:   "en",
+		Data:           "conv_open:7",
+	})
+
+	require.NoError(t, err)
+}
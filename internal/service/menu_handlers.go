@@ -39,7 +39,7 @@ func (s *UpdateService) HandleMenuState(ctx context.Context, user *domain.User,
 	}
 
 	// If message doesn't match any menu option, create new conversation and process the message
-	if update.MessageText != "" {
+	if update.HasPrompt() {
 		return s.createNewConversationFromMenu(ctx, user, update)
 	}
 
@@ -115,6 +115,12 @@ func (s *UpdateService) transitionToConversation(
 		buttons = append(buttons, []domain.KeyboardButton{webSearchButton})
 	}
 
+	// Add conversation tools row
+	buttons = append(buttons, []domain.KeyboardButton{
+		{Text: i18n.GetString(user.Language, i18n.ButtonPersona)},
+		{Text: i18n.GetString(user.Language, i18n.ButtonExport)},
+	})
+
 	// Add back to menu button
 	buttons = append(buttons, []domain.KeyboardButton{
 		{Text: i18n.GetString(user.Language, i18n.ButtonBackToMenu)},
@@ -119,6 +119,9 @@ func TestUpdateService_HandleMenuState(t *testing.T) {
 				mockStorage.EXPECT().
 					GetUserTokenBalance(gomock.Any(), int64(1)).
 					Return(&domain.TokenBalance{RegularBalance: 100, PremiumBalance: 50}, nil)
+				mockStorage.EXPECT().
+					GetUserUsageTotals(gomock.Any(), int64(1)).
+					Return(&domain.UsageTotals{}, nil)
 				mockSender.EXPECT().
 					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
 					Return("msg123", nil)
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/vladimish/talk/internal/domain"
 	"github.com/vladimish/talk/pkg/i18n"
@@ -37,7 +38,7 @@ func (s *UpdateService) HandleModelSelectState(ctx context.Context, user *domain
 	hasActiveSubscription := err == nil && activeSubscription != nil
 
 	// Check if user selected a model by comparing with display names
-	for _, model := range domain.AvailableModels {
+	for _, model := range domain.AvailableModels() {
 		displayName := model.GetDisplayNameForUser(user.Language, hasActiveSubscription, *balance)
 		if update.MessageText == displayName {
 			// Check if user can actually use this model
@@ -72,7 +73,7 @@ func (s *UpdateService) showModelSelection(ctx context.Context, user *domain.Use
 
 	// Create buttons for each model with user-specific display names
 	var modelButtons [][]domain.KeyboardButton
-	for _, model := range domain.AvailableModels {
+	for _, model := range domain.AvailableModels() {
 		displayName := model.GetDisplayNameForUser(user.Language, hasActiveSubscription, *balance)
 		modelButtons = append(modelButtons, []domain.KeyboardButton{
 			{Text: displayName},
@@ -134,3 +135,56 @@ func (s *UpdateService) selectModel(ctx context.Context, user *domain.User, mode
 	successMessage := fmt.Sprintf("%s %s", i18n.GetString(user.Language, i18n.ModelUpdateSuccess), modelDisplayName)
 	return s.sendMenu(ctx, user, successMessage+"\n\n"+i18n.GetString(user.Language, i18n.MenuBackToMain))
 }
+
+// currentModel returns the model selected by the user. Users of a model that was retired or dropped from the
+// catalog since they selected it are moved to its replacement.
+func (s *UpdateService) currentModel(ctx context.Context, user *domain.User) *domain.ModelInfo {
+	if model := domain.GetModelByID(user.SelectedModel); model != nil {
+		return model
+	}
+
+	replacement := domain.ReplacementModel(user.SelectedModel)
+	if replacement == nil {
+		return nil
+	}
+
+	err := s.storage.UpdateUserSelectedModel(ctx, user.ID, replacement.ID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to move user to the replacement model",
+			slog.String("error", err.Error()),
+			slog.String("model", user.SelectedModel))
+	}
+	user.SelectedModel = replacement.ID
+
+	return replacement
+}
+
+// MigrateRetiredModels moves users whose selected model is no longer available to its replacement.
+// It runs on start and after every catalog reload.
+func (s *UpdateService) MigrateRetiredModels(ctx context.Context) error {
+	selectedModels, err := s.storage.GetSelectedModels(ctx)
+	if err != nil {
+		return fmt.Errorf("can't get selected models: %w", err)
+	}
+
+	for _, modelID := range selectedModels {
+		if domain.GetModelByID(modelID) != nil {
+			continue
+		}
+		replacement := domain.ReplacementModel(modelID)
+		if replacement == nil {
+			continue
+		}
+
+		updated, updateErr := s.storage.UpdateUsersSelectedModel(ctx, modelID, replacement.ID)
+		if updateErr != nil {
+			return fmt.Errorf("can't move users of %s to %s: %w", modelID, replacement.ID, updateErr)
+		}
+		s.logger.InfoContext(ctx, "moved users of a retired model",
+			slog.String("model", modelID),
+			slog.String("replacement", replacement.ID),
+			slog.Int64("users", updated))
+	}
+
+	return nil
+}
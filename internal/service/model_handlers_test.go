@@ -136,3 +136,48 @@ func TestUpdateService_HandleModelSelectState(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateService_MigrateRetiredModels(t *testing.T) {
+	catalog := domain.DefaultModelCatalog()
+	for i := range catalog.Models {
+		if catalog.Models[i].ID == "openai/gpt-4o" {
+			catalog.Models[i].Retired = true
+			catalog.Models[i].ReplacedBy = "openai/gpt-4o-mini"
+		}
+	}
+	require.NoError(t, catalog.Validate())
+	domain.SetModelCatalog(catalog)
+	t.Cleanup(func() { domain.SetModelCatalog(domain.DefaultModelCatalog()) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	updateService := service.NewUpdateService(
+		slog.Default(),
+		mockStorage,
+		mocks.NewMockSender(ctrl),
+		mocks.NewMockCompletion(ctrl),
+		mocks.NewMockQueue(ctrl),
+		mocks.NewMockFileStorage(ctrl),
+	)
+
+	mockStorage.EXPECT().
+		GetSelectedModels(gomock.Any()).
+		Return([]string{"google/gemini-2.5-flash", "openai/gpt-4o", "anthropic/claude-3-opus"}, nil)
+	// A retired model is replaced by its successor, a model dropped from the catalog by the default one
+	mockStorage.EXPECT().
+		UpdateUsersSelectedModel(gomock.Any(), "openai/gpt-4o", "openai/gpt-4o-mini").
+		Return(int64(3), nil)
+	mockStorage.EXPECT().
+		UpdateUsersSelectedModel(gomock.Any(), "anthropic/claude-3-opus", "google/gemini-2.5-flash").
+		Return(int64(1), nil)
+
+	err := updateService.MigrateRetiredModels(t.Context())
+
+	require.NoError(t, err)
+	assert.Nil(t, domain.GetModelByID("openai/gpt-4o"))
+	for _, model := range domain.AvailableModels() {
+		assert.NotEqual(t, "openai/gpt-4o", model.ID)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const personaButtonsPerRow = 2
+
+// HandleDefaultPersonaSelectState handles editing of the user's default system prompt from settings.
+func (s *UpdateService) HandleDefaultPersonaSelectState(
+	ctx context.Context,
+	user *domain.User,
+	update domain.Update,
+) error {
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonBackToMenu) {
+		return s.transitionToSettings(ctx, user)
+	}
+
+	if update.MessageText == "" {
+		return s.sendPersonaSelection(ctx, user, nil)
+	}
+
+	prompt, ok, err := s.parsePersonaInput(ctx, user, update.MessageText)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = s.storage.UpdateUserDefaultSystemPrompt(ctx, user.ID, prompt); err != nil {
+		return fmt.Errorf("can't update default system prompt: %w", err)
+	}
+	user.DefaultSystemPrompt = prompt
+
+	if err = s.sendPersonaConfirmation(ctx, user, prompt); err != nil {
+		return err
+	}
+
+	return s.transitionToSettings(ctx, user)
+}
+
+// HandleConversationPersonaSelectState handles editing of the current conversation's system prompt.
+func (s *UpdateService) HandleConversationPersonaSelectState(
+	ctx context.Context,
+	user *domain.User,
+	update domain.Update,
+) error {
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonBackToMenu) {
+		return s.transitionToConversation(ctx, user, nil)
+	}
+
+	conversation, err := s.getCurrentConversation(ctx, user)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		// Nothing to attach the prompt to, so fall back to the default prompt editor
+		return s.transitionToDefaultPersonaSelect(ctx, user)
+	}
+
+	if update.MessageText == "" {
+		return s.sendPersonaSelection(ctx, user, conversation)
+	}
+
+	prompt, ok, err := s.parsePersonaInput(ctx, user, update.MessageText)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = s.storage.UpdateConversationSystemPrompt(ctx, conversation.ID, prompt); err != nil {
+		return fmt.Errorf("can't update conversation system prompt: %w", err)
+	}
+
+	if err = s.sendPersonaConfirmation(ctx, user, prompt); err != nil {
+		return err
+	}
+
+	return s.transitionToConversation(ctx, user, nil)
+}
+
+func (s *UpdateService) transitionToDefaultPersonaSelect(ctx context.Context, user *domain.User) error {
+	personaState := domain.UserStateDefaultPersonaSelect
+	user.CurrentStep = personaState
+
+	err := s.storage.UpdateUserCurrentStep(ctx, user.ID, personaState)
+	if err != nil {
+		return fmt.Errorf("can't update user state: %w", err)
+	}
+
+	return s.sendPersonaSelection(ctx, user, nil)
+}
+
+func (s *UpdateService) transitionToConversationPersonaSelect(ctx context.Context, user *domain.User) error {
+	conversation, err := s.getCurrentConversation(ctx, user)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		return s.transitionToDefaultPersonaSelect(ctx, user)
+	}
+
+	personaState := domain.UserStateConversationPersonaSelect
+	user.CurrentStep = personaState
+
+	err = s.storage.UpdateUserCurrentStep(ctx, user.ID, personaState)
+	if err != nil {
+		return fmt.Errorf("can't update user state: %w", err)
+	}
+
+	return s.sendPersonaSelection(ctx, user, conversation)
+}
+
+// parsePersonaInput converts a persona button or free text into a prompt to store.
+// A nil prompt means "reset". The boolean is false when the input was rejected and the user was notified.
+func (s *UpdateService) parsePersonaInput(
+	ctx context.Context,
+	user *domain.User,
+	text string,
+) (*string, bool, error) {
+	if text == i18n.GetString(user.Language, i18n.ButtonPersonaReset) {
+		return nil, true, nil
+	}
+
+	if persona := domain.GetPersonaByLabel(user.Language, text); persona != nil {
+		return &persona.Prompt, true, nil
+	}
+
+	if utf8.RuneCountInString(text) > domain.MaxSystemPromptLength {
+		tooLongMsg := fmt.Sprintf(i18n.GetString(user.Language, i18n.PersonaTooLong), domain.MaxSystemPromptLength)
+		_, err := s.sender.SendMessage(ctx, user.ExternalID, tooLongMsg)
+		return nil, false, err
+	}
+
+	return &text, true, nil
+}
+
+func (s *UpdateService) sendPersonaConfirmation(ctx context.Context, user *domain.User, prompt *string) error {
+	confirmationKey := i18n.PersonaUpdated
+	if prompt == nil {
+		confirmationKey = i18n.PersonaResetDone
+	}
+
+	_, err := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, confirmationKey))
+	return err
+}
+
+// sendPersonaSelection shows the current prompt and the persona keyboard.
+// When conversation is nil the user's default prompt is being edited.
+func (s *UpdateService) sendPersonaSelection(
+	ctx context.Context,
+	user *domain.User,
+	conversation *domain.Conversation,
+) error {
+	var buttons [][]domain.KeyboardButton
+	var row []domain.KeyboardButton
+	for _, persona := range domain.AvailablePersonas {
+		row = append(row, domain.KeyboardButton{Text: i18n.GetString(user.Language, persona.I18nKey)})
+		if len(row) == personaButtonsPerRow {
+			buttons = append(buttons, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		buttons = append(buttons, row)
+	}
+
+	buttons = append(buttons,
+		[]domain.KeyboardButton{{Text: i18n.GetString(user.Language, i18n.ButtonPersonaReset)}},
+		[]domain.KeyboardButton{{Text: i18n.GetString(user.Language, i18n.ButtonBackToMenu)}},
+	)
+
+	titleKey := i18n.PersonaSelectTitle
+	if conversation != nil {
+		titleKey = i18n.PersonaConversationSelectTitle
+	}
+
+	content := domain.MessageContent{
+		Text: fmt.Sprintf(
+			i18n.GetString(user.Language, titleKey),
+			domain.ResolveSystemPrompt(user, conversation),
+		),
+		ReplyKeyboard: &domain.ReplyKeyboard{
+			Buttons: buttons,
+			Resize:  true,
+			OneTime: false,
+		},
+	}
+
+	_, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, content)
+	return err
+}
+
+// getCurrentConversation returns the user's current conversation or nil if there is none.
+func (s *UpdateService) getCurrentConversation(
+	ctx context.Context,
+	user *domain.User,
+) (*domain.Conversation, error) {
+	if user.CurrentConversationID == nil {
+		return nil, nil //nolint:nilnil // No current conversation is a valid state
+	}
+
+	conversation, err := s.storage.GetConversationByID(ctx, *user.CurrentConversationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil //nolint:nilnil // Conversation was removed
+		}
+		return nil, fmt.Errorf("can't get current conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// resolveConversationSystemPrompt returns the system prompt for the user's current conversation.
+func (s *UpdateService) resolveConversationSystemPrompt(ctx context.Context, user *domain.User) string {
+	conversation, err := s.getCurrentConversation(ctx, user)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to get conversation for system prompt, using default",
+			slog.String("error", err.Error()))
+	}
+
+	return domain.ResolveSystemPrompt(user, conversation)
+}
@@ -0,0 +1,289 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleDefaultPersonaSelectState(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *domain.User
+		update         domain.Update
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "preset persona button",
+			user: &domain.User{
+				ID:         1,
+				ExternalID: "12345",
+				Language:   "en",
+			},
+			update: domain.Update{
+				MessageText: i18n.GetString("en", i18n.PersonaProgrammer),
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					UpdateUserDefaultSystemPrompt(gomock.Any(), int64(1), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, prompt *string) error {
+						require.NotNil(t, prompt)
+						assert.Contains(t, *prompt, "software engineer")
+						return nil
+					})
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.PersonaUpdated)).
+					Return("msg1", nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateSettings).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					Return("msg2", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "custom prompt text",
+			user: &domain.User{
+				ID:         1,
+				ExternalID: "12345",
+				Language:   "en",
+			},
+			update: domain.Update{
+				MessageText: "You are a pirate.",
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					UpdateUserDefaultSystemPrompt(gomock.Any(), int64(1), pointer.To("You are a pirate.")).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", gomock.Any()).
+					Return("msg1", nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateSettings).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					Return("msg2", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "reset button clears prompt",
+			user: &domain.User{
+				ID:                  1,
+				ExternalID:          "12345",
+				Language:            "en",
+				DefaultSystemPrompt: pointer.To("You are a pirate."),
+			},
+			update: domain.Update{
+				MessageText: i18n.GetString("en", i18n.ButtonPersonaReset),
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					UpdateUserDefaultSystemPrompt(gomock.Any(), int64(1), nil).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.PersonaResetDone)).
+					Return("msg1", nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateSettings).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					Return("msg2", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "too long prompt is rejected",
+			user: &domain.User{
+				ID:         1,
+				ExternalID: "12345",
+				Language:   "en",
+			},
+			update: domain.Update{
+				MessageText: strings.Repeat("a", domain.MaxSystemPromptLength+1),
+			},
+			setupMocks: func(_ *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, text string) (string, error) {
+						assert.Contains(t, text, "too long")
+						return "msg1", nil
+					})
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "back button returns to settings",
+			user: &domain.User{
+				ID:         1,
+				ExternalID: "12345",
+				Language:   "en",
+			},
+			update: domain.Update{
+				MessageText: i18n.GetString("en", i18n.ButtonBackToMenu),
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateSettings).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					Return("msg1", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			tt.setupMocks(mockStorage, mockSender)
+
+			ctx := t.Context()
+			err := updateService.HandleDefaultPersonaSelectState(ctx, tt.user, tt.update)
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
+
+func TestUpdateService_HandleConversationPersonaSelectState(t *testing.T) {
+	conversationID := int64(7)
+
+	tests := []struct {
+		name           string
+		user           *domain.User
+		update         domain.Update
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "custom prompt is stored on the conversation",
+			user: &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				SelectedModel:         "openai/gpt-4o-mini",
+				CurrentConversationID: &conversationID,
+			},
+			update: domain.Update{
+				MessageText: "Answer only in haiku.",
+			},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+				mockStorage.EXPECT().
+					UpdateConversationSystemPrompt(gomock.Any(), conversationID, pointer.To("Answer only in haiku.")).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.PersonaUpdated)).
+					Return("msg1", nil)
+				mockStorage.EXPECT().
+					UpdateUserCurrentStep(gomock.Any(), int64(1), domain.UserStateConversation).
+					Return(nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					Return("msg2", nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "empty update shows conversation prompt",
+			user: &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentConversationID: &conversationID,
+				DefaultSystemPrompt:   pointer.To("User default prompt"),
+			},
+			update: domain.Update{},
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{
+						ID:           conversationID,
+						UserID:       1,
+						SystemPrompt: pointer.To("Conversation prompt"),
+					}, nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						assert.Contains(t, content.Text, "Conversation prompt")
+						assert.NotContains(t, content.Text, "User default prompt")
+						assert.NotNil(t, content.ReplyKeyboard)
+						return "msg1", nil
+					})
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			tt.setupMocks(mockStorage, mockSender)
+
+			ctx := t.Context()
+			err := updateService.HandleConversationPersonaSelectState(ctx, tt.user, tt.update)
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
@@ -45,6 +45,9 @@ func (s *UpdateService) showProfile(ctx context.Context, user *domain.User) erro
 	regularText := fmt.Sprintf(i18n.GetString(user.Language, i18n.ProfileRegularTokens), balance.RegularBalance)
 
 	profileText := fmt.Sprintf("%s\n\n%s\n%s\n%s", title, tokenBalanceText, premiumText, regularText)
+	if usageText := s.profileUsage(ctx, user); usageText != "" {
+		profileText += "\n\n" + usageText
+	}
 
 	content := domain.MessageContent{
 		Text:         profileText,
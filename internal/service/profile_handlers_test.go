@@ -59,6 +59,9 @@ func TestUpdateService_HandleProfileState(t *testing.T) {
 				mockStorage.EXPECT().
 					GetUserTokenBalance(gomock.Any(), int64(1)).
 					Return(&domain.TokenBalance{RegularBalance: 100, PremiumBalance: 50}, nil)
+				mockStorage.EXPECT().
+					GetUserUsageTotals(gomock.Any(), int64(1)).
+					Return(&domain.UsageTotals{}, nil)
 				mockSender.EXPECT().
 					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
 					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/storage"
+)
+
+// maxQuoteRunes limits how much of a replied message is quoted when the user didn't select a part of it.
+const maxQuoteRunes = 2000
+
+// resolveQuote returns the part of an earlier message the update replies to. A partial Telegram quote is
+// used as is, otherwise the whole replied message is quoted if it's stored. history is the current
+// conversation before the update.
+func (s *UpdateService) resolveQuote(
+	ctx context.Context,
+	user *domain.User,
+	update domain.Update,
+	history []*domain.Message,
+) *domain.Quote {
+	if update.ReplyToMessageID <= 0 || int64(update.ReplyToMessageID) > int64(^uint32(0)>>1) {
+		return nil
+	}
+
+	var quoted *domain.Message
+	message, err := s.storage.GetMessageByForeignID(ctx, user.ID, int32(update.ReplyToMessageID)) //nolint:gosec
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.logger.WarnContext(ctx, "failed to resolve replied message",
+			slog.String("error", err.Error()),
+			slog.Int("reply_to_message_id", update.ReplyToMessageID))
+	}
+	if err == nil {
+		quoted = message
+	}
+
+	if update.QuoteText != "" {
+		quote := &domain.Quote{Text: update.QuoteText}
+		if quoted != nil {
+			quote.MessageID = quoted.ID
+		}
+		return quote
+	}
+
+	if quoted == nil || quoted.MessageType.Text == "" {
+		return nil
+	}
+
+	// Replying to the latest message adds nothing, the model sees it right before the new one
+	if len(history) > 0 && history[len(history)-1].ID == quoted.ID {
+		return nil
+	}
+
+	return &domain.Quote{
+		MessageID: quoted.ID,
+		Text:      truncateRunes(quoted.MessageType.Text, maxQuoteRunes),
+	}
+}
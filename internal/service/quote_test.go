@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+)
+
+func TestUpdateService_HandleConversationState_Quote(t *testing.T) {
+	conversationID := int64(7)
+	errStop := errors.New("stop after saving the user message")
+	history := []*domain.Message{
+		{
+			ID:             41,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Explain closures"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             42,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "A closure captures variables.\nIt keeps them alive."},
+			SentBy:         domain.MessageSenderBot,
+			ConversationID: &conversationID,
+		},
+		{
+			ID:             43,
+			UserID:         1,
+			MessageType:    domain.MessageType{Text: "Thanks"},
+			SentBy:         domain.MessageSenderUser,
+			ConversationID: &conversationID,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		update        domain.Update
+		repliedTo     *domain.Message
+		expectedQuote *domain.Quote
+		expectedText  string
+	}{
+		{
+			name: "partial quote is used as is",
+			update: domain.Update{
+				MessageText:      "Explain this part",
+				ReplyToMessageID: 100,
+				QuoteText:        "keeps them alive",
+			},
+			repliedTo:     history[1],
+			expectedQuote: &domain.Quote{MessageID: 42, Text: "keeps them alive"},
+			expectedText:  "> keeps them alive\n\nExplain this part",
+		},
+		{
+			name: "reply without quote quotes the whole message",
+			update: domain.Update{
+				MessageText:      "Explain this",
+				ReplyToMessageID: 100,
+			},
+			repliedTo:     history[1],
+			expectedQuote: &domain.Quote{MessageID: 42, Text: "A closure captures variables.\nIt keeps them alive."},
+			expectedText:  "> A closure captures variables.\n> It keeps them alive.\n\nExplain this",
+		},
+		{
+			name: "reply to the latest message is not quoted",
+			update: domain.Update{
+				MessageText:      "You're welcome?",
+				ReplyToMessageID: 101,
+			},
+			repliedTo:    history[2],
+			expectedText: "You're welcome?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			// Failing to batch the message makes it processed right away
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(history, nil)
+			mockStorage.EXPECT().
+				GetMessageByForeignID(gomock.Any(), int64(1), int32(tt.update.ReplyToMessageID)).
+				Return(tt.repliedTo, nil)
+			mockStorage.EXPECT().
+				CreateMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+					assert.Equal(t, tt.expectedQuote, message.MessageType.Quote)
+					assert.Equal(t, tt.expectedText, message.MessageType.PromptText())
+					return nil, errStop
+				})
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			user := &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateConversation,
+				SelectedModel:         "google/gemini-2.5-flash",
+				CurrentConversationID: &conversationID,
+			}
+			update := tt.update
+			update.ExternalUserID = "12345"
+			update.UserLanguage = "en"
+
+			err := updateService.HandleConversationState(t.Context(), user, update)
+			require.ErrorIs(t, err, errStop)
+		})
+	}
+}
@@ -0,0 +1,662 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// Callback data of the inline keyboard under bot answers. Every route carries the stored message ID.
+const (
+	callbackRegenerate       = "regen"        // regen:<messageID>
+	callbackRegenerateModels = "regen_models" // regen_models:<messageID>
+	callbackRegenerateWith   = "regen_with"   // regen_with:<messageID>:<modelKey>
+	callbackAnswerVersion    = "answer_ver"   // answer_ver:<messageID>:<versionIndex>
+	callbackAnswerKeyboard   = "answer_kb"    // answer_kb:<messageID>
+	callbackNoop             = "noop"
+)
+
+// isAnswerCallback reports whether callback data belongs to the keyboard under bot answers.
+func isAnswerCallback(data string) bool {
+	action, _, _ := strings.Cut(data, ":")
+	switch action {
+	case callbackRegenerate, callbackRegenerateModels, callbackRegenerateWith,
+		callbackAnswerVersion, callbackAnswerKeyboard, callbackNoop:
+		return true
+	}
+	return false
+}
+
+// handleAnswerCallback routes button presses on the keyboard under bot answers.
+func (s *UpdateService) handleAnswerCallback(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+) error {
+	action, args, _ := strings.Cut(callbackQuery.Data, ":")
+	if action == callbackNoop {
+		s.answerCallback(ctx, callbackQuery.ID, "")
+		return nil
+	}
+
+	messageArg, valueArg, _ := strings.Cut(args, ":")
+	messageID, err := strconv.ParseInt(messageArg, 10, 64)
+	index := 0
+	if err == nil && action == callbackAnswerVersion {
+		index, err = strconv.Atoi(valueArg)
+	}
+	if err != nil {
+		s.logger.WarnContext(ctx, "invalid answer callback data", slog.String("data", callbackQuery.Data))
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateUnavailable))
+		return nil
+	}
+
+	botMessage, err := s.getAnswerMessage(ctx, user, messageID)
+	if err != nil {
+		return err
+	}
+	if botMessage == nil {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateUnavailable))
+		return nil
+	}
+
+	switch action {
+	case callbackRegenerate:
+		return s.regenerateAnswer(ctx, user, callbackQuery, botMessage, s.currentModel(ctx, user))
+	case callbackRegenerateWith:
+		return s.regenerateAnswer(ctx, user, callbackQuery, botMessage, modelByCallbackKey(valueArg))
+	case callbackRegenerateModels:
+		return s.showRegenerateModels(ctx, user, callbackQuery, botMessage)
+	case callbackAnswerVersion:
+		return s.showAnswerVersion(ctx, user, callbackQuery, botMessage, index)
+	default:
+		return s.restoreAnswerKeyboard(ctx, user, callbackQuery, botMessage)
+	}
+}
+
+// regenerateAnswer replays the user message that preceded botMessage with the given model and streams
+// the new answer into the same Telegram messages. The new answer is stored as another version.
+func (s *UpdateService) regenerateAnswer(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+	botMessage *domain.Message,
+	model *domain.ModelInfo,
+) error {
+	// Image models don't stream, so they can't answer in the messages of a text answer
+	if model == nil || model.ImageOutput {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateModelUnavailable))
+		return nil
+	}
+
+	balance, err := s.storage.GetUserTokenBalance(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("can't get user token balance: %w", err)
+	}
+
+	activeSubscription, err := s.storage.GetActiveSubscriptionByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to check subscription status: %w", err)
+	}
+	hasActiveSubscription := activeSubscription != nil
+
+	if !domain.CanUserUseModel(model, hasActiveSubscription, *balance) {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateModelUnavailable))
+		return nil
+	}
+
+	history, err := s.storage.GetMessagesByConversationID(ctx, *botMessage.ConversationID)
+	if err != nil {
+		return fmt.Errorf("can't get messages: %w", err)
+	}
+
+	prompt := messagesBefore(history, botMessage.ID)
+	if len(prompt) == 0 || prompt[len(prompt)-1].SentBy != domain.MessageSenderUser {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateUnavailable))
+		return nil
+	}
+	userMessage := prompt[len(prompt)-1]
+
+	if notice := unsupportedFilesNotice(model, user.Language, userMessage.MessageType.Files); notice != "" {
+		s.answerCallback(ctx, callbackQuery.ID, notice)
+		return nil
+	}
+
+	webSearchEnabled := model.WebSearch && user.WebSearchEnabled && hasActiveSubscription
+
+	release, locked := s.lockAnswer(ctx, user)
+	if !locked {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateBusy))
+		return nil
+	}
+	defer release()
+
+	s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateStarted))
+
+	request, err := s.prepareRerun(ctx, user, botMessage.ConversationID, prompt, model, webSearchEnabled)
+	if errors.Is(err, errInsufficientBalance) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer s.releaseAnswer(ctx, request.bill)
+
+	answer, err := s.rerunAnswer(ctx, user, botMessage, request, model, webSearchEnabled)
+	if errors.Is(err, errGenerationStopped) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Answers created before versioning have no stored versions, so keep the original text first
+	versions, err := s.storage.GetMessageVersions(ctx, botMessage.ID)
+	if err != nil {
+		return fmt.Errorf("can't get message versions: %w", err)
+	}
+	if len(versions) == 0 {
+		_, err = s.storage.CreateMessageVersion(ctx, &domain.MessageVersion{
+			MessageID: botMessage.ID,
+			Text:      botMessage.MessageType.Text,
+		})
+		if err != nil {
+			return fmt.Errorf("can't save original message version: %w", err)
+		}
+	}
+
+	_, err = s.storage.CreateMessageVersion(ctx, &domain.MessageVersion{
+		MessageID: botMessage.ID,
+		Text:      answer.text,
+		Model:     answer.model.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't save message version: %w", err)
+	}
+
+	versionCount := max(len(versions), 1) + 1
+	s.attachAnswerKeyboard(ctx, user, botMessage.ID, answer.messageIDs, versionCount-1, versionCount,
+		usageFooter(user, answer.usage))
+
+	return nil
+}
+
+// rerunRequest is a prompt to answer again, with its context built and the cost of the answer reserved.
+type rerunRequest struct {
+	llmContext  conversationContext
+	attachments []completion.Part
+	bill        *answerBill
+}
+
+// prepareRerun builds the context of a new answer to prompt and reserves its cost, so nothing is changed
+// before it is known the answer can be paid for. When the balance can't cover the estimated cost,
+// errInsufficientBalance is returned and the user was notified.
+func (s *UpdateService) prepareRerun(
+	ctx context.Context,
+	user *domain.User,
+	conversationID *int64,
+	prompt []*domain.Message,
+	model *domain.ModelInfo,
+	webSearchEnabled bool,
+) (*rerunRequest, error) {
+	conversation, err := s.storage.GetConversationByID(ctx, *conversationID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to get conversation for system prompt, using default",
+			slog.String("error", err.Error()))
+	}
+	systemPrompt := domain.ResolveSystemPrompt(user, conversation)
+
+	attachments := s.storedMessageAttachments(ctx, prompt[len(prompt)-1])
+	llmContext := s.buildConversationContext(ctx, user, conversationID, model, systemPrompt, prompt)
+
+	bill, err := s.reserveAnswer(ctx, user, model, llmContext, attachments, webSearchEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rerunRequest{llmContext: llmContext, attachments: attachments, bill: bill}, nil
+}
+
+// rerunAnswer generates a new answer to the prepared request and streams it into the messages that display
+// botMessage. The stored message is updated to the new text and the answer is charged. When the user stops
+// the generation before it produced any text, errGenerationStopped is returned and nothing changes.
+func (s *UpdateService) rerunAnswer(
+	ctx context.Context,
+	user *domain.User,
+	botMessage *domain.Message,
+	request *rerunRequest,
+	model *domain.ModelInfo,
+	webSearchEnabled bool,
+) (*shownAnswer, error) {
+	typingDone := make(chan struct{})
+	defer close(typingDone)
+	go s.sendPeriodicTyping(ctx, user.ExternalID, typingDone)
+
+	gen := s.startGeneration(ctx, user)
+	defer gen.finish()
+
+	tokenStream, answeringModel, err := s.completeAnswer(
+		ctx, gen, user, model, request.llmContext, request.attachments, webSearchEnabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.bill.answeredBy(answeringModel)
+
+	messageIDs, err := s.answerMessageIDs(ctx, botMessage.ID)
+	if err != nil {
+		return nil, err
+	}
+	previous := &shownAnswer{messageIDs: messageIDs, text: botMessage.MessageType.Text}
+
+	answer, err := s.streamAnswer(ctx, user, tokenStream, gen, previous, nil)
+	gen.finish()
+	if err != nil {
+		return nil, err
+	}
+	if answer.stopped && answer.text == "" {
+		// The previous answer is still displayed untouched
+		return nil, errGenerationStopped
+	}
+
+	answer.model = answeringModel
+
+	s.replaceShownAnswer(ctx, user, botMessage.ID, previous, answer)
+	s.saveUsage(ctx, user, botMessage.ID, answeringModel.ID, answer.usage)
+	s.settleAnswer(ctx, request.bill, answer)
+
+	return answer, nil
+}
+
+// showAnswerVersion replaces the displayed answer with one of its stored versions.
+func (s *UpdateService) showAnswerVersion(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+	botMessage *domain.Message,
+	index int,
+) error {
+	versions, err := s.storage.GetMessageVersions(ctx, botMessage.ID)
+	if err != nil {
+		return fmt.Errorf("can't get message versions: %w", err)
+	}
+	if index < 0 || index >= len(versions) {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateUnavailable))
+		return nil
+	}
+
+	release, locked := s.lockAnswer(ctx, user)
+	if !locked {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.RegenerateBusy))
+		return nil
+	}
+	defer release()
+
+	messageIDs, err := s.answerMessageIDs(ctx, botMessage.ID)
+	if err != nil {
+		return err
+	}
+	previous := &shownAnswer{messageIDs: messageIDs, text: botMessage.MessageType.Text}
+
+	version := versions[index]
+	updatedMessageIDs, err := s.sender.UpdateMessages(ctx, user.ExternalID, messageIDs, previous.text, version.Text)
+	if err != nil {
+		return fmt.Errorf("can't show answer version: %w", err)
+	}
+
+	current := &shownAnswer{messageIDs: updatedMessageIDs, text: version.Text}
+	s.replaceShownAnswer(ctx, user, botMessage.ID, previous, current)
+	s.attachAnswerKeyboard(ctx, user, botMessage.ID, current.messageIDs, index, len(versions), "")
+	s.answerCallback(ctx, callbackQuery.ID, "")
+
+	return nil
+}
+
+// showRegenerateModels swaps the answer keyboard for a model picker.
+func (s *UpdateService) showRegenerateModels(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+	botMessage *domain.Message,
+) error {
+	balance, err := s.storage.GetUserTokenBalance(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("can't get user token balance: %w", err)
+	}
+
+	activeSubscription, err := s.storage.GetActiveSubscriptionByUserID(ctx, user.ID)
+	hasActiveSubscription := err == nil && activeSubscription != nil
+
+	models := domain.AvailableModels()
+	buttons := make([][]domain.InlineKeyboardButton, 0, len(models)+1)
+	for _, model := range models {
+		buttons = append(buttons, []domain.InlineKeyboardButton{{
+			Text:         model.GetDisplayNameForUser(user.Language, hasActiveSubscription, *balance),
+			CallbackData: fmt.Sprintf("%s:%d:%s", callbackRegenerateWith, botMessage.ID, modelCallbackKey(model.ID)),
+		}})
+	}
+	buttons = append(buttons, []domain.InlineKeyboardButton{{
+		Text:         i18n.GetString(user.Language, i18n.ButtonBack),
+		CallbackData: fmt.Sprintf("%s:%d", callbackAnswerKeyboard, botMessage.ID),
+	}})
+
+	messageIDs, err := s.answerMessageIDs(ctx, botMessage.ID)
+	if err != nil {
+		return err
+	}
+	if len(messageIDs) > 0 {
+		keyboard := &domain.InlineKeyboard{Buttons: buttons}
+		if err = s.sender.EditMessageKeyboard(ctx, user.ExternalID, messageIDs[len(messageIDs)-1], keyboard); err != nil {
+			s.logger.WarnContext(ctx, "failed to show regenerate models", slog.String("error", err.Error()))
+		}
+	}
+
+	s.answerCallback(ctx, callbackQuery.ID, "")
+	return nil
+}
+
+// restoreAnswerKeyboard puts the regular answer keyboard back after the model picker.
+func (s *UpdateService) restoreAnswerKeyboard(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+	botMessage *domain.Message,
+) error {
+	versions, err := s.storage.GetMessageVersions(ctx, botMessage.ID)
+	if err != nil {
+		return fmt.Errorf("can't get message versions: %w", err)
+	}
+
+	messageIDs, err := s.answerMessageIDs(ctx, botMessage.ID)
+	if err != nil {
+		return err
+	}
+
+	index := currentVersionIndex(versions, botMessage.MessageType.Text)
+	s.attachAnswerKeyboard(ctx, user, botMessage.ID, messageIDs, index, max(len(versions), 1), "")
+	s.answerCallback(ctx, callbackQuery.ID, "")
+
+	return nil
+}
+
+// saveAnswerVersion stores a freshly generated answer as its first version and attaches the answer keyboard.
+func (s *UpdateService) saveAnswerVersion(
+	ctx context.Context,
+	user *domain.User,
+	botMessageID int64,
+	model string,
+	answer *shownAnswer,
+) {
+	_, err := s.storage.CreateMessageVersion(ctx, &domain.MessageVersion{
+		MessageID: botMessageID,
+		Text:      answer.text,
+		Model:     model,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to save message version",
+			slog.String("error", err.Error()),
+			slog.Int64("db_message_id", botMessageID))
+	}
+
+	s.attachAnswerKeyboard(ctx, user, botMessageID, answer.messageIDs, 0, 1, usageFooter(user, answer.usage))
+}
+
+// replaceShownAnswer brings the stored message and its Telegram mapping in line with the displayed answer.
+func (s *UpdateService) replaceShownAnswer(
+	ctx context.Context,
+	user *domain.User,
+	botMessageID int64,
+	previous *shownAnswer,
+	current *shownAnswer,
+) {
+	// UpdateMessages never removes chunks, so drop the ones the shorter answer doesn't need
+	for _, messageID := range previous.messageIDs {
+		if slices.Contains(current.messageIDs, messageID) {
+			continue
+		}
+		if err := s.sender.DeleteMessage(ctx, user.ExternalID, messageID); err != nil {
+			s.logger.WarnContext(ctx, "failed to delete leftover answer message",
+				slog.String("message_id", messageID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	if !slices.Equal(previous.messageIDs, current.messageIDs) && botMessageID <= int64(^uint32(0)>>1) {
+		if err := s.storage.DeleteForeignMessages(ctx, int32(botMessageID)); err != nil { //nolint:gosec
+			s.logger.WarnContext(ctx, "failed to delete foreign message mappings", slog.String("error", err.Error()))
+		}
+		s.saveForeignMessages(ctx, botMessageID, current.messageIDs)
+	}
+
+	if err := s.storage.UpdateMessageType(ctx, botMessageID, domain.MessageType{Text: current.text}); err != nil {
+		s.logger.WarnContext(ctx, "failed to update bot message text",
+			slog.String("error", err.Error()),
+			slog.Int64("db_message_id", botMessageID))
+	}
+}
+
+// attachAnswerKeyboard puts the regenerate buttons and the version pager under the last chunk of an answer.
+// A non-empty footer is shown above them as a label, e.g. the usage of a freshly generated answer.
+func (s *UpdateService) attachAnswerKeyboard(
+	ctx context.Context,
+	user *domain.User,
+	botMessageID int64,
+	messageIDs []string,
+	versionIndex int,
+	versionCount int,
+	footer string,
+) {
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	keyboard := answerKeyboard(user.Language, botMessageID, versionIndex, versionCount, footer)
+	if err := s.sender.EditMessageKeyboard(ctx, user.ExternalID, messageIDs[len(messageIDs)-1], keyboard); err != nil {
+		s.logger.WarnContext(ctx, "failed to attach answer keyboard", slog.String("error", err.Error()))
+	}
+}
+
+// answerKeyboard builds the keyboard under a bot answer: the footer label when there is one, a ‹ n/N › pager
+// when there are several versions, followed by the regenerate buttons.
+func answerKeyboard(
+	language string,
+	botMessageID int64,
+	versionIndex, versionCount int,
+	footer string,
+) *domain.InlineKeyboard {
+	var buttons [][]domain.InlineKeyboardButton
+
+	if footer != "" {
+		buttons = append(buttons, []domain.InlineKeyboardButton{{Text: footer, CallbackData: callbackNoop}})
+	}
+
+	if versionCount > 1 {
+		previousIndex := (versionIndex - 1 + versionCount) % versionCount
+		nextIndex := (versionIndex + 1) % versionCount
+		buttons = append(buttons, []domain.InlineKeyboardButton{
+			{Text: "‹", CallbackData: fmt.Sprintf("%s:%d:%d", callbackAnswerVersion, botMessageID, previousIndex)},
+			{Text: fmt.Sprintf("%d/%d", versionIndex+1, versionCount), CallbackData: callbackNoop},
+			{Text: "›", CallbackData: fmt.Sprintf("%s:%d:%d", callbackAnswerVersion, botMessageID, nextIndex)},
+		})
+	}
+
+	buttons = append(buttons, []domain.InlineKeyboardButton{
+		{
+			Text:         i18n.GetString(language, i18n.ButtonRegenerate),
+			CallbackData: fmt.Sprintf("%s:%d", callbackRegenerate, botMessageID),
+		},
+		{
+			Text:         i18n.GetString(language, i18n.ButtonRegenerateOtherModel),
+			CallbackData: fmt.Sprintf("%s:%d", callbackRegenerateModels, botMessageID),
+		},
+	})
+
+	return &domain.InlineKeyboard{Buttons: buttons}
+}
+
+// getAnswerMessage returns the user's bot message with the given ID or nil if it can't be regenerated.
+func (s *UpdateService) getAnswerMessage(
+	ctx context.Context,
+	user *domain.User,
+	messageID int64,
+) (*domain.Message, error) {
+	message, err := s.storage.GetMessageByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil //nolint:nilnil // Message was removed
+		}
+		return nil, fmt.Errorf("can't get message: %w", err)
+	}
+
+	if message.UserID != user.ID || message.SentBy != domain.MessageSenderBot || message.ConversationID == nil {
+		return nil, nil //nolint:nilnil // Not an answer of this user
+	}
+
+	return message, nil
+}
+
+// answerMessageIDs returns the Telegram messages that display a bot message, in order.
+func (s *UpdateService) answerMessageIDs(ctx context.Context, botMessageID int64) ([]string, error) {
+	if botMessageID > int64(^uint32(0)>>1) {
+		return nil, nil
+	}
+
+	foreignIDs, err := s.storage.GetForeignMessagesByMessageID(ctx, int32(botMessageID)) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("can't get answer messages: %w", err)
+	}
+
+	messageIDs := make([]string, len(foreignIDs))
+	for i, foreignID := range foreignIDs {
+		messageIDs[i] = strconv.Itoa(int(foreignID))
+	}
+
+	return messageIDs, nil
+}
+
+// storedMessageAttachments restores the attachments of an earlier user message for a repeated request
+// in the order they were sent. Images are passed by links to their uploads, PDFs by their data, which is
+// downloaded only now, and other documents by their extracted text.
+func (s *UpdateService) storedMessageAttachments(ctx context.Context, message *domain.Message) []completion.Part {
+	if s.fileStorage == nil {
+		return nil
+	}
+
+	var attachments []completion.Part
+	for _, file := range message.MessageType.Files {
+		if !file.IsPDF() && file.IsDocument() {
+			if part, ok := s.storedDocumentPart(ctx, file); ok {
+				attachments = append(attachments, part)
+			}
+			continue
+		}
+		if file.S3Name == "" {
+			continue
+		}
+
+		switch {
+		case file.IsImage():
+			imageURL, err := s.fileStorage.GetPreSignedURL(ctx, file.S3Name, time.Hour)
+			if err != nil {
+				s.logger.WarnContext(ctx, "failed to generate pre-signed URL", slog.String("error", err.Error()))
+				continue
+			}
+			attachments = append(attachments, completion.Part{
+				Type:     completion.PartTypeImage,
+				URL:      imageURL,
+				MimeType: file.MimeType,
+			})
+		case file.IsPDF():
+			data, err := s.fileStorage.Download(ctx, file.S3Name)
+			if err != nil {
+				s.logger.WarnContext(ctx, "failed to download PDF", slog.String("error", err.Error()))
+				continue
+			}
+			attachments = append(attachments, completion.Part{
+				Type:     completion.PartTypeFile,
+				MimeType: file.MimeType,
+				FileName: file.FileName,
+				Data:     data,
+			})
+		}
+	}
+
+	return attachments
+}
+
+// lockAnswer takes the user's processing lock. The returned function releases it and resumes the queue.
+func (s *UpdateService) lockAnswer(ctx context.Context, user *domain.User) (func(), bool) {
+	if err := s.queue.SetProcessing(ctx, user.ExternalID, processingLockTimeout); err != nil {
+		if errors.Is(err, queue.ErrAlreadyProcessing) {
+			return nil, false
+		}
+		s.logger.WarnContext(ctx, "failed to set processing lock",
+			slog.String("error", err.Error()))
+	}
+
+	return func() {
+		if clearErr := s.queue.ClearProcessing(ctx, user.ExternalID); clearErr != nil {
+			s.logger.ErrorContext(ctx, "failed to clear processing lock",
+				slog.String("error", clearErr.Error()))
+		}
+
+		go s.processQueuedMessages(context.Background(), user)
+	}, true
+}
+
+// answerCallback stops the loading indicator on the pressed button, optionally showing a short notice.
+func (s *UpdateService) answerCallback(ctx context.Context, callbackQueryID string, text string) {
+	if err := s.sender.AnswerCallbackQuery(ctx, callbackQueryID, text); err != nil {
+		s.logger.WarnContext(ctx, "failed to answer callback query", slog.String("error", err.Error()))
+	}
+}
+
+// messagesBefore returns the messages that precede the message with the given ID.
+func messagesBefore(messages []*domain.Message, messageID int64) []*domain.Message {
+	for i, message := range messages {
+		if message.ID == messageID {
+			return messages[:i]
+		}
+	}
+	return nil
+}
+
+// modelCallbackKey returns a short key of a model for callback data. Telegram limits callback data to 64 bytes,
+// model IDs can be longer and contain colons, and catalog indexes shift when the catalog is reloaded.
+func modelCallbackKey(modelID string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(modelID))
+	return strconv.FormatUint(uint64(hash.Sum32()), 36)
+}
+
+// modelByCallbackKey finds the available model with the given callback key, nil when there is none.
+func modelByCallbackKey(key string) *domain.ModelInfo {
+	for _, model := range domain.AvailableModels() {
+		if modelCallbackKey(model.ID) == key {
+			return &model
+		}
+	}
+	return nil
+}
+
+// currentVersionIndex finds which stored version is currently displayed.
+func currentVersionIndex(versions []*domain.MessageVersion, text string) int {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Text == text {
+			return i
+		}
+	}
+	return max(len(versions)-1, 0)
+}
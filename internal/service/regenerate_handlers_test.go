@@ -0,0 +1,341 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleCallbackQuery_Regenerate(t *testing.T) {
+	conversationID := int64(7)
+	user := &domain.User{
+		ID:                    1,
+		ExternalID:            "12345",
+		Language:              "en",
+		SelectedModel:         "google/gemini-2.5-flash",
+		CurrentConversationID: &conversationID,
+	}
+	userMessage := &domain.Message{
+		ID:             41,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "Tell me a joke"},
+		SentBy:         domain.MessageSenderUser,
+		ConversationID: &conversationID,
+	}
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "First joke"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+
+	tests := []struct {
+		name           string
+		data           string
+		setupMocks     func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockCompletion, *mocks.MockQueue)
+		expectedResult func(*testing.T, error)
+	}{
+		{
+			name: "regenerate with another model stores a new version",
+			// f6p6o9 is the callback key of openai/gpt-4o
+			data: "regen_with:42:f6p6o9",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+				mockStorage.EXPECT().
+					GetUserTokenBalance(gomock.Any(), int64(1)).
+					Return(&domain.TokenBalance{RegularBalance: 100, PremiumBalance: 100}, nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return([]*domain.Message{userMessage, botMessage}, nil)
+				mockStorage.EXPECT().
+					GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypePremium).
+					Return(int64(100), nil)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+					Return(nil)
+				mockStorage.EXPECT().
+					GetConversationByID(gomock.Any(), conversationID).
+					Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+				mockStorage.EXPECT().
+					GetConversationSummary(gomock.Any(), conversationID).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().
+					SubscribeCancel(gomock.Any(), "12345").
+					Return(make(chan struct{}), func() {}, nil)
+				mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+				mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("openai/gpt-4o")).
+					DoAndReturn(func(_ context.Context, req completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+						require.Len(t, req.Messages, 1)
+						assert.Equal(t, "Tell me a joke", req.Messages[0].Parts[0].Text)
+
+						tokens := make(chan completion.StreamToken, 1)
+						tokens <- completion.StreamToken{Content: "Second joke"}
+						close(tokens)
+						return tokens, nil
+					})
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First joke", "Second joke").
+					Return([]string{"100"}, nil)
+				mockStorage.EXPECT().
+					GetMessageVersions(gomock.Any(), int64(42)).
+					Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First joke"}}, nil)
+				mockStorage.EXPECT().
+					CreateMessageVersion(gomock.Any(), &domain.MessageVersion{
+						MessageID: 42,
+						Text:      "Second joke",
+						Model:     "openai/gpt-4o",
+					}).
+					Return(&domain.MessageVersion{ID: 2}, nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second joke"}).
+					Return(nil)
+				mockSender.EXPECT().
+					EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ string, keyboard *domain.InlineKeyboard) error {
+						require.Len(t, keyboard.Buttons, 2)
+						assert.Equal(t, "2/2", keyboard.Buttons[0][1].Text)
+						return nil
+					})
+				mockStorage.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+						require.NotNil(t, transaction.ModelUsed)
+						assert.Equal(t, "openai/gpt-4o", *transaction.ModelUsed)
+						assert.Equal(t, domain.TokenTypePremium, transaction.TokenType)
+						if transaction.TransactionType == domain.TransactionTypeReservationRelease {
+							assert.Positive(t, transaction.Amount)
+						} else {
+							assert.Negative(t, transaction.Amount)
+						}
+						return transaction, nil
+					}).
+					Times(3)
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "pager shows a stored version",
+			data: "answer_ver:42:0",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				shownMessage := *botMessage
+				shownMessage.MessageType = domain.MessageType{Text: "Second joke"}
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(&shownMessage, nil)
+				mockStorage.EXPECT().
+					GetMessageVersions(gomock.Any(), int64(42)).
+					Return([]*domain.MessageVersion{
+						{ID: 1, MessageID: 42, Text: "First joke"},
+						{ID: 2, MessageID: 42, Text: "Second joke"},
+					}, nil)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100, 101}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100", "101"}, "Second joke", "First joke").
+					Return([]string{"100"}, nil)
+				mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "101").Return(nil)
+				mockStorage.EXPECT().DeleteForeignMessages(gomock.Any(), int32(42)).Return(nil)
+				mockStorage.EXPECT().CreateForeignMessage(gomock.Any(), int32(42), int32(100)).Return(nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "First joke"}).
+					Return(nil)
+				mockSender.EXPECT().
+					EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ string, keyboard *domain.InlineKeyboard) error {
+						assert.Equal(t, "1/2", keyboard.Buttons[0][1].Text)
+						assert.Equal(t, "answer_ver:42:1", keyboard.Buttons[0][0].CallbackData)
+						return nil
+					})
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "model picker keys models by their IDs",
+			data: "regen_models:42",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				_ *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+				mockStorage.EXPECT().
+					GetUserTokenBalance(gomock.Any(), int64(1)).
+					Return(&domain.TokenBalance{RegularBalance: 100, PremiumBalance: 100}, nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ string, keyboard *domain.InlineKeyboard) error {
+						var callbackData []string
+						for _, row := range keyboard.Buttons {
+							// Telegram rejects keyboards with longer callback data
+							assert.LessOrEqual(t, len(row[0].CallbackData), 64)
+							callbackData = append(callbackData, row[0].CallbackData)
+						}
+						assert.Contains(t, callbackData, "regen_with:42:f6p6o9")
+						return nil
+					})
+				mockSender.EXPECT().AnswerCallbackQuery(gomock.Any(), "cb1", "").Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "regenerate with a model that is no longer offered",
+			data: "regen_with:42:gone",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				_ *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateModelUnavailable)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "regenerate while another answer is generated",
+			data: "regen:42",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				mockQueue *mocks.MockQueue,
+			) {
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+				mockStorage.EXPECT().
+					GetUserTokenBalance(gomock.Any(), int64(1)).
+					Return(&domain.TokenBalance{RegularBalance: 100}, nil)
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return([]*domain.Message{userMessage, botMessage}, nil)
+				mockQueue.EXPECT().
+					SetProcessing(gomock.Any(), "12345", gomock.Any()).
+					Return(queue.ErrAlreadyProcessing)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateBusy)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "answer of another user is rejected",
+			data: "regen:42",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				_ *mocks.MockCompletion,
+				_ *mocks.MockQueue,
+			) {
+				foreignMessage := *botMessage
+				foreignMessage.UserID = 2
+				mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(&foreignMessage, nil)
+				mockSender.EXPECT().
+					AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateUnavailable)).
+					Return(nil)
+			},
+			expectedResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			tt.setupMocks(mockStorage, mockSender, mockCompletion, mockQueue)
+
+			ctx := t.Context()
+			err := updateService.HandleCallbackQuery(ctx, domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           tt.data,
+			})
+
+			tt.expectedResult(t, err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const (
+	searchCommand = "/search"
+
+	// maxSearchResults is how many of the best matching messages are shown.
+	maxSearchResults = 5
+	// maxSearchQueryRunes limits the query echoed back to the user.
+	maxSearchQueryRunes = 100
+
+	searchDateLayout = "2006-01-02"
+)
+
+// isSearchCommand reports whether the message is the /search command, with or without a query.
+func isSearchCommand(text string) bool {
+	return text == searchCommand || strings.HasPrefix(text, searchCommand+" ")
+}
+
+// searchMessages finds the user's messages matching the query of the /search command and offers
+// to jump into the conversations they were found in.
+func (s *UpdateService) searchMessages(ctx context.Context, user *domain.User, update domain.Update) error {
+	query := strings.TrimSpace(strings.TrimPrefix(update.MessageText, searchCommand))
+	if query == "" {
+		_, err := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, i18n.SearchUsage))
+		return err
+	}
+
+	results, err := s.storage.SearchMessages(ctx, user.ID, user.Language, query, maxSearchResults)
+	if err != nil {
+		return fmt.Errorf("can't search messages: %w", err)
+	}
+
+	shownQuery := truncateRunes(query, maxSearchQueryRunes)
+	if len(results) == 0 {
+		_, err = s.sender.SendMessage(ctx, user.ExternalID,
+			fmt.Sprintf(i18n.GetString(user.Language, i18n.SearchNoResults), shownQuery))
+		return err
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(i18n.GetString(user.Language, i18n.SearchResults), shownQuery))
+	keyboard := &domain.InlineKeyboard{}
+	for i, result := range results {
+		author := "👤"
+		if result.SentBy == domain.MessageSenderBot {
+			author = "🤖"
+		}
+		fmt.Fprintf(&builder, "\n\n%d. %s %s · %s\n%s", i+1, author, result.ConversationName,
+			result.CreatedAt.Format(searchDateLayout), strings.Join(strings.Fields(result.Snippet), " "))
+
+		keyboard.Buttons = append(keyboard.Buttons, []domain.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%d. 💬 %s", i+1, result.ConversationName),
+			CallbackData: fmt.Sprintf("%s:%d", callbackOpenConversation, result.ConversationID),
+		}})
+	}
+
+	_, err = s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text:           builder.String(),
+		InlineKeyboard: keyboard,
+	})
+	return err
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleUpdate_Search(t *testing.T) {
+	user := &domain.User{
+		ID:          1,
+		ExternalID:  "12345",
+		Language:    "ru",
+		CurrentStep: domain.UserStateConversation,
+	}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func(*mocks.MockStorage, *mocks.MockSender)
+	}{
+		{
+			name: "matches are listed with buttons opening their conversations",
+			text: "/search  рецепт пасты ",
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					SearchMessages(gomock.Any(), int64(1), "ru", "рецепт пасты", 5).
+					Return([]*domain.MessageSearchResult{
+						{
+							MessageID:        42,
+							ConversationID:   7,
+							ConversationName: "Ужин",
+							SentBy:           domain.MessageSenderBot,
+							Snippet:          "Вот **рецепт**\nпасты с томатами",
+							CreatedAt:        time.Date(2025, 6, 19, 10, 30, 0, 0, time.UTC),
+						},
+						{
+							MessageID:        12,
+							ConversationID:   3,
+							ConversationName: "Кухня",
+							SentBy:           domain.MessageSenderUser,
+							Snippet:          "Нужен **рецепт**",
+							CreatedAt:        time.Date(2025, 6, 12, 8, 0, 0, 0, time.UTC),
+						},
+					}, nil)
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+						assert.Contains(t, content.Text, "«рецепт пасты»")
+						assert.Contains(t, content.Text, "1. 🤖 Ужин · 2025-06-19\nВот **рецепт** пасты с томатами")
+						assert.Contains(t, content.Text, "2. 👤 Кухня · 2025-06-12\nНужен **рецепт**")
+
+						require.NotNil(t, content.InlineKeyboard)
+						require.Len(t, content.InlineKeyboard.Buttons, 2)
+						assert.Equal(t, "1. 💬 Ужин", content.InlineKeyboard.Buttons[0][0].Text)
+						assert.Equal(t, "conv_open:7", content.InlineKeyboard.Buttons[0][0].CallbackData)
+						assert.Equal(t, "conv_open:3", content.InlineKeyboard.Buttons[1][0].CallbackData)
+						return "msg1", nil
+					})
+			},
+		},
+		{
+			name: "command without query explains usage",
+			text: "/search",
+			setupMocks: func(_ *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("ru", i18n.SearchUsage)).
+					Return("msg1", nil)
+			},
+		},
+		{
+			name: "nothing found",
+			text: "/search квазар",
+			setupMocks: func(mockStorage *mocks.MockStorage, mockSender *mocks.MockSender) {
+				mockStorage.EXPECT().
+					SearchMessages(gomock.Any(), int64(1), "ru", "квазар", 5).
+					Return(nil, nil)
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", "🔎 По запросу «квазар» ничего не найдено. Попробуйте другие слова или меньше слов.").
+					Return("msg1", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			tt.setupMocks(mockStorage, mockSender)
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "ru",
+				MessageText:    tt.text,
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -20,6 +20,21 @@ func (s *UpdateService) HandleSettingsState(ctx context.Context, user *domain.Us
 		return s.transitionToLanguageSelect(ctx, user)
 	}
 
+	// Check if user sent "persona" text
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonPersona) {
+		return s.transitionToDefaultPersonaSelect(ctx, user)
+	}
+
+	// Check if user sent "usage" text
+	if isUsageToggle(user.Language, update.MessageText) {
+		return s.toggleShowUsage(ctx, user)
+	}
+
+	// Check if user sent "voice replies" text, which is offered only with a speech service
+	if s.speech != nil && isVoiceRepliesToggle(user.Language, update.MessageText) {
+		return s.toggleVoiceReplies(ctx, user)
+	}
+
 	// Send settings with keyboard
 	return s.sendSettings(ctx, user, i18n.GetString(user.Language, i18n.SettingsTitle))
 }
@@ -40,6 +55,15 @@ func (s *UpdateService) HandleLanguageSelectState(ctx context.Context, user *dom
 }
 
 func (s *UpdateService) sendSettings(ctx context.Context, user *domain.User, text string) error {
+	usageRow := []domain.KeyboardButton{
+		{
+			Text: usageButtonText(user),
+		},
+	}
+	if s.speech != nil {
+		usageRow = append(usageRow, domain.KeyboardButton{Text: voiceRepliesButtonText(user)})
+	}
+
 	content := domain.MessageContent{
 		Text:         text,
 		IsPersistent: true,
@@ -49,7 +73,11 @@ func (s *UpdateService) sendSettings(ctx context.Context, user *domain.User, tex
 					{
 						Text: i18n.GetString(user.Language, i18n.ButtonLanguage),
 					},
+					{
+						Text: i18n.GetString(user.Language, i18n.ButtonPersona),
+					},
 				},
+				usageRow,
 				{
 					{
 						Text: i18n.GetString(user.Language, i18n.ButtonBackToMenu),
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+const (
+	stopCommand            = "/stop"
+	callbackStopGeneration = "stop_generation"
+)
+
+// errGenerationStopped is returned when the user stopped a generation before it produced any text.
+var errGenerationStopped = errors.New("generation stopped before any text")
+
+// generation is a completion request that the user can stop from any replica.
+type generation struct {
+	// ctx is cancelled when the user stops the generation and must be passed to the completion request.
+	ctx     context.Context
+	stopped atomic.Bool
+	once    sync.Once
+	release func()
+	// finished is closed once the generation is finished and nothing reads its tokens anymore.
+	finished chan struct{}
+}
+
+// isStopped reports whether the user stopped the generation.
+func (g *generation) isStopped() bool {
+	return g.stopped.Load()
+}
+
+// finish stops listening for cancel requests and removes the Stop button. It is safe to call more than once.
+func (g *generation) finish() {
+	g.once.Do(func() {
+		g.release()
+		close(g.finished)
+	})
+}
+
+// startGeneration listens for cancel requests of the user's generation and shows a Stop button while
+// the answer is generated. Without the queue the generation works as before, just can't be stopped.
+func (s *UpdateService) startGeneration(ctx context.Context, user *domain.User) *generation {
+	generationCtx, cancel := context.WithCancel(ctx)
+	g := &generation{ctx: generationCtx, release: cancel, finished: make(chan struct{})}
+
+	cancelled, unsubscribe, err := s.queue.SubscribeCancel(ctx, user.ExternalID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to subscribe to generation cancel", slog.String("error", err.Error()))
+		return g
+	}
+
+	go func() {
+		select {
+		case <-cancelled:
+			g.stopped.Store(true)
+			cancel()
+		case <-generationCtx.Done():
+		}
+	}()
+
+	// Edits of the streamed answer drop its keyboard, so the button lives in a message of its own
+	stopMessageID, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, domain.MessageContent{
+		Text: i18n.GetString(user.Language, i18n.StopGenerating),
+		InlineKeyboard: &domain.InlineKeyboard{
+			Buttons: [][]domain.InlineKeyboardButton{{{
+				Text:         i18n.GetString(user.Language, i18n.ButtonStop),
+				CallbackData: callbackStopGeneration,
+			}}},
+		},
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to send stop button", slog.String("error", err.Error()))
+	}
+
+	g.release = func() {
+		cancel()
+		unsubscribe()
+		if stopMessageID == "" {
+			return
+		}
+		if deleteErr := s.sender.DeleteMessage(ctx, user.ExternalID, stopMessageID); deleteErr != nil {
+			s.logger.WarnContext(ctx, "failed to delete stop button", slog.String("error", deleteErr.Error()))
+		}
+	}
+
+	return g
+}
+
+// stopGeneration asks the replica generating an answer for the user to stop it and keep what was generated.
+func (s *UpdateService) stopGeneration(ctx context.Context, user *domain.User) (bool, error) {
+	stopped, err := s.queue.PublishCancel(ctx, user.ExternalID)
+	if err != nil {
+		return false, err
+	}
+
+	if stopped {
+		s.logger.InfoContext(ctx, "generation stop requested", slog.String("user_id", user.ExternalID))
+	}
+
+	return stopped, nil
+}
+
+// handleStopCommand stops the answer that is being generated for the user.
+func (s *UpdateService) handleStopCommand(ctx context.Context, user *domain.User) error {
+	stopped, err := s.stopGeneration(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	text := i18n.GetString(user.Language, i18n.StopNothing)
+	if stopped {
+		text = i18n.GetString(user.Language, i18n.StopRequested)
+	}
+	_, err = s.sender.SendMessage(ctx, user.ExternalID, text)
+	return err
+}
+
+// handleStopCallback stops the answer from the Stop button.
+func (s *UpdateService) handleStopCallback(
+	ctx context.Context,
+	user *domain.User,
+	callbackQuery domain.CallbackQuery,
+) error {
+	stopped, err := s.stopGeneration(ctx, user)
+	if err != nil {
+		s.answerCallback(ctx, callbackQuery.ID, "")
+		return err
+	}
+
+	if !stopped {
+		s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.StopNothing))
+		return nil
+	}
+
+	s.answerCallback(ctx, callbackQuery.ID, i18n.GetString(user.Language, i18n.StopRequested))
+	return nil
+}
@@ -0,0 +1,264 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+func TestUpdateService_HandleUpdate_Stop(t *testing.T) {
+	tests := []struct {
+		name         string
+		stopped      bool
+		expectedText string
+	}{
+		{
+			name:         "running generation is stopped",
+			stopped:      true,
+			expectedText: i18n.GetString("en", i18n.StopRequested),
+		},
+		{
+			name:         "nothing to stop",
+			stopped:      false,
+			expectedText: i18n.GetString("en", i18n.StopNothing),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:          1,
+					ExternalID:  "12345",
+					Language:    "en",
+					CurrentStep: domain.UserStateConversation,
+				}, nil)
+			// The command must not be queued behind the generation it stops
+			mockQueue.EXPECT().PublishCancel(gomock.Any(), "12345").Return(tt.stopped, nil)
+			mockSender.EXPECT().SendMessage(gomock.Any(), "12345", tt.expectedText).Return("msg1", nil)
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    "/stop",
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateService_HandleCallbackQuery_StopRegeneration(t *testing.T) {
+	conversationID := int64(7)
+	user := &domain.User{
+		ID:                    1,
+		ExternalID:            "12345",
+		Language:              "en",
+		SelectedModel:         "google/gemini-2.5-flash",
+		CurrentConversationID: &conversationID,
+	}
+	userMessage := &domain.Message{
+		ID:             41,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "Tell me a joke"},
+		SentBy:         domain.MessageSenderUser,
+		ConversationID: &conversationID,
+	}
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "First joke"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+
+	// stoppedStream waits for the stop and then yields what the model generated before it
+	stoppedStream := func(partial string) func(
+		context.Context, completion.CompletionRequest,
+	) (<-chan completion.StreamToken, error) {
+		return func(ctx context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+			<-ctx.Done()
+
+			tokens := make(chan completion.StreamToken, 2)
+			if partial != "" {
+				tokens <- completion.StreamToken{Content: partial}
+			}
+			tokens <- completion.StreamToken{Error: ctx.Err()}
+			close(tokens)
+			return tokens, nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		setupMocks func(*mocks.MockStorage, *mocks.MockSender, *mocks.MockCompletion)
+	}{
+		{
+			name: "partial answer is kept and charged",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				mockSender *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+			) {
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(stoppedStream("Second"))
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				mockSender.EXPECT().
+					UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First joke", "Second").
+					Return([]string{"100"}, nil)
+				mockStorage.EXPECT().
+					UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second"}).
+					Return(nil)
+				mockStorage.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					Return(&domain.Transaction{}, nil).
+					Times(3)
+				mockStorage.EXPECT().
+					GetMessageVersions(gomock.Any(), int64(42)).
+					Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First joke"}}, nil)
+				mockStorage.EXPECT().
+					CreateMessageVersion(gomock.Any(), &domain.MessageVersion{
+						MessageID: 42,
+						Text:      "Second",
+						Model:     "google/gemini-2.5-flash",
+					}).
+					Return(&domain.MessageVersion{ID: 2}, nil)
+				mockSender.EXPECT().EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "answer stopped before any text keeps the previous one free of charge",
+			setupMocks: func(
+				mockStorage *mocks.MockStorage,
+				_ *mocks.MockSender,
+				mockCompletion *mocks.MockCompletion,
+			) {
+				mockCompletion.EXPECT().
+					CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+					DoAndReturn(stoppedStream(""))
+				mockStorage.EXPECT().
+					GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+					Return([]int32{100}, nil)
+				// The reservation is returned in full
+				mockStorage.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+						assert.NotEqual(t, domain.TransactionTypeMessageCost, transaction.TransactionType)
+						return transaction, nil
+					}).
+					Times(2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				DoAndReturn(func(_ context.Context, _ string) (*domain.User, error) {
+					userCopy := *user
+					return &userCopy, nil
+				})
+			mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalance(gomock.Any(), int64(1)).
+				Return(&domain.TokenBalance{RegularBalance: 100}, nil)
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return([]*domain.Message{userMessage, botMessage}, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(int64(100), nil)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockSender.EXPECT().
+				AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+
+			// The user presses Stop right away
+			cancelled := make(chan struct{})
+			close(cancelled)
+			unsubscribed := false
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(cancelled, func() { unsubscribed = true }, nil)
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+					require.NotNil(t, content.InlineKeyboard)
+					assert.Equal(t, "stop_generation", content.InlineKeyboard.Buttons[0][0].CallbackData)
+					return "stop1", nil
+				})
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+			tt.setupMocks(mockStorage, mockSender, mockCompletion)
+
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           "regen:42",
+			})
+
+			require.NoError(t, err)
+			assert.True(t, unsubscribed)
+		})
+	}
+}
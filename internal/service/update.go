@@ -11,10 +11,14 @@ import (
 
 	"github.com/vladimish/talk/internal/domain"
 	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/extraction"
 	"github.com/vladimish/talk/internal/port/filestorage"
+	"github.com/vladimish/talk/internal/port/imagegen"
 	"github.com/vladimish/talk/internal/port/queue"
 	"github.com/vladimish/talk/internal/port/sender"
+	"github.com/vladimish/talk/internal/port/speech"
 	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/port/transcription"
 	"github.com/vladimish/talk/pkg/i18n"
 )
 
@@ -32,6 +36,13 @@ type UpdateService struct {
 	completion  completion.Completion
 	queue       queue.Queue
 	fileStorage filestorage.FileStorage
+
+	imageGeneration  imagegen.ImageGeneration
+	extraction       extraction.Extraction
+	transcription    transcription.Transcription
+	speech           speech.Speech
+	speechPrice      domain.SpeechPrice
+	historyFileLimit int
 }
 
 func NewUpdateService(
@@ -49,6 +60,8 @@ func NewUpdateService(
 		completion:  completion,
 		queue:       queue,
 		fileStorage: fileStorage,
+
+		historyFileLimit: DefaultHistoryFileLimit,
 	}
 }
 
@@ -88,6 +101,45 @@ func (s *UpdateService) HandleUpdate(ctx context.Context, update domain.Update)
 		return err
 	}
 
+	// Voice messages are handled as the text they say
+	if update.Voice != nil {
+		var transcribed bool
+		update, transcribed, err = s.transcribeVoice(ctx, user, update)
+		if err != nil || !transcribed {
+			return err
+		}
+	}
+
+	// Stopping, forking and search work from any state, so they are handled before the message can be queued
+	if update.MessageText == stopCommand {
+		return s.handleStopCommand(ctx, user)
+	}
+
+	if update.MessageText == forkCommand {
+		return s.forkConversation(ctx, user, update)
+	}
+
+	if isSearchCommand(update.MessageText) {
+		return s.searchMessages(ctx, user, update)
+	}
+
+	// Data exports are imported in the background whatever state the user is in
+	if isConversationImport(update) {
+		return s.startConversationImport(ctx, user, update)
+	}
+	update = withJSONDocument(update)
+
+	// Albums arrive as an update per photo or file, they are answered once all of them are received
+	if update.MediaGroupID != "" {
+		return s.collectAlbum(ctx, user, update)
+	}
+
+	return s.queueOrProcessUpdate(ctx, user, update)
+}
+
+// queueOrProcessUpdate queues a conversation message while the previous one is being answered,
+// otherwise processes the update.
+func (s *UpdateService) queueOrProcessUpdate(ctx context.Context, user *domain.User, update domain.Update) error {
 	// Only queue messages in conversation state
 	if s.shouldQueueMessage(user, update) {
 		queued, queueErr := s.handleMessageQueueing(ctx, user, update)
@@ -123,6 +175,12 @@ func (s *UpdateService) processUpdate(ctx context.Context, user *domain.User, up
 		err = s.HandleLanguageSelectState(ctx, user, update)
 	case domain.UserStateProfile:
 		err = s.HandleProfileState(ctx, user, update)
+	case domain.UserStateDefaultPersonaSelect:
+		err = s.HandleDefaultPersonaSelectState(ctx, user, update)
+	case domain.UserStateConversationPersonaSelect:
+		err = s.HandleConversationPersonaSelectState(ctx, user, update)
+	case domain.UserStateConversationRename:
+		err = s.HandleConversationRenameState(ctx, user, update)
 	default:
 		// Default to menu state for unknown states
 		err = s.HandleMenuState(ctx, user, update)
@@ -174,8 +232,12 @@ func (s *UpdateService) handleConversationMessageWithConcatenation(
 		// Continue anyway
 	}
 
-	// If there's an ongoing generation, we'll cancel it by clearing the lock and adding to pending
+	// If there's an ongoing generation, stop it so the new message is answered together with the batch
 	if isGenerating {
+		if _, cancelErr := s.stopGeneration(ctx, user); cancelErr != nil {
+			s.logger.WarnContext(ctx, "failed to cancel ongoing generation",
+				slog.String("error", cancelErr.Error()))
+		}
 		if clearErr := s.queue.ClearGenerationLock(ctx, user.ExternalID); clearErr != nil {
 			s.logger.WarnContext(ctx, "failed to clear generation lock",
 				slog.String("error", clearErr.Error()))
@@ -326,34 +388,29 @@ func (s *UpdateService) combineMessages(messages []domain.Update) domain.Update
 		slog.Int("message_count", len(sortedMessages)),
 		slog.Any("message_ids", extractMessageIDs(sortedMessages)))
 
-	// Combine all message texts in sorted order
+	// Combine all message texts and files in sorted order
 	combinedText := ""
-	var combinedImageData []byte
-	var combinedImageMimeType string
-	var combinedPDFData []byte
-	var combinedPDFMimeType string
-	var combinedPDFFileName string
+	var combinedFiles []domain.File
 	var lastExternalMessageID int64
+	var replyToMessageID int
+	var quoteText string
 
-	for i, msg := range sortedMessages {
-		if i > 0 {
+	for _, msg := range sortedMessages {
+		// Album items without a caption add only their files
+		if combinedText != "" && msg.MessageText != "" {
 			combinedText += " " // Add space between messages
 		}
 		combinedText += msg.MessageText
 
-		// Use attachments from the last message that has them (in sorted order)
-		if len(msg.ImageData) > 0 {
-			combinedImageData = msg.ImageData
-			combinedImageMimeType = msg.ImageMimeType
-		}
-		if len(msg.PDFData) > 0 {
-			combinedPDFData = msg.PDFData
-			combinedPDFMimeType = msg.PDFMimeType
-			combinedPDFFileName = msg.PDFFileName
-		}
+		combinedFiles = append(combinedFiles, msg.Files...)
 		if msg.ExternalMessageID > 0 {
 			lastExternalMessageID = int64(msg.ExternalMessageID)
 		}
+		// Keep the reply of the first message that has one
+		if replyToMessageID == 0 && msg.ReplyToMessageID > 0 {
+			replyToMessageID = msg.ReplyToMessageID
+			quoteText = msg.QuoteText
+		}
 	}
 
 	// Create combined update using the first message as base
@@ -362,12 +419,10 @@ func (s *UpdateService) combineMessages(messages []domain.Update) domain.Update
 		ExternalUserID:    baseMsg.ExternalUserID,
 		UserLanguage:      baseMsg.UserLanguage,
 		MessageText:       combinedText,
-		ImageData:         combinedImageData,
-		ImageMimeType:     combinedImageMimeType,
-		PDFData:           combinedPDFData,
-		PDFMimeType:       combinedPDFMimeType,
-		PDFFileName:       combinedPDFFileName,
+		Files:             combinedFiles,
 		ExternalMessageID: int(lastExternalMessageID),
+		ReplyToMessageID:  replyToMessageID,
+		QuoteText:         quoteText,
 		ReceivedAt:        baseMsg.ReceivedAt, // Use the first message's timestamp
 	}
 }
@@ -388,7 +443,7 @@ func (s *UpdateService) createNewUserWithTokens(ctx context.Context, update doma
 		ExternalID:             update.ExternalUserID,
 		Language:               language,
 		CurrentStep:            domain.UserStateMenu,
-		SelectedModel:          "google/gemini-2.5-flash",
+		SelectedModel:          domain.DefaultModelID(),
 		ConversationListOffset: 0,
 		CreatedAt:              now,
 		UpdatedAt:              now,
@@ -450,8 +505,18 @@ func (s *UpdateService) HandleConversationState(ctx context.Context, user *domai
 		return s.handleSubscriptionBuyCallback(ctx, user)
 	}
 
+	// Check if user clicked persona button
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonPersona) {
+		return s.transitionToConversationPersonaSelect(ctx, user)
+	}
+
+	// Check if user clicked export button
+	if update.MessageText == i18n.GetString(user.Language, i18n.ButtonExport) {
+		return s.showExportFormats(ctx, user)
+	}
+
 	// Handle regular conversation message with concatenation
-	if update.MessageText != "" && update.MessageText != i18n.GetString(user.Language, i18n.ButtonBackToMenu) {
+	if update.HasPrompt() && update.MessageText != i18n.GetString(user.Language, i18n.ButtonBackToMenu) {
 		return s.handleConversationMessageWithConcatenation(ctx, user, update)
 	}
 
@@ -520,7 +585,7 @@ func (s *UpdateService) handleWebSearchToggle(ctx context.Context, user *domain.
 
 func (s *UpdateService) shouldQueueMessage(user *domain.User, update domain.Update) bool {
 	return user.CurrentStep == domain.UserStateConversation &&
-		update.MessageText != "" &&
+		update.HasPrompt() &&
 		update.MessageText != i18n.GetString(user.Language, i18n.ButtonBackToMenu)
 }
 
@@ -593,18 +658,85 @@ func (s *UpdateService) HandleCallbackQuery(ctx context.Context, callbackQuery d
 		return fmt.Errorf("can't get user for callback: %w", err)
 	}
 
+	// Buttons under bot answers carry arguments, so they are matched by prefix
+	if isAnswerCallback(callbackQuery.Data) {
+		return s.handleAnswerCallback(ctx, user, callbackQuery)
+	}
+
+	if isExportCallback(callbackQuery.Data) {
+		return s.handleExportCallback(ctx, user, callbackQuery)
+	}
+
+	if isOpenConversationCallback(callbackQuery.Data) {
+		return s.handleOpenConversationCallback(ctx, user, callbackQuery)
+	}
+
+	if isConversationActionCallback(callbackQuery.Data) {
+		return s.handleConversationActionCallback(ctx, user, callbackQuery)
+	}
+
 	// Handle callback based on data
 	switch callbackQuery.Data {
 	case "subscription_buy_monthly":
 		return s.handleSubscriptionBuyCallback(ctx, user)
 	case "back_to_menu":
 		return s.transitionToMenu(ctx, user)
+	case callbackStopGeneration:
+		return s.handleStopCallback(ctx, user, callbackQuery)
 	default:
 		s.logger.WarnContext(ctx, "unknown callback data", slog.String("data", callbackQuery.Data))
 		return nil
 	}
 }
 
+func (s *UpdateService) HandleEditedMessage(ctx context.Context, edit domain.EditedMessage) (err error) {
+	// Add panic recovery to prevent crashes during edit handling
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := debug.Stack()
+			s.logger.ErrorContext(ctx, "Panic occurred while handling edited message",
+				"panic", r,
+				"stack_trace", string(stackTrace),
+				"user_id", edit.ExternalUserID,
+				"message_id", edit.ExternalMessageID)
+
+			// Convert panic to error
+			err = fmt.Errorf("panic occurred while handling edited message: %v", r)
+		}
+	}()
+
+	// Get user for edit (don't create if not exists)
+	user, err := s.storage.GetUserByExternalUserID(ctx, edit.ExternalUserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("can't get user for edited message: %w", err)
+	}
+
+	if edit.MessageText == "" || edit.ExternalMessageID <= 0 || int64(edit.ExternalMessageID) > int64(^uint32(0)>>1) {
+		return nil
+	}
+
+	message, err := s.storage.GetMessageByForeignID(ctx, user.ID, int32(edit.ExternalMessageID)) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			// Menu buttons and messages from before the mapping existed are not stored
+			s.logger.DebugContext(ctx, "edited message is not part of a conversation",
+				slog.Int("message_id", edit.ExternalMessageID))
+			return nil
+		}
+		return fmt.Errorf("can't get edited message: %w", err)
+	}
+
+	if message.SentBy != domain.MessageSenderUser || message.ConversationID == nil ||
+		message.MessageType.Text == edit.MessageText {
+		return nil
+	}
+
+	return s.rerunFromEditedMessage(ctx, user, message, edit.MessageText)
+}
+
 func (s *UpdateService) HandlePreCheckoutQuery(ctx context.Context, query domain.PreCheckoutQuery) (err error) {
 	// Add panic recovery to prevent crashes during pre-checkout handling
 	defer func() {
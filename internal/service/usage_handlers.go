@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// saveUsage stores what generating the answer took when the provider reported it.
+func (s *UpdateService) saveUsage(
+	ctx context.Context,
+	user *domain.User,
+	botMessageID int64,
+	model string,
+	usage *completion.Usage,
+) {
+	if usage == nil {
+		return
+	}
+
+	_, err := s.storage.CreateMessageUsage(ctx, &domain.MessageUsage{
+		UserID:           user.ID,
+		MessageID:        &botMessageID,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		ReasoningTokens:  usage.ReasoningTokens,
+		Cost:             usage.Cost,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to save message usage",
+			slog.String("error", err.Error()),
+			slog.Int64("db_message_id", botMessageID))
+	}
+}
+
+// usageFooter describes what an answer took for the answer keyboard, or returns an empty string
+// when the user doesn't want to see it or the provider didn't report it.
+func usageFooter(user *domain.User, usage *completion.Usage) string {
+	if !user.ShowUsage || usage == nil {
+		return ""
+	}
+
+	var footer strings.Builder
+	footer.WriteString(fmt.Sprintf(i18n.GetString(user.Language, i18n.UsageFooter),
+		usage.PromptTokens, usage.CompletionTokens))
+	if usage.ReasoningTokens > 0 {
+		footer.WriteString(fmt.Sprintf(i18n.GetString(user.Language, i18n.UsageFooterReasoning), usage.ReasoningTokens))
+	}
+	if usage.Cost != nil {
+		footer.WriteString(" · $" + formatUSD(*usage.Cost))
+	}
+
+	return footer.String()
+}
+
+// formatUSD keeps two significant digits of the small amounts a single answer usually costs.
+func formatUSD(cost float64) string {
+	switch {
+	case cost >= 1:
+		return strconv.FormatFloat(cost, 'f', 2, 64)
+	case cost == 0:
+		return "0"
+	case cost < 0.0001:
+		return "<0.0001"
+	default:
+		return strconv.FormatFloat(cost, 'g', 2, 64)
+	}
+}
+
+// isUsageToggle reports whether the text is the settings button that shows or hides the usage.
+func isUsageToggle(language, text string) bool {
+	return text == i18n.GetString(language, i18n.ButtonUsageShown) ||
+		text == i18n.GetString(language, i18n.ButtonUsageHidden)
+}
+
+// toggleShowUsage shows or hides the tokens and cost under answers.
+func (s *UpdateService) toggleShowUsage(ctx context.Context, user *domain.User) error {
+	user.ShowUsage = !user.ShowUsage
+	if err := s.storage.UpdateUserShowUsage(ctx, user.ID, user.ShowUsage); err != nil {
+		return fmt.Errorf("can't update usage visibility: %w", err)
+	}
+
+	text := i18n.GetString(user.Language, i18n.UsageHidden)
+	if user.ShowUsage {
+		text = i18n.GetString(user.Language, i18n.UsageShown)
+	}
+
+	return s.sendSettings(ctx, user, text)
+}
+
+// usageButtonText shows whether the usage is displayed under answers.
+func usageButtonText(user *domain.User) string {
+	if user.ShowUsage {
+		return i18n.GetString(user.Language, i18n.ButtonUsageShown)
+	}
+	return i18n.GetString(user.Language, i18n.ButtonUsageHidden)
+}
+
+// profileUsage sums up what the user's answers took, or returns an empty string before the first answer.
+func (s *UpdateService) profileUsage(ctx context.Context, user *domain.User) string {
+	totals, err := s.storage.GetUserUsageTotals(ctx, user.ID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to get usage totals", slog.String("error", err.Error()))
+		return ""
+	}
+	if totals.Answers == 0 {
+		return ""
+	}
+
+	lines := []string{
+		i18n.GetString(user.Language, i18n.ProfileUsageTitle),
+		fmt.Sprintf(i18n.GetString(user.Language, i18n.ProfileUsageAnswers), totals.Answers),
+		fmt.Sprintf(i18n.GetString(user.Language, i18n.ProfileUsageTokens), totals.PromptTokens, totals.CompletionTokens),
+	}
+	if totals.ReasoningTokens > 0 {
+		lines = append(lines, fmt.Sprintf(i18n.GetString(user.Language, i18n.ProfileUsageReasoning),
+			totals.ReasoningTokens))
+	}
+	lines = append(lines, fmt.Sprintf(i18n.GetString(user.Language, i18n.ProfileUsageCost), formatUSD(totals.Cost)))
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,296 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleCallbackQuery_RegenerateUsage(t *testing.T) {
+	conversationID := int64(7)
+	userMessage := &domain.Message{
+		ID:             41,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "Tell me a joke"},
+		SentBy:         domain.MessageSenderUser,
+		ConversationID: &conversationID,
+	}
+	botMessage := &domain.Message{
+		ID:             42,
+		UserID:         1,
+		MessageType:    domain.MessageType{Text: "First joke"},
+		SentBy:         domain.MessageSenderBot,
+		ConversationID: &conversationID,
+	}
+
+	tests := []struct {
+		name           string
+		showUsage      bool
+		expectedFooter string
+	}{
+		{
+			name:           "usage is shown above the answer buttons",
+			showUsage:      true,
+			expectedFooter: "🧮 120 → 30 tokens (🧠 12) · $0.00042",
+		},
+		{
+			name:      "usage is hidden by default",
+			showUsage: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+					ShowUsage:             tt.showUsage,
+				}, nil)
+			mockStorage.EXPECT().GetMessageByID(gomock.Any(), int64(42)).Return(botMessage, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalance(gomock.Any(), int64(1)).
+				Return(&domain.TokenBalance{RegularBalance: 100}, nil)
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return([]*domain.Message{userMessage, botMessage}, nil)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(int64(100), nil)
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockSender.EXPECT().
+				AnswerCallbackQuery(gomock.Any(), "cb1", i18n.GetString("en", i18n.RegenerateStarted)).
+				Return(nil)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil)
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockSender.EXPECT().SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).Return("stop1", nil)
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+			mockCompletion.EXPECT().
+				CompleteStream(gomock.Any(), textOnlyRequest("google/gemini-2.5-flash")).
+				DoAndReturn(func(_ context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+					tokens := make(chan completion.StreamToken, 2)
+					tokens <- completion.StreamToken{Content: "Second joke"}
+					// The provider reports the usage in a chunk of its own after the content
+					tokens <- completion.StreamToken{Usage: &completion.Usage{
+						PromptTokens:     120,
+						CompletionTokens: 30,
+						ReasoningTokens:  12,
+						Cost:             pointer.To(0.00042),
+					}}
+					close(tokens)
+					return tokens, nil
+				})
+			mockStorage.EXPECT().
+				GetForeignMessagesByMessageID(gomock.Any(), int32(42)).
+				Return([]int32{100}, nil)
+			mockSender.EXPECT().
+				UpdateMessages(gomock.Any(), "12345", []string{"100"}, "First joke", "Second joke").
+				Return([]string{"100"}, nil)
+			mockStorage.EXPECT().
+				UpdateMessageType(gomock.Any(), int64(42), domain.MessageType{Text: "Second joke"}).
+				Return(nil)
+			mockStorage.EXPECT().
+				CreateMessageUsage(gomock.Any(), &domain.MessageUsage{
+					UserID:           1,
+					MessageID:        pointer.To(int64(42)),
+					Model:            "google/gemini-2.5-flash",
+					PromptTokens:     120,
+					CompletionTokens: 30,
+					ReasoningTokens:  12,
+					Cost:             pointer.To(0.00042),
+				}).
+				Return(&domain.MessageUsage{ID: 1}, nil)
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				Return(&domain.Transaction{}, nil).
+				Times(3)
+			mockStorage.EXPECT().
+				GetMessageVersions(gomock.Any(), int64(42)).
+				Return([]*domain.MessageVersion{{ID: 1, MessageID: 42, Text: "First joke"}}, nil)
+			mockStorage.EXPECT().CreateMessageVersion(gomock.Any(), gomock.Any()).Return(&domain.MessageVersion{ID: 2}, nil)
+			mockSender.EXPECT().
+				EditMessageKeyboard(gomock.Any(), "12345", "100", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ string, keyboard *domain.InlineKeyboard) error {
+					if tt.expectedFooter == "" {
+						require.Len(t, keyboard.Buttons, 2)
+						assert.Equal(t, "2/2", keyboard.Buttons[0][1].Text)
+						return nil
+					}
+
+					require.Len(t, keyboard.Buttons, 3)
+					assert.Equal(t, tt.expectedFooter, keyboard.Buttons[0][0].Text)
+					assert.Equal(t, "noop", keyboard.Buttons[0][0].CallbackData)
+					return nil
+				})
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			err := updateService.HandleCallbackQuery(t.Context(), domain.CallbackQuery{
+				ID:             "cb1",
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				Data:           "regen:42",
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateService_HandleSettingsState_ShowUsage(t *testing.T) {
+	tests := []struct {
+		name           string
+		showUsage      bool
+		buttonText     string
+		expectedText   string
+		expectedButton string
+	}{
+		{
+			name:           "usage is turned on",
+			showUsage:      false,
+			buttonText:     i18n.GetString("en", i18n.ButtonUsageHidden),
+			expectedText:   i18n.GetString("en", i18n.UsageShown),
+			expectedButton: i18n.GetString("en", i18n.ButtonUsageShown),
+		},
+		{
+			name:           "usage is turned off",
+			showUsage:      true,
+			buttonText:     i18n.GetString("en", i18n.ButtonUsageShown),
+			expectedText:   i18n.GetString("en", i18n.UsageHidden),
+			expectedButton: i18n.GetString("en", i18n.ButtonUsageHidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+
+			user := &domain.User{
+				ID:          1,
+				ExternalID:  "12345",
+				Language:    "en",
+				CurrentStep: domain.UserStateSettings,
+				ShowUsage:   tt.showUsage,
+			}
+
+			mockStorage.EXPECT().UpdateUserShowUsage(gomock.Any(), int64(1), !tt.showUsage).Return(nil)
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+					assert.Equal(t, tt.expectedText, content.Text)
+					require.NotNil(t, content.ReplyKeyboard)
+					assert.Equal(t, tt.expectedButton, content.ReplyKeyboard.Buttons[1][0].Text)
+					return "msg1", nil
+				})
+
+			err := updateService.HandleSettingsState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				MessageText:    tt.buttonText,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, !tt.showUsage, user.ShowUsage)
+		})
+	}
+}
+
+func TestUpdateService_HandleProfileState_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockSender := mocks.NewMockSender(ctrl)
+	mockCompletion := mocks.NewMockCompletion(ctrl)
+	mockQueue := mocks.NewMockQueue(ctrl)
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	logger := slog.Default()
+
+	updateService := service.NewUpdateService(
+		logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+	)
+
+	mockStorage.EXPECT().
+		GetUserTokenBalance(gomock.Any(), int64(1)).
+		Return(&domain.TokenBalance{RegularBalance: 100, PremiumBalance: 50}, nil)
+	mockStorage.EXPECT().
+		GetUserUsageTotals(gomock.Any(), int64(1)).
+		Return(&domain.UsageTotals{
+			Answers:          3,
+			PromptTokens:     1500,
+			CompletionTokens: 420,
+			Cost:             0.0123,
+		}, nil)
+	mockSender.EXPECT().
+		SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+			assert.Contains(t, content.Text, "Answers: 3")
+			assert.Contains(t, content.Text, "Tokens: 1500 in, 420 out")
+			assert.Contains(t, content.Text, "Cost: $0.012")
+			assert.NotContains(t, content.Text, "Reasoning")
+			return "msg1", nil
+		})
+
+	err := updateService.HandleProfileState(t.Context(), &domain.User{
+		ID:         1,
+		ExternalID: "12345",
+		Language:   "en",
+	}, domain.Update{ExternalUserID: "12345", MessageText: "profile"})
+
+	require.NoError(t, err)
+}
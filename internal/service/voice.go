@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/transcription"
+	"github.com/vladimish/talk/pkg/i18n"
+)
+
+// SetTranscription sets the speech to text service voice messages are transcribed with.
+// Without it voice messages are declined.
+func (s *UpdateService) SetTranscription(transcription transcription.Transcription) {
+	s.transcription = transcription
+}
+
+// transcribeVoice replaces the voice message of an update with its transcript, after the caption if there is
+// one, and shows the transcript to the user as a quote. It reports false when the voice message can't be
+// transcribed, the user has been told so.
+func (s *UpdateService) transcribeVoice(
+	ctx context.Context,
+	user *domain.User,
+	update domain.Update,
+) (domain.Update, bool, error) {
+	if s.transcription == nil {
+		return update, false, s.sendVoiceNotice(ctx, user, i18n.VoiceNotSupported)
+	}
+
+	if err := s.sender.SendTyping(ctx, user.ExternalID); err != nil {
+		s.logger.WarnContext(ctx, "failed to send typing indicator", slog.String("error", err.Error()))
+	}
+
+	var transcript string
+	if len(update.Voice.Data) > 0 {
+		var err error
+		transcript, err = s.transcription.Transcribe(
+			ctx, update.Voice.Data, update.Voice.MimeType, update.Voice.FileName,
+		)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to transcribe voice message", slog.String("error", err.Error()))
+		}
+	}
+	if transcript == "" {
+		return update, false, s.sendVoiceNotice(ctx, user, i18n.VoiceTranscriptionFailed)
+	}
+
+	// The user sees what was understood before the answer to it
+	content := domain.MessageContent{Text: quoteMarkdown(transcript)}
+	if update.ExternalMessageID > 0 {
+		voiceMessageID := int64(update.ExternalMessageID)
+		content.ReplyToMessageID = &voiceMessageID
+	}
+	if _, err := s.sender.SendMessageWithContent(ctx, user.ExternalID, content); err != nil {
+		s.logger.WarnContext(ctx, "failed to send voice transcript", slog.String("error", err.Error()))
+	}
+
+	if update.MessageText != "" {
+		transcript = update.MessageText + "\n\n" + transcript
+	}
+	update.MessageText = transcript
+	update.Voice = nil
+
+	return update, true, nil
+}
+
+func (s *UpdateService) sendVoiceNotice(ctx context.Context, user *domain.User, key string) error {
+	if _, err := s.sender.SendMessage(ctx, user.ExternalID, i18n.GetString(user.Language, key)); err != nil {
+		return fmt.Errorf("can't send voice message notice: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/speech"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+// maxSpeechRunes is the longest text spoken in a single voice message, speech APIs take about 4096 characters.
+const maxSpeechRunes = 4000
+
+var (
+	// speechCodeBlockPattern matches the code blocks of an answer, which aren't worth listening to.
+	speechCodeBlockPattern = regexp.MustCompile("(?s)```.*?```")
+	// speechLinkPattern matches markdown links, of which only the text is spoken.
+	speechLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// speechMarkupPattern matches emphasis, inline code, headings and quotes.
+	speechMarkupPattern = regexp.MustCompile("(?m)[*`~]+|^[ \t]*(#+|>)[ \t]*")
+)
+
+// speechBreaks are where a long answer is split into voice messages, in order of preference:
+// between paragraphs, sentences and words.
+var speechBreaks = [][]string{{"\n\n"}, {". ", "! ", "? ", "\n"}, {" "}}
+
+// SetSpeech sets the text to speech service answers are spoken with for users who turned voice replies on,
+// and what the spoken characters cost. Without it the voice replies setting isn't offered.
+func (s *UpdateService) SetSpeech(speech speech.Speech, price domain.SpeechPrice) {
+	s.speech = speech
+	s.speechPrice = price
+}
+
+// isVoiceRepliesToggle reports whether the text is the settings button that turns voice replies on or off.
+func isVoiceRepliesToggle(language, text string) bool {
+	return text == i18n.GetString(language, i18n.ButtonVoiceRepliesOn) ||
+		text == i18n.GetString(language, i18n.ButtonVoiceRepliesOff)
+}
+
+// toggleVoiceReplies turns sending answers as voice messages on or off.
+func (s *UpdateService) toggleVoiceReplies(ctx context.Context, user *domain.User) error {
+	user.VoiceReplies = !user.VoiceReplies
+	if err := s.storage.UpdateUserVoiceReplies(ctx, user.ID, user.VoiceReplies); err != nil {
+		return fmt.Errorf("can't update voice replies: %w", err)
+	}
+
+	text := i18n.GetString(user.Language, i18n.VoiceRepliesOff)
+	if user.VoiceReplies {
+		text = i18n.GetString(user.Language, i18n.VoiceRepliesOn)
+	}
+
+	return s.sendSettings(ctx, user, text)
+}
+
+// voiceRepliesButtonText shows whether answers are sent as voice messages.
+func voiceRepliesButtonText(user *domain.User) string {
+	if user.VoiceReplies {
+		return i18n.GetString(user.Language, i18n.ButtonVoiceRepliesOn)
+	}
+	return i18n.GetString(user.Language, i18n.ButtonVoiceRepliesOff)
+}
+
+// speakAnswer also sends an answer as voice messages, in reply to its first message, when the user turned
+// voice replies on. Long answers are spoken in several messages. The spoken characters are charged afterwards,
+// and nothing is spoken when the balance can't cover them.
+func (s *UpdateService) speakAnswer(ctx context.Context, user *domain.User, answer *shownAnswer) {
+	if s.speech == nil || !user.VoiceReplies || answer.stopped {
+		return
+	}
+
+	parts := splitSpeech(speechText(answer.text), maxSpeechRunes)
+	if len(parts) == 0 {
+		return
+	}
+
+	characters := 0
+	for _, part := range parts {
+		characters += utf8.RuneCountInString(part)
+	}
+	if !s.canPayForSpeech(ctx, user, characters) {
+		return
+	}
+
+	var replyToMessageID *int64
+	if len(answer.messageIDs) > 0 {
+		if messageID, err := strconv.ParseInt(answer.messageIDs[0], 10, 64); err == nil {
+			replyToMessageID = &messageID
+		}
+	}
+
+	spoken := 0
+	for _, part := range parts {
+		audio, err := s.speech.Synthesize(ctx, part)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to synthesize answer", slog.String("error", err.Error()))
+			break
+		}
+		// The speech is paid for once it's synthesized
+		spoken += utf8.RuneCountInString(part)
+
+		voice := domain.VoiceMessage{Data: audio, ReplyToMessageID: replyToMessageID}
+		if _, err = s.sender.SendVoice(ctx, user.ExternalID, voice); err != nil {
+			s.logger.WarnContext(ctx, "failed to send voice answer", slog.String("error", err.Error()))
+			break
+		}
+	}
+
+	if spoken > 0 {
+		s.chargeSpeech(ctx, user, spoken)
+	}
+}
+
+// canPayForSpeech checks that the balance covers speaking the characters.
+// It returns false when it doesn't and the user was notified.
+func (s *UpdateService) canPayForSpeech(ctx context.Context, user *domain.User, characters int) bool {
+	charge := s.speechPrice.PriceSpeech(characters)
+	balance, err := s.storage.GetUserTokenBalanceByType(ctx, user.ID, charge.TokenType)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to get user token balance", slog.String("error", err.Error()))
+		return false
+	}
+	if balance >= charge.Amount {
+		return true
+	}
+
+	insufficientTokensMsg := fmt.Sprintf(
+		i18n.GetString(user.Language, i18n.ProfileInsufficientTokens),
+		charge.Amount,
+		string(charge.TokenType),
+	)
+	if _, err = s.sender.SendMessage(ctx, user.ExternalID, insufficientTokensMsg); err != nil {
+		s.logger.WarnContext(ctx, "failed to send insufficient tokens message", slog.String("error", err.Error()))
+	}
+	return false
+}
+
+// chargeSpeech charges the spoken characters of an answer.
+func (s *UpdateService) chargeSpeech(ctx context.Context, user *domain.User, characters int) {
+	charge := s.speechPrice.PriceSpeech(characters)
+	model := s.speechPrice.Model
+
+	_, err := s.storage.CreateTransaction(ctx, &domain.Transaction{
+		UserID:          user.ID,
+		TokenType:       charge.TokenType,
+		Amount:          -charge.Amount, // Negative for debit
+		TransactionType: domain.TransactionTypeMessageCost,
+		ModelUsed:       &model,
+		Description:     pointer.To(charge.Description),
+		CreatedAt:       time.Now(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create speech cost transaction",
+			slog.String("error", err.Error()),
+			slog.String("model", model),
+			slog.Int64("amount", charge.Amount))
+	}
+}
+
+// speechText returns the text of a markdown answer that is worth speaking: without code blocks and markup.
+func speechText(markdown string) string {
+	text := speechCodeBlockPattern.ReplaceAllString(markdown, "")
+	text = speechLinkPattern.ReplaceAllString(text, "$1")
+	text = speechMarkupPattern.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// splitSpeech splits text into parts of at most limit characters, preferably between paragraphs or sentences.
+func splitSpeech(text string, limit int) []string {
+	var parts []string
+	for utf8.RuneCountInString(text) > limit {
+		window := string([]rune(text)[:limit])
+		cut := speechBreak(window)
+		parts = append(parts, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// speechBreak returns where the part of the speech that starts with window ends: after the last paragraph,
+// sentence or word in the second half of the window, otherwise at its end.
+func speechBreak(window string) int {
+	for _, separators := range speechBreaks {
+		cut := -1
+		for _, separator := range separators {
+			if i := strings.LastIndex(window, separator); i >= 0 {
+				cut = max(cut, i+len(separator))
+			}
+		}
+		if cut > len(window)/2 {
+			return cut
+		}
+	}
+	return len(window)
+}
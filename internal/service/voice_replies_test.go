@@ -0,0 +1,305 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/completion"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleConversationState_VoiceReplies(t *testing.T) {
+	conversationID := int64(7)
+	speechPrice := domain.SpeechPrice{Model: "tts-1", TokenType: domain.TokenTypeRegular, Rate: 3000}
+
+	tests := []struct {
+		name         string
+		voiceReplies bool
+		answer       string
+		balance      int64
+		// spoken are the parts of the answer synthesized into voice messages
+		spoken []string
+		// speechCharge is what the speech is charged, nil when nothing is spoken
+		speechCharge *domain.TokenCharge
+		// expectedNotice is sent when the balance doesn't cover the speech
+		expectedNotice string
+	}{
+		{
+			name:         "answer is spoken without markup",
+			voiceReplies: true,
+			answer:       "## Closures\n\nA **closure** captures variables.\n\n```go\nfunc() {}\n```",
+			balance:      100,
+			spoken:       []string{"Closures\n\nA closure captures variables."},
+			speechCharge: &domain.TokenCharge{
+				TokenType:   domain.TokenTypeRegular,
+				Amount:      1,
+				Description: "Speech cost: 39 characters",
+			},
+		},
+		{
+			name:         "long answer is spoken in parts split between sentences",
+			voiceReplies: true,
+			answer:       strings.Repeat("A closure captures variables. ", 150),
+			balance:      100,
+			spoken: []string{
+				strings.TrimSpace(strings.Repeat("A closure captures variables. ", 133)),
+				strings.TrimSpace(strings.Repeat("A closure captures variables. ", 17)),
+			},
+			speechCharge: &domain.TokenCharge{
+				TokenType:   domain.TokenTypeRegular,
+				Amount:      14,
+				Description: "Speech cost: 4498 characters",
+			},
+		},
+		{
+			name:         "voice replies are off",
+			answer:       "A closure captures variables.",
+			balance:      100,
+			voiceReplies: false,
+		},
+		{
+			name:         "answer is too long for the balance",
+			voiceReplies: true,
+			answer:       strings.Repeat("A closure captures variables. ", 40),
+			balance:      1,
+			expectedNotice: fmt.Sprintf(
+				i18n.GetString("en", i18n.ProfileInsufficientTokens), 4, "regular",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			mockSpeech := mocks.NewMockSpeech(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetSpeech(mockSpeech, speechPrice)
+
+			// Failing to batch the message makes it answered right away
+			mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+			mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+			mockQueue.EXPECT().
+				SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+				Return(errors.New("redis is down"))
+			mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+			mockQueue.EXPECT().
+				SubscribeCancel(gomock.Any(), "12345").
+				Return(make(chan struct{}), func() {}, nil)
+			mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+			mockQueue.EXPECT().
+				DequeueWithMetadata(gomock.Any(), "12345").
+				Return(nil, queue.ErrEmptyQueue).
+				AnyTimes()
+
+			history := []*domain.Message{{
+				ID:             41,
+				UserID:         1,
+				MessageType:    domain.MessageType{Text: "Hi"},
+				SentBy:         domain.MessageSenderUser,
+				ConversationID: &conversationID,
+			}}
+			mockStorage.EXPECT().
+				GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().
+				GetUserTokenBalanceByType(gomock.Any(), int64(1), domain.TokenTypeRegular).
+				Return(tt.balance, nil).
+				AnyTimes()
+			mockStorage.EXPECT().
+				GetMessagesByConversationID(gomock.Any(), conversationID).
+				Return(history, nil).
+				Times(2)
+			mockStorage.EXPECT().
+				CreateMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+					message.ID = 42
+					if message.SentBy == domain.MessageSenderBot {
+						message.ID = 43
+					}
+					return message, nil
+				}).
+				Times(2)
+			mockStorage.EXPECT().UpdateConversationTimestamp(gomock.Any(), conversationID).Return(nil).Times(2)
+			mockStorage.EXPECT().
+				GetConversationByID(gomock.Any(), conversationID).
+				Return(&domain.Conversation{ID: conversationID, UserID: 1}, nil).
+				AnyTimes()
+			mockStorage.EXPECT().
+				GetConversationSummary(gomock.Any(), conversationID).
+				Return(nil, storage.ErrNotFound)
+			mockStorage.EXPECT().CreateForeignMessage(gomock.Any(), gomock.Any(), int32(200)).Return(nil)
+			mockStorage.EXPECT().CreateMessageVersion(gomock.Any(), gomock.Any()).Return(&domain.MessageVersion{ID: 1}, nil)
+
+			mockSender.EXPECT().SendTyping(gomock.Any(), "12345").Return(nil).AnyTimes()
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+					if content.InlineKeyboard != nil {
+						return "stop1", nil
+					}
+					return "200", nil
+				}).
+				Times(2)
+			mockSender.EXPECT().DeleteMessage(gomock.Any(), "12345", "stop1").Return(nil)
+			mockSender.EXPECT().EditMessageKeyboard(gomock.Any(), "12345", "200", gomock.Any()).Return(nil)
+
+			mockCompletion.EXPECT().
+				CompleteStream(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ completion.CompletionRequest) (<-chan completion.StreamToken, error) {
+					tokens := make(chan completion.StreamToken, 1)
+					tokens <- completion.StreamToken{Content: tt.answer}
+					close(tokens)
+					return tokens, nil
+				})
+
+			var speechCharges []domain.TokenCharge
+			mockStorage.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+					if transaction.ModelUsed != nil && *transaction.ModelUsed == "tts-1" {
+						assert.Equal(t, domain.TransactionTypeMessageCost, transaction.TransactionType)
+						require.NotNil(t, transaction.Description)
+						speechCharges = append(speechCharges, domain.TokenCharge{
+							TokenType:   transaction.TokenType,
+							Amount:      -transaction.Amount,
+							Description: *transaction.Description,
+						})
+					}
+					return transaction, nil
+				}).
+				AnyTimes()
+
+			for i, part := range tt.spoken {
+				audio := []byte(fmt.Sprintf("OggS%d", i))
+				mockSpeech.EXPECT().Synthesize(gomock.Any(), part).Return(audio, nil)
+				mockSender.EXPECT().
+					SendVoice(gomock.Any(), "12345", domain.VoiceMessage{
+						Data:             audio,
+						ReplyToMessageID: pointer.To(int64(200)),
+					}).
+					Return("300", nil)
+			}
+			if tt.expectedNotice != "" {
+				mockSender.EXPECT().SendMessage(gomock.Any(), "12345", tt.expectedNotice).Return("msg1", nil)
+			}
+
+			user := &domain.User{
+				ID:                    1,
+				ExternalID:            "12345",
+				Language:              "en",
+				CurrentStep:           domain.UserStateConversation,
+				SelectedModel:         "google/gemini-2.5-flash",
+				CurrentConversationID: &conversationID,
+				VoiceReplies:          tt.voiceReplies,
+			}
+
+			err := updateService.HandleConversationState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				UserLanguage:   "en",
+				MessageText:    "What is a closure?",
+			})
+
+			require.NoError(t, err)
+			var expectedCharges []domain.TokenCharge
+			if tt.speechCharge != nil {
+				expectedCharges = []domain.TokenCharge{*tt.speechCharge}
+			}
+			assert.Equal(t, expectedCharges, speechCharges)
+		})
+	}
+}
+
+func TestUpdateService_HandleSettingsState_VoiceReplies(t *testing.T) {
+	tests := []struct {
+		name           string
+		voiceReplies   bool
+		buttonText     string
+		expectedText   string
+		expectedButton string
+	}{
+		{
+			name:           "voice replies are turned on",
+			voiceReplies:   false,
+			buttonText:     i18n.GetString("en", i18n.ButtonVoiceRepliesOff),
+			expectedText:   i18n.GetString("en", i18n.VoiceRepliesOn),
+			expectedButton: i18n.GetString("en", i18n.ButtonVoiceRepliesOn),
+		},
+		{
+			name:           "voice replies are turned off",
+			voiceReplies:   true,
+			buttonText:     i18n.GetString("en", i18n.ButtonVoiceRepliesOn),
+			expectedText:   i18n.GetString("en", i18n.VoiceRepliesOff),
+			expectedButton: i18n.GetString("en", i18n.ButtonVoiceRepliesOff),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			updateService.SetSpeech(mocks.NewMockSpeech(ctrl), domain.SpeechPrice{})
+
+			user := &domain.User{
+				ID:           1,
+				ExternalID:   "12345",
+				Language:     "en",
+				CurrentStep:  domain.UserStateSettings,
+				VoiceReplies: tt.voiceReplies,
+			}
+
+			mockStorage.EXPECT().UpdateUserVoiceReplies(gomock.Any(), int64(1), !tt.voiceReplies).Return(nil)
+			mockSender.EXPECT().
+				SendMessageWithContent(gomock.Any(), "12345", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, content domain.MessageContent) (string, error) {
+					assert.Equal(t, tt.expectedText, content.Text)
+					require.NotNil(t, content.ReplyKeyboard)
+					assert.Equal(t, tt.expectedButton, content.ReplyKeyboard.Buttons[1][1].Text)
+					return "msg1", nil
+				})
+
+			err := updateService.HandleSettingsState(t.Context(), user, domain.Update{
+				ExternalUserID: "12345",
+				MessageText:    tt.buttonText,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, !tt.voiceReplies, user.VoiceReplies)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vladimish/talk/internal/domain"
+	"github.com/vladimish/talk/internal/port/queue"
+	"github.com/vladimish/talk/internal/port/storage"
+	"github.com/vladimish/talk/internal/service"
+	"github.com/vladimish/talk/mocks"
+	"github.com/vladimish/talk/pkg/i18n"
+	"github.com/vladimish/talk/pkg/pointer"
+)
+
+func TestUpdateService_HandleUpdate_Voice(t *testing.T) {
+	conversationID := int64(7)
+	errStop := errors.New("stop after saving the user message")
+	voice := &domain.File{Data: []byte("OggS"), MimeType: "audio/ogg", FileName: "voice.ogg"}
+
+	tests := []struct {
+		name          string
+		caption       string
+		transcription bool
+		transcript    string
+		transcribeErr error
+		// expectedNotice is the message the user is told the voice message can't be answered with
+		expectedNotice string
+		expectedText   string
+	}{
+		{
+			name:          "transcript is quoted and answered",
+			transcription: true,
+			transcript:    "What is a closure?",
+			expectedText:  "What is a closure?",
+		},
+		{
+			name:          "caption goes before the transcript",
+			caption:       "Answer briefly",
+			transcription: true,
+			transcript:    "What is a closure?",
+			expectedText:  "Answer briefly\n\nWhat is a closure?",
+		},
+		{
+			name:           "failed transcription is reported",
+			transcription:  true,
+			transcribeErr:  errors.New("whisper is down"),
+			expectedNotice: i18n.GetString("en", i18n.VoiceTranscriptionFailed),
+		},
+		{
+			name:           "silence is reported",
+			transcription:  true,
+			expectedNotice: i18n.GetString("en", i18n.VoiceTranscriptionFailed),
+		},
+		{
+			name:           "voice messages are declined without transcription",
+			expectedNotice: i18n.GetString("en", i18n.VoiceNotSupported),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSender := mocks.NewMockSender(ctrl)
+			mockCompletion := mocks.NewMockCompletion(ctrl)
+			mockQueue := mocks.NewMockQueue(ctrl)
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			mockTranscription := mocks.NewMockTranscription(ctrl)
+			logger := slog.Default()
+
+			updateService := service.NewUpdateService(
+				logger, mockStorage, mockSender, mockCompletion, mockQueue, mockFileStorage,
+			)
+			if tt.transcription {
+				updateService.SetTranscription(mockTranscription)
+				mockSender.EXPECT().SendTyping(gomock.Any(), "12345").Return(nil)
+				mockTranscription.EXPECT().
+					Transcribe(gomock.Any(), voice.Data, "audio/ogg", "voice.ogg").
+					Return(tt.transcript, tt.transcribeErr)
+			}
+
+			mockStorage.EXPECT().
+				GetUserByExternalUserID(gomock.Any(), "12345").
+				Return(&domain.User{
+					ID:                    1,
+					ExternalID:            "12345",
+					Language:              "en",
+					CurrentStep:           domain.UserStateConversation,
+					SelectedModel:         "google/gemini-2.5-flash",
+					CurrentConversationID: &conversationID,
+				}, nil)
+
+			if tt.expectedNotice != "" {
+				mockSender.EXPECT().SendMessage(gomock.Any(), "12345", tt.expectedNotice).Return("msg1", nil)
+			} else {
+				mockSender.EXPECT().
+					SendMessageWithContent(gomock.Any(), "12345", domain.MessageContent{
+						Text:             "> " + tt.transcript,
+						ReplyToMessageID: pointer.To(int64(100)),
+					}).
+					Return("msg1", nil)
+
+				// The transcript is answered like a typed message
+				mockQueue.EXPECT().IsProcessing(gomock.Any(), "12345").Return(false, nil)
+				mockQueue.EXPECT().IsGenerating(gomock.Any(), "12345").Return(false, nil)
+				mockQueue.EXPECT().GetPendingMessages(gomock.Any(), "12345").Return(nil, queue.ErrEmptyQueue)
+				mockQueue.EXPECT().
+					SetPendingMessages(gomock.Any(), "12345", gomock.Any(), gomock.Any()).
+					Return(errors.New("redis is down"))
+				mockStorage.EXPECT().
+					GetActiveSubscriptionByUserID(gomock.Any(), int64(1)).
+					Return(nil, storage.ErrNotFound)
+				mockQueue.EXPECT().SetProcessing(gomock.Any(), "12345", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().
+					GetMessagesByConversationID(gomock.Any(), conversationID).
+					Return(nil, nil)
+				mockStorage.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, message *domain.Message) (*domain.Message, error) {
+						assert.Equal(t, tt.expectedText, message.MessageType.Text)
+						assert.Empty(t, message.MessageType.Files)
+						return nil, errStop
+					})
+				mockSender.EXPECT().
+					SendMessage(gomock.Any(), "12345", i18n.GetString("en", i18n.ErrorResponseGeneration)).
+					Return("msg2", nil)
+				mockQueue.EXPECT().ClearProcessing(gomock.Any(), "12345").Return(nil)
+				mockQueue.EXPECT().
+					DequeueWithMetadata(gomock.Any(), "12345").
+					Return(nil, queue.ErrEmptyQueue).
+					AnyTimes()
+			}
+
+			err := updateService.HandleUpdate(t.Context(), domain.Update{
+				ExternalUserID:    "12345",
+				UserLanguage:      "en",
+				MessageText:       tt.caption,
+				Voice:             voice,
+				ExternalMessageID: 100,
+			})
+
+			if tt.expectedNotice != "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errStop)
+		})
+	}
+}
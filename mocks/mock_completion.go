@@ -13,7 +13,6 @@ import (
 	context "context"
 	reflect "reflect"
 
-	domain "github.com/vladimish/talk/internal/domain"
 	completion "github.com/vladimish/talk/internal/port/completion"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,31 +41,16 @@ func (m *MockCompletion) EXPECT() *MockCompletionMockRecorder {
 }
 
 // CompleteStream mocks base method.
-func (m *MockCompletion) CompleteStream(ctx context.Context, model, systemPrompt string, messages []*domain.Message, currentImageURL string, webSearchEnabled bool) (<-chan completion.StreamToken, error) {
+func (m *MockCompletion) CompleteStream(ctx context.Context, req completion.CompletionRequest) (<-chan completion.StreamToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CompleteStream", ctx, model, systemPrompt, messages, currentImageURL, webSearchEnabled)
+	ret := m.ctrl.Call(m, "CompleteStream", ctx, req)
 	ret0, _ := ret[0].(<-chan completion.StreamToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CompleteStream indicates an expected call of CompleteStream.
-func (mr *MockCompletionMockRecorder) CompleteStream(ctx, model, systemPrompt, messages, currentImageURL, webSearchEnabled any) *gomock.Call {
+func (mr *MockCompletionMockRecorder) CompleteStream(ctx, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteStream", reflect.TypeOf((*MockCompletion)(nil).CompleteStream), ctx, model, systemPrompt, messages, currentImageURL, webSearchEnabled)
-}
-
-// CompleteStreamWithAttachments mocks base method.
-func (m *MockCompletion) CompleteStreamWithAttachments(ctx context.Context, model, systemPrompt string, messages []*domain.Message, imageAttachment, pdfAttachment *completion.FileAttachment, webSearchEnabled bool) (<-chan completion.StreamToken, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CompleteStreamWithAttachments", ctx, model, systemPrompt, messages, imageAttachment, pdfAttachment, webSearchEnabled)
-	ret0, _ := ret[0].(<-chan completion.StreamToken)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// CompleteStreamWithAttachments indicates an expected call of CompleteStreamWithAttachments.
-func (mr *MockCompletionMockRecorder) CompleteStreamWithAttachments(ctx, model, systemPrompt, messages, imageAttachment, pdfAttachment, webSearchEnabled any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteStreamWithAttachments", reflect.TypeOf((*MockCompletion)(nil).CompleteStreamWithAttachments), ctx, model, systemPrompt, messages, imageAttachment, pdfAttachment, webSearchEnabled)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteStream", reflect.TypeOf((*MockCompletion)(nil).CompleteStream), ctx, req)
 }
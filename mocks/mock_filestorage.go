@@ -54,6 +54,21 @@ func (mr *MockFileStorageMockRecorder) Delete(ctx, objectName any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileStorage)(nil).Delete), ctx, objectName)
 }
 
+// Download mocks base method.
+func (m *MockFileStorage) Download(ctx context.Context, objectName string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", ctx, objectName)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockFileStorageMockRecorder) Download(ctx, objectName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockFileStorage)(nil).Download), ctx, objectName)
+}
+
 // GetPreSignedURL mocks base method.
 func (m *MockFileStorage) GetPreSignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
 	m.ctrl.T.Helper()
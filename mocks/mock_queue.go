@@ -42,6 +42,21 @@ func (m *MockQueue) EXPECT() *MockQueueMockRecorder {
 	return m.recorder
 }
 
+// AddAlbumMessage mocks base method.
+func (m *MockQueue) AddAlbumMessage(ctx context.Context, userID, albumID string, update domain.Update, ttl time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAlbumMessage", ctx, userID, albumID, update, ttl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAlbumMessage indicates an expected call of AddAlbumMessage.
+func (mr *MockQueueMockRecorder) AddAlbumMessage(ctx, userID, albumID, update, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAlbumMessage", reflect.TypeOf((*MockQueue)(nil).AddAlbumMessage), ctx, userID, albumID, update, ttl)
+}
+
 // ClearGenerationLock mocks base method.
 func (m *MockQueue) ClearGenerationLock(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -173,6 +188,21 @@ func (mr *MockQueueMockRecorder) IsProcessing(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsProcessing", reflect.TypeOf((*MockQueue)(nil).IsProcessing), ctx, userID)
 }
 
+// PublishCancel mocks base method.
+func (m *MockQueue) PublishCancel(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishCancel", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishCancel indicates an expected call of PublishCancel.
+func (mr *MockQueueMockRecorder) PublishCancel(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCancel", reflect.TypeOf((*MockQueue)(nil).PublishCancel), ctx, userID)
+}
+
 // SetGenerationLock mocks base method.
 func (m *MockQueue) SetGenerationLock(ctx context.Context, userID string, ttl time.Duration) error {
 	m.ctrl.T.Helper()
@@ -214,3 +244,34 @@ func (mr *MockQueueMockRecorder) SetProcessing(ctx, userID, ttl any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProcessing", reflect.TypeOf((*MockQueue)(nil).SetProcessing), ctx, userID, ttl)
 }
+
+// SubscribeCancel mocks base method.
+func (m *MockQueue) SubscribeCancel(ctx context.Context, userID string) (<-chan struct{}, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeCancel", ctx, userID)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SubscribeCancel indicates an expected call of SubscribeCancel.
+func (mr *MockQueueMockRecorder) SubscribeCancel(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeCancel", reflect.TypeOf((*MockQueue)(nil).SubscribeCancel), ctx, userID)
+}
+
+// TakeAlbum mocks base method.
+func (m *MockQueue) TakeAlbum(ctx context.Context, userID, albumID string, length int) ([]domain.Update, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeAlbum", ctx, userID, albumID, length)
+	ret0, _ := ret[0].([]domain.Update)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeAlbum indicates an expected call of TakeAlbum.
+func (mr *MockQueueMockRecorder) TakeAlbum(ctx, userID, albumID, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeAlbum", reflect.TypeOf((*MockQueue)(nil).TakeAlbum), ctx, userID, albumID, length)
+}
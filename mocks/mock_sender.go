@@ -40,6 +40,20 @@ func (m *MockSender) EXPECT() *MockSenderMockRecorder {
 	return m.recorder
 }
 
+// AnswerCallbackQuery mocks base method.
+func (m *MockSender) AnswerCallbackQuery(ctx context.Context, callbackQueryID, text string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerCallbackQuery", ctx, callbackQueryID, text)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnswerCallbackQuery indicates an expected call of AnswerCallbackQuery.
+func (mr *MockSenderMockRecorder) AnswerCallbackQuery(ctx, callbackQueryID, text any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerCallbackQuery", reflect.TypeOf((*MockSender)(nil).AnswerCallbackQuery), ctx, callbackQueryID, text)
+}
+
 // AnswerPreCheckoutQuery mocks base method.
 func (m *MockSender) AnswerPreCheckoutQuery(ctx context.Context, preCheckoutQueryID string, ok bool, errorMessage string) error {
 	m.ctrl.T.Helper()
@@ -83,6 +97,35 @@ func (mr *MockSenderMockRecorder) DeleteMessage(ctx, externalUserID, messageID a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockSender)(nil).DeleteMessage), ctx, externalUserID, messageID)
 }
 
+// EditMessageKeyboard mocks base method.
+func (m *MockSender) EditMessageKeyboard(ctx context.Context, externalUserID, messageID string, keyboard *domain.InlineKeyboard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessageKeyboard", ctx, externalUserID, messageID, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessageKeyboard indicates an expected call of EditMessageKeyboard.
+func (mr *MockSenderMockRecorder) EditMessageKeyboard(ctx, externalUserID, messageID, keyboard any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessageKeyboard", reflect.TypeOf((*MockSender)(nil).EditMessageKeyboard), ctx, externalUserID, messageID, keyboard)
+}
+
+// SendDocument mocks base method.
+func (m *MockSender) SendDocument(ctx context.Context, externalUserID string, document domain.Document) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDocument", ctx, externalUserID, document)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDocument indicates an expected call of SendDocument.
+func (mr *MockSenderMockRecorder) SendDocument(ctx, externalUserID, document any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDocument", reflect.TypeOf((*MockSender)(nil).SendDocument), ctx, externalUserID, document)
+}
+
 // SendMessage mocks base method.
 func (m *MockSender) SendMessage(ctx context.Context, externalUserID, text string) (string, error) {
 	m.ctrl.T.Helper()
@@ -113,6 +156,21 @@ func (mr *MockSenderMockRecorder) SendMessageWithContent(ctx, externalUserID, co
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithContent", reflect.TypeOf((*MockSender)(nil).SendMessageWithContent), ctx, externalUserID, content)
 }
 
+// SendPhoto mocks base method.
+func (m *MockSender) SendPhoto(ctx context.Context, externalUserID string, photo domain.Photo) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPhoto", ctx, externalUserID, photo)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPhoto indicates an expected call of SendPhoto.
+func (mr *MockSenderMockRecorder) SendPhoto(ctx, externalUserID, photo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPhoto", reflect.TypeOf((*MockSender)(nil).SendPhoto), ctx, externalUserID, photo)
+}
+
 // SendTyping mocks base method.
 func (m *MockSender) SendTyping(ctx context.Context, externalUserID string) error {
 	m.ctrl.T.Helper()
@@ -127,6 +185,21 @@ func (mr *MockSenderMockRecorder) SendTyping(ctx, externalUserID any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTyping", reflect.TypeOf((*MockSender)(nil).SendTyping), ctx, externalUserID)
 }
 
+// SendVoice mocks base method.
+func (m *MockSender) SendVoice(ctx context.Context, externalUserID string, voice domain.VoiceMessage) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVoice", ctx, externalUserID, voice)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendVoice indicates an expected call of SendVoice.
+func (mr *MockSenderMockRecorder) SendVoice(ctx, externalUserID, voice any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVoice", reflect.TypeOf((*MockSender)(nil).SendVoice), ctx, externalUserID, voice)
+}
+
 // UpdateMessage mocks base method.
 func (m *MockSender) UpdateMessage(ctx context.Context, externalUserID, messageID, text string) ([]string, error) {
 	m.ctrl.T.Helper()
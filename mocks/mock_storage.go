@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	domain "github.com/vladimish/talk/internal/domain"
+	storage "github.com/vladimish/talk/internal/port/storage"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -99,6 +100,36 @@ func (mr *MockStorageMockRecorder) CreateMessage(ctx, message any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockStorage)(nil).CreateMessage), ctx, message)
 }
 
+// CreateMessageUsage mocks base method.
+func (m *MockStorage) CreateMessageUsage(ctx context.Context, usage *domain.MessageUsage) (*domain.MessageUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessageUsage", ctx, usage)
+	ret0, _ := ret[0].(*domain.MessageUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessageUsage indicates an expected call of CreateMessageUsage.
+func (mr *MockStorageMockRecorder) CreateMessageUsage(ctx, usage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessageUsage", reflect.TypeOf((*MockStorage)(nil).CreateMessageUsage), ctx, usage)
+}
+
+// CreateMessageVersion mocks base method.
+func (m *MockStorage) CreateMessageVersion(ctx context.Context, version *domain.MessageVersion) (*domain.MessageVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessageVersion", ctx, version)
+	ret0, _ := ret[0].(*domain.MessageVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessageVersion indicates an expected call of CreateMessageVersion.
+func (mr *MockStorageMockRecorder) CreateMessageVersion(ctx, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessageVersion", reflect.TypeOf((*MockStorage)(nil).CreateMessageVersion), ctx, version)
+}
+
 // CreatePayment mocks base method.
 func (m *MockStorage) CreatePayment(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +190,90 @@ func (mr *MockStorageMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStorage)(nil).CreateUser), ctx, user)
 }
 
+// DeleteConversation mocks base method.
+func (m *MockStorage) DeleteConversation(ctx context.Context, conversationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteConversation", ctx, conversationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteConversation indicates an expected call of DeleteConversation.
+func (mr *MockStorageMockRecorder) DeleteConversation(ctx, conversationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConversation", reflect.TypeOf((*MockStorage)(nil).DeleteConversation), ctx, conversationID)
+}
+
+// DeleteConversationSummary mocks base method.
+func (m *MockStorage) DeleteConversationSummary(ctx context.Context, conversationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteConversationSummary", ctx, conversationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteConversationSummary indicates an expected call of DeleteConversationSummary.
+func (mr *MockStorageMockRecorder) DeleteConversationSummary(ctx, conversationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConversationSummary", reflect.TypeOf((*MockStorage)(nil).DeleteConversationSummary), ctx, conversationID)
+}
+
+// DeleteForeignMessages mocks base method.
+func (m *MockStorage) DeleteForeignMessages(ctx context.Context, messageID int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteForeignMessages", ctx, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteForeignMessages indicates an expected call of DeleteForeignMessages.
+func (mr *MockStorageMockRecorder) DeleteForeignMessages(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteForeignMessages", reflect.TypeOf((*MockStorage)(nil).DeleteForeignMessages), ctx, messageID)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockStorage) DeleteMessage(ctx context.Context, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockStorageMockRecorder) DeleteMessage(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockStorage)(nil).DeleteMessage), ctx, messageID)
+}
+
+// DeleteMessageVersions mocks base method.
+func (m *MockStorage) DeleteMessageVersions(ctx context.Context, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessageVersions", ctx, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessageVersions indicates an expected call of DeleteMessageVersions.
+func (mr *MockStorageMockRecorder) DeleteMessageVersions(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessageVersions", reflect.TypeOf((*MockStorage)(nil).DeleteMessageVersions), ctx, messageID)
+}
+
+// DeleteMessagesAfter mocks base method.
+func (m *MockStorage) DeleteMessagesAfter(ctx context.Context, conversationID, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessagesAfter", ctx, conversationID, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessagesAfter indicates an expected call of DeleteMessagesAfter.
+func (mr *MockStorageMockRecorder) DeleteMessagesAfter(ctx, conversationID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessagesAfter", reflect.TypeOf((*MockStorage)(nil).DeleteMessagesAfter), ctx, conversationID, messageID)
+}
+
 // GetActiveSubscriptionByUserID mocks base method.
 func (m *MockStorage) GetActiveSubscriptionByUserID(ctx context.Context, userID int64) (*domain.Subscription, error) {
 	m.ctrl.T.Helper()
@@ -174,6 +289,36 @@ func (mr *MockStorageMockRecorder) GetActiveSubscriptionByUserID(ctx, userID any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveSubscriptionByUserID", reflect.TypeOf((*MockStorage)(nil).GetActiveSubscriptionByUserID), ctx, userID)
 }
 
+// GetArchivedConversationsByUserID mocks base method.
+func (m *MockStorage) GetArchivedConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchivedConversationsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchivedConversationsByUserID indicates an expected call of GetArchivedConversationsByUserID.
+func (mr *MockStorageMockRecorder) GetArchivedConversationsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchivedConversationsByUserID", reflect.TypeOf((*MockStorage)(nil).GetArchivedConversationsByUserID), ctx, userID)
+}
+
+// GetAttachmentsByMessageID mocks base method.
+func (m *MockStorage) GetAttachmentsByMessageID(ctx context.Context, messageID int64) ([]*domain.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachmentsByMessageID", ctx, messageID)
+	ret0, _ := ret[0].([]*domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachmentsByMessageID indicates an expected call of GetAttachmentsByMessageID.
+func (mr *MockStorageMockRecorder) GetAttachmentsByMessageID(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentsByMessageID", reflect.TypeOf((*MockStorage)(nil).GetAttachmentsByMessageID), ctx, messageID)
+}
+
 // GetConversationByID mocks base method.
 func (m *MockStorage) GetConversationByID(ctx context.Context, conversationID int64) (*domain.Conversation, error) {
 	m.ctrl.T.Helper()
@@ -189,6 +334,21 @@ func (mr *MockStorageMockRecorder) GetConversationByID(ctx, conversationID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationByID", reflect.TypeOf((*MockStorage)(nil).GetConversationByID), ctx, conversationID)
 }
 
+// GetConversationSummary mocks base method.
+func (m *MockStorage) GetConversationSummary(ctx context.Context, conversationID int64) (*domain.ConversationSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversationSummary", ctx, conversationID)
+	ret0, _ := ret[0].(*domain.ConversationSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversationSummary indicates an expected call of GetConversationSummary.
+func (mr *MockStorageMockRecorder) GetConversationSummary(ctx, conversationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationSummary", reflect.TypeOf((*MockStorage)(nil).GetConversationSummary), ctx, conversationID)
+}
+
 // GetConversationsByUserID mocks base method.
 func (m *MockStorage) GetConversationsByUserID(ctx context.Context, userID int64) ([]*domain.Conversation, error) {
 	m.ctrl.T.Helper()
@@ -219,6 +379,21 @@ func (mr *MockStorageMockRecorder) GetForeignMessageByMessageID(ctx, messageID a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForeignMessageByMessageID", reflect.TypeOf((*MockStorage)(nil).GetForeignMessageByMessageID), ctx, messageID)
 }
 
+// GetForeignMessagesByMessageID mocks base method.
+func (m *MockStorage) GetForeignMessagesByMessageID(ctx context.Context, messageID int32) ([]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForeignMessagesByMessageID", ctx, messageID)
+	ret0, _ := ret[0].([]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForeignMessagesByMessageID indicates an expected call of GetForeignMessagesByMessageID.
+func (mr *MockStorageMockRecorder) GetForeignMessagesByMessageID(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForeignMessagesByMessageID", reflect.TypeOf((*MockStorage)(nil).GetForeignMessagesByMessageID), ctx, messageID)
+}
+
 // GetLatestMessageByConversationID mocks base method.
 func (m *MockStorage) GetLatestMessageByConversationID(ctx context.Context, conversationID int64) (*domain.Message, error) {
 	m.ctrl.T.Helper()
@@ -234,6 +409,51 @@ func (mr *MockStorageMockRecorder) GetLatestMessageByConversationID(ctx, convers
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestMessageByConversationID", reflect.TypeOf((*MockStorage)(nil).GetLatestMessageByConversationID), ctx, conversationID)
 }
 
+// GetMessageByForeignID mocks base method.
+func (m *MockStorage) GetMessageByForeignID(ctx context.Context, userID int64, foreignMessageID int32) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageByForeignID", ctx, userID, foreignMessageID)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageByForeignID indicates an expected call of GetMessageByForeignID.
+func (mr *MockStorageMockRecorder) GetMessageByForeignID(ctx, userID, foreignMessageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageByForeignID", reflect.TypeOf((*MockStorage)(nil).GetMessageByForeignID), ctx, userID, foreignMessageID)
+}
+
+// GetMessageByID mocks base method.
+func (m *MockStorage) GetMessageByID(ctx context.Context, messageID int64) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageByID", ctx, messageID)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageByID indicates an expected call of GetMessageByID.
+func (mr *MockStorageMockRecorder) GetMessageByID(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageByID", reflect.TypeOf((*MockStorage)(nil).GetMessageByID), ctx, messageID)
+}
+
+// GetMessageVersions mocks base method.
+func (m *MockStorage) GetMessageVersions(ctx context.Context, messageID int64) ([]*domain.MessageVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageVersions", ctx, messageID)
+	ret0, _ := ret[0].([]*domain.MessageVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageVersions indicates an expected call of GetMessageVersions.
+func (mr *MockStorageMockRecorder) GetMessageVersions(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageVersions", reflect.TypeOf((*MockStorage)(nil).GetMessageVersions), ctx, messageID)
+}
+
 // GetMessagesByConversationID mocks base method.
 func (m *MockStorage) GetMessagesByConversationID(ctx context.Context, conversationID int64) ([]*domain.Message, error) {
 	m.ctrl.T.Helper()
@@ -279,6 +499,21 @@ func (mr *MockStorageMockRecorder) GetPaymentByInvoicePayload(ctx, invoicePayloa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByInvoicePayload", reflect.TypeOf((*MockStorage)(nil).GetPaymentByInvoicePayload), ctx, invoicePayload)
 }
 
+// GetSelectedModels mocks base method.
+func (m *MockStorage) GetSelectedModels(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSelectedModels", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSelectedModels indicates an expected call of GetSelectedModels.
+func (mr *MockStorageMockRecorder) GetSelectedModels(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSelectedModels", reflect.TypeOf((*MockStorage)(nil).GetSelectedModels), ctx)
+}
+
 // GetUserByExternalUserID mocks base method.
 func (m *MockStorage) GetUserByExternalUserID(ctx context.Context, id string) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -324,6 +559,78 @@ func (mr *MockStorageMockRecorder) GetUserTokenBalanceByType(ctx, userID, tokenT
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTokenBalanceByType", reflect.TypeOf((*MockStorage)(nil).GetUserTokenBalanceByType), ctx, userID, tokenType)
 }
 
+// GetUserUsageTotals mocks base method.
+func (m *MockStorage) GetUserUsageTotals(ctx context.Context, userID int64) (*domain.UsageTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserUsageTotals", ctx, userID)
+	ret0, _ := ret[0].(*domain.UsageTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserUsageTotals indicates an expected call of GetUserUsageTotals.
+func (mr *MockStorageMockRecorder) GetUserUsageTotals(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserUsageTotals", reflect.TypeOf((*MockStorage)(nil).GetUserUsageTotals), ctx, userID)
+}
+
+// InTransaction mocks base method.
+func (m *MockStorage) InTransaction(ctx context.Context, fn func(storage.Storage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTransaction indicates an expected call of InTransaction.
+func (mr *MockStorageMockRecorder) InTransaction(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTransaction", reflect.TypeOf((*MockStorage)(nil).InTransaction), ctx, fn)
+}
+
+// RestoreConversation mocks base method.
+func (m *MockStorage) RestoreConversation(ctx context.Context, conversationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreConversation", ctx, conversationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreConversation indicates an expected call of RestoreConversation.
+func (mr *MockStorageMockRecorder) RestoreConversation(ctx, conversationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreConversation", reflect.TypeOf((*MockStorage)(nil).RestoreConversation), ctx, conversationID)
+}
+
+// SearchMessages mocks base method.
+func (m *MockStorage) SearchMessages(ctx context.Context, userID int64, language, query string, limit int) ([]*domain.MessageSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessages", ctx, userID, language, query, limit)
+	ret0, _ := ret[0].([]*domain.MessageSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessages indicates an expected call of SearchMessages.
+func (mr *MockStorageMockRecorder) SearchMessages(ctx, userID, language, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockStorage)(nil).SearchMessages), ctx, userID, language, query, limit)
+}
+
+// UpdateConversationArchived mocks base method.
+func (m *MockStorage) UpdateConversationArchived(ctx context.Context, conversationID int64, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConversationArchived", ctx, conversationID, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConversationArchived indicates an expected call of UpdateConversationArchived.
+func (mr *MockStorageMockRecorder) UpdateConversationArchived(ctx, conversationID, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConversationArchived", reflect.TypeOf((*MockStorage)(nil).UpdateConversationArchived), ctx, conversationID, archived)
+}
+
 // UpdateConversationName mocks base method.
 func (m *MockStorage) UpdateConversationName(ctx context.Context, conversationID int64, name string) error {
 	m.ctrl.T.Helper()
@@ -338,6 +645,34 @@ func (mr *MockStorageMockRecorder) UpdateConversationName(ctx, conversationID, n
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConversationName", reflect.TypeOf((*MockStorage)(nil).UpdateConversationName), ctx, conversationID, name)
 }
 
+// UpdateConversationPinned mocks base method.
+func (m *MockStorage) UpdateConversationPinned(ctx context.Context, conversationID int64, pinned bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConversationPinned", ctx, conversationID, pinned)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConversationPinned indicates an expected call of UpdateConversationPinned.
+func (mr *MockStorageMockRecorder) UpdateConversationPinned(ctx, conversationID, pinned any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConversationPinned", reflect.TypeOf((*MockStorage)(nil).UpdateConversationPinned), ctx, conversationID, pinned)
+}
+
+// UpdateConversationSystemPrompt mocks base method.
+func (m *MockStorage) UpdateConversationSystemPrompt(ctx context.Context, conversationID int64, systemPrompt *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConversationSystemPrompt", ctx, conversationID, systemPrompt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConversationSystemPrompt indicates an expected call of UpdateConversationSystemPrompt.
+func (mr *MockStorageMockRecorder) UpdateConversationSystemPrompt(ctx, conversationID, systemPrompt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConversationSystemPrompt", reflect.TypeOf((*MockStorage)(nil).UpdateConversationSystemPrompt), ctx, conversationID, systemPrompt)
+}
+
 // UpdateConversationTimestamp mocks base method.
 func (m *MockStorage) UpdateConversationTimestamp(ctx context.Context, conversationID int64) error {
 	m.ctrl.T.Helper()
@@ -352,6 +687,20 @@ func (mr *MockStorageMockRecorder) UpdateConversationTimestamp(ctx, conversation
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConversationTimestamp", reflect.TypeOf((*MockStorage)(nil).UpdateConversationTimestamp), ctx, conversationID)
 }
 
+// UpdateMessageType mocks base method.
+func (m *MockStorage) UpdateMessageType(ctx context.Context, messageID int64, messageType domain.MessageType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMessageType", ctx, messageID, messageType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMessageType indicates an expected call of UpdateMessageType.
+func (mr *MockStorageMockRecorder) UpdateMessageType(ctx, messageID, messageType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMessageType", reflect.TypeOf((*MockStorage)(nil).UpdateMessageType), ctx, messageID, messageType)
+}
+
 // UpdatePaymentStatus mocks base method.
 func (m *MockStorage) UpdatePaymentStatus(ctx context.Context, paymentID int64, status domain.PaymentStatus, telegramChargeID, providerChargeID *string) (*domain.Payment, error) {
 	m.ctrl.T.Helper()
@@ -424,6 +773,20 @@ func (mr *MockStorageMockRecorder) UpdateUserCurrentStep(ctx, userID, currentSte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserCurrentStep", reflect.TypeOf((*MockStorage)(nil).UpdateUserCurrentStep), ctx, userID, currentStep)
 }
 
+// UpdateUserDefaultSystemPrompt mocks base method.
+func (m *MockStorage) UpdateUserDefaultSystemPrompt(ctx context.Context, userID int64, systemPrompt *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDefaultSystemPrompt", ctx, userID, systemPrompt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserDefaultSystemPrompt indicates an expected call of UpdateUserDefaultSystemPrompt.
+func (mr *MockStorageMockRecorder) UpdateUserDefaultSystemPrompt(ctx, userID, systemPrompt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDefaultSystemPrompt", reflect.TypeOf((*MockStorage)(nil).UpdateUserDefaultSystemPrompt), ctx, userID, systemPrompt)
+}
+
 // UpdateUserLanguage mocks base method.
 func (m *MockStorage) UpdateUserLanguage(ctx context.Context, userID int64, language string) error {
 	m.ctrl.T.Helper()
@@ -452,6 +815,34 @@ func (mr *MockStorageMockRecorder) UpdateUserSelectedModel(ctx, userID, selected
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSelectedModel", reflect.TypeOf((*MockStorage)(nil).UpdateUserSelectedModel), ctx, userID, selectedModel)
 }
 
+// UpdateUserShowUsage mocks base method.
+func (m *MockStorage) UpdateUserShowUsage(ctx context.Context, userID int64, show bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserShowUsage", ctx, userID, show)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserShowUsage indicates an expected call of UpdateUserShowUsage.
+func (mr *MockStorageMockRecorder) UpdateUserShowUsage(ctx, userID, show any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserShowUsage", reflect.TypeOf((*MockStorage)(nil).UpdateUserShowUsage), ctx, userID, show)
+}
+
+// UpdateUserVoiceReplies mocks base method.
+func (m *MockStorage) UpdateUserVoiceReplies(ctx context.Context, userID int64, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserVoiceReplies", ctx, userID, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserVoiceReplies indicates an expected call of UpdateUserVoiceReplies.
+func (mr *MockStorageMockRecorder) UpdateUserVoiceReplies(ctx, userID, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserVoiceReplies", reflect.TypeOf((*MockStorage)(nil).UpdateUserVoiceReplies), ctx, userID, enabled)
+}
+
 // UpdateUserWebSearchEnabled mocks base method.
 func (m *MockStorage) UpdateUserWebSearchEnabled(ctx context.Context, userID int64, enabled bool) error {
 	m.ctrl.T.Helper()
@@ -465,3 +856,32 @@ func (mr *MockStorageMockRecorder) UpdateUserWebSearchEnabled(ctx, userID, enabl
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserWebSearchEnabled", reflect.TypeOf((*MockStorage)(nil).UpdateUserWebSearchEnabled), ctx, userID, enabled)
 }
+
+// UpdateUsersSelectedModel mocks base method.
+func (m *MockStorage) UpdateUsersSelectedModel(ctx context.Context, oldModel, newModel string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUsersSelectedModel", ctx, oldModel, newModel)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUsersSelectedModel indicates an expected call of UpdateUsersSelectedModel.
+func (mr *MockStorageMockRecorder) UpdateUsersSelectedModel(ctx, oldModel, newModel any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsersSelectedModel", reflect.TypeOf((*MockStorage)(nil).UpdateUsersSelectedModel), ctx, oldModel, newModel)
+}
+
+// UpsertConversationSummary mocks base method.
+func (m *MockStorage) UpsertConversationSummary(ctx context.Context, summary *domain.ConversationSummary) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertConversationSummary", ctx, summary)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertConversationSummary indicates an expected call of UpsertConversationSummary.
+func (mr *MockStorageMockRecorder) UpsertConversationSummary(ctx, summary any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertConversationSummary", reflect.TypeOf((*MockStorage)(nil).UpsertConversationSummary), ctx, summary)
+}
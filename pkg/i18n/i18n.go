@@ -27,6 +27,7 @@ const (
 	ConversationStartedWebSearchOn  = "conversation.started_web_search_on"
 	ConversationResumedWebSearchOff = "conversation.resumed_web_search_off"
 	ConversationResumedWebSearchOn  = "conversation.resumed_web_search_on"
+	ConversationUnavailable         = "conversation.unavailable"
 
 	// Settings messages.
 	SettingsTitle         = "settings.title"
@@ -43,16 +44,8 @@ const (
 	ModelUpdateSuccess     = "model.update_success"
 	ModelImageNotSupported = "model.image_not_supported"
 	ModelPDFNotSupported   = "model.pdf_not_supported"
-
-	// Model names (for internationalization).
-	ModelGeminiFlash   = "model.gemini_flash"
-	ModelGPT4o         = "model.gpt4o"
-	ModelGPT4oMini     = "model.gpt4o_mini"
-	ModelClaude4Sonnet = "model.claude4_sonnet"
-	ModelGeminiPro     = "model.gemini_pro"
-	ModelO3Mini        = "model.o3_mini"
-	ModelDeepSeekChat  = "model.deepseek_chat"
-	ModelDeepSeekR1    = "model.deepseek_r1"
+	ModelImageLimit        = "model.image_limit"
+	ModelPDFLimit          = "model.pdf_limit"
 
 	// Queue messages.
 	QueueMessageQueued = "queue.message_queued"
@@ -82,6 +75,119 @@ const (
 	SubscriptionActiveInfo   = "subscription.active_info"
 	SubscriptionExpired      = "subscription.expired"
 
+	// Persona messages.
+	ButtonPersona                  = "button.persona"
+	ButtonPersonaReset             = "button.persona_reset"
+	PersonaSelectTitle             = "persona.select_title"
+	PersonaConversationSelectTitle = "persona.conversation_select_title"
+	PersonaUpdated                 = "persona.updated"
+	PersonaResetDone               = "persona.reset_done"
+	PersonaTooLong                 = "persona.too_long"
+	PersonaAssistant               = "persona.assistant"
+	PersonaProgrammer              = "persona.programmer"
+	PersonaTranslator              = "persona.translator"
+	PersonaEditor                  = "persona.editor"
+	PersonaTeacher                 = "persona.teacher"
+	PersonaConcise                 = "persona.concise"
+
+	// Regenerate messages.
+	ButtonRegenerate           = "button.regenerate"
+	ButtonRegenerateOtherModel = "button.regenerate_other_model"
+	ButtonBack                 = "button.back"
+	RegenerateBusy             = "regenerate.busy"
+	RegenerateUnavailable      = "regenerate.unavailable"
+	RegenerateModelUnavailable = "regenerate.model_unavailable"
+	RegenerateStarted          = "regenerate.started"
+
+	// Edit messages.
+	EditHistoryTrimmed = "edit.history_trimmed"
+	EditBusy           = "edit.busy"
+	EditImagesAnswer   = "edit.images_answer"
+	EditFailed         = "edit.failed"
+
+	// Fork messages.
+	ForkUsage       = "fork.usage"
+	ForkUnavailable = "fork.unavailable"
+	ForkCreated     = "fork.created"
+
+	// Export messages.
+	ButtonExport       = "button.export"
+	ExportChooseFormat = "export.choose_format"
+	ExportEmpty        = "export.empty"
+	ExportUnavailable  = "export.unavailable"
+	ExportCaption      = "export.caption"
+
+	// Import messages.
+	ImportInvalid  = "import.invalid"
+	ImportEmpty    = "import.empty"
+	ImportProgress = "import.progress"
+	ImportFinished = "import.finished"
+	ImportSkipped  = "import.skipped"
+
+	// Search messages.
+	SearchUsage     = "search.usage"
+	SearchNoResults = "search.no_results"
+	SearchResults   = "search.results"
+
+	// Conversation management messages.
+	ButtonManageConversations   = "button.manage_conversations"
+	ButtonArchivedConversations = "button.archived_conversations"
+	ButtonOpen                  = "button.open"
+	ButtonRename                = "button.rename"
+	ButtonPin                   = "button.pin"
+	ButtonUnpin                 = "button.unpin"
+	ButtonArchive               = "button.archive"
+	ButtonUnarchive             = "button.unarchive"
+	ButtonDelete                = "button.delete"
+	ButtonUndo                  = "button.undo"
+	ManageChooseConversation    = "manage.choose_conversation"
+	ManageArchivedList          = "manage.archived_list"
+	ManageNoArchived            = "manage.no_archived"
+	ManageConversation          = "manage.conversation"
+	ManagePinned                = "manage.pinned"
+	ManageUnpinned              = "manage.unpinned"
+	ManageArchived              = "manage.archived"
+	ManageUnarchived            = "manage.unarchived"
+	ManageDeleted               = "manage.deleted"
+	ManageRestored              = "manage.restored"
+	ManageRenamePrompt          = "manage.rename_prompt"
+	ManageRenamed               = "manage.renamed"
+	ManageNameInvalid           = "manage.name_invalid"
+
+	// Stop messages.
+	StopGenerating = "stop.generating"
+	ButtonStop     = "stop.button"
+	StopRequested  = "stop.requested"
+	StopNothing    = "stop.nothing"
+
+	// Usage messages.
+	UsageFooter           = "usage.footer"
+	UsageFooterReasoning  = "usage.footer_reasoning"
+	ButtonUsageShown      = "usage.button_shown"
+	ButtonUsageHidden     = "usage.button_hidden"
+	UsageShown            = "usage.shown"
+	UsageHidden           = "usage.hidden"
+	ProfileUsageTitle     = "usage.profile_title"
+	ProfileUsageAnswers   = "usage.profile_answers"
+	ProfileUsageTokens    = "usage.profile_tokens"
+	ProfileUsageReasoning = "usage.profile_reasoning"
+	ProfileUsageCost      = "usage.profile_cost"
+
+	// Model fallback messages.
+	ModelFallbackUsed = "model.fallback_used"
+
+	// Voice messages.
+	VoiceNotSupported        = "voice.not_supported"
+	VoiceTranscriptionFailed = "voice.transcription_failed"
+	ButtonVoiceRepliesOn     = "voice.button_replies_on"
+	ButtonVoiceRepliesOff    = "voice.button_replies_off"
+	VoiceRepliesOn           = "voice.replies_on"
+	VoiceRepliesOff          = "voice.replies_off"
+
+	// Document messages.
+	DocumentUnreadable = "document.unreadable"
+	DocumentTruncated  = "document.truncated"
+
 	// Language names (for language selection).
 	LangEnglish    = "lang.english"
 	LangSpanish    = "lang.spanish"
@@ -169,6 +275,7 @@ var Strings = map[string]map[string]string{
 		ConversationStartedWebSearchOn:  "🗣️ Conversation started! Send me a message and I'll respond. Web search is ON (costs 1 premium token per query).",
 		ConversationResumedWebSearchOff: "🗣️ Conversation resumed! Send me a message and I'll respond. Web search is OFF.",
 		ConversationResumedWebSearchOn:  "🗣️ Conversation resumed! Send me a message and I'll respond. Web search is ON (costs 1 premium token per query).",
+		ConversationUnavailable:         "This conversation is no longer available.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Settings. Choose an option:",
@@ -185,16 +292,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Model updated successfully!",
 		ModelImageNotSupported: "❌ The selected model does not support image inputs. Please choose a different model or send a text message.",
 		ModelPDFNotSupported:   "❌ The selected model does not support PDF inputs. Please choose a different model or send a text message.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Fast & efficient for quick responses)",
-		ModelGPT4o:         "🧠 GPT-4o (Most capable for complex tasks)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Balanced speed & performance)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Creative writing & analysis)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Long documents & context)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Advanced reasoning model)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Research & coding)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Deep reasoning & logic)",
+		ModelImageLimit:        "❌ %s takes up to %d images in one message. Please send fewer images or choose a different model.",
+		ModelPDFLimit:          "❌ %s takes up to %d PDF files in one message. Please send fewer files or choose a different model.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Your message has been queued (position: %d). I'll process it after finishing the current response.",
@@ -241,6 +340,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Reset to default",
+		PersonaSelectTitle:             "🎭 Your default system prompt:\n\n%s\n\nChoose a preset persona or send your own system prompt as a message:",
+		PersonaConversationSelectTitle: "🎭 System prompt for this conversation:\n\n%s\n\nChoose a preset persona, send your own system prompt as a message, or reset to use your default:",
+		PersonaUpdated:                 "✅ System prompt updated!",
+		PersonaResetDone:               "✅ System prompt reset to default.",
+		PersonaTooLong:                 "❌ The system prompt is too long (maximum %d characters).",
+		PersonaAssistant:               "🤝 Assistant",
+		PersonaProgrammer:              "💻 Programmer",
+		PersonaTranslator:              "🌍 Translator",
+		PersonaEditor:                  "✍️ Editor",
+		PersonaTeacher:                 "🎓 Teacher",
+		PersonaConcise:                 "⚡ Concise",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Regenerate",
+		ButtonRegenerateOtherModel: "🤖 Other model",
+		ButtonBack:                 "🔙 Back",
+		RegenerateBusy:             "⏳ Please wait until the current answer is finished",
+		RegenerateUnavailable:      "This answer can no longer be regenerated",
+		RegenerateModelUnavailable: "This model is not available for you",
+		RegenerateStarted:          "🔄 Regenerating...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Message edited. The conversation now continues from it, %d later messages were removed from its history.",
+		EditBusy:           "⏳ The answer can't be updated while another one is being generated. Edit the message again when it is finished.",
+		EditImagesAnswer:   "🎨 Answers with images can't be updated. Send the changed prompt as a new message to get new images.",
+		EditFailed:         "⚠️ The answer couldn't be generated, so the edit wasn't applied. Edit the message again to retry.",
+
+		// Fork
+		ForkUsage:       "🌿 To fork a conversation, reply to one of its messages with /fork. A new conversation will continue from that message.",
+		ForkUnavailable: "🌿 This message can't be forked. Reply with /fork to a message of one of your conversations.",
+		ForkCreated:     "🌿 Forked «%s»: %d messages were copied to a new conversation. The original conversation stays unchanged.",
+
+		// Export
+		ButtonExport:       "📤 Export",
+		ExportChooseFormat: "📤 Choose a format to export «%s»:",
+		ExportEmpty:        "📤 There is nothing to export yet, the conversation has no messages.",
+		ExportUnavailable:  "This conversation can't be exported.",
+		ExportCaption:      "📤 «%s», %d messages. Attachment links are valid for 7 days.",
+
+		// Import
+		ImportInvalid:  "📥 This file can't be imported. Send the conversations.json file from a ChatGPT data export.",
+		ImportEmpty:    "📥 There are no conversations with messages in this file.",
+		ImportProgress: "📥 Importing conversations: %d of %d…",
+		ImportFinished: "✅ Imported %d conversations with %d messages. Pick one below to continue it, all of them are also in the conversation list.",
+		ImportSkipped:  "⚠️ %d conversations couldn't be imported.",
+
+		// Search
+		SearchUsage:     "🔎 To search your conversations, send /search with the words to find, e.g. /search pasta recipe. Put a phrase in quotes to find it exactly, and add -word to skip messages with a word.",
+		SearchNoResults: "🔎 Nothing was found for «%s». Try other words or fewer of them.",
+		SearchResults:   "🔎 Best matches for «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Manage",
+		ButtonArchivedConversations: "🗄 Archived",
+		ButtonOpen:                  "💬 Open",
+		ButtonRename:                "✏️ Rename",
+		ButtonPin:                   "📌 Pin",
+		ButtonUnpin:                 "📍 Unpin",
+		ButtonArchive:               "🗄 Archive",
+		ButtonUnarchive:             "📤 Unarchive",
+		ButtonDelete:                "🗑 Delete",
+		ButtonUndo:                  "↩️ Undo",
+		ManageChooseConversation:    "🛠 Choose a conversation to rename, pin, archive or delete:",
+		ManageArchivedList:          "🗄 Archived conversations. Choose one to open or unarchive it:",
+		ManageNoArchived:            "🗄 There are no archived conversations.",
+		ManageConversation:          "🛠 «%s»\n\nWhat do you want to do with this conversation?",
+		ManagePinned:                "📌 «%s» is pinned to the top of the list.",
+		ManageUnpinned:              "📍 «%s» is unpinned.",
+		ManageArchived:              "🗄 «%s» is archived. You can find it under 🗄 Archived.",
+		ManageUnarchived:            "📤 «%s» is back in the conversation list.",
+		ManageDeleted:               "🗑 «%s» is deleted.",
+		ManageRestored:              "↩️ «%s» is restored.",
+		ManageRenamePrompt:          "✏️ Send a new name for «%s».",
+		ManageRenamed:               "✏️ The conversation is renamed to «%s».",
+		ManageNameInvalid:           "✏️ The name must be from 1 to %d characters long. Send another one.",
+
+		// Stop
+		StopGenerating: "⏳ Generating the answer…",
+		ButtonStop:     "⏹ Stop",
+		StopRequested:  "⏹ Stopping the answer. The part generated so far is kept.",
+		StopNothing:    "Nothing is being generated right now.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d tokens",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Usage: shown",
+		ButtonUsageHidden:     "🧮 Usage: hidden",
+		UsageShown:            "🧮 Answers now show the tokens and cost they took.",
+		UsageHidden:           "🧮 Answers no longer show the tokens and cost they took.",
+		ProfileUsageTitle:     "🧮 Usage:",
+		ProfileUsageAnswers:   "Answers: %d",
+		ProfileUsageTokens:    "Tokens: %d in, %d out",
+		ProfileUsageReasoning: "Reasoning tokens: %d",
+		ProfileUsageCost:      "Cost: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s is unavailable right now, %s answers instead.",
+		VoiceNotSupported:        "❌ Voice messages aren't supported right now. Please type your message.",
+		VoiceTranscriptionFailed: "❌ Couldn't recognize the voice message. Please try again or type your message.",
+		ButtonVoiceRepliesOn:     "🔊 Voice replies: on",
+		ButtonVoiceRepliesOff:    "🔇 Voice replies: off",
+		VoiceRepliesOn:           "🔊 Answers are now also sent as voice messages.",
+		VoiceRepliesOff:          "🔇 Answers are no longer sent as voice messages.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Couldn't read %s, so it was left out of the message.",
+		DocumentTruncated:  "✂️ %s is too long: only its first %d of %d characters were read.",
 	},
 	"es": {
 		// Buttons
@@ -268,6 +478,7 @@ var Strings = map[string]map[string]string{
 		ConversationStartedWebSearchOn:  "🗣️ ¡Conversación iniciada! Envíame un mensaje y te responderé. Búsqueda web está ACTIVADA (cuesta 1 token premium por consulta).",
 		ConversationResumedWebSearchOff: "🗣️ ¡Conversación reanudada! Envíame un mensaje y te responderé. Búsqueda web está DESACTIVADA.",
 		ConversationResumedWebSearchOn:  "🗣️ ¡Conversación reanudada! Envíame un mensaje y te responderé. Búsqueda web está ACTIVADA (cuesta 1 token premium por consulta).",
+		ConversationUnavailable:         "Esta conversación ya no está disponible.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Configuración. Elige una opción:",
@@ -284,16 +495,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ ¡Modelo actualizado exitosamente!",
 		ModelImageNotSupported: "❌ El modelo seleccionado no admite imágenes. Por favor elige un modelo diferente o envía un mensaje de texto.",
 		ModelPDFNotSupported:   "❌ El modelo seleccionado no admite archivos PDF. Por favor elige un modelo diferente o envía un mensaje de texto.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Rápido y eficiente para respuestas ágiles)",
-		ModelGPT4o:         "🧠 GPT-4o (Más capaz para tareas complejas)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Velocidad y rendimiento equilibrados)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Escritura creativa y análisis)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Documentos largos y contexto)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Modelo de razonamiento avanzado)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Investigación y programación)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Razonamiento profundo y lógica)",
+		ModelImageLimit:        "❌ %s admite hasta %d imágenes en un mensaje. Por favor envía menos imágenes o elige un modelo diferente.",
+		ModelPDFLimit:          "❌ %s admite hasta %d archivos PDF en un mensaje. Por favor envía menos archivos o elige un modelo diferente.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Tu mensaje ha sido puesto en cola (posición: %d). Lo procesaré después de terminar la respuesta actual.",
@@ -340,6 +543,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Restablecer",
+		PersonaSelectTitle:             "🎭 Tu prompt de sistema predeterminado:\n\n%s\n\nElige una persona predefinida o envía tu propio prompt de sistema como mensaje:",
+		PersonaConversationSelectTitle: "🎭 Prompt de sistema de esta conversación:\n\n%s\n\nElige una persona predefinida, envía tu propio prompt de sistema como mensaje o restablécelo para usar el predeterminado:",
+		PersonaUpdated:                 "✅ ¡Prompt de sistema actualizado!",
+		PersonaResetDone:               "✅ Prompt de sistema restablecido al predeterminado.",
+		PersonaTooLong:                 "❌ El prompt de sistema es demasiado largo (máximo %d caracteres).",
+		PersonaAssistant:               "🤝 Asistente",
+		PersonaProgrammer:              "💻 Programador",
+		PersonaTranslator:              "🌍 Traductor",
+		PersonaEditor:                  "✍️ Editor",
+		PersonaTeacher:                 "🎓 Profesor",
+		PersonaConcise:                 "⚡ Conciso",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Regenerar",
+		ButtonRegenerateOtherModel: "🤖 Otro modelo",
+		ButtonBack:                 "🔙 Atrás",
+		RegenerateBusy:             "⏳ Espera a que termine la respuesta actual",
+		RegenerateUnavailable:      "Esta respuesta ya no se puede regenerar",
+		RegenerateModelUnavailable: "Este modelo no está disponible para ti",
+		RegenerateStarted:          "🔄 Regenerando...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Mensaje editado. La conversación continúa ahora desde él, se eliminaron %d mensajes posteriores de su historial.",
+		EditBusy:           "⏳ La respuesta no se puede actualizar mientras se genera otra. Vuelve a editar el mensaje cuando termine.",
+		EditImagesAnswer:   "🎨 Las respuestas con imágenes no se pueden actualizar. Envía el prompt modificado como un mensaje nuevo para obtener imágenes nuevas.",
+		EditFailed:         "⚠️ No se pudo generar la respuesta, así que la edición no se aplicó. Vuelve a editar el mensaje para intentarlo de nuevo.",
+
+		// Fork
+		ForkUsage:       "🌿 Para bifurcar una conversación, responde a uno de sus mensajes con /fork. Una nueva conversación continuará desde ese mensaje.",
+		ForkUnavailable: "🌿 Este mensaje no se puede bifurcar. Responde con /fork a un mensaje de una de tus conversaciones.",
+		ForkCreated:     "🌿 Se bifurcó «%s»: se copiaron %d mensajes a una nueva conversación. La conversación original no cambia.",
+
+		// Export
+		ButtonExport:       "📤 Exportar",
+		ExportChooseFormat: "📤 Elige un formato para exportar «%s»:",
+		ExportEmpty:        "📤 Todavía no hay nada que exportar, la conversación no tiene mensajes.",
+		ExportUnavailable:  "Esta conversación no se puede exportar.",
+		ExportCaption:      "📤 «%s», mensajes: %d. Los enlaces de los adjuntos son válidos durante 7 días.",
+
+		// Import
+		ImportInvalid:  "📥 Este archivo no se puede importar. Envía el archivo conversations.json de una exportación de datos de ChatGPT.",
+		ImportEmpty:    "📥 No hay conversaciones con mensajes en este archivo.",
+		ImportProgress: "📥 Importando conversaciones: %d de %d…",
+		ImportFinished: "✅ Se importaron %d conversaciones con %d mensajes. Elige una abajo para continuarla, todas están también en la lista de conversaciones.",
+		ImportSkipped:  "⚠️ No se pudieron importar %d conversaciones.",
+
+		// Search
+		SearchUsage:     "🔎 Para buscar en tus conversaciones, envía /search con las palabras a buscar, p. ej. /search receta de pasta. Pon una frase entre comillas para encontrarla exacta y añade -palabra para omitir los mensajes con esa palabra.",
+		SearchNoResults: "🔎 No se encontró nada para «%s». Prueba con otras palabras o con menos.",
+		SearchResults:   "🔎 Mejores coincidencias para «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Gestionar",
+		ButtonArchivedConversations: "🗄 Archivadas",
+		ButtonOpen:                  "💬 Abrir",
+		ButtonRename:                "✏️ Renombrar",
+		ButtonPin:                   "📌 Fijar",
+		ButtonUnpin:                 "📍 Desfijar",
+		ButtonArchive:               "🗄 Archivar",
+		ButtonUnarchive:             "📤 Desarchivar",
+		ButtonDelete:                "🗑 Eliminar",
+		ButtonUndo:                  "↩️ Deshacer",
+		ManageChooseConversation:    "🛠 Elige una conversación para renombrarla, fijarla, archivarla o eliminarla:",
+		ManageArchivedList:          "🗄 Conversaciones archivadas. Elige una para abrirla o desarchivarla:",
+		ManageNoArchived:            "🗄 No hay conversaciones archivadas.",
+		ManageConversation:          "🛠 «%s»\n\n¿Qué quieres hacer con esta conversación?",
+		ManagePinned:                "📌 «%s» está fijada al principio de la lista.",
+		ManageUnpinned:              "📍 «%s» ya no está fijada.",
+		ManageArchived:              "🗄 «%s» está archivada. Puedes encontrarla en 🗄 Archivadas.",
+		ManageUnarchived:            "📤 «%s» vuelve a estar en la lista de conversaciones.",
+		ManageDeleted:               "🗑 «%s» se eliminó.",
+		ManageRestored:              "↩️ «%s» se restauró.",
+		ManageRenamePrompt:          "✏️ Envía un nuevo nombre para «%s».",
+		ManageRenamed:               "✏️ La conversación ahora se llama «%s».",
+		ManageNameInvalid:           "✏️ El nombre debe tener entre 1 y %d caracteres. Envía otro.",
+
+		// Stop
+		StopGenerating: "⏳ Generando la respuesta…",
+		ButtonStop:     "⏹ Detener",
+		StopRequested:  "⏹ Deteniendo la respuesta. Se conserva la parte generada hasta ahora.",
+		StopNothing:    "No se está generando nada en este momento.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d tokens",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Consumo: visible",
+		ButtonUsageHidden:     "🧮 Consumo: oculto",
+		UsageShown:            "🧮 Las respuestas ahora muestran los tokens y el coste que consumieron.",
+		UsageHidden:           "🧮 Las respuestas ya no muestran los tokens y el coste que consumieron.",
+		ProfileUsageTitle:     "🧮 Consumo:",
+		ProfileUsageAnswers:   "Respuestas: %d",
+		ProfileUsageTokens:    "Tokens: %d de entrada, %d de salida",
+		ProfileUsageReasoning: "Tokens de razonamiento: %d",
+		ProfileUsageCost:      "Coste: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s no está disponible ahora, responde %s en su lugar.",
+		VoiceNotSupported:        "❌ Los mensajes de voz no están disponibles ahora. Por favor escribe tu mensaje.",
+		VoiceTranscriptionFailed: "❌ No se pudo reconocer el mensaje de voz. Por favor inténtalo de nuevo o escribe tu mensaje.",
+		ButtonVoiceRepliesOn:     "🔊 Respuestas de voz: activadas",
+		ButtonVoiceRepliesOff:    "🔇 Respuestas de voz: desactivadas",
+		VoiceRepliesOn:           "🔊 Las respuestas ahora también se envían como mensajes de voz.",
+		VoiceRepliesOff:          "🔇 Las respuestas ya no se envían como mensajes de voz.",
+
+		// Document
+		DocumentUnreadable: "⚠️ No se pudo leer %s, así que se omitió del mensaje.",
+		DocumentTruncated:  "✂️ %s es demasiado largo: solo se leyeron sus primeros %d de %d caracteres.",
 	},
 	"ru": {
 		// Buttons
@@ -361,6 +675,7 @@ var Strings = map[string]map[string]string{
 		// Conversation
 		ConversationStarted:       "🗣️ Беседа начата! Отправьте мне сообщение, и я отвечу. Вы всегда можете вернуться в меню.",
 		ConversationResumed:       "🗣️ Беседа возобновлена! Отправьте мне сообщение, и я отвечу. Вы всегда можете вернуться в меню.",
+		ConversationUnavailable:   "Этот диалог больше недоступен.",
 		ConversationModePrompt:    "Вы в режиме беседы. Отправьте сообщение для чата или вернитесь в меню:",
 		ConversationNameGenerated: "название беседы успешно создано",
 
@@ -379,16 +694,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Модель успешно обновлена!",
 		ModelImageNotSupported: "❌ Выбранная модель не поддерживает изображения. Пожалуйста, выберите другую модель или отправьте текстовое сообщение.",
 		ModelPDFNotSupported:   "❌ Выбранная модель не поддерживает PDF файлы. Пожалуйста, выберите другую модель или отправьте текстовое сообщение.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Быстрый и эффективный для оперативных ответов)",
-		ModelGPT4o:         "🧠 GPT-4o (Самый способный для сложных задач)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Сбалансированная скорость и производительность)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Творческое письмо и анализ)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Длинные документы и контекст)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Продвинутая модель рассуждений)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Исследования и программирование)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Глубокие рассуждения и логика)",
+		ModelImageLimit:        "❌ %s принимает не больше %d изображений в одном сообщении. Пожалуйста, отправьте меньше изображений или выберите другую модель.",
+		ModelPDFLimit:          "❌ %s принимает не больше %d PDF файлов в одном сообщении. Пожалуйста, отправьте меньше файлов или выберите другую модель.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Ваше сообщение поставлено в очередь (позиция: %d). Я обработаю его после завершения текущего ответа.",
@@ -435,6 +742,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Персона",
+		ButtonPersonaReset:             "♻️ Сбросить",
+		PersonaSelectTitle:             "🎭 Ваш системный промпт по умолчанию:\n\n%s\n\nВыберите готовую персону или отправьте свой системный промпт сообщением:",
+		PersonaConversationSelectTitle: "🎭 Системный промпт этого диалога:\n\n%s\n\nВыберите готовую персону, отправьте свой системный промпт сообщением или сбросьте его, чтобы использовать промпт по умолчанию:",
+		PersonaUpdated:                 "✅ Системный промпт обновлен!",
+		PersonaResetDone:               "✅ Системный промпт сброшен.",
+		PersonaTooLong:                 "❌ Системный промпт слишком длинный (максимум %d символов).",
+		PersonaAssistant:               "🤝 Ассистент",
+		PersonaProgrammer:              "💻 Программист",
+		PersonaTranslator:              "🌍 Переводчик",
+		PersonaEditor:                  "✍️ Редактор",
+		PersonaTeacher:                 "🎓 Учитель",
+		PersonaConcise:                 "⚡ Кратко",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Перегенерировать",
+		ButtonRegenerateOtherModel: "🤖 Другая модель",
+		ButtonBack:                 "🔙 Назад",
+		RegenerateBusy:             "⏳ Дождитесь окончания текущего ответа",
+		RegenerateUnavailable:      "Этот ответ больше нельзя перегенерировать",
+		RegenerateModelUnavailable: "Эта модель вам недоступна",
+		RegenerateStarted:          "🔄 Генерирую заново...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Сообщение изменено. Диалог продолжается с него, %d последующих сообщений удалено из истории.",
+		EditBusy:           "⏳ Ответ нельзя обновить, пока генерируется другой. Отредактируйте сообщение ещё раз, когда генерация закончится.",
+		EditImagesAnswer:   "🎨 Ответы с изображениями нельзя обновить. Отправьте изменённый запрос новым сообщением, чтобы получить новые изображения.",
+		EditFailed:         "⚠️ Не удалось сгенерировать ответ, поэтому правка не применена. Отредактируйте сообщение ещё раз, чтобы повторить попытку.",
+
+		// Fork
+		ForkUsage:       "🌿 Чтобы создать ответвление диалога, ответьте на одно из его сообщений командой /fork. Новый диалог продолжится с этого сообщения.",
+		ForkUnavailable: "🌿 От этого сообщения нельзя создать ответвление. Ответьте командой /fork на сообщение одного из ваших диалогов.",
+		ForkCreated:     "🌿 Создано ответвление «%s»: %d сообщений скопировано в новый диалог. Исходный диалог не изменился.",
+
+		// Export
+		ButtonExport:       "📤 Экспорт",
+		ExportChooseFormat: "📤 Выберите формат для экспорта «%s»:",
+		ExportEmpty:        "📤 Экспортировать пока нечего, в диалоге нет сообщений.",
+		ExportUnavailable:  "Этот диалог нельзя экспортировать.",
+		ExportCaption:      "📤 «%s», сообщений: %d. Ссылки на вложения действительны 7 дней.",
+
+		// Import
+		ImportInvalid:  "📥 Этот файл нельзя импортировать. Отправьте файл conversations.json из экспорта данных ChatGPT.",
+		ImportEmpty:    "📥 В этом файле нет диалогов с сообщениями.",
+		ImportProgress: "📥 Импорт диалогов: %d из %d…",
+		ImportFinished: "✅ Импортировано диалогов: %d, сообщений: %d. Выберите диалог ниже, чтобы продолжить его, все они также есть в списке диалогов.",
+		ImportSkipped:  "⚠️ Не удалось импортировать диалогов: %d.",
+
+		// Search
+		SearchUsage:     "🔎 Чтобы найти сообщение в ваших диалогах, отправьте /search и слова для поиска, например /search рецепт пасты. Возьмите фразу в кавычки, чтобы найти её целиком, и добавьте -слово, чтобы исключить сообщения с этим словом.",
+		SearchNoResults: "🔎 По запросу «%s» ничего не найдено. Попробуйте другие слова или меньше слов.",
+		SearchResults:   "🔎 Лучшие совпадения по запросу «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Управление",
+		ButtonArchivedConversations: "🗄 Архив",
+		ButtonOpen:                  "💬 Открыть",
+		ButtonRename:                "✏️ Переименовать",
+		ButtonPin:                   "📌 Закрепить",
+		ButtonUnpin:                 "📍 Открепить",
+		ButtonArchive:               "🗄 В архив",
+		ButtonUnarchive:             "📤 Из архива",
+		ButtonDelete:                "🗑 Удалить",
+		ButtonUndo:                  "↩️ Отменить",
+		ManageChooseConversation:    "🛠 Выберите диалог, чтобы переименовать, закрепить, архивировать или удалить его:",
+		ManageArchivedList:          "🗄 Архивные диалоги. Выберите диалог, чтобы открыть его или вернуть из архива:",
+		ManageNoArchived:            "🗄 В архиве нет диалогов.",
+		ManageConversation:          "🛠 «%s»\n\nЧто сделать с этим диалогом?",
+		ManagePinned:                "📌 «%s» закреплён вверху списка.",
+		ManageUnpinned:              "📍 «%s» откреплён.",
+		ManageArchived:              "🗄 «%s» перемещён в архив. Его можно найти в разделе 🗄 Архив.",
+		ManageUnarchived:            "📤 «%s» снова в списке диалогов.",
+		ManageDeleted:               "🗑 «%s» удалён.",
+		ManageRestored:              "↩️ «%s» восстановлен.",
+		ManageRenamePrompt:          "✏️ Отправьте новое название для «%s».",
+		ManageRenamed:               "✏️ Диалог переименован в «%s».",
+		ManageNameInvalid:           "✏️ Название должно содержать от 1 до %d символов. Отправьте другое.",
+
+		// Stop
+		StopGenerating: "⏳ Генерирую ответ…",
+		ButtonStop:     "⏹ Остановить",
+		StopRequested:  "⏹ Останавливаю ответ. Уже сгенерированная часть сохранится.",
+		StopNothing:    "Сейчас ничего не генерируется.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d токенов",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Расход: показан",
+		ButtonUsageHidden:     "🧮 Расход: скрыт",
+		UsageShown:            "🧮 Теперь под ответами видно, сколько токенов и денег они заняли.",
+		UsageHidden:           "🧮 Под ответами больше не видно, сколько токенов и денег они заняли.",
+		ProfileUsageTitle:     "🧮 Расход:",
+		ProfileUsageAnswers:   "Ответов: %d",
+		ProfileUsageTokens:    "Токены: %d на входе, %d на выходе",
+		ProfileUsageReasoning: "Токены рассуждений: %d",
+		ProfileUsageCost:      "Стоимость: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s сейчас недоступна, вместо неё отвечает %s.",
+		VoiceNotSupported:        "❌ Голосовые сообщения сейчас не поддерживаются. Пожалуйста, напишите сообщение текстом.",
+		VoiceTranscriptionFailed: "❌ Не удалось распознать голосовое сообщение. Пожалуйста, попробуйте ещё раз или напишите текстом.",
+		ButtonVoiceRepliesOn:     "🔊 Голосовые ответы: вкл",
+		ButtonVoiceRepliesOff:    "🔇 Голосовые ответы: выкл",
+		VoiceRepliesOn:           "🔊 Теперь ответы также приходят голосовыми сообщениями.",
+		VoiceRepliesOff:          "🔇 Ответы больше не приходят голосовыми сообщениями.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Не удалось прочитать %s, поэтому он не вошёл в сообщение.",
+		DocumentTruncated:  "✂️ %s слишком длинный: прочитаны только первые %d из %d символов.",
 	},
 	"fr": {
 		// Buttons
@@ -458,6 +876,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Conversation reprise ! Envoyez-moi un message et je répondrai. Vous pouvez toujours retourner au menu.",
 		ConversationModePrompt:    "Vous êtes en mode conversation. Envoyez un message pour discuter, ou retournez au menu :",
 		ConversationNameGenerated: "nom de conversation généré avec succès",
+		ConversationUnavailable:   "Cette conversation n'est plus disponible.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Paramètres. Choisissez une option :",
@@ -474,16 +893,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Modèle mis à jour avec succès !",
 		ModelImageNotSupported: "❌ Le modèle sélectionné ne prend pas en charge les images. Veuillez choisir un modèle différent ou envoyer un message texte.",
 		ModelPDFNotSupported:   "❌ Le modèle sélectionné ne prend pas en charge les fichiers PDF. Veuillez choisir un modèle différent ou envoyer un message texte.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Rapide et efficace pour réponses vives)",
-		ModelGPT4o:         "🧠 GPT-4o (Le plus capable pour tâches complexes)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Vitesse et performance équilibrées)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Écriture créative et analyse)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Longs documents et contexte)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Modèle de raisonnement avancé)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Recherche et programmation)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Raisonnement profond et logique)",
+		ModelImageLimit:        "❌ %s accepte jusqu'à %d images par message. Veuillez envoyer moins d'images ou choisir un modèle différent.",
+		ModelPDFLimit:          "❌ %s accepte jusqu'à %d fichiers PDF par message. Veuillez envoyer moins de fichiers ou choisir un modèle différent.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Votre message a été mis en file d'attente (position : %d). Je le traiterai après avoir terminé la réponse actuelle.",
@@ -530,6 +941,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Réinitialiser",
+		PersonaSelectTitle:             "🎭 Votre prompt système par défaut :\n\n%s\n\nChoisissez une persona prédéfinie ou envoyez votre propre prompt système en message :",
+		PersonaConversationSelectTitle: "🎭 Prompt système de cette conversation :\n\n%s\n\nChoisissez une persona prédéfinie, envoyez votre propre prompt système en message ou réinitialisez-le pour utiliser celui par défaut :",
+		PersonaUpdated:                 "✅ Prompt système mis à jour !",
+		PersonaResetDone:               "✅ Prompt système réinitialisé.",
+		PersonaTooLong:                 "❌ Le prompt système est trop long (%d caractères maximum).",
+		PersonaAssistant:               "🤝 Assistant",
+		PersonaProgrammer:              "💻 Programmeur",
+		PersonaTranslator:              "🌍 Traducteur",
+		PersonaEditor:                  "✍️ Rédacteur",
+		PersonaTeacher:                 "🎓 Enseignant",
+		PersonaConcise:                 "⚡ Concis",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Régénérer",
+		ButtonRegenerateOtherModel: "🤖 Autre modèle",
+		ButtonBack:                 "🔙 Retour",
+		RegenerateBusy:             "⏳ Veuillez attendre la fin de la réponse en cours",
+		RegenerateUnavailable:      "Cette réponse ne peut plus être régénérée",
+		RegenerateModelUnavailable: "Ce modèle n'est pas disponible pour vous",
+		RegenerateStarted:          "🔄 Régénération...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Message modifié. La conversation reprend désormais à partir de celui-ci, %d messages suivants ont été retirés de son historique.",
+		EditBusy:           "⏳ La réponse ne peut pas être mise à jour pendant qu'une autre est en cours de génération. Modifiez à nouveau le message une fois celle-ci terminée.",
+		EditImagesAnswer:   "🎨 Les réponses contenant des images ne peuvent pas être mises à jour. Envoyez le prompt modifié dans un nouveau message pour obtenir de nouvelles images.",
+		EditFailed:         "⚠️ La réponse n'a pas pu être générée, la modification n'a donc pas été appliquée. Modifiez à nouveau le message pour réessayer.",
+
+		// Fork
+		ForkUsage:       "🌿 Pour dupliquer une conversation, répondez à l'un de ses messages avec /fork. Une nouvelle conversation reprendra à partir de ce message.",
+		ForkUnavailable: "🌿 Ce message ne peut pas être dupliqué. Répondez avec /fork à un message de l'une de vos conversations.",
+		ForkCreated:     "🌿 « %s » dupliquée : %d messages ont été copiés dans une nouvelle conversation. La conversation d'origine reste inchangée.",
+
+		// Export
+		ButtonExport:       "📤 Exporter",
+		ExportChooseFormat: "📤 Choisissez un format pour exporter « %s » :",
+		ExportEmpty:        "📤 Il n'y a encore rien à exporter, la conversation ne contient aucun message.",
+		ExportUnavailable:  "Cette conversation ne peut pas être exportée.",
+		ExportCaption:      "📤 « %s », messages : %d. Les liens des pièces jointes sont valables 7 jours.",
+
+		// Import
+		ImportInvalid:  "📥 Ce fichier ne peut pas être importé. Envoyez le fichier conversations.json d'un export de données ChatGPT.",
+		ImportEmpty:    "📥 Ce fichier ne contient aucune conversation avec des messages.",
+		ImportProgress: "📥 Importation des conversations : %d sur %d…",
+		ImportFinished: "✅ %d conversations importées avec %d messages. Choisissez-en une ci-dessous pour la poursuivre, elles figurent toutes aussi dans la liste des conversations.",
+		ImportSkipped:  "⚠️ %d conversations n'ont pas pu être importées.",
+
+		// Search
+		SearchUsage:     "🔎 Pour rechercher dans vos conversations, envoyez /search suivi des mots à trouver, p. ex. /search recette de pâtes. Mettez une phrase entre guillemets pour la trouver telle quelle et ajoutez -mot pour ignorer les messages contenant ce mot.",
+		SearchNoResults: "🔎 Aucun résultat pour « %s ». Essayez d'autres mots ou moins de mots.",
+		SearchResults:   "🔎 Meilleurs résultats pour « %s » :",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Gérer",
+		ButtonArchivedConversations: "🗄 Archivées",
+		ButtonOpen:                  "💬 Ouvrir",
+		ButtonRename:                "✏️ Renommer",
+		ButtonPin:                   "📌 Épingler",
+		ButtonUnpin:                 "📍 Désépingler",
+		ButtonArchive:               "🗄 Archiver",
+		ButtonUnarchive:             "📤 Désarchiver",
+		ButtonDelete:                "🗑 Supprimer",
+		ButtonUndo:                  "↩️ Annuler",
+		ManageChooseConversation:    "🛠 Choisissez une conversation à renommer, épingler, archiver ou supprimer :",
+		ManageArchivedList:          "🗄 Conversations archivées. Choisissez-en une pour l'ouvrir ou la désarchiver :",
+		ManageNoArchived:            "🗄 Il n'y a aucune conversation archivée.",
+		ManageConversation:          "🛠 « %s »\n\nQue voulez-vous faire de cette conversation ?",
+		ManagePinned:                "📌 « %s » est épinglée en haut de la liste.",
+		ManageUnpinned:              "📍 « %s » n'est plus épinglée.",
+		ManageArchived:              "🗄 « %s » est archivée. Vous la trouverez dans 🗄 Archivées.",
+		ManageUnarchived:            "📤 « %s » est de retour dans la liste des conversations.",
+		ManageDeleted:               "🗑 « %s » a été supprimée.",
+		ManageRestored:              "↩️ « %s » a été restaurée.",
+		ManageRenamePrompt:          "✏️ Envoyez un nouveau nom pour « %s ».",
+		ManageRenamed:               "✏️ La conversation a été renommée en « %s ».",
+		ManageNameInvalid:           "✏️ Le nom doit comporter de 1 à %d caractères. Envoyez-en un autre.",
+
+		// Stop
+		StopGenerating: "⏳ Génération de la réponse…",
+		ButtonStop:     "⏹ Arrêter",
+		StopRequested:  "⏹ Arrêt de la réponse. La partie déjà générée est conservée.",
+		StopNothing:    "Rien n'est en cours de génération pour le moment.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d jetons",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Consommation : affichée",
+		ButtonUsageHidden:     "🧮 Consommation : masquée",
+		UsageShown:            "🧮 Les réponses affichent désormais les jetons et le coût qu'elles ont utilisés.",
+		UsageHidden:           "🧮 Les réponses n'affichent plus les jetons et le coût qu'elles ont utilisés.",
+		ProfileUsageTitle:     "🧮 Consommation :",
+		ProfileUsageAnswers:   "Réponses : %d",
+		ProfileUsageTokens:    "Jetons : %d en entrée, %d en sortie",
+		ProfileUsageReasoning: "Jetons de raisonnement : %d",
+		ProfileUsageCost:      "Coût : $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s est indisponible pour le moment, %s répond à sa place.",
+		VoiceNotSupported:        "❌ Les messages vocaux ne sont pas pris en charge pour le moment. Veuillez écrire votre message.",
+		VoiceTranscriptionFailed: "❌ Impossible de reconnaître le message vocal. Veuillez réessayer ou écrire votre message.",
+		ButtonVoiceRepliesOn:     "🔊 Réponses vocales : activées",
+		ButtonVoiceRepliesOff:    "🔇 Réponses vocales : désactivées",
+		VoiceRepliesOn:           "🔊 Les réponses sont désormais aussi envoyées en messages vocaux.",
+		VoiceRepliesOff:          "🔇 Les réponses ne sont plus envoyées en messages vocaux.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Impossible de lire %s, il a donc été retiré du message.",
+		DocumentTruncated:  "✂️ %s est trop long : seuls ses %d premiers caractères sur %d ont été lus.",
 	},
 	"de": {
 		// Buttons
@@ -553,6 +1075,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Gespräch fortgesetzt! Senden Sie mir eine Nachricht und ich werde antworten. Sie können jederzeit zum Menü zurückkehren.",
 		ConversationModePrompt:    "Sie befinden sich im Gesprächsmodus. Senden Sie eine Nachricht zum Chatten oder kehren Sie zum Menü zurück:",
 		ConversationNameGenerated: "Gesprächsname erfolgreich generiert",
+		ConversationUnavailable:   "Dieses Gespräch ist nicht mehr verfügbar.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Einstellungen. Wählen Sie eine Option:",
@@ -569,16 +1092,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Modell erfolgreich aktualisiert!",
 		ModelImageNotSupported: "❌ Das ausgewählte Modell unterstützt keine Bilder. Bitte wählen Sie ein anderes Modell oder senden Sie eine Textnachricht.",
 		ModelPDFNotSupported:   "❌ Das ausgewählte Modell unterstützt keine PDF-Dateien. Bitte wählen Sie ein anderes Modell oder senden Sie eine Textnachricht.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Schnell und effizient für rasche Antworten)",
-		ModelGPT4o:         "🧠 GPT-4o (Am fähigsten für komplexe Aufgaben)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Ausgewogene Geschwindigkeit und Leistung)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Kreatives Schreiben und Analyse)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Lange Dokumente und Kontext)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Fortgeschrittenes Denkmodell)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Forschung und Programmierung)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Tiefes Denken und Logik)",
+		ModelImageLimit:        "❌ %s akzeptiert bis zu %d Bilder pro Nachricht. Bitte senden Sie weniger Bilder oder wählen Sie ein anderes Modell.",
+		ModelPDFLimit:          "❌ %s akzeptiert bis zu %d PDF-Dateien pro Nachricht. Bitte senden Sie weniger Dateien oder wählen Sie ein anderes Modell.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Ihre Nachricht wurde in die Warteschlange eingereiht (Position: %d). Ich werde sie nach Beendigung der aktuellen Antwort bearbeiten.",
@@ -625,6 +1140,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Zurücksetzen",
+		PersonaSelectTitle:             "🎭 Ihr Standard-Systemprompt:\n\n%s\n\nWählen Sie eine vordefinierte Persona oder senden Sie Ihren eigenen Systemprompt als Nachricht:",
+		PersonaConversationSelectTitle: "🎭 Systemprompt dieses Gesprächs:\n\n%s\n\nWählen Sie eine vordefinierte Persona, senden Sie Ihren eigenen Systemprompt als Nachricht oder setzen Sie ihn zurück, um Ihren Standard zu verwenden:",
+		PersonaUpdated:                 "✅ Systemprompt aktualisiert!",
+		PersonaResetDone:               "✅ Systemprompt auf den Standard zurückgesetzt.",
+		PersonaTooLong:                 "❌ Der Systemprompt ist zu lang (maximal %d Zeichen).",
+		PersonaAssistant:               "🤝 Assistent",
+		PersonaProgrammer:              "💻 Programmierer",
+		PersonaTranslator:              "🌍 Übersetzer",
+		PersonaEditor:                  "✍️ Lektor",
+		PersonaTeacher:                 "🎓 Lehrer",
+		PersonaConcise:                 "⚡ Knapp",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Neu generieren",
+		ButtonRegenerateOtherModel: "🤖 Anderes Modell",
+		ButtonBack:                 "🔙 Zurück",
+		RegenerateBusy:             "⏳ Bitte warten Sie, bis die aktuelle Antwort fertig ist",
+		RegenerateUnavailable:      "Diese Antwort kann nicht mehr neu generiert werden",
+		RegenerateModelUnavailable: "Dieses Modell ist für Sie nicht verfügbar",
+		RegenerateStarted:          "🔄 Wird neu generiert...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Nachricht bearbeitet. Das Gespräch wird jetzt ab dieser Nachricht fortgesetzt, %d spätere Nachrichten wurden aus dem Verlauf entfernt.",
+		EditBusy:           "⏳ Die Antwort kann nicht aktualisiert werden, während eine andere generiert wird. Bearbeiten Sie die Nachricht erneut, wenn diese fertig ist.",
+		EditImagesAnswer:   "🎨 Antworten mit Bildern können nicht aktualisiert werden. Senden Sie den geänderten Prompt als neue Nachricht, um neue Bilder zu erhalten.",
+		EditFailed:         "⚠️ Die Antwort konnte nicht generiert werden, daher wurde die Änderung nicht übernommen. Bearbeiten Sie die Nachricht erneut, um es noch einmal zu versuchen.",
+
+		// Fork
+		ForkUsage:       "🌿 Um ein Gespräch abzuzweigen, antworten Sie auf eine seiner Nachrichten mit /fork. Ein neues Gespräch wird ab dieser Nachricht fortgesetzt.",
+		ForkUnavailable: "🌿 Von dieser Nachricht kann nicht abgezweigt werden. Antworten Sie mit /fork auf eine Nachricht aus einem Ihrer Gespräche.",
+		ForkCreated:     "🌿 „%s“ abgezweigt: %d Nachrichten wurden in ein neues Gespräch kopiert. Das ursprüngliche Gespräch bleibt unverändert.",
+
+		// Export
+		ButtonExport:       "📤 Exportieren",
+		ExportChooseFormat: "📤 Wählen Sie ein Format für den Export von „%s“:",
+		ExportEmpty:        "📤 Es gibt noch nichts zu exportieren, das Gespräch enthält keine Nachrichten.",
+		ExportUnavailable:  "Dieses Gespräch kann nicht exportiert werden.",
+		ExportCaption:      "📤 „%s“, Nachrichten: %d. Links zu Anhängen sind 7 Tage gültig.",
+
+		// Import
+		ImportInvalid:  "📥 Diese Datei kann nicht importiert werden. Senden Sie die Datei conversations.json aus einem ChatGPT-Datenexport.",
+		ImportEmpty:    "📥 Diese Datei enthält keine Gespräche mit Nachrichten.",
+		ImportProgress: "📥 Gespräche werden importiert: %d von %d…",
+		ImportFinished: "✅ %d Gespräche mit %d Nachrichten importiert. Wählen Sie unten eines aus, um es fortzusetzen, alle finden Sie auch in der Gesprächsliste.",
+		ImportSkipped:  "⚠️ %d Gespräche konnten nicht importiert werden.",
+
+		// Search
+		SearchUsage:     "🔎 Um Ihre Gespräche zu durchsuchen, senden Sie /search mit den gesuchten Wörtern, z. B. /search Nudelrezept. Setzen Sie eine Wortgruppe in Anführungszeichen, um sie genau zu finden, und fügen Sie -Wort hinzu, um Nachrichten mit diesem Wort auszulassen.",
+		SearchNoResults: "🔎 Für „%s“ wurde nichts gefunden. Versuchen Sie andere oder weniger Wörter.",
+		SearchResults:   "🔎 Beste Treffer für „%s“:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Verwalten",
+		ButtonArchivedConversations: "🗄 Archiviert",
+		ButtonOpen:                  "💬 Öffnen",
+		ButtonRename:                "✏️ Umbenennen",
+		ButtonPin:                   "📌 Anheften",
+		ButtonUnpin:                 "📍 Lösen",
+		ButtonArchive:               "🗄 Archivieren",
+		ButtonUnarchive:             "📤 Wiederherstellen",
+		ButtonDelete:                "🗑 Löschen",
+		ButtonUndo:                  "↩️ Rückgängig",
+		ManageChooseConversation:    "🛠 Wählen Sie ein Gespräch zum Umbenennen, Anheften, Archivieren oder Löschen:",
+		ManageArchivedList:          "🗄 Archivierte Gespräche. Wählen Sie eines, um es zu öffnen oder aus dem Archiv zu holen:",
+		ManageNoArchived:            "🗄 Es gibt keine archivierten Gespräche.",
+		ManageConversation:          "🛠 „%s“\n\nWas möchten Sie mit diesem Gespräch tun?",
+		ManagePinned:                "📌 „%s“ ist oben in der Liste angeheftet.",
+		ManageUnpinned:              "📍 „%s“ ist nicht mehr angeheftet.",
+		ManageArchived:              "🗄 „%s“ ist archiviert. Sie finden es unter 🗄 Archiviert.",
+		ManageUnarchived:            "📤 „%s“ ist wieder in der Gesprächsliste.",
+		ManageDeleted:               "🗑 „%s“ wurde gelöscht.",
+		ManageRestored:              "↩️ „%s“ wurde wiederhergestellt.",
+		ManageRenamePrompt:          "✏️ Senden Sie einen neuen Namen für „%s“.",
+		ManageRenamed:               "✏️ Das Gespräch heißt jetzt „%s“.",
+		ManageNameInvalid:           "✏️ Der Name muss 1 bis %d Zeichen lang sein. Senden Sie einen anderen.",
+
+		// Stop
+		StopGenerating: "⏳ Die Antwort wird generiert…",
+		ButtonStop:     "⏹ Stoppen",
+		StopRequested:  "⏹ Die Antwort wird gestoppt. Der bisher generierte Teil bleibt erhalten.",
+		StopNothing:    "Gerade wird nichts generiert.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d Tokens",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Verbrauch: angezeigt",
+		ButtonUsageHidden:     "🧮 Verbrauch: ausgeblendet",
+		UsageShown:            "🧮 Antworten zeigen jetzt die verbrauchten Tokens und Kosten an.",
+		UsageHidden:           "🧮 Antworten zeigen die verbrauchten Tokens und Kosten nicht mehr an.",
+		ProfileUsageTitle:     "🧮 Verbrauch:",
+		ProfileUsageAnswers:   "Antworten: %d",
+		ProfileUsageTokens:    "Tokens: %d Eingabe, %d Ausgabe",
+		ProfileUsageReasoning: "Reasoning-Tokens: %d",
+		ProfileUsageCost:      "Kosten: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s ist gerade nicht verfügbar, stattdessen antwortet %s.",
+		VoiceNotSupported:        "❌ Sprachnachrichten werden gerade nicht unterstützt. Bitte schreiben Sie Ihre Nachricht.",
+		VoiceTranscriptionFailed: "❌ Die Sprachnachricht konnte nicht erkannt werden. Bitte versuchen Sie es erneut oder schreiben Sie Ihre Nachricht.",
+		ButtonVoiceRepliesOn:     "🔊 Sprachantworten: an",
+		ButtonVoiceRepliesOff:    "🔇 Sprachantworten: aus",
+		VoiceRepliesOn:           "🔊 Antworten werden jetzt auch als Sprachnachrichten gesendet.",
+		VoiceRepliesOff:          "🔇 Antworten werden nicht mehr als Sprachnachrichten gesendet.",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s konnte nicht gelesen werden und wurde aus der Nachricht entfernt.",
+		DocumentTruncated:  "✂️ %s ist zu lang: Nur die ersten %d von %d Zeichen wurden gelesen.",
 	},
 	"it": {
 		// Buttons
@@ -648,6 +1274,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Conversazione ripresa! Inviami un messaggio e ti risponderò. Puoi sempre tornare al menu.",
 		ConversationModePrompt:    "Sei in modalità conversazione. Invia un messaggio per chattare, o torna al menu:",
 		ConversationNameGenerated: "nome conversazione generato con successo",
+		ConversationUnavailable:   "Questa conversazione non è più disponibile.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Impostazioni. Scegli un'opzione:",
@@ -664,16 +1291,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Modello aggiornato con successo!",
 		ModelImageNotSupported: "❌ Il modello selezionato non supporta le immagini. Per favore scegli un modello diverso o invia un messaggio di testo.",
 		ModelPDFNotSupported:   "❌ Il modello selezionato non supporta i file PDF. Per favore scegli un modello diverso o invia un messaggio di testo.",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Veloce ed efficiente per risposte rapide)",
-		ModelGPT4o:         "🧠 GPT-4o (Più capace per compiti complessi)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Velocità e prestazioni bilanciate)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Scrittura creativa e analisi)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Documenti lunghi e contesto)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Modello di ragionamento avanzato)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Ricerca e programmazione)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Ragionamento profondo e logica)",
+		ModelImageLimit:        "❌ %s accetta fino a %d immagini in un messaggio. Per favore invia meno immagini o scegli un modello diverso.",
+		ModelPDFLimit:          "❌ %s accetta fino a %d file PDF in un messaggio. Per favore invia meno file o scegli un modello diverso.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Il tuo messaggio è stato messo in coda (posizione: %d). Lo elaborerò dopo aver terminato la risposta attuale.",
@@ -720,6 +1339,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Ripristina",
+		PersonaSelectTitle:             "🎭 Il tuo prompt di sistema predefinito:\n\n%s\n\nScegli una persona predefinita o invia il tuo prompt di sistema come messaggio:",
+		PersonaConversationSelectTitle: "🎭 Prompt di sistema di questa conversazione:\n\n%s\n\nScegli una persona predefinita, invia il tuo prompt di sistema come messaggio o ripristinalo per usare quello predefinito:",
+		PersonaUpdated:                 "✅ Prompt di sistema aggiornato!",
+		PersonaResetDone:               "✅ Prompt di sistema ripristinato.",
+		PersonaTooLong:                 "❌ Il prompt di sistema è troppo lungo (massimo %d caratteri).",
+		PersonaAssistant:               "🤝 Assistente",
+		PersonaProgrammer:              "💻 Programmatore",
+		PersonaTranslator:              "🌍 Traduttore",
+		PersonaEditor:                  "✍️ Redattore",
+		PersonaTeacher:                 "🎓 Insegnante",
+		PersonaConcise:                 "⚡ Conciso",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Rigenera",
+		ButtonRegenerateOtherModel: "🤖 Altro modello",
+		ButtonBack:                 "🔙 Indietro",
+		RegenerateBusy:             "⏳ Attendi che la risposta attuale sia terminata",
+		RegenerateUnavailable:      "Questa risposta non può più essere rigenerata",
+		RegenerateModelUnavailable: "Questo modello non è disponibile per te",
+		RegenerateStarted:          "🔄 Rigenerazione in corso...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Messaggio modificato. La conversazione ora riprende da qui, %d messaggi successivi sono stati rimossi dalla cronologia.",
+		EditBusy:           "⏳ La risposta non può essere aggiornata mentre ne viene generata un'altra. Modifica di nuovo il messaggio quando avrà finito.",
+		EditImagesAnswer:   "🎨 Le risposte con immagini non possono essere aggiornate. Invia il prompt modificato come nuovo messaggio per ottenere nuove immagini.",
+		EditFailed:         "⚠️ Non è stato possibile generare la risposta, quindi la modifica non è stata applicata. Modifica di nuovo il messaggio per riprovare.",
+
+		// Fork
+		ForkUsage:       "🌿 Per diramare una conversazione, rispondi a uno dei suoi messaggi con /fork. Una nuova conversazione continuerà da quel messaggio.",
+		ForkUnavailable: "🌿 Non è possibile diramare da questo messaggio. Rispondi con /fork a un messaggio di una delle tue conversazioni.",
+		ForkCreated:     "🌿 «%s» diramata: %d messaggi sono stati copiati in una nuova conversazione. La conversazione originale resta invariata.",
+
+		// Export
+		ButtonExport:       "📤 Esporta",
+		ExportChooseFormat: "📤 Scegli un formato per esportare «%s»:",
+		ExportEmpty:        "📤 Non c'è ancora nulla da esportare, la conversazione non ha messaggi.",
+		ExportUnavailable:  "Questa conversazione non può essere esportata.",
+		ExportCaption:      "📤 «%s», messaggi: %d. I link agli allegati sono validi per 7 giorni.",
+
+		// Import
+		ImportInvalid:  "📥 Questo file non può essere importato. Invia il file conversations.json di un'esportazione dei dati di ChatGPT.",
+		ImportEmpty:    "📥 In questo file non ci sono conversazioni con messaggi.",
+		ImportProgress: "📥 Importazione delle conversazioni: %d di %d…",
+		ImportFinished: "✅ Importate %d conversazioni con %d messaggi. Scegline una qui sotto per continuarla, le trovi tutte anche nell'elenco delle conversazioni.",
+		ImportSkipped:  "⚠️ Non è stato possibile importare %d conversazioni.",
+
+		// Search
+		SearchUsage:     "🔎 Per cercare nelle tue conversazioni, invia /search con le parole da trovare, ad es. /search ricetta pasta. Metti una frase tra virgolette per trovarla esatta e aggiungi -parola per escludere i messaggi con quella parola.",
+		SearchNoResults: "🔎 Nessun risultato per «%s». Prova con altre parole o con meno parole.",
+		SearchResults:   "🔎 Migliori risultati per «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Gestisci",
+		ButtonArchivedConversations: "🗄 Archiviate",
+		ButtonOpen:                  "💬 Apri",
+		ButtonRename:                "✏️ Rinomina",
+		ButtonPin:                   "📌 Fissa",
+		ButtonUnpin:                 "📍 Sblocca",
+		ButtonArchive:               "🗄 Archivia",
+		ButtonUnarchive:             "📤 Ripristina",
+		ButtonDelete:                "🗑 Elimina",
+		ButtonUndo:                  "↩️ Annulla",
+		ManageChooseConversation:    "🛠 Scegli una conversazione da rinominare, fissare, archiviare o eliminare:",
+		ManageArchivedList:          "🗄 Conversazioni archiviate. Scegline una per aprirla o toglierla dall'archivio:",
+		ManageNoArchived:            "🗄 Non ci sono conversazioni archiviate.",
+		ManageConversation:          "🛠 «%s»\n\nCosa vuoi fare con questa conversazione?",
+		ManagePinned:                "📌 «%s» è fissata in cima all'elenco.",
+		ManageUnpinned:              "📍 «%s» non è più fissata.",
+		ManageArchived:              "🗄 «%s» è archiviata. La trovi in 🗄 Archiviate.",
+		ManageUnarchived:            "📤 «%s» è di nuovo nell'elenco delle conversazioni.",
+		ManageDeleted:               "🗑 «%s» è stata eliminata.",
+		ManageRestored:              "↩️ «%s» è stata ripristinata.",
+		ManageRenamePrompt:          "✏️ Invia un nuovo nome per «%s».",
+		ManageRenamed:               "✏️ La conversazione ora si chiama «%s».",
+		ManageNameInvalid:           "✏️ Il nome deve essere lungo da 1 a %d caratteri. Inviane un altro.",
+
+		// Stop
+		StopGenerating: "⏳ Generazione della risposta…",
+		ButtonStop:     "⏹ Interrompi",
+		StopRequested:  "⏹ Interruzione della risposta. La parte generata finora viene mantenuta.",
+		StopNothing:    "Al momento non viene generato nulla.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d token",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Consumo: visibile",
+		ButtonUsageHidden:     "🧮 Consumo: nascosto",
+		UsageShown:            "🧮 Le risposte ora mostrano i token e il costo che hanno richiesto.",
+		UsageHidden:           "🧮 Le risposte non mostrano più i token e il costo che hanno richiesto.",
+		ProfileUsageTitle:     "🧮 Consumo:",
+		ProfileUsageAnswers:   "Risposte: %d",
+		ProfileUsageTokens:    "Token: %d in ingresso, %d in uscita",
+		ProfileUsageReasoning: "Token di ragionamento: %d",
+		ProfileUsageCost:      "Costo: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s non è disponibile ora, risponde invece %s.",
+		VoiceNotSupported:        "❌ I messaggi vocali non sono supportati al momento. Per favore scrivi il tuo messaggio.",
+		VoiceTranscriptionFailed: "❌ Impossibile riconoscere il messaggio vocale. Per favore riprova o scrivi il tuo messaggio.",
+		ButtonVoiceRepliesOn:     "🔊 Risposte vocali: attive",
+		ButtonVoiceRepliesOff:    "🔇 Risposte vocali: disattivate",
+		VoiceRepliesOn:           "🔊 Le risposte ora vengono inviate anche come messaggi vocali.",
+		VoiceRepliesOff:          "🔇 Le risposte non vengono più inviate come messaggi vocali.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Impossibile leggere %s, quindi è stato escluso dal messaggio.",
+		DocumentTruncated:  "✂️ %s è troppo lungo: sono stati letti solo i primi %d di %d caratteri.",
 	},
 	"zh": {
 		// Buttons
@@ -743,6 +1473,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ 对话已恢复！发送消息，我会回复您。您随时可以返回菜单。",
 		ConversationModePrompt:    "您处于对话模式。发送消息进行聊天，或返回菜单：",
 		ConversationNameGenerated: "对话名称生成成功",
+		ConversationUnavailable:   "此对话已不可用。",
 
 		// Settings
 		SettingsTitle:         "⚙️ 设置。请选择一个选项：",
@@ -759,16 +1490,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ 模型更新成功！",
 		ModelImageNotSupported: "❌ 所选模型不支持图像输入。请选择其他模型或发送文本消息。",
 		ModelPDFNotSupported:   "❌ 所选模型不支持PDF文件。请选择其他模型或发送文本消息。",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash（快速高效，适合快速响应）",
-		ModelGPT4o:         "🧠 GPT-4o（最强能力，适合复杂任务）",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini（平衡速度与性能）",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet（创意写作与分析）",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro（长文档与上下文）",
-		ModelO3Mini:        "🤖 OpenAI o3-mini（高级推理模型）",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3（研究与编程）",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1（深度推理与逻辑）",
+		ModelImageLimit:        "❌ %s 每条消息最多接受 %d 张图片。请减少图片数量或选择其他模型。",
+		ModelPDFLimit:          "❌ %s 每条消息最多接受 %d 个PDF文件。请减少文件数量或选择其他模型。",
 
 		// Queue
 		QueueMessageQueued: "⏳ 您的消息已排队（位置：%d）。我会在完成当前回复后处理它。",
@@ -815,6 +1538,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 角色",
+		ButtonPersonaReset:             "♻️ 恢复默认",
+		PersonaSelectTitle:             "🎭 您的默认系统提示词：\n\n%s\n\n请选择预设角色，或将您自己的系统提示词作为消息发送：",
+		PersonaConversationSelectTitle: "🎭 此对话的系统提示词：\n\n%s\n\n请选择预设角色、将您自己的系统提示词作为消息发送，或恢复为您的默认提示词：",
+		PersonaUpdated:                 "✅ 系统提示词已更新！",
+		PersonaResetDone:               "✅ 系统提示词已恢复默认。",
+		PersonaTooLong:                 "❌ 系统提示词太长（最多 %d 个字符）。",
+		PersonaAssistant:               "🤝 助手",
+		PersonaProgrammer:              "💻 程序员",
+		PersonaTranslator:              "🌍 翻译",
+		PersonaEditor:                  "✍️ 编辑",
+		PersonaTeacher:                 "🎓 老师",
+		PersonaConcise:                 "⚡ 简洁",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 重新生成",
+		ButtonRegenerateOtherModel: "🤖 其他模型",
+		ButtonBack:                 "🔙 返回",
+		RegenerateBusy:             "⏳ 请等待当前回答完成",
+		RegenerateUnavailable:      "此回答已无法重新生成",
+		RegenerateModelUnavailable: "您无法使用此模型",
+		RegenerateStarted:          "🔄 正在重新生成...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ 消息已编辑。对话将从这条消息继续，之后的 %d 条消息已从历史记录中移除。",
+		EditBusy:           "⏳ 正在生成另一个回答时无法更新此回答。请在生成完成后再次编辑该消息。",
+		EditImagesAnswer:   "🎨 包含图片的回答无法更新。请将修改后的提示作为新消息发送以获取新图片。",
+		EditFailed:         "⚠️ 无法生成回答，因此编辑未生效。请再次编辑该消息以重试。",
+
+		// Fork
+		ForkUsage:       "🌿 要分叉对话，请用 /fork 回复其中的一条消息。新对话将从该消息继续。",
+		ForkUnavailable: "🌿 无法从此消息分叉。请用 /fork 回复您某个对话中的消息。",
+		ForkCreated:     "🌿 已分叉「%s」：%d 条消息已复制到新对话中。原对话保持不变。",
+
+		// Export
+		ButtonExport:       "📤 导出",
+		ExportChooseFormat: "📤 请选择导出「%s」的格式：",
+		ExportEmpty:        "📤 暂时没有可导出的内容，此对话还没有消息。",
+		ExportUnavailable:  "此对话无法导出。",
+		ExportCaption:      "📤 「%s」，共 %d 条消息。附件链接 7 天内有效。",
+
+		// Import
+		ImportInvalid:  "📥 无法导入此文件。请发送 ChatGPT 数据导出中的 conversations.json 文件。",
+		ImportEmpty:    "📥 此文件中没有包含消息的对话。",
+		ImportProgress: "📥 正在导入对话：%d / %d…",
+		ImportFinished: "✅ 已导入 %d 个对话，共 %d 条消息。请在下方选择一个继续，所有对话也都在对话列表中。",
+		ImportSkipped:  "⚠️ 有 %d 个对话无法导入。",
+
+		// Search
+		SearchUsage:     "🔎 要搜索您的对话，请发送 /search 加上要查找的词，例如 /search 意面食谱。用引号括起短语可精确查找，添加 -词语 可排除包含该词的消息。",
+		SearchNoResults: "🔎 未找到与「%s」相关的内容。请尝试其他词或减少词语。",
+		SearchResults:   "🔎 「%s」的最佳匹配：",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 管理",
+		ButtonArchivedConversations: "🗄 已归档",
+		ButtonOpen:                  "💬 打开",
+		ButtonRename:                "✏️ 重命名",
+		ButtonPin:                   "📌 置顶",
+		ButtonUnpin:                 "📍 取消置顶",
+		ButtonArchive:               "🗄 归档",
+		ButtonUnarchive:             "📤 取消归档",
+		ButtonDelete:                "🗑 删除",
+		ButtonUndo:                  "↩️ 撤销",
+		ManageChooseConversation:    "🛠 请选择要重命名、置顶、归档或删除的对话：",
+		ManageArchivedList:          "🗄 已归档的对话。选择一个以打开或取消归档：",
+		ManageNoArchived:            "🗄 没有已归档的对话。",
+		ManageConversation:          "🛠 「%s」\n\n您想对此对话做什么？",
+		ManagePinned:                "📌 「%s」已置顶到列表顶部。",
+		ManageUnpinned:              "📍 「%s」已取消置顶。",
+		ManageArchived:              "🗄 「%s」已归档。您可以在 🗄 已归档 中找到它。",
+		ManageUnarchived:            "📤 「%s」已回到对话列表。",
+		ManageDeleted:               "🗑 「%s」已删除。",
+		ManageRestored:              "↩️ 「%s」已恢复。",
+		ManageRenamePrompt:          "✏️ 请发送「%s」的新名称。",
+		ManageRenamed:               "✏️ 对话已重命名为「%s」。",
+		ManageNameInvalid:           "✏️ 名称长度必须为 1 到 %d 个字符。请重新发送。",
+
+		// Stop
+		StopGenerating: "⏳ 正在生成回答…",
+		ButtonStop:     "⏹ 停止",
+		StopRequested:  "⏹ 正在停止回答。已生成的部分将被保留。",
+		StopNothing:    "当前没有正在生成的内容。",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d 个令牌",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 用量：显示",
+		ButtonUsageHidden:     "🧮 用量：隐藏",
+		UsageShown:            "🧮 回答现在会显示所用的令牌和费用。",
+		UsageHidden:           "🧮 回答不再显示所用的令牌和费用。",
+		ProfileUsageTitle:     "🧮 用量：",
+		ProfileUsageAnswers:   "回答：%d",
+		ProfileUsageTokens:    "令牌：输入 %d，输出 %d",
+		ProfileUsageReasoning: "推理令牌：%d",
+		ProfileUsageCost:      "费用：$%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s 暂时不可用，改由 %s 回答。",
+		VoiceNotSupported:        "❌ 暂不支持语音消息。请输入文字消息。",
+		VoiceTranscriptionFailed: "❌ 无法识别语音消息。请重试或输入文字消息。",
+		ButtonVoiceRepliesOn:     "🔊 语音回复：开",
+		ButtonVoiceRepliesOff:    "🔇 语音回复：关",
+		VoiceRepliesOn:           "🔊 回答现在也会以语音消息发送。",
+		VoiceRepliesOff:          "🔇 回答不再以语音消息发送。",
+
+		// Document
+		DocumentUnreadable: "⚠️ 无法读取 %s，已将其从消息中略去。",
+		DocumentTruncated:  "✂️ %s 过长：仅读取了前 %d 个字符（共 %d 个）。",
 	},
 	"ja": {
 		// Buttons
@@ -838,6 +1672,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ 会話が再開されました！メッセージを送信すると返信します。いつでもメニューに戻れます。",
 		ConversationModePrompt:    "会話モードです。メッセージを送信してチャットするか、メニューに戻ってください：",
 		ConversationNameGenerated: "会話名が正常に生成されました",
+		ConversationUnavailable:   "この会話はもう利用できません。",
 
 		// Settings
 		SettingsTitle:         "⚙️ 設定。オプションを選択してください：",
@@ -854,16 +1689,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ モデルが正常に更新されました！",
 		ModelImageNotSupported: "❌ 選択されたモデルは画像入力をサポートしていません。別のモデルを選択するか、テキストメッセージを送信してください。",
 		ModelPDFNotSupported:   "❌ 選択されたモデルはPDFファイルをサポートしていません。別のモデルを選択するか、テキストメッセージを送信してください。",
-
-		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash（高速で効率的、素早い応答に最適）",
-		ModelGPT4o:         "🧠 GPT-4o（最高性能、複雑なタスクに最適）",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini（速度と性能のバランス）",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet（創造的な文章作成と分析）",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro（長文書とコンテキスト）",
-		ModelO3Mini:        "🤖 OpenAI o3-mini（高度な推論モデル）",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3（研究とプログラミング）",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1（深い推論と論理）",
+		ModelImageLimit:        "❌ %s は1つのメッセージで最大%d枚の画像を受け付けます。画像を減らすか、別のモデルを選択してください。",
+		ModelPDFLimit:          "❌ %s は1つのメッセージで最大%d個のPDFファイルを受け付けます。ファイルを減らすか、別のモデルを選択してください。",
 
 		// Queue
 		QueueMessageQueued: "⏳ メッセージがキューに追加されました（位置：%d）。現在の応答を完了した後に処理します。",
@@ -910,6 +1737,117 @@ var Strings = map[string]map[string]string{
 		LangKyrgyz:     "🇰🇬 Кыргызча",
 		LangArabic:     "🇸🇦 العربية",
 		LangHindi:      "🇮🇳 हिन्दी",
+
+		// Persona
+		ButtonPersona:                  "🎭 ペルソナ",
+		ButtonPersonaReset:             "♻️ デフォルトに戻す",
+		PersonaSelectTitle:             "🎭 デフォルトのシステムプロンプト：\n\n%s\n\nプリセットのペルソナを選ぶか、独自のシステムプロンプトをメッセージで送信してください：",
+		PersonaConversationSelectTitle: "🎭 この会話のシステムプロンプト：\n\n%s\n\nプリセットのペルソナを選ぶか、独自のシステムプロンプトをメッセージで送信するか、リセットしてデフォルトを使用してください：",
+		PersonaUpdated:                 "✅ システムプロンプトを更新しました！",
+		PersonaResetDone:               "✅ システムプロンプトをデフォルトに戻しました。",
+		PersonaTooLong:                 "❌ システムプロンプトが長すぎます（最大 %d 文字）。",
+		PersonaAssistant:               "🤝 アシスタント",
+		PersonaProgrammer:              "💻 プログラマー",
+		PersonaTranslator:              "🌍 翻訳者",
+		PersonaEditor:                  "✍️ 編集者",
+		PersonaTeacher:                 "🎓 先生",
+		PersonaConcise:                 "⚡ 簡潔",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 再生成",
+		ButtonRegenerateOtherModel: "🤖 別のモデル",
+		ButtonBack:                 "🔙 戻る",
+		RegenerateBusy:             "⏳ 現在の回答が終わるまでお待ちください",
+		RegenerateUnavailable:      "この回答はもう再生成できません",
+		RegenerateModelUnavailable: "このモデルはご利用いただけません",
+		RegenerateStarted:          "🔄 再生成しています...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ メッセージを編集しました。会話はこのメッセージから続き、以降の %d 件のメッセージは履歴から削除されました。",
+		EditBusy:           "⏳ 別の回答を生成している間は回答を更新できません。生成が終わってからもう一度メッセージを編集してください。",
+		EditImagesAnswer:   "🎨 画像を含む回答は更新できません。新しい画像を得るには、変更したプロンプトを新しいメッセージとして送信してください。",
+		EditFailed:         "⚠️ 回答を生成できなかったため、編集は反映されませんでした。もう一度メッセージを編集して再試行してください。",
+
+		// Fork
+		ForkUsage:       "🌿 会話を分岐するには、そのメッセージのいずれかに /fork で返信してください。そのメッセージから新しい会話が続きます。",
+		ForkUnavailable: "🌿 このメッセージからは分岐できません。ご自身の会話のメッセージに /fork で返信してください。",
+		ForkCreated:     "🌿 「%s」を分岐しました：%d 件のメッセージを新しい会話にコピーしました。元の会話は変更されません。",
+
+		// Export
+		ButtonExport:       "📤 エクスポート",
+		ExportChooseFormat: "📤 「%s」をエクスポートする形式を選んでください：",
+		ExportEmpty:        "📤 まだエクスポートするものがありません。この会話にはメッセージがありません。",
+		ExportUnavailable:  "この会話はエクスポートできません。",
+		ExportCaption:      "📤 「%s」、メッセージ %d 件。添付ファイルのリンクは 7 日間有効です。",
+
+		// Import
+		ImportInvalid:  "📥 このファイルはインポートできません。ChatGPT のデータエクスポートに含まれる conversations.json ファイルを送信してください。",
+		ImportEmpty:    "📥 このファイルにはメッセージのある会話がありません。",
+		ImportProgress: "📥 会話をインポートしています：%d / %d…",
+		ImportFinished: "✅ %d 件の会話（メッセージ %d 件）をインポートしました。続ける会話を下から選んでください。すべての会話は会話一覧にもあります。",
+		ImportSkipped:  "⚠️ %d 件の会話をインポートできませんでした。",
+
+		// Search
+		SearchUsage:     "🔎 会話を検索するには、/search に続けて検索したい言葉を送信してください（例：/search パスタ レシピ）。フレーズを引用符で囲むと完全一致で検索でき、-単語 を付けるとその単語を含むメッセージを除外できます。",
+		SearchNoResults: "🔎 「%s」に一致するものは見つかりませんでした。別の言葉や少ない言葉でお試しください。",
+		SearchResults:   "🔎 「%s」の検索結果：",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 管理",
+		ButtonArchivedConversations: "🗄 アーカイブ済み",
+		ButtonOpen:                  "💬 開く",
+		ButtonRename:                "✏️ 名前を変更",
+		ButtonPin:                   "📌 ピン留め",
+		ButtonUnpin:                 "📍 ピン留めを解除",
+		ButtonArchive:               "🗄 アーカイブ",
+		ButtonUnarchive:             "📤 アーカイブ解除",
+		ButtonDelete:                "🗑 削除",
+		ButtonUndo:                  "↩️ 元に戻す",
+		ManageChooseConversation:    "🛠 名前の変更、ピン留め、アーカイブ、削除を行う会話を選んでください：",
+		ManageArchivedList:          "🗄 アーカイブ済みの会話です。開くかアーカイブを解除する会話を選んでください：",
+		ManageNoArchived:            "🗄 アーカイブ済みの会話はありません。",
+		ManageConversation:          "🛠 「%s」\n\nこの会話をどうしますか？",
+		ManagePinned:                "📌 「%s」を一覧の先頭にピン留めしました。",
+		ManageUnpinned:              "📍 「%s」のピン留めを解除しました。",
+		ManageArchived:              "🗄 「%s」をアーカイブしました。🗄 アーカイブ済み から見つけられます。",
+		ManageUnarchived:            "📤 「%s」を会話一覧に戻しました。",
+		ManageDeleted:               "🗑 「%s」を削除しました。",
+		ManageRestored:              "↩️ 「%s」を復元しました。",
+		ManageRenamePrompt:          "✏️ 「%s」の新しい名前を送信してください。",
+		ManageRenamed:               "✏️ 会話の名前を「%s」に変更しました。",
+		ManageNameInvalid:           "✏️ 名前は 1～%d 文字にしてください。別の名前を送信してください。",
+
+		// Stop
+		StopGenerating: "⏳ 回答を生成しています…",
+		ButtonStop:     "⏹ 停止",
+		StopRequested:  "⏹ 回答を停止しています。ここまでに生成された部分は保持されます。",
+		StopNothing:    "現在生成中のものはありません。",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d トークン",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 使用量：表示",
+		ButtonUsageHidden:     "🧮 使用量：非表示",
+		UsageShown:            "🧮 回答に使用したトークンと費用が表示されるようになりました。",
+		UsageHidden:           "🧮 回答に使用したトークンと費用は表示されなくなりました。",
+		ProfileUsageTitle:     "🧮 使用量：",
+		ProfileUsageAnswers:   "回答：%d",
+		ProfileUsageTokens:    "トークン：入力 %d、出力 %d",
+		ProfileUsageReasoning: "推論トークン：%d",
+		ProfileUsageCost:      "費用：$%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s は現在利用できないため、代わりに %s が回答します。",
+		VoiceNotSupported:        "❌ 現在、音声メッセージには対応していません。テキストで入力してください。",
+		VoiceTranscriptionFailed: "❌ 音声メッセージを認識できませんでした。もう一度お試しいただくか、テキストで入力してください。",
+		ButtonVoiceRepliesOn:     "🔊 音声返信：オン",
+		ButtonVoiceRepliesOff:    "🔇 音声返信：オフ",
+		VoiceRepliesOn:           "🔊 回答は音声メッセージでも送信されるようになりました。",
+		VoiceRepliesOff:          "🔇 回答は音声メッセージで送信されなくなりました。",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s を読み取れなかったため、メッセージから除外しました。",
+		DocumentTruncated:  "✂️ %[1]s は長すぎるため、%[3]d 文字中 最初の %[2]d 文字だけを読み取りました。",
 	},
 	"ko": {
 		// Buttons
@@ -933,6 +1871,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ 대화가 재개되었습니다! 메시지를 보내시면 답변해드리겠습니다. 언제든지 메뉴로 돌아갈 수 있습니다.",
 		ConversationModePrompt:    "대화 모드입니다. 메시지를 보내서 채팅하거나 메뉴로 돌아가세요:",
 		ConversationNameGenerated: "대화 이름이 성공적으로 생성되었습니다",
+		ConversationUnavailable:   "이 대화는 더 이상 사용할 수 없습니다.",
 
 		// Settings
 		SettingsTitle:         "⚙️ 설정. 옵션을 선택하세요:",
@@ -949,6 +1888,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ 모델이 성공적으로 업데이트되었습니다!",
 		ModelImageNotSupported: "❌ 선택한 모델은 이미지 입력을 지원하지 않습니다. 다른 모델을 선택하거나 텍스트 메시지를 보내주세요.",
 		ModelPDFNotSupported:   "❌ 선택한 모델은 PDF 파일을 지원하지 않습니다. 다른 모델을 선택하거나 텍스트 메시지를 보내주세요.",
+		ModelImageLimit:        "❌ %s은(는) 한 메시지에 최대 %d개의 이미지를 받습니다. 이미지를 줄이거나 다른 모델을 선택하세요.",
+		ModelPDFLimit:          "❌ %s은(는) 한 메시지에 최대 %d개의 PDF 파일을 받습니다. 파일을 줄이거나 다른 모델을 선택하세요.",
 
 		// Queue
 		QueueMessageQueued: "⏳ 메시지가 대기열에 추가되었습니다 (위치: %d). 현재 응답을 완료한 후 처리하겠습니다.",
@@ -997,14 +1938,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (빠르고 효율적인 응답)",
-		ModelGPT4o:         "🧠 GPT-4o (복잡한 작업에 가장 적합)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (속도와 성능의 균형)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (창의적 글쓰기 및 분석)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (긴 문서 및 컨텍스트)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (고급 추론 모델)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (연구 및 코딩)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (심층 추론 및 논리)",
+
+		// Persona
+		ButtonPersona:                  "🎭 페르소나",
+		ButtonPersonaReset:             "♻️ 기본값으로 재설정",
+		PersonaSelectTitle:             "🎭 기본 시스템 프롬프트:\n\n%s\n\n프리셋 페르소나를 선택하거나 직접 작성한 시스템 프롬프트를 메시지로 보내세요:",
+		PersonaConversationSelectTitle: "🎭 이 대화의 시스템 프롬프트:\n\n%s\n\n프리셋 페르소나를 선택하거나, 직접 작성한 시스템 프롬프트를 메시지로 보내거나, 재설정하여 기본값을 사용하세요:",
+		PersonaUpdated:                 "✅ 시스템 프롬프트가 업데이트되었습니다!",
+		PersonaResetDone:               "✅ 시스템 프롬프트가 기본값으로 재설정되었습니다.",
+		PersonaTooLong:                 "❌ 시스템 프롬프트가 너무 깁니다 (최대 %d자).",
+		PersonaAssistant:               "🤝 어시스턴트",
+		PersonaProgrammer:              "💻 프로그래머",
+		PersonaTranslator:              "🌍 번역가",
+		PersonaEditor:                  "✍️ 에디터",
+		PersonaTeacher:                 "🎓 선생님",
+		PersonaConcise:                 "⚡ 간결하게",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 다시 생성",
+		ButtonRegenerateOtherModel: "🤖 다른 모델",
+		ButtonBack:                 "🔙 뒤로",
+		RegenerateBusy:             "⏳ 현재 답변이 끝날 때까지 기다려 주세요",
+		RegenerateUnavailable:      "이 답변은 더 이상 다시 생성할 수 없습니다",
+		RegenerateModelUnavailable: "이 모델은 사용할 수 없습니다",
+		RegenerateStarted:          "🔄 다시 생성하는 중...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ 메시지가 수정되었습니다. 이제 대화가 이 메시지부터 이어지며, 이후 메시지 %d개가 기록에서 삭제되었습니다.",
+		EditBusy:           "⏳ 다른 답변을 생성하는 동안에는 답변을 업데이트할 수 없습니다. 생성이 끝나면 메시지를 다시 수정하세요.",
+		EditImagesAnswer:   "🎨 이미지가 포함된 답변은 업데이트할 수 없습니다. 새 이미지를 받으려면 수정한 프롬프트를 새 메시지로 보내세요.",
+		EditFailed:         "⚠️ 답변을 생성하지 못해 수정 내용이 적용되지 않았습니다. 다시 시도하려면 메시지를 다시 수정하세요.",
+
+		// Fork
+		ForkUsage:       "🌿 대화를 분기하려면 대화의 메시지 중 하나에 /fork로 답장하세요. 해당 메시지부터 새 대화가 이어집니다.",
+		ForkUnavailable: "🌿 이 메시지에서는 분기할 수 없습니다. 내 대화의 메시지에 /fork로 답장하세요.",
+		ForkCreated:     "🌿 «%s» 분기됨: 메시지 %d개가 새 대화로 복사되었습니다. 원래 대화는 그대로 유지됩니다.",
+
+		// Export
+		ButtonExport:       "📤 내보내기",
+		ExportChooseFormat: "📤 «%s»을(를) 내보낼 형식을 선택하세요:",
+		ExportEmpty:        "📤 아직 내보낼 내용이 없습니다. 대화에 메시지가 없습니다.",
+		ExportUnavailable:  "이 대화는 내보낼 수 없습니다.",
+		ExportCaption:      "📤 «%s», 메시지 %d개. 첨부 파일 링크는 7일 동안 유효합니다.",
+
+		// Import
+		ImportInvalid:  "📥 이 파일은 가져올 수 없습니다. ChatGPT 데이터 내보내기의 conversations.json 파일을 보내세요.",
+		ImportEmpty:    "📥 이 파일에는 메시지가 있는 대화가 없습니다.",
+		ImportProgress: "📥 대화를 가져오는 중: %d / %d…",
+		ImportFinished: "✅ 대화 %d개(메시지 %d개)를 가져왔습니다. 아래에서 이어갈 대화를 선택하세요. 모든 대화는 대화 목록에도 있습니다.",
+		ImportSkipped:  "⚠️ 대화 %d개를 가져오지 못했습니다.",
+
+		// Search
+		SearchUsage:     "🔎 대화를 검색하려면 /search와 찾을 단어를 보내세요. 예: /search 파스타 레시피. 구문을 따옴표로 묶으면 정확히 일치하는 결과를 찾고, -단어를 추가하면 해당 단어가 있는 메시지를 제외합니다.",
+		SearchNoResults: "🔎 «%s»에 대한 결과가 없습니다. 다른 단어나 더 적은 단어로 시도해 보세요.",
+		SearchResults:   "🔎 «%s» 검색 결과:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 관리",
+		ButtonArchivedConversations: "🗄 보관됨",
+		ButtonOpen:                  "💬 열기",
+		ButtonRename:                "✏️ 이름 변경",
+		ButtonPin:                   "📌 고정",
+		ButtonUnpin:                 "📍 고정 해제",
+		ButtonArchive:               "🗄 보관",
+		ButtonUnarchive:             "📤 보관 해제",
+		ButtonDelete:                "🗑 삭제",
+		ButtonUndo:                  "↩️ 실행 취소",
+		ManageChooseConversation:    "🛠 이름을 변경하거나 고정, 보관, 삭제할 대화를 선택하세요:",
+		ManageArchivedList:          "🗄 보관된 대화입니다. 열거나 보관을 해제할 대화를 선택하세요:",
+		ManageNoArchived:            "🗄 보관된 대화가 없습니다.",
+		ManageConversation:          "🛠 «%s»\n\n이 대화로 무엇을 하시겠습니까?",
+		ManagePinned:                "📌 «%s»이(가) 목록 맨 위에 고정되었습니다.",
+		ManageUnpinned:              "📍 «%s»의 고정이 해제되었습니다.",
+		ManageArchived:              "🗄 «%s»이(가) 보관되었습니다. 🗄 보관됨에서 찾을 수 있습니다.",
+		ManageUnarchived:            "📤 «%s»이(가) 대화 목록으로 돌아왔습니다.",
+		ManageDeleted:               "🗑 «%s»이(가) 삭제되었습니다.",
+		ManageRestored:              "↩️ «%s»이(가) 복원되었습니다.",
+		ManageRenamePrompt:          "✏️ «%s»의 새 이름을 보내세요.",
+		ManageRenamed:               "✏️ 대화 이름이 «%s»(으)로 변경되었습니다.",
+		ManageNameInvalid:           "✏️ 이름은 1자에서 %d자 사이여야 합니다. 다른 이름을 보내세요.",
+
+		// Stop
+		StopGenerating: "⏳ 답변을 생성하는 중…",
+		ButtonStop:     "⏹ 중지",
+		StopRequested:  "⏹ 답변을 중지하는 중입니다. 지금까지 생성된 부분은 유지됩니다.",
+		StopNothing:    "지금 생성 중인 것이 없습니다.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d 토큰",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 사용량: 표시",
+		ButtonUsageHidden:     "🧮 사용량: 숨김",
+		UsageShown:            "🧮 이제 답변에 사용된 토큰과 비용이 표시됩니다.",
+		UsageHidden:           "🧮 이제 답변에 사용된 토큰과 비용이 표시되지 않습니다.",
+		ProfileUsageTitle:     "🧮 사용량:",
+		ProfileUsageAnswers:   "답변: %d",
+		ProfileUsageTokens:    "토큰: 입력 %d, 출력 %d",
+		ProfileUsageReasoning: "추론 토큰: %d",
+		ProfileUsageCost:      "비용: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s을(를) 지금 사용할 수 없어 %s이(가) 대신 답변합니다.",
+		VoiceNotSupported:        "❌ 지금은 음성 메시지를 지원하지 않습니다. 메시지를 입력해 주세요.",
+		VoiceTranscriptionFailed: "❌ 음성 메시지를 인식하지 못했습니다. 다시 시도하거나 메시지를 입력해 주세요.",
+		ButtonVoiceRepliesOn:     "🔊 음성 답변: 켜짐",
+		ButtonVoiceRepliesOff:    "🔇 음성 답변: 꺼짐",
+		VoiceRepliesOn:           "🔊 이제 답변이 음성 메시지로도 전송됩니다.",
+		VoiceRepliesOff:          "🔇 답변이 더 이상 음성 메시지로 전송되지 않습니다.",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s 파일을 읽을 수 없어 메시지에서 제외했습니다.",
+		DocumentTruncated:  "✂️ %s 파일이 너무 깁니다: 처음 %d자만 읽었습니다 (전체 %d자).",
 	},
 	"pt": {
 		// Buttons
@@ -1028,6 +2072,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Conversa retomada! Envie-me uma mensagem e eu responderei. Você sempre pode voltar ao menu.",
 		ConversationModePrompt:    "Você está no modo conversa. Envie uma mensagem para conversar, ou volte ao menu:",
 		ConversationNameGenerated: "nome da conversa gerado com sucesso",
+		ConversationUnavailable:   "Esta conversa já não está disponível.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Configurações. Escolha uma opção:",
@@ -1044,6 +2089,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Modelo atualizado com sucesso!",
 		ModelImageNotSupported: "❌ O modelo selecionado não suporta entradas de imagem. Por favor, escolha um modelo diferente ou envie uma mensagem de texto.",
 		ModelPDFNotSupported:   "❌ O modelo selecionado não suporta arquivos PDF. Por favor, escolha um modelo diferente ou envie uma mensagem de texto.",
+		ModelImageLimit:        "❌ %s aceita até %d imagens numa mensagem. Por favor envie menos imagens ou escolha um modelo diferente.",
+		ModelPDFLimit:          "❌ %s aceita até %d arquivos PDF numa mensagem. Por favor envie menos arquivos ou escolha um modelo diferente.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Sua mensagem foi colocada na fila (posição: %d). Vou processá-la após terminar a resposta atual.",
@@ -1092,14 +2139,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Rápido e eficiente para respostas ágeis)",
-		ModelGPT4o:         "🧠 GPT-4o (Mais capaz para tarefas complexas)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Velocidade e desempenho equilibrados)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Escrita criativa e análise)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Documentos longos e contexto)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Modelo de raciocínio avançado)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Pesquisa e programação)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Raciocínio profundo e lógica)",
+
+		// Persona
+		ButtonPersona:                  "🎭 Persona",
+		ButtonPersonaReset:             "♻️ Repor predefinição",
+		PersonaSelectTitle:             "🎭 O seu prompt de sistema predefinido:\n\n%s\n\nEscolha uma persona predefinida ou envie o seu próprio prompt de sistema como mensagem:",
+		PersonaConversationSelectTitle: "🎭 Prompt de sistema desta conversa:\n\n%s\n\nEscolha uma persona predefinida, envie o seu próprio prompt de sistema como mensagem ou reponha-o para usar o predefinido:",
+		PersonaUpdated:                 "✅ Prompt de sistema atualizado!",
+		PersonaResetDone:               "✅ Prompt de sistema reposto para o predefinido.",
+		PersonaTooLong:                 "❌ O prompt de sistema é demasiado longo (máximo de %d caracteres).",
+		PersonaAssistant:               "🤝 Assistente",
+		PersonaProgrammer:              "💻 Programador",
+		PersonaTranslator:              "🌍 Tradutor",
+		PersonaEditor:                  "✍️ Editor",
+		PersonaTeacher:                 "🎓 Professor",
+		PersonaConcise:                 "⚡ Conciso",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Regenerar",
+		ButtonRegenerateOtherModel: "🤖 Outro modelo",
+		ButtonBack:                 "🔙 Voltar",
+		RegenerateBusy:             "⏳ Aguarde até que a resposta atual termine",
+		RegenerateUnavailable:      "Esta resposta já não pode ser regenerada",
+		RegenerateModelUnavailable: "Este modelo não está disponível para si",
+		RegenerateStarted:          "🔄 A regenerar...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Mensagem editada. A conversa continua agora a partir dela, %d mensagens posteriores foram removidas do histórico.",
+		EditBusy:           "⏳ A resposta não pode ser atualizada enquanto outra está a ser gerada. Edite a mensagem novamente quando terminar.",
+		EditImagesAnswer:   "🎨 As respostas com imagens não podem ser atualizadas. Envie o prompt alterado como uma nova mensagem para obter novas imagens.",
+		EditFailed:         "⚠️ Não foi possível gerar a resposta, por isso a edição não foi aplicada. Edite a mensagem novamente para tentar de novo.",
+
+		// Fork
+		ForkUsage:       "🌿 Para ramificar uma conversa, responda a uma das suas mensagens com /fork. Uma nova conversa continuará a partir dessa mensagem.",
+		ForkUnavailable: "🌿 Não é possível ramificar a partir desta mensagem. Responda com /fork a uma mensagem de uma das suas conversas.",
+		ForkCreated:     "🌿 «%s» ramificada: %d mensagens foram copiadas para uma nova conversa. A conversa original mantém-se inalterada.",
+
+		// Export
+		ButtonExport:       "📤 Exportar",
+		ExportChooseFormat: "📤 Escolha um formato para exportar «%s»:",
+		ExportEmpty:        "📤 Ainda não há nada para exportar, a conversa não tem mensagens.",
+		ExportUnavailable:  "Esta conversa não pode ser exportada.",
+		ExportCaption:      "📤 «%s», mensagens: %d. As ligações dos anexos são válidas durante 7 dias.",
+
+		// Import
+		ImportInvalid:  "📥 Este ficheiro não pode ser importado. Envie o ficheiro conversations.json de uma exportação de dados do ChatGPT.",
+		ImportEmpty:    "📥 Não há conversas com mensagens neste ficheiro.",
+		ImportProgress: "📥 A importar conversas: %d de %d…",
+		ImportFinished: "✅ Foram importadas %d conversas com %d mensagens. Escolha uma abaixo para a continuar, todas estão também na lista de conversas.",
+		ImportSkipped:  "⚠️ Não foi possível importar %d conversas.",
+
+		// Search
+		SearchUsage:     "🔎 Para pesquisar nas suas conversas, envie /search com as palavras a procurar, p. ex. /search receita de massa. Coloque uma frase entre aspas para a encontrar exatamente e adicione -palavra para ignorar mensagens com essa palavra.",
+		SearchNoResults: "🔎 Nada foi encontrado para «%s». Experimente outras palavras ou menos palavras.",
+		SearchResults:   "🔎 Melhores resultados para «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Gerir",
+		ButtonArchivedConversations: "🗄 Arquivadas",
+		ButtonOpen:                  "💬 Abrir",
+		ButtonRename:                "✏️ Mudar o nome",
+		ButtonPin:                   "📌 Fixar",
+		ButtonUnpin:                 "📍 Desafixar",
+		ButtonArchive:               "🗄 Arquivar",
+		ButtonUnarchive:             "📤 Desarquivar",
+		ButtonDelete:                "🗑 Eliminar",
+		ButtonUndo:                  "↩️ Anular",
+		ManageChooseConversation:    "🛠 Escolha uma conversa para mudar o nome, fixar, arquivar ou eliminar:",
+		ManageArchivedList:          "🗄 Conversas arquivadas. Escolha uma para a abrir ou desarquivar:",
+		ManageNoArchived:            "🗄 Não há conversas arquivadas.",
+		ManageConversation:          "🛠 «%s»\n\nO que quer fazer com esta conversa?",
+		ManagePinned:                "📌 «%s» está fixada no topo da lista.",
+		ManageUnpinned:              "📍 «%s» já não está fixada.",
+		ManageArchived:              "🗄 «%s» está arquivada. Pode encontrá-la em 🗄 Arquivadas.",
+		ManageUnarchived:            "📤 «%s» está de volta à lista de conversas.",
+		ManageDeleted:               "🗑 «%s» foi eliminada.",
+		ManageRestored:              "↩️ «%s» foi restaurada.",
+		ManageRenamePrompt:          "✏️ Envie um novo nome para «%s».",
+		ManageRenamed:               "✏️ A conversa passou a chamar-se «%s».",
+		ManageNameInvalid:           "✏️ O nome deve ter de 1 a %d caracteres. Envie outro.",
+
+		// Stop
+		StopGenerating: "⏳ A gerar a resposta…",
+		ButtonStop:     "⏹ Parar",
+		StopRequested:  "⏹ A parar a resposta. A parte gerada até agora é mantida.",
+		StopNothing:    "Nada está a ser gerado neste momento.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d tokens",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Consumo: visível",
+		ButtonUsageHidden:     "🧮 Consumo: oculto",
+		UsageShown:            "🧮 As respostas passam a mostrar os tokens e o custo que consumiram.",
+		UsageHidden:           "🧮 As respostas deixam de mostrar os tokens e o custo que consumiram.",
+		ProfileUsageTitle:     "🧮 Consumo:",
+		ProfileUsageAnswers:   "Respostas: %d",
+		ProfileUsageTokens:    "Tokens: %d de entrada, %d de saída",
+		ProfileUsageReasoning: "Tokens de raciocínio: %d",
+		ProfileUsageCost:      "Custo: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s está indisponível agora, %s responde no lugar.",
+		VoiceNotSupported:        "❌ Mensagens de voz não são suportadas no momento. Por favor digite sua mensagem.",
+		VoiceTranscriptionFailed: "❌ Não foi possível reconhecer a mensagem de voz. Por favor tente novamente ou digite sua mensagem.",
+		ButtonVoiceRepliesOn:     "🔊 Respostas por voz: ativadas",
+		ButtonVoiceRepliesOff:    "🔇 Respostas por voz: desativadas",
+		VoiceRepliesOn:           "🔊 As respostas agora também são enviadas como mensagens de voz.",
+		VoiceRepliesOff:          "🔇 As respostas não são mais enviadas como mensagens de voz.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Não foi possível ler %s, então ele foi deixado de fora da mensagem.",
+		DocumentTruncated:  "✂️ %s é muito longo: apenas os primeiros %d de %d caracteres foram lidos.",
 	},
 	"hy": {
 		// Buttons
@@ -1123,6 +2273,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Խոսակցությունը շարունակվեց: Ուղարկեք ինձ հաղորդագրություն և ես կպատասխանեմ: Դուք միշտ կարող եք վերադառնալ մենյու:",
 		ConversationModePrompt:    "Դուք խոսակցության ռեժիմում եք: Ուղարկեք հաղորդագրություն՝ չատ անելու համար, կամ վերադառնալ մենյու:",
 		ConversationNameGenerated: "խոսակցության անունը հաջողությամբ ստեղծվեց",
+		ConversationUnavailable:   "Այս խոսակցությունն այլևս հասանելի չէ։",
 
 		// Settings
 		SettingsTitle:         "⚙️ Կարգավորումներ: Ընտրեք տարբերակը:",
@@ -1139,6 +2290,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Մոդելը հաջողությամբ թարմացվեց:",
 		ModelImageNotSupported: "❌ Ընտրված մոդելը չի աջակցում պատկերների մուտքագրմանը: Խնդրում ենք ընտրել այլ մոդել կամ ուղարկել տեքստային հաղորդագրություն:",
 		ModelPDFNotSupported:   "❌ Ընտրված մոդելը չի աջակցում PDF ֆայլերին: Խնդրում ենք ընտրել այլ մոդել կամ ուղարկել տեքստային հաղորդագրություն:",
+		ModelImageLimit:        "❌ %s-ը մեկ հաղորդագրությունում ընդունում է մինչև %d պատկեր։ Խնդրում ենք ուղարկել ավելի քիչ պատկերներ կամ ընտրել այլ մոդել։",
+		ModelPDFLimit:          "❌ %s-ը մեկ հաղորդագրությունում ընդունում է մինչև %d PDF ֆայլ։ Խնդրում ենք ուղարկել ավելի քիչ ֆայլեր կամ ընտրել այլ մոդել։",
 
 		// Queue
 		QueueMessageQueued: "⏳ Ձեր հաղորդագրությունը հերթի մեջ է (դիրքը՝ %d): Ես կմշակեմ այն ընթացիկ պատասխանն ավարտելուց հետո:",
@@ -1184,14 +2337,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Արագ և արդյունավետ պատասխանների համար)",
-		ModelGPT4o:         "🧠 GPT-4o (Լավագույնը բարդ խնդիրների համար)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Հավասարակշռված արագություն և արդյունավետություն)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Ստեղծագործական գրություն և վերլուծություն)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Երկար փաստաթղթեր և համատեքստ)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Առաջադեմ տրամաբանության մոդել)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Հետազոտություն և ծրագրավորում)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Խորը տրամաբանություն և տրամաբանություն)",
+
+		// Persona
+		ButtonPersona:                  "🎭 Պերսոնա",
+		ButtonPersonaReset:             "♻️ Վերականգնել",
+		PersonaSelectTitle:             "🎭 Ձեր լռելյայն համակարգային հուշումը՝\n\n%s\n\nԸնտրեք պատրաստի պերսոնա կամ ուղարկեք ձեր սեփական համակարգային հուշումը հաղորդագրությամբ՝",
+		PersonaConversationSelectTitle: "🎭 Այս խոսակցության համակարգային հուշումը՝\n\n%s\n\nԸնտրեք պատրաստի պերսոնա, ուղարկեք ձեր սեփական համակարգային հուշումը հաղորդագրությամբ կամ վերականգնեք այն՝ լռելյայնն օգտագործելու համար՝",
+		PersonaUpdated:                 "✅ Համակարգային հուշումը թարմացվեց։",
+		PersonaResetDone:               "✅ Համակարգային հուշումը վերականգնվեց լռելյայնին։",
+		PersonaTooLong:                 "❌ Համակարգային հուշումը չափազանց երկար է (առավելագույնը %d նիշ)։",
+		PersonaAssistant:               "🤝 Օգնական",
+		PersonaProgrammer:              "💻 Ծրագրավորող",
+		PersonaTranslator:              "🌍 Թարգմանիչ",
+		PersonaEditor:                  "✍️ Խմբագիր",
+		PersonaTeacher:                 "🎓 Ուսուցիչ",
+		PersonaConcise:                 "⚡ Հակիրճ",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Վերագեներացնել",
+		ButtonRegenerateOtherModel: "🤖 Այլ մոդել",
+		ButtonBack:                 "🔙 Հետ",
+		RegenerateBusy:             "⏳ Սպասեք, մինչև ընթացիկ պատասխանն ավարտվի",
+		RegenerateUnavailable:      "Այս պատասխանն այլևս հնարավոր չէ վերագեներացնել",
+		RegenerateModelUnavailable: "Այս մոդելը ձեզ հասանելի չէ",
+		RegenerateStarted:          "🔄 Վերագեներացվում է...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Հաղորդագրությունը խմբագրվեց։ Խոսակցությունն այժմ շարունակվում է դրանից, հետագա %d հաղորդագրությունները հեռացվեցին պատմությունից։",
+		EditBusy:           "⏳ Պատասխանը հնարավոր չէ թարմացնել, քանի դեռ մեկ այլ պատասխան է գեներացվում։ Խմբագրեք հաղորդագրությունը կրկին, երբ այն ավարտվի։",
+		EditImagesAnswer:   "🎨 Պատկերներով պատասխանները հնարավոր չէ թարմացնել։ Նոր պատկերներ ստանալու համար ուղարկեք փոփոխված հարցումը որպես նոր հաղորդագրություն։",
+		EditFailed:         "⚠️ Պատասխանը հնարավոր չեղավ գեներացնել, ուստի խմբագրումը չկիրառվեց։ Կրկին փորձելու համար խմբագրեք հաղորդագրությունը ևս մեկ անգամ։",
+
+		// Fork
+		ForkUsage:       "🌿 Խոսակցությունը ճյուղավորելու համար պատասխանեք դրա հաղորդագրություններից մեկին /fork հրամանով։ Նոր խոսակցությունը կշարունակվի այդ հաղորդագրությունից։",
+		ForkUnavailable: "🌿 Այս հաղորդագրությունից հնարավոր չէ ճյուղավորել։ Պատասխանեք /fork հրամանով ձեր խոսակցություններից մեկի հաղորդագրությանը։",
+		ForkCreated:     "🌿 «%s»-ը ճյուղավորվեց․ %d հաղորդագրություն պատճենվեց նոր խոսակցության մեջ։ Սկզբնական խոսակցությունը մնում է անփոփոխ։",
+
+		// Export
+		ButtonExport:       "📤 Արտահանել",
+		ExportChooseFormat: "📤 Ընտրեք «%s»-ի արտահանման ձևաչափը՝",
+		ExportEmpty:        "📤 Դեռ արտահանելու բան չկա, խոսակցությունում հաղորդագրություններ չկան։",
+		ExportUnavailable:  "Այս խոսակցությունը հնարավոր չէ արտահանել։",
+		ExportCaption:      "📤 «%s», հաղորդագրություններ՝ %d։ Կցորդների հղումները վավեր են 7 օր։",
+
+		// Import
+		ImportInvalid:  "📥 Այս ֆայլը հնարավոր չէ ներմուծել։ Ուղարկեք ChatGPT-ի տվյալների արտահանման conversations.json ֆայլը։",
+		ImportEmpty:    "📥 Այս ֆայլում հաղորդագրություններով խոսակցություններ չկան։",
+		ImportProgress: "📥 Խոսակցությունների ներմուծում՝ %d / %d…",
+		ImportFinished: "✅ Ներմուծվեց %d խոսակցություն՝ %d հաղորդագրությամբ։ Ընտրեք մեկը ստորև՝ այն շարունակելու համար, բոլորը նաև խոսակցությունների ցանկում են։",
+		ImportSkipped:  "⚠️ %d խոսակցություն հնարավոր չեղավ ներմուծել։",
+
+		// Search
+		SearchUsage:     "🔎 Ձեր խոսակցություններում որոնելու համար ուղարկեք /search և որոնվող բառերը, օրինակ՝ /search մակարոնի բաղադրատոմս։ Արտահայտությունը վերցրեք չակերտների մեջ՝ այն ճշգրիտ գտնելու համար, և ավելացրեք -բառ՝ այդ բառով հաղորդագրությունները բաց թողնելու համար։",
+		SearchNoResults: "🔎 «%s»-ի համար ոչինչ չգտնվեց։ Փորձեք այլ կամ ավելի քիչ բառեր։",
+		SearchResults:   "🔎 Լավագույն արդյունքները «%s»-ի համար՝",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Կառավարել",
+		ButtonArchivedConversations: "🗄 Արխիվացված",
+		ButtonOpen:                  "💬 Բացել",
+		ButtonRename:                "✏️ Վերանվանել",
+		ButtonPin:                   "📌 Ամրացնել",
+		ButtonUnpin:                 "📍 Ապամրացնել",
+		ButtonArchive:               "🗄 Արխիվացնել",
+		ButtonUnarchive:             "📤 Հանել արխիվից",
+		ButtonDelete:                "🗑 Ջնջել",
+		ButtonUndo:                  "↩️ Հետարկել",
+		ManageChooseConversation:    "🛠 Ընտրեք խոսակցություն՝ վերանվանելու, ամրացնելու, արխիվացնելու կամ ջնջելու համար՝",
+		ManageArchivedList:          "🗄 Արխիվացված խոսակցություններ։ Ընտրեք մեկը՝ այն բացելու կամ արխիվից հանելու համար՝",
+		ManageNoArchived:            "🗄 Արխիվացված խոսակցություններ չկան։",
+		ManageConversation:          "🛠 «%s»\n\nԻ՞նչ եք ուզում անել այս խոսակցության հետ։",
+		ManagePinned:                "📌 «%s»-ն ամրացված է ցանկի վերևում։",
+		ManageUnpinned:              "📍 «%s»-ն ապամրացված է։",
+		ManageArchived:              "🗄 «%s»-ն արխիվացված է։ Այն կարող եք գտնել 🗄 Արխիվացված բաժնում։",
+		ManageUnarchived:            "📤 «%s»-ն կրկին խոսակցությունների ցանկում է։",
+		ManageDeleted:               "🗑 «%s»-ն ջնջված է։",
+		ManageRestored:              "↩️ «%s»-ն վերականգնված է։",
+		ManageRenamePrompt:          "✏️ Ուղարկեք «%s»-ի նոր անունը։",
+		ManageRenamed:               "✏️ Խոսակցությունը վերանվանվեց «%s»։",
+		ManageNameInvalid:           "✏️ Անունը պետք է լինի 1-ից %d նիշ։ Ուղարկեք մեկ այլ անուն։",
+
+		// Stop
+		StopGenerating: "⏳ Պատասխանը գեներացվում է…",
+		ButtonStop:     "⏹ Կանգնեցնել",
+		StopRequested:  "⏹ Պատասխանը կանգնեցվում է։ Մինչ այժմ գեներացված մասը պահպանվում է։",
+		StopNothing:    "Այս պահին ոչինչ չի գեներացվում։",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d թոքեն",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Ծախս՝ ցուցադրված",
+		ButtonUsageHidden:     "🧮 Ծախս՝ թաքցված",
+		UsageShown:            "🧮 Պատասխաններն այժմ ցույց են տալիս իրենց ծախսած թոքեններն ու արժեքը։",
+		UsageHidden:           "🧮 Պատասխաններն այլևս ցույց չեն տալիս իրենց ծախսած թոքեններն ու արժեքը։",
+		ProfileUsageTitle:     "🧮 Ծախս՝",
+		ProfileUsageAnswers:   "Պատասխաններ՝ %d",
+		ProfileUsageTokens:    "Թոքեններ՝ %d մուտք, %d ելք",
+		ProfileUsageReasoning: "Դատողության թոքեններ՝ %d",
+		ProfileUsageCost:      "Արժեք՝ $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s-ը հիմա հասանելի չէ, փոխարենը պատասխանում է %s-ը։",
+		VoiceNotSupported:        "❌ Ձայնային հաղորդագրությունները հիմա չեն աջակցվում։ Խնդրում ենք գրել ձեր հաղորդագրությունը։",
+		VoiceTranscriptionFailed: "❌ Չհաջողվեց ճանաչել ձայնային հաղորդագրությունը։ Խնդրում ենք կրկին փորձել կամ գրել ձեր հաղորդագրությունը։",
+		ButtonVoiceRepliesOn:     "🔊 Ձայնային պատասխաններ՝ միացված",
+		ButtonVoiceRepliesOff:    "🔇 Ձայնային պատասխաններ՝ անջատված",
+		VoiceRepliesOn:           "🔊 Պատասխաններն այժմ ուղարկվում են նաև ձայնային հաղորդագրություններով։",
+		VoiceRepliesOff:          "🔇 Պատասխաններն այլևս չեն ուղարկվում ձայնային հաղորդագրություններով։",
+
+		// Document
+		DocumentUnreadable: "⚠️ Չհաջողվեց կարդալ %s-ը, ուստի այն չներառվեց հաղորդագրության մեջ։",
+		DocumentTruncated:  "✂️ %s-ը չափազանց երկար է․ կարդացվել են միայն առաջին %d նիշերը %d-ից։",
 	},
 	"uk": {
 		// Buttons
@@ -1215,6 +2471,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Розмову відновлено! Надішліть мені повідомлення, і я відповім. Ви завжди можете повернутися до меню.",
 		ConversationModePrompt:    "Ви в режимі розмови. Надішліть повідомлення для чату або поверніться до меню:",
 		ConversationNameGenerated: "назву розмови успішно створено",
+		ConversationUnavailable:   "Ця розмова більше недоступна.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Налаштування. Оберіть опцію:",
@@ -1231,6 +2488,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Модель успішно оновлено!",
 		ModelImageNotSupported: "❌ Обрана модель не підтримує зображення. Будь ласка, оберіть іншу модель або надішліть текстове повідомлення.",
 		ModelPDFNotSupported:   "❌ Обрана модель не підтримує PDF файли. Будь ласка, оберіть іншу модель або надішліть текстове повідомлення.",
+		ModelImageLimit:        "❌ %s приймає не більше %d зображень в одному повідомленні. Будь ласка, надішліть менше зображень або виберіть іншу модель.",
+		ModelPDFLimit:          "❌ %s приймає не більше %d PDF файлів в одному повідомленні. Будь ласка, надішліть менше файлів або виберіть іншу модель.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Ваше повідомлення поставлено в чергу (позиція: %d). Я оброблю його після завершення поточної відповіді.",
@@ -1276,14 +2535,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Швидкий та ефективний для швидких відповідей)",
-		ModelGPT4o:         "🧠 GPT-4o (Найкращий для складних завдань)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Збалансована швидкість і продуктивність)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Творче письмо та аналіз)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Довгі документи та контекст)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Модель розширеного міркування)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Дослідження та програмування)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Глибоке міркування та логіка)",
+
+		// Persona
+		ButtonPersona:                  "🎭 Персона",
+		ButtonPersonaReset:             "♻️ Скинути",
+		PersonaSelectTitle:             "🎭 Ваш системний промпт за замовчуванням:\n\n%s\n\nОберіть готову персону або надішліть свій системний промпт повідомленням:",
+		PersonaConversationSelectTitle: "🎭 Системний промпт цієї розмови:\n\n%s\n\nОберіть готову персону, надішліть свій системний промпт повідомленням або скиньте його, щоб використовувати промпт за замовчуванням:",
+		PersonaUpdated:                 "✅ Системний промпт оновлено!",
+		PersonaResetDone:               "✅ Системний промпт скинуто.",
+		PersonaTooLong:                 "❌ Системний промпт задовгий (максимум %d символів).",
+		PersonaAssistant:               "🤝 Асистент",
+		PersonaProgrammer:              "💻 Програміст",
+		PersonaTranslator:              "🌍 Перекладач",
+		PersonaEditor:                  "✍️ Редактор",
+		PersonaTeacher:                 "🎓 Вчитель",
+		PersonaConcise:                 "⚡ Стисло",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Згенерувати знову",
+		ButtonRegenerateOtherModel: "🤖 Інша модель",
+		ButtonBack:                 "🔙 Назад",
+		RegenerateBusy:             "⏳ Зачекайте, доки завершиться поточна відповідь",
+		RegenerateUnavailable:      "Цю відповідь більше не можна згенерувати знову",
+		RegenerateModelUnavailable: "Ця модель вам недоступна",
+		RegenerateStarted:          "🔄 Генерую знову...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Повідомлення змінено. Розмова тепер продовжується з нього, %d наступних повідомлень видалено з історії.",
+		EditBusy:           "⏳ Відповідь не можна оновити, поки генерується інша. Відредагуйте повідомлення ще раз, коли генерацію буде завершено.",
+		EditImagesAnswer:   "🎨 Відповіді із зображеннями не можна оновити. Надішліть змінений запит новим повідомленням, щоб отримати нові зображення.",
+		EditFailed:         "⚠️ Не вдалося згенерувати відповідь, тому редагування не застосовано. Відредагуйте повідомлення ще раз, щоб повторити спробу.",
+
+		// Fork
+		ForkUsage:       "🌿 Щоб створити відгалуження розмови, дайте відповідь на одне з її повідомлень командою /fork. Нова розмова продовжиться з цього повідомлення.",
+		ForkUnavailable: "🌿 Від цього повідомлення не можна створити відгалуження. Дайте відповідь командою /fork на повідомлення однієї з ваших розмов.",
+		ForkCreated:     "🌿 Створено відгалуження «%s»: %d повідомлень скопійовано в нову розмову. Початкова розмова не змінилася.",
+
+		// Export
+		ButtonExport:       "📤 Експорт",
+		ExportChooseFormat: "📤 Оберіть формат для експорту «%s»:",
+		ExportEmpty:        "📤 Експортувати поки нічого, у розмові немає повідомлень.",
+		ExportUnavailable:  "Цю розмову не можна експортувати.",
+		ExportCaption:      "📤 «%s», повідомлень: %d. Посилання на вкладення дійсні 7 днів.",
+
+		// Import
+		ImportInvalid:  "📥 Цей файл не можна імпортувати. Надішліть файл conversations.json з експорту даних ChatGPT.",
+		ImportEmpty:    "📥 У цьому файлі немає розмов із повідомленнями.",
+		ImportProgress: "📥 Імпорт розмов: %d з %d…",
+		ImportFinished: "✅ Імпортовано розмов: %d, повідомлень: %d. Оберіть розмову нижче, щоб продовжити її, усі вони також є у списку розмов.",
+		ImportSkipped:  "⚠️ Не вдалося імпортувати розмов: %d.",
+
+		// Search
+		SearchUsage:     "🔎 Щоб знайти повідомлення у ваших розмовах, надішліть /search і слова для пошуку, наприклад /search рецепт пасти. Візьміть фразу в лапки, щоб знайти її повністю, і додайте -слово, щоб виключити повідомлення з цим словом.",
+		SearchNoResults: "🔎 За запитом «%s» нічого не знайдено. Спробуйте інші слова або менше слів.",
+		SearchResults:   "🔎 Найкращі збіги для «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Керувати",
+		ButtonArchivedConversations: "🗄 Архів",
+		ButtonOpen:                  "💬 Відкрити",
+		ButtonRename:                "✏️ Перейменувати",
+		ButtonPin:                   "📌 Закріпити",
+		ButtonUnpin:                 "📍 Відкріпити",
+		ButtonArchive:               "🗄 В архів",
+		ButtonUnarchive:             "📤 З архіву",
+		ButtonDelete:                "🗑 Видалити",
+		ButtonUndo:                  "↩️ Скасувати",
+		ManageChooseConversation:    "🛠 Оберіть розмову, щоб перейменувати, закріпити, архівувати або видалити її:",
+		ManageArchivedList:          "🗄 Архівні розмови. Оберіть розмову, щоб відкрити її або повернути з архіву:",
+		ManageNoArchived:            "🗄 Архівних розмов немає.",
+		ManageConversation:          "🛠 «%s»\n\nЩо ви хочете зробити з цією розмовою?",
+		ManagePinned:                "📌 «%s» закріплено вгорі списку.",
+		ManageUnpinned:              "📍 «%s» відкріплено.",
+		ManageArchived:              "🗄 «%s» перенесено в архів. Її можна знайти в розділі 🗄 Архів.",
+		ManageUnarchived:            "📤 «%s» знову у списку розмов.",
+		ManageDeleted:               "🗑 «%s» видалено.",
+		ManageRestored:              "↩️ «%s» відновлено.",
+		ManageRenamePrompt:          "✏️ Надішліть нову назву для «%s».",
+		ManageRenamed:               "✏️ Розмову перейменовано на «%s».",
+		ManageNameInvalid:           "✏️ Назва має містити від 1 до %d символів. Надішліть іншу.",
+
+		// Stop
+		StopGenerating: "⏳ Генерую відповідь…",
+		ButtonStop:     "⏹ Зупинити",
+		StopRequested:  "⏹ Зупиняю відповідь. Уже згенерована частина зберігається.",
+		StopNothing:    "Зараз нічого не генерується.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d токенів",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Витрати: показано",
+		ButtonUsageHidden:     "🧮 Витрати: приховано",
+		UsageShown:            "🧮 Тепер під відповідями видно, скільки токенів і грошей вони забрали.",
+		UsageHidden:           "🧮 Під відповідями більше не видно, скільки токенів і грошей вони забрали.",
+		ProfileUsageTitle:     "🧮 Витрати:",
+		ProfileUsageAnswers:   "Відповідей: %d",
+		ProfileUsageTokens:    "Токени: %d на вході, %d на виході",
+		ProfileUsageReasoning: "Токени міркувань: %d",
+		ProfileUsageCost:      "Вартість: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s зараз недоступна, замість неї відповідає %s.",
+		VoiceNotSupported:        "❌ Голосові повідомлення зараз не підтримуються. Будь ласка, напишіть повідомлення текстом.",
+		VoiceTranscriptionFailed: "❌ Не вдалося розпізнати голосове повідомлення. Будь ласка, спробуйте ще раз або напишіть текстом.",
+		ButtonVoiceRepliesOn:     "🔊 Голосові відповіді: увімк",
+		ButtonVoiceRepliesOff:    "🔇 Голосові відповіді: вимк",
+		VoiceRepliesOn:           "🔊 Тепер відповіді також надходять голосовими повідомленнями.",
+		VoiceRepliesOff:          "🔇 Відповіді більше не надходять голосовими повідомленнями.",
+
+		// Document
+		DocumentUnreadable: "⚠️ Не вдалося прочитати %s, тому його не додано до повідомлення.",
+		DocumentTruncated:  "✂️ %s задовгий: прочитано лише перші %d з %d символів.",
 	},
 	"kk": {
 		// Buttons
@@ -1307,6 +2669,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Сөйлесу жалғасты! Маған хабарлама жіберіңіз, мен жауап беремін. Сіз әрқашан мәзірге орала аласыз.",
 		ConversationModePrompt:    "Сіз сөйлесу режимінде жүрсіз. Чат үшін хабарлама жіберіңіз немесе мәзірге оралыңыз:",
 		ConversationNameGenerated: "сөйлесу атауы сәтті құрылды",
+		ConversationUnavailable:   "Бұл сөйлесу енді қолжетімсіз.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Баптаулар. Опцияны таңдаңыз:",
@@ -1323,6 +2686,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Модель сәтті жаңартылды!",
 		ModelImageNotSupported: "❌ Таңдалған модель кескіндерді қолдамайды. Басқа модель таңдаңыз немесе мәтіндік хабарлама жіберіңіз.",
 		ModelPDFNotSupported:   "❌ Таңдалған модель PDF файлдарын қолдамайды. Басқа модель таңдаңыз немесе мәтіндік хабарлама жіберіңіз.",
+		ModelImageLimit:        "❌ %s бір хабарламада %d суреттен аспайтын қабылдайды. Азырақ сурет жіберіңіз немесе басқа модельді таңдаңыз.",
+		ModelPDFLimit:          "❌ %s бір хабарламада %d PDF файлдан аспайтын қабылдайды. Азырақ файл жіберіңіз немесе басқа модельді таңдаңыз.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Сіздің хабарламаңыз кезекке қойылды (орын: %d). Мен оны ағымдағы жауапты аяқтағаннан кейін өңдеймін.",
@@ -1368,14 +2733,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Жылдам жауаптар үшін тез және тиімді)",
-		ModelGPT4o:         "🧠 GPT-4o (Күрделі тапсырмалар үшін ең жақсы)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Теңдестірілген жылдамдық және өнімділік)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Шығармашылық жазу және талдау)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Ұзақ құжаттар және мәнмәтін)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Жетілдірілген ойлау моделі)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Зерттеу және бағдарламалау)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Терең ойлау және логика)",
+
+		// Persona
+		ButtonPersona:                  "🎭 Персона",
+		ButtonPersonaReset:             "♻️ Қалпына келтіру",
+		PersonaSelectTitle:             "🎭 Әдепкі жүйелік промптыңыз:\n\n%s\n\nДайын персонаны таңдаңыз немесе өз жүйелік промптыңызды хабарлама ретінде жіберіңіз:",
+		PersonaConversationSelectTitle: "🎭 Осы сөйлесудің жүйелік промпты:\n\n%s\n\nДайын персонаны таңдаңыз, өз жүйелік промптыңызды хабарлама ретінде жіберіңіз немесе әдепкі промптты пайдалану үшін оны қалпына келтіріңіз:",
+		PersonaUpdated:                 "✅ Жүйелік промпт жаңартылды!",
+		PersonaResetDone:               "✅ Жүйелік промпт әдепкіге қайтарылды.",
+		PersonaTooLong:                 "❌ Жүйелік промпт тым ұзын (ең көбі %d таңба).",
+		PersonaAssistant:               "🤝 Көмекші",
+		PersonaProgrammer:              "💻 Бағдарламашы",
+		PersonaTranslator:              "🌍 Аудармашы",
+		PersonaEditor:                  "✍️ Редактор",
+		PersonaTeacher:                 "🎓 Мұғалім",
+		PersonaConcise:                 "⚡ Қысқа",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Қайта жасау",
+		ButtonRegenerateOtherModel: "🤖 Басқа модель",
+		ButtonBack:                 "🔙 Артқа",
+		RegenerateBusy:             "⏳ Ағымдағы жауап аяқталғанша күтіңіз",
+		RegenerateUnavailable:      "Бұл жауапты енді қайта жасау мүмкін емес",
+		RegenerateModelUnavailable: "Бұл модель сізге қолжетімсіз",
+		RegenerateStarted:          "🔄 Қайта жасалуда...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Хабарлама өңделді. Сөйлесу енді осы хабарламадан жалғасады, кейінгі %d хабарлама тарихтан жойылды.",
+		EditBusy:           "⏳ Басқа жауап жасалып жатқанда жауапты жаңарту мүмкін емес. Ол аяқталғанда хабарламаны қайта өңдеңіз.",
+		EditImagesAnswer:   "🎨 Суреттері бар жауаптарды жаңарту мүмкін емес. Жаңа суреттер алу үшін өзгертілген сұрауды жаңа хабарлама ретінде жіберіңіз.",
+		EditFailed:         "⚠️ Жауапты жасау мүмкін болмады, сондықтан өңдеу қолданылмады. Қайталап көру үшін хабарламаны қайта өңдеңіз.",
+
+		// Fork
+		ForkUsage:       "🌿 Сөйлесуді тармақтау үшін оның хабарламаларының біріне /fork командасымен жауап беріңіз. Жаңа сөйлесу сол хабарламадан жалғасады.",
+		ForkUnavailable: "🌿 Бұл хабарламадан тармақ жасау мүмкін емес. Өз сөйлесулеріңіздің біріндегі хабарламаға /fork командасымен жауап беріңіз.",
+		ForkCreated:     "🌿 «%s» тармақталды: %d хабарлама жаңа сөйлесуге көшірілді. Бастапқы сөйлесу өзгеріссіз қалды.",
+
+		// Export
+		ButtonExport:       "📤 Экспорт",
+		ExportChooseFormat: "📤 «%s» экспорттау пішімін таңдаңыз:",
+		ExportEmpty:        "📤 Әзірге экспорттайтын ештеңе жоқ, сөйлесуде хабарламалар жоқ.",
+		ExportUnavailable:  "Бұл сөйлесуді экспорттау мүмкін емес.",
+		ExportCaption:      "📤 «%s», хабарламалар: %d. Тіркемелер сілтемелері 7 күн жарамды.",
+
+		// Import
+		ImportInvalid:  "📥 Бұл файлды импорттау мүмкін емес. ChatGPT деректер экспортындағы conversations.json файлын жіберіңіз.",
+		ImportEmpty:    "📥 Бұл файлда хабарламалары бар сөйлесулер жоқ.",
+		ImportProgress: "📥 Сөйлесулер импортталуда: %d / %d…",
+		ImportFinished: "✅ Импортталған сөйлесулер: %d, хабарламалар: %d. Жалғастыру үшін төменнен біреуін таңдаңыз, олардың барлығы сөйлесулер тізімінде де бар.",
+		ImportSkipped:  "⚠️ Импорттау мүмкін болмаған сөйлесулер: %d.",
+
+		// Search
+		SearchUsage:     "🔎 Сөйлесулеріңізден іздеу үшін /search және ізделетін сөздерді жіберіңіз, мысалы /search паста рецепті. Тіркесті дәл табу үшін оны тырнақшаға алыңыз, ал сол сөзі бар хабарламаларды өткізіп жіберу үшін -сөз қосыңыз.",
+		SearchNoResults: "🔎 «%s» бойынша ештеңе табылмады. Басқа немесе азырақ сөздерді қолданып көріңіз.",
+		SearchResults:   "🔎 «%s» бойынша ең жақсы сәйкестіктер:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Басқару",
+		ButtonArchivedConversations: "🗄 Мұрағат",
+		ButtonOpen:                  "💬 Ашу",
+		ButtonRename:                "✏️ Атын өзгерту",
+		ButtonPin:                   "📌 Бекіту",
+		ButtonUnpin:                 "📍 Босату",
+		ButtonArchive:               "🗄 Мұрағаттау",
+		ButtonUnarchive:             "📤 Мұрағаттан шығару",
+		ButtonDelete:                "🗑 Жою",
+		ButtonUndo:                  "↩️ Болдырмау",
+		ManageChooseConversation:    "🛠 Атын өзгерту, бекіту, мұрағаттау немесе жою үшін сөйлесуді таңдаңыз:",
+		ManageArchivedList:          "🗄 Мұрағатталған сөйлесулер. Ашу немесе мұрағаттан шығару үшін біреуін таңдаңыз:",
+		ManageNoArchived:            "🗄 Мұрағатталған сөйлесулер жоқ.",
+		ManageConversation:          "🛠 «%s»\n\nБұл сөйлесумен не істегіңіз келеді?",
+		ManagePinned:                "📌 «%s» тізімнің басына бекітілді.",
+		ManageUnpinned:              "📍 «%s» босатылды.",
+		ManageArchived:              "🗄 «%s» мұрағатталды. Оны 🗄 Мұрағат бөлімінен таба аласыз.",
+		ManageUnarchived:            "📤 «%s» қайтадан сөйлесулер тізімінде.",
+		ManageDeleted:               "🗑 «%s» жойылды.",
+		ManageRestored:              "↩️ «%s» қалпына келтірілді.",
+		ManageRenamePrompt:          "✏️ «%s» үшін жаңа атау жіберіңіз.",
+		ManageRenamed:               "✏️ Сөйлесудің жаңа атауы: «%s».",
+		ManageNameInvalid:           "✏️ Атау 1-ден %d таңбаға дейін болуы керек. Басқасын жіберіңіз.",
+
+		// Stop
+		StopGenerating: "⏳ Жауап жасалуда…",
+		ButtonStop:     "⏹ Тоқтату",
+		StopRequested:  "⏹ Жауап тоқтатылуда. Осы уақытқа дейін жасалған бөлігі сақталады.",
+		StopNothing:    "Қазір ештеңе жасалып жатқан жоқ.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d токен",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Шығын: көрсетілген",
+		ButtonUsageHidden:     "🧮 Шығын: жасырылған",
+		UsageShown:            "🧮 Енді жауаптар жұмсалған токендер мен құнды көрсетеді.",
+		UsageHidden:           "🧮 Жауаптар енді жұмсалған токендер мен құнды көрсетпейді.",
+		ProfileUsageTitle:     "🧮 Шығын:",
+		ProfileUsageAnswers:   "Жауаптар: %d",
+		ProfileUsageTokens:    "Токендер: кіріс %d, шығыс %d",
+		ProfileUsageReasoning: "Ойлау токендері: %d",
+		ProfileUsageCost:      "Құны: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s қазір қолжетімсіз, оның орнына %s жауап береді.",
+		VoiceNotSupported:        "❌ Дауыстық хабарламалар қазір қолдау көрсетілмейді. Хабарламаңызды жазып жіберіңіз.",
+		VoiceTranscriptionFailed: "❌ Дауыстық хабарламаны тану мүмкін болмады. Қайталап көріңіз немесе хабарламаңызды жазып жіберіңіз.",
+		ButtonVoiceRepliesOn:     "🔊 Дауыстық жауаптар: қосулы",
+		ButtonVoiceRepliesOff:    "🔇 Дауыстық жауаптар: өшірулі",
+		VoiceRepliesOn:           "🔊 Енді жауаптар дауыстық хабарлама ретінде де жіберіледі.",
+		VoiceRepliesOff:          "🔇 Жауаптар енді дауыстық хабарлама ретінде жіберілмейді.",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s оқылмады, сондықтан ол хабарламаға қосылмады.",
+		DocumentTruncated:  "✂️ %[1]s тым ұзын: %[3]d таңбаның тек алғашқы %[2]d таңбасы оқылды.",
 	},
 	"ky": {
 		// Buttons
@@ -1399,6 +2867,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ Маек улантылды! Мага билдирүү жөнөтүңүз, мен жооп берем. Сиз дайыма менюга кайта аласыз.",
 		ConversationModePrompt:    "Сиз маек режиминдесиз. Сүйлөшүү үчүн билдирүү жөнөтүңүз же менюга кайтыңыз:",
 		ConversationNameGenerated: "маек аталышы ийгиликтүү түзүлдү",
+		ConversationUnavailable:   "Бул баарлашуу мындан ары жеткиликсиз.",
 
 		// Settings
 		SettingsTitle:         "⚙️ Жөндөөлөр. Опцияны тандаңыз:",
@@ -1415,6 +2884,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ Модель ийгиликтүү жаңыртылды!",
 		ModelImageNotSupported: "❌ Тандалган модель сүрөттөрдү колдобойт. Башка модель тандаңыз же текст билдирүү жөнөтүңүз.",
 		ModelPDFNotSupported:   "❌ Тандалган модель PDF файлдарды колдобойт. Башка модель тандаңыз же текст билдирүү жөнөтүңүз.",
+		ModelImageLimit:        "❌ %s бир билдирүүдө %d сүрөттөн ашпаганды кабыл алат. Азыраак сүрөт жөнөтүңүз же башка моделди тандаңыз.",
+		ModelPDFLimit:          "❌ %s бир билдирүүдө %d PDF файлдан ашпаганды кабыл алат. Азыраак файл жөнөтүңүз же башка моделди тандаңыз.",
 
 		// Queue
 		QueueMessageQueued: "⏳ Сиздин билдирүүңүз кезекке коюлду (орун: %d). Мен аны учурдагы жоопту бүткөндөн кийин иштетем.",
@@ -1460,14 +2931,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (Тез жана натыйжалуу жооптор үчүн)",
-		ModelGPT4o:         "🧠 GPT-4o (Татаал тапшырмалар үчүн эң мыкты)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (Теңдештирилген ылдамдык жана аткаруу)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (Чыгармачыл жазуу жана анализ)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (Узун документтер жана контекст)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (Өркүндөтүлгөн ой жүгүртүү модели)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (Изилдөө жана программалоо)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (Терең ой жүгүртүү жана логика)",
+
+		// Persona
+		ButtonPersona:                  "🎭 Персона",
+		ButtonPersonaReset:             "♻️ Баштапкыга кайтаруу",
+		PersonaSelectTitle:             "🎭 Демейки системалык промптуңуз:\n\n%s\n\nДаяр персонаны тандаңыз же өз системалык промптуңузду билдирүү катары жөнөтүңүз:",
+		PersonaConversationSelectTitle: "🎭 Бул баарлашуунун системалык промпту:\n\n%s\n\nДаяр персонаны тандаңыз, өз системалык промптуңузду билдирүү катары жөнөтүңүз же демейки промптту колдонуу үчүн аны баштапкыга кайтарыңыз:",
+		PersonaUpdated:                 "✅ Системалык промпт жаңыртылды!",
+		PersonaResetDone:               "✅ Системалык промпт демейкиге кайтарылды.",
+		PersonaTooLong:                 "❌ Системалык промпт өтө узун (эң көп %d белги).",
+		PersonaAssistant:               "🤝 Жардамчы",
+		PersonaProgrammer:              "💻 Программист",
+		PersonaTranslator:              "🌍 Котормочу",
+		PersonaEditor:                  "✍️ Редактор",
+		PersonaTeacher:                 "🎓 Мугалим",
+		PersonaConcise:                 "⚡ Кыска",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 Кайра түзүү",
+		ButtonRegenerateOtherModel: "🤖 Башка модель",
+		ButtonBack:                 "🔙 Артка",
+		RegenerateBusy:             "⏳ Учурдагы жооп бүткөнчө күтө туруңуз",
+		RegenerateUnavailable:      "Бул жоопту мындан ары кайра түзүүгө болбойт",
+		RegenerateModelUnavailable: "Бул модель сиз үчүн жеткиликсиз",
+		RegenerateStarted:          "🔄 Кайра түзүлүүдө...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ Билдирүү түзөтүлдү. Баарлашуу эми ушул билдирүүдөн уланат, кийинки %d билдирүү тарыхтан өчүрүлдү.",
+		EditBusy:           "⏳ Башка жооп түзүлүп жатканда жоопту жаңыртууга болбойт. Ал бүткөндө билдирүүнү кайра түзөтүңүз.",
+		EditImagesAnswer:   "🎨 Сүрөттөрү бар жоопторду жаңыртууга болбойт. Жаңы сүрөттөрдү алуу үчүн өзгөртүлгөн сурамды жаңы билдирүү катары жөнөтүңүз.",
+		EditFailed:         "⚠️ Жоопту түзүү мүмкүн болбоду, ошондуктан түзөтүү колдонулган жок. Кайра аракет кылуу үчүн билдирүүнү кайра түзөтүңүз.",
+
+		// Fork
+		ForkUsage:       "🌿 Баарлашууну бутактоо үчүн анын билдирүүлөрүнүн бирине /fork буйругу менен жооп бериңиз. Жаңы баарлашуу ошол билдирүүдөн уланат.",
+		ForkUnavailable: "🌿 Бул билдирүүдөн бутак түзүүгө болбойт. Өз баарлашууларыңыздын бириндеги билдирүүгө /fork буйругу менен жооп бериңиз.",
+		ForkCreated:     "🌿 «%s» бутакталды: %d билдирүү жаңы баарлашууга көчүрүлдү. Баштапкы баарлашуу өзгөрүүсүз калды.",
+
+		// Export
+		ButtonExport:       "📤 Экспорт",
+		ExportChooseFormat: "📤 «%s» экспорттоо форматын тандаңыз:",
+		ExportEmpty:        "📤 Азырынча экспорттой турган эч нерсе жок, баарлашууда билдирүүлөр жок.",
+		ExportUnavailable:  "Бул баарлашууну экспорттоого болбойт.",
+		ExportCaption:      "📤 «%s», билдирүүлөр: %d. Тиркемелердин шилтемелери 7 күн жарактуу.",
+
+		// Import
+		ImportInvalid:  "📥 Бул файлды импорттоого болбойт. ChatGPT маалымат экспортундагы conversations.json файлын жөнөтүңүз.",
+		ImportEmpty:    "📥 Бул файлда билдирүүлөрү бар баарлашуулар жок.",
+		ImportProgress: "📥 Баарлашуулар импорттолууда: %d / %d…",
+		ImportFinished: "✅ Импорттолгон баарлашуулар: %d, билдирүүлөр: %d. Улантуу үчүн төмөндөн бирин тандаңыз, алардын баары баарлашуулар тизмесинде да бар.",
+		ImportSkipped:  "⚠️ Импорттолбой калган баарлашуулар: %d.",
+
+		// Search
+		SearchUsage:     "🔎 Баарлашууларыңыздан издөө үчүн /search жана изделүүчү сөздөрдү жөнөтүңүз, мисалы /search паста рецеби. Сөз айкашын так табуу үчүн аны тырмакчага алыңыз, ал эми ошол сөзү бар билдирүүлөрдү өткөрүп жиберүү үчүн -сөз кошуңуз.",
+		SearchNoResults: "🔎 «%s» боюнча эч нерсе табылган жок. Башка же азыраак сөздөрдү колдонуп көрүңүз.",
+		SearchResults:   "🔎 «%s» боюнча эң жакшы дал келүүлөр:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 Башкаруу",
+		ButtonArchivedConversations: "🗄 Архив",
+		ButtonOpen:                  "💬 Ачуу",
+		ButtonRename:                "✏️ Атын өзгөртүү",
+		ButtonPin:                   "📌 Бекитүү",
+		ButtonUnpin:                 "📍 Бошотуу",
+		ButtonArchive:               "🗄 Архивдөө",
+		ButtonUnarchive:             "📤 Архивден чыгаруу",
+		ButtonDelete:                "🗑 Өчүрүү",
+		ButtonUndo:                  "↩️ Жокко чыгаруу",
+		ManageChooseConversation:    "🛠 Атын өзгөртүү, бекитүү, архивдөө же өчүрүү үчүн баарлашууну тандаңыз:",
+		ManageArchivedList:          "🗄 Архивделген баарлашуулар. Ачуу же архивден чыгаруу үчүн бирин тандаңыз:",
+		ManageNoArchived:            "🗄 Архивделген баарлашуулар жок.",
+		ManageConversation:          "🛠 «%s»\n\nБул баарлашуу менен эмне кылгыңыз келет?",
+		ManagePinned:                "📌 «%s» тизменин башына бекитилди.",
+		ManageUnpinned:              "📍 «%s» бошотулду.",
+		ManageArchived:              "🗄 «%s» архивделди. Аны 🗄 Архив бөлүмүнөн таба аласыз.",
+		ManageUnarchived:            "📤 «%s» кайрадан баарлашуулар тизмесинде.",
+		ManageDeleted:               "🗑 «%s» өчүрүлдү.",
+		ManageRestored:              "↩️ «%s» калыбына келтирилди.",
+		ManageRenamePrompt:          "✏️ «%s» үчүн жаңы ат жөнөтүңүз.",
+		ManageRenamed:               "✏️ Баарлашуунун жаңы аты: «%s».",
+		ManageNameInvalid:           "✏️ Аты 1ден %d белгиге чейин болушу керек. Башкасын жөнөтүңүз.",
+
+		// Stop
+		StopGenerating: "⏳ Жооп түзүлүүдө…",
+		ButtonStop:     "⏹ Токтотуу",
+		StopRequested:  "⏹ Жооп токтотулууда. Ушул убакытка чейин түзүлгөн бөлүгү сакталат.",
+		StopNothing:    "Азыр эч нерсе түзүлүп жаткан жок.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d токен",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 Чыгым: көрсөтүлгөн",
+		ButtonUsageHidden:     "🧮 Чыгым: жашырылган",
+		UsageShown:            "🧮 Эми жооптор короткон токендерди жана баасын көрсөтөт.",
+		UsageHidden:           "🧮 Жооптор мындан ары короткон токендерди жана баасын көрсөтпөйт.",
+		ProfileUsageTitle:     "🧮 Чыгым:",
+		ProfileUsageAnswers:   "Жооптор: %d",
+		ProfileUsageTokens:    "Токендер: кириш %d, чыгыш %d",
+		ProfileUsageReasoning: "Ой жүгүртүү токендери: %d",
+		ProfileUsageCost:      "Баасы: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s азыр жеткиликсиз, анын ордуна %s жооп берет.",
+		VoiceNotSupported:        "❌ Үн билдирүүлөр азыр колдоого алынбайт. Билдирүүңүздү жазып жөнөтүңүз.",
+		VoiceTranscriptionFailed: "❌ Үн билдирүүнү таануу мүмкүн болгон жок. Кайра аракет кылыңыз же билдирүүңүздү жазып жөнөтүңүз.",
+		ButtonVoiceRepliesOn:     "🔊 Үн жооптор: күйүк",
+		ButtonVoiceRepliesOff:    "🔇 Үн жооптор: өчүк",
+		VoiceRepliesOn:           "🔊 Эми жооптор үн билдирүү катары да жөнөтүлөт.",
+		VoiceRepliesOff:          "🔇 Жооптор мындан ары үн билдирүү катары жөнөтүлбөйт.",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s окулган жок, ошондуктан ал билдирүүгө кошулган жок.",
+		DocumentTruncated:  "✂️ %[1]s өтө узун: %[3]d белгинин алгачкы %[2]d белгиси гана окулду.",
 	},
 	"ar": {
 		// Buttons
@@ -1491,6 +3065,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ استُأنِفت المحادثة! أرسل لي رسالة وسأرد عليك. يمكنك العودة للقائمة في أي وقت.",
 		ConversationModePrompt:    "أنت في وضع المحادثة. أرسل رسالة للدردشة أو ارجع للقائمة:",
 		ConversationNameGenerated: "تم إنشاء اسم المحادثة بنجاح",
+		ConversationUnavailable:   "لم تعد هذه المحادثة متاحة.",
 
 		// Settings
 		SettingsTitle:         "⚙️ الإعدادات. اختر خياراً:",
@@ -1507,6 +3082,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ تم تحديث النموذج بنجاح!",
 		ModelImageNotSupported: "❌ النموذج المحدد لا يدعم مدخلات الصور. يرجى اختيار نموذج مختلف أو إرسال رسالة نصية.",
 		ModelPDFNotSupported:   "❌ النموذج المحدد لا يدعم ملفات PDF. يرجى اختيار نموذج مختلف أو إرسال رسالة نصية.",
+		ModelImageLimit:        "❌ يقبل %s حتى %d صور في الرسالة الواحدة. يرجى إرسال صور أقل أو اختيار نموذج مختلف.",
+		ModelPDFLimit:          "❌ يقبل %s حتى %d ملفات PDF في الرسالة الواحدة. يرجى إرسال ملفات أقل أو اختيار نموذج مختلف.",
 
 		// Queue
 		QueueMessageQueued: "⏳ تم وضع رسالتك في طابور الانتظار (الموضع: %d). سأقوم بمعالجتها بعد إنهاء الرد الحالي.",
@@ -1552,14 +3129,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (سريع وفعال للإجابات السريعة)",
-		ModelGPT4o:         "🧠 GPT-4o (الأكثر قدرة للمهام المعقدة)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (سرعة وأداء متوازنان)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (الكتابة الإبداعية والتحليل)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (مستندات طويلة وسياق)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (نموذج تفكير متقدم)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (البحث والبرمجة)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (التفكير العميق والمنطق)",
+
+		// Persona
+		ButtonPersona:                  "🎭 الشخصية",
+		ButtonPersonaReset:             "♻️ إعادة التعيين",
+		PersonaSelectTitle:             "🎭 موجّه النظام الافتراضي الخاص بك:\n\n%s\n\nاختر شخصية جاهزة أو أرسل موجّه النظام الخاص بك كرسالة:",
+		PersonaConversationSelectTitle: "🎭 موجّه النظام لهذه المحادثة:\n\n%s\n\nاختر شخصية جاهزة، أو أرسل موجّه النظام الخاص بك كرسالة، أو أعد تعيينه لاستخدام الموجّه الافتراضي:",
+		PersonaUpdated:                 "✅ تم تحديث موجّه النظام!",
+		PersonaResetDone:               "✅ تمت إعادة موجّه النظام إلى الافتراضي.",
+		PersonaTooLong:                 "❌ موجّه النظام طويل جدًا (الحد الأقصى %d حرف).",
+		PersonaAssistant:               "🤝 مساعد",
+		PersonaProgrammer:              "💻 مبرمج",
+		PersonaTranslator:              "🌍 مترجم",
+		PersonaEditor:                  "✍️ محرر",
+		PersonaTeacher:                 "🎓 معلم",
+		PersonaConcise:                 "⚡ موجز",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 إعادة التوليد",
+		ButtonRegenerateOtherModel: "🤖 نموذج آخر",
+		ButtonBack:                 "🔙 رجوع",
+		RegenerateBusy:             "⏳ يرجى الانتظار حتى تنتهي الإجابة الحالية",
+		RegenerateUnavailable:      "لم يعد من الممكن إعادة توليد هذه الإجابة",
+		RegenerateModelUnavailable: "هذا النموذج غير متاح لك",
+		RegenerateStarted:          "🔄 جارٍ إعادة التوليد...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ تم تعديل الرسالة. تستمر المحادثة الآن منها، وتمت إزالة %d من الرسائل اللاحقة من سجلها.",
+		EditBusy:           "⏳ لا يمكن تحديث الإجابة أثناء توليد إجابة أخرى. عدّل الرسالة مرة أخرى عند انتهائها.",
+		EditImagesAnswer:   "🎨 لا يمكن تحديث الإجابات التي تحتوي على صور. أرسل الطلب المعدّل كرسالة جديدة للحصول على صور جديدة.",
+		EditFailed:         "⚠️ تعذّر توليد الإجابة، لذلك لم يُطبَّق التعديل. عدّل الرسالة مرة أخرى لإعادة المحاولة.",
+
+		// Fork
+		ForkUsage:       "🌿 لتفريع محادثة، رُدّ على إحدى رسائلها بالأمر /fork. ستستمر محادثة جديدة من تلك الرسالة.",
+		ForkUnavailable: "🌿 لا يمكن التفريع من هذه الرسالة. رُدّ بالأمر /fork على رسالة من إحدى محادثاتك.",
+		ForkCreated:     "🌿 تم تفريع «%s»: تم نسخ %d رسالة إلى محادثة جديدة. تبقى المحادثة الأصلية دون تغيير.",
+
+		// Export
+		ButtonExport:       "📤 تصدير",
+		ExportChooseFormat: "📤 اختر صيغة لتصدير «%s»:",
+		ExportEmpty:        "📤 لا يوجد شيء لتصديره بعد، المحادثة لا تحتوي على رسائل.",
+		ExportUnavailable:  "لا يمكن تصدير هذه المحادثة.",
+		ExportCaption:      "📤 «%s»، عدد الرسائل: %d. روابط المرفقات صالحة لمدة 7 أيام.",
+
+		// Import
+		ImportInvalid:  "📥 لا يمكن استيراد هذا الملف. أرسل ملف conversations.json من تصدير بيانات ChatGPT.",
+		ImportEmpty:    "📥 لا توجد في هذا الملف محادثات تحتوي على رسائل.",
+		ImportProgress: "📥 جارٍ استيراد المحادثات: %d من %d…",
+		ImportFinished: "✅ تم استيراد %d محادثة تحتوي على %d رسالة. اختر واحدة أدناه لمتابعتها، وجميعها موجودة أيضًا في قائمة المحادثات.",
+		ImportSkipped:  "⚠️ تعذّر استيراد %d محادثة.",
+
+		// Search
+		SearchUsage:     "🔎 للبحث في محادثاتك، أرسل /search مع الكلمات المطلوبة، مثلًا /search وصفة معكرونة. ضع عبارة بين علامتي اقتباس للعثور عليها كما هي، وأضف -كلمة لتجاهل الرسائل التي تحتوي على تلك الكلمة.",
+		SearchNoResults: "🔎 لم يُعثر على شيء لـ «%s». جرّب كلمات أخرى أو عددًا أقل منها.",
+		SearchResults:   "🔎 أفضل النتائج لـ «%s»:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 إدارة",
+		ButtonArchivedConversations: "🗄 المؤرشفة",
+		ButtonOpen:                  "💬 فتح",
+		ButtonRename:                "✏️ إعادة التسمية",
+		ButtonPin:                   "📌 تثبيت",
+		ButtonUnpin:                 "📍 إلغاء التثبيت",
+		ButtonArchive:               "🗄 أرشفة",
+		ButtonUnarchive:             "📤 إلغاء الأرشفة",
+		ButtonDelete:                "🗑 حذف",
+		ButtonUndo:                  "↩️ تراجع",
+		ManageChooseConversation:    "🛠 اختر محادثة لإعادة تسميتها أو تثبيتها أو أرشفتها أو حذفها:",
+		ManageArchivedList:          "🗄 المحادثات المؤرشفة. اختر واحدة لفتحها أو إلغاء أرشفتها:",
+		ManageNoArchived:            "🗄 لا توجد محادثات مؤرشفة.",
+		ManageConversation:          "🛠 «%s»\n\nماذا تريد أن تفعل بهذه المحادثة؟",
+		ManagePinned:                "📌 تم تثبيت «%s» في أعلى القائمة.",
+		ManageUnpinned:              "📍 تم إلغاء تثبيت «%s».",
+		ManageArchived:              "🗄 تمت أرشفة «%s». يمكنك العثور عليها في 🗄 المؤرشفة.",
+		ManageUnarchived:            "📤 عادت «%s» إلى قائمة المحادثات.",
+		ManageDeleted:               "🗑 تم حذف «%s».",
+		ManageRestored:              "↩️ تمت استعادة «%s».",
+		ManageRenamePrompt:          "✏️ أرسل اسمًا جديدًا لـ «%s».",
+		ManageRenamed:               "✏️ تمت إعادة تسمية المحادثة إلى «%s».",
+		ManageNameInvalid:           "✏️ يجب أن يتراوح طول الاسم بين 1 و%d حرفًا. أرسل اسمًا آخر.",
+
+		// Stop
+		StopGenerating: "⏳ جارٍ توليد الإجابة…",
+		ButtonStop:     "⏹ إيقاف",
+		StopRequested:  "⏹ جارٍ إيقاف الإجابة. يُحتفظ بالجزء الذي تم توليده حتى الآن.",
+		StopNothing:    "لا يتم توليد أي شيء الآن.",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d رمز",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 الاستهلاك: ظاهر",
+		ButtonUsageHidden:     "🧮 الاستهلاك: مخفي",
+		UsageShown:            "🧮 تعرض الإجابات الآن الرموز والتكلفة التي استهلكتها.",
+		UsageHidden:           "🧮 لم تعد الإجابات تعرض الرموز والتكلفة التي استهلكتها.",
+		ProfileUsageTitle:     "🧮 الاستهلاك:",
+		ProfileUsageAnswers:   "الإجابات: %d",
+		ProfileUsageTokens:    "الرموز: %d للإدخال، %d للإخراج",
+		ProfileUsageReasoning: "رموز الاستدلال: %d",
+		ProfileUsageCost:      "التكلفة: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s غير متاح حاليًا، يجيب %s بدلًا منه.",
+		VoiceNotSupported:        "❌ الرسائل الصوتية غير مدعومة حاليًا. يرجى كتابة رسالتك.",
+		VoiceTranscriptionFailed: "❌ تعذر التعرف على الرسالة الصوتية. يرجى المحاولة مرة أخرى أو كتابة رسالتك.",
+		ButtonVoiceRepliesOn:     "🔊 الردود الصوتية: مفعلة",
+		ButtonVoiceRepliesOff:    "🔇 الردود الصوتية: معطلة",
+		VoiceRepliesOn:           "🔊 تُرسل الإجابات الآن أيضًا كرسائل صوتية.",
+		VoiceRepliesOff:          "🔇 لم تعد الإجابات تُرسل كرسائل صوتية.",
+
+		// Document
+		DocumentUnreadable: "⚠️ تعذّرت قراءة %s، لذلك تم استبعاده من الرسالة.",
+		DocumentTruncated:  "✂️ %s طويل جدًا: تمت قراءة أول %d من أصل %d حرف فقط.",
 	},
 	"hi": {
 		// Buttons
@@ -1583,6 +3263,7 @@ var Strings = map[string]map[string]string{
 		ConversationResumed:       "🗣️ बातचीत फिर से शुरू! मुझे संदेश भेजें और मैं जवाब दूंगा। आप कभी भी मेनू में वापस जा सकते हैं।",
 		ConversationModePrompt:    "आप बातचीत मोड में हैं। चैट करने के लिए संदेश भेजें, या मेनू में वापस जाएं:",
 		ConversationNameGenerated: "बातचीत का नाम सफलतापूर्वक बनाया गया",
+		ConversationUnavailable:   "यह बातचीत अब उपलब्ध नहीं है।",
 
 		// Settings
 		SettingsTitle:         "⚙️ सेटिंग्स। एक विकल्प चुनें:",
@@ -1599,6 +3280,8 @@ var Strings = map[string]map[string]string{
 		ModelUpdateSuccess:     "✅ मॉडल सफलतापूर्वक अपडेट हो गया!",
 		ModelImageNotSupported: "❌ चयनित मॉडल छवि इनपुट का समर्थन नहीं करता है। कृपया एक अलग मॉडल चुनें या टेक्स्ट संदेश भेजें।",
 		ModelPDFNotSupported:   "❌ चयनित मॉडल PDF फाइलों का समर्थन नहीं करता है। कृपया एक अलग मॉडल चुनें या टेक्स्ट संदेश भेजें।",
+		ModelImageLimit:        "❌ %s एक संदेश में अधिकतम %d छवियाँ लेता है। कृपया कम छवियाँ भेजें या कोई अलग मॉडल चुनें।",
+		ModelPDFLimit:          "❌ %s एक संदेश में अधिकतम %d PDF फ़ाइलें लेता है। कृपया कम फ़ाइलें भेजें या कोई अलग मॉडल चुनें।",
 
 		// Queue
 		QueueMessageQueued: "⏳ आपका संदेश कतार में रखा गया है (स्थिति: %d)। मैं वर्तमान जवाब पूरा करने के बाد इसे प्रोसेस करूंगा।",
@@ -1644,14 +3327,117 @@ var Strings = map[string]map[string]string{
 		LangHindi:      "🇮🇳 हिन्दी",
 
 		// Model Names
-		ModelGeminiFlash:   "🚀 Gemini 2.5 Flash (त्वरित उत्तरों के लिए तेज़ और कुशल)",
-		ModelGPT4o:         "🧠 GPT-4o (जटिल कार्यों के लिए सबसे सक्षम)",
-		ModelGPT4oMini:     "⚡ GPT-4o Mini (संतुलित गति और प्रदर्शन)",
-		ModelClaude4Sonnet: "🎭 Claude 4 Sonnet (रचनात्मक लेखन और विश्लेषण)",
-		ModelGeminiPro:     "🌸 Gemini 2.5 Pro (लंबे दस्तावेज़ और संदर्भ)",
-		ModelO3Mini:        "🤖 OpenAI o3-mini (उन्नत तर्क मॉडल)",
-		ModelDeepSeekChat:  "🔬 DeepSeek Chat v3 (अनुसंधान और कोडिंग)",
-		ModelDeepSeekR1:    "🔍 DeepSeek R1 (गहन तर्क और तर्कशास्त्र)",
+
+		// Persona
+		ButtonPersona:                  "🎭 पर्सोना",
+		ButtonPersonaReset:             "♻️ डिफ़ॉल्ट पर रीसेट करें",
+		PersonaSelectTitle:             "🎭 आपका डिफ़ॉल्ट सिस्टम प्रॉम्प्ट:\n\n%s\n\nकोई तैयार पर्सोना चुनें या अपना सिस्टम प्रॉम्प्ट संदेश के रूप में भेजें:",
+		PersonaConversationSelectTitle: "🎭 इस बातचीत का सिस्टम प्रॉम्प्ट:\n\n%s\n\nकोई तैयार पर्सोना चुनें, अपना सिस्टम प्रॉम्प्ट संदेश के रूप में भेजें, या डिफ़ॉल्ट उपयोग करने के लिए इसे रीसेट करें:",
+		PersonaUpdated:                 "✅ सिस्टम प्रॉम्प्ट अपडेट हो गया!",
+		PersonaResetDone:               "✅ सिस्टम प्रॉम्प्ट डिफ़ॉल्ट पर रीसेट हो गया।",
+		PersonaTooLong:                 "❌ सिस्टम प्रॉम्प्ट बहुत लंबा है (अधिकतम %d अक्षर)।",
+		PersonaAssistant:               "🤝 सहायक",
+		PersonaProgrammer:              "💻 प्रोग्रामर",
+		PersonaTranslator:              "🌍 अनुवादक",
+		PersonaEditor:                  "✍️ संपादक",
+		PersonaTeacher:                 "🎓 शिक्षक",
+		PersonaConcise:                 "⚡ संक्षिप्त",
+
+		// Regenerate
+		ButtonRegenerate:           "🔄 फिर से बनाएँ",
+		ButtonRegenerateOtherModel: "🤖 दूसरा मॉडल",
+		ButtonBack:                 "🔙 वापस",
+		RegenerateBusy:             "⏳ कृपया मौजूदा उत्तर पूरा होने तक प्रतीक्षा करें",
+		RegenerateUnavailable:      "यह उत्तर अब फिर से नहीं बनाया जा सकता",
+		RegenerateModelUnavailable: "यह मॉडल आपके लिए उपलब्ध नहीं है",
+		RegenerateStarted:          "🔄 फिर से बनाया जा रहा है...",
+
+		// Edit
+		EditHistoryTrimmed: "✏️ संदेश संपादित हो गया। बातचीत अब इसी संदेश से आगे बढ़ेगी, बाद के %d संदेश इतिहास से हटा दिए गए।",
+		EditBusy:           "⏳ जब दूसरा उत्तर बन रहा हो तब उत्तर अपडेट नहीं किया जा सकता। उसके पूरा होने पर संदेश फिर से संपादित करें।",
+		EditImagesAnswer:   "🎨 छवियों वाले उत्तर अपडेट नहीं किए जा सकते। नई छवियाँ पाने के लिए बदला हुआ प्रॉम्प्ट नए संदेश के रूप में भेजें।",
+		EditFailed:         "⚠️ उत्तर नहीं बन सका, इसलिए संपादन लागू नहीं हुआ। फिर से कोशिश करने के लिए संदेश दोबारा संपादित करें।",
+
+		// Fork
+		ForkUsage:       "🌿 किसी बातचीत की शाखा बनाने के लिए उसके किसी संदेश का जवाब /fork से दें। उस संदेश से एक नई बातचीत आगे बढ़ेगी।",
+		ForkUnavailable: "🌿 इस संदेश से शाखा नहीं बनाई जा सकती। अपनी किसी बातचीत के संदेश का जवाब /fork से दें।",
+		ForkCreated:     "🌿 «%s» की शाखा बनाई गई: %d संदेश एक नई बातचीत में कॉपी किए गए। मूल बातचीत में कोई बदलाव नहीं हुआ।",
+
+		// Export
+		ButtonExport:       "📤 निर्यात",
+		ExportChooseFormat: "📤 «%s» निर्यात करने के लिए प्रारूप चुनें:",
+		ExportEmpty:        "📤 अभी निर्यात करने के लिए कुछ नहीं है, बातचीत में कोई संदेश नहीं है।",
+		ExportUnavailable:  "इस बातचीत को निर्यात नहीं किया जा सकता।",
+		ExportCaption:      "📤 «%s», संदेश: %d। अटैचमेंट के लिंक 7 दिनों तक मान्य हैं।",
+
+		// Import
+		ImportInvalid:  "📥 इस फ़ाइल को आयात नहीं किया जा सकता। ChatGPT डेटा निर्यात की conversations.json फ़ाइल भेजें।",
+		ImportEmpty:    "📥 इस फ़ाइल में संदेशों वाली कोई बातचीत नहीं है।",
+		ImportProgress: "📥 बातचीत आयात की जा रही हैं: %d / %d…",
+		ImportFinished: "✅ आयात की गई बातचीत: %d, संदेश: %d। जारी रखने के लिए नीचे से कोई एक चुनें, सभी बातचीत सूची में भी हैं।",
+		ImportSkipped:  "⚠️ %d बातचीत आयात नहीं की जा सकीं।",
+
+		// Search
+		SearchUsage:     "🔎 अपनी बातचीत में खोजने के लिए /search के साथ खोजे जाने वाले शब्द भेजें, जैसे /search पास्ता रेसिपी। किसी वाक्यांश को ठीक उसी रूप में खोजने के लिए उसे उद्धरण चिह्नों में रखें, और किसी शब्द वाले संदेशों को छोड़ने के लिए -शब्द जोड़ें।",
+		SearchNoResults: "🔎 «%s» के लिए कुछ नहीं मिला। दूसरे या कम शब्द आज़माएँ।",
+		SearchResults:   "🔎 «%s» के लिए सबसे अच्छे परिणाम:",
+
+		// Conversation management
+		ButtonManageConversations:   "🛠 प्रबंधित करें",
+		ButtonArchivedConversations: "🗄 संग्रहीत",
+		ButtonOpen:                  "💬 खोलें",
+		ButtonRename:                "✏️ नाम बदलें",
+		ButtonPin:                   "📌 पिन करें",
+		ButtonUnpin:                 "📍 अनपिन करें",
+		ButtonArchive:               "🗄 संग्रहित करें",
+		ButtonUnarchive:             "📤 संग्रह से निकालें",
+		ButtonDelete:                "🗑 हटाएँ",
+		ButtonUndo:                  "↩️ पूर्ववत करें",
+		ManageChooseConversation:    "🛠 नाम बदलने, पिन करने, संग्रहित करने या हटाने के लिए बातचीत चुनें:",
+		ManageArchivedList:          "🗄 संग्रहीत बातचीत। खोलने या संग्रह से निकालने के लिए कोई एक चुनें:",
+		ManageNoArchived:            "🗄 कोई संग्रहीत बातचीत नहीं है।",
+		ManageConversation:          "🛠 «%s»\n\nआप इस बातचीत के साथ क्या करना चाहते हैं?",
+		ManagePinned:                "📌 «%s» सूची में सबसे ऊपर पिन की गई है।",
+		ManageUnpinned:              "📍 «%s» अनपिन कर दी गई है।",
+		ManageArchived:              "🗄 «%s» संग्रहित कर दी गई है। आप इसे 🗄 संग्रहीत में पा सकते हैं।",
+		ManageUnarchived:            "📤 «%s» फिर से बातचीत सूची में है।",
+		ManageDeleted:               "🗑 «%s» हटा दी गई है।",
+		ManageRestored:              "↩️ «%s» बहाल कर दी गई है।",
+		ManageRenamePrompt:          "✏️ «%s» के लिए नया नाम भेजें।",
+		ManageRenamed:               "✏️ बातचीत का नाम बदलकर «%s» कर दिया गया है।",
+		ManageNameInvalid:           "✏️ नाम 1 से %d वर्णों का होना चाहिए। कोई दूसरा नाम भेजें।",
+
+		// Stop
+		StopGenerating: "⏳ उत्तर बनाया जा रहा है…",
+		ButtonStop:     "⏹ रोकें",
+		StopRequested:  "⏹ उत्तर रोका जा रहा है। अब तक बना हिस्सा रखा जाएगा।",
+		StopNothing:    "अभी कुछ भी नहीं बनाया जा रहा है।",
+
+		// Usage
+		UsageFooter:           "🧮 %d → %d टोकन",
+		UsageFooterReasoning:  " (🧠 %d)",
+		ButtonUsageShown:      "🧮 खपत: दिखाई दे रही है",
+		ButtonUsageHidden:     "🧮 खपत: छिपी हुई",
+		UsageShown:            "🧮 अब उत्तर दिखाते हैं कि उनमें कितने टोकन और कितनी लागत लगी।",
+		UsageHidden:           "🧮 उत्तर अब नहीं दिखाते कि उनमें कितने टोकन और कितनी लागत लगी।",
+		ProfileUsageTitle:     "🧮 खपत:",
+		ProfileUsageAnswers:   "उत्तर: %d",
+		ProfileUsageTokens:    "टोकन: %d इनपुट, %d आउटपुट",
+		ProfileUsageReasoning: "तर्क टोकन: %d",
+		ProfileUsageCost:      "लागत: $%s",
+
+		// Model fallback
+		ModelFallbackUsed:        "⚠️ %s अभी उपलब्ध नहीं है, उसकी जगह %s जवाब दे रहा है।",
+		VoiceNotSupported:        "❌ वॉइस संदेश अभी समर्थित नहीं हैं। कृपया अपना संदेश टाइप करें।",
+		VoiceTranscriptionFailed: "❌ वॉइस संदेश को पहचाना नहीं जा सका। कृपया फिर से कोशिश करें या अपना संदेश टाइप करें।",
+		ButtonVoiceRepliesOn:     "🔊 वॉइस जवाब: चालू",
+		ButtonVoiceRepliesOff:    "🔇 वॉइस जवाब: बंद",
+		VoiceRepliesOn:           "🔊 अब जवाब वॉइस संदेश के रूप में भी भेजे जाते हैं।",
+		VoiceRepliesOff:          "🔇 अब जवाब वॉइस संदेश के रूप में नहीं भेजे जाते।",
+
+		// Document
+		DocumentUnreadable: "⚠️ %s पढ़ा नहीं जा सका, इसलिए उसे संदेश से हटा दिया गया।",
+		DocumentTruncated:  "✂️ %[1]s बहुत लंबा है: %[3]d में से केवल पहले %[2]d अक्षर पढ़े गए।",
 	},
 }
 
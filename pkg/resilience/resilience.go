@@ -0,0 +1,303 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the endpoint keeps failing.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Metrics exposed with expvar, keyed by "client/host".
+var (
+	breakerStates = expvar.NewMap("circuit_breaker_state")
+	breakerOpens  = expvar.NewMap("circuit_breaker_opens")
+	retries       = expvar.NewMap("http_retries")
+)
+
+// Config configures the timeouts, retries and circuit breakers of a client.
+type Config struct {
+	Timeout          time.Duration // Limit of a whole request including the body, zero for streams
+	HeaderTimeout    time.Duration // Limit of waiting for the response headers, zero for none
+	MaxRetries       int           // Retries of an idempotent request after the first attempt
+	BaseDelay        time.Duration // Delay before the first retry, doubled for every next one
+	MaxDelay         time.Duration // Upper limit of a retry delay
+	RetryPost        bool          // Whether POST requests of the client are safe to repeat
+	FailureThreshold int           // Consecutive failures that open the breaker of an endpoint, zero disables it
+	OpenTimeout      time.Duration // How long an open breaker rejects requests before it lets one through
+}
+
+// DefaultConfig returns the settings for short API calls.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          30 * time.Second, //nolint:mnd // default
+		MaxRetries:       3,                //nolint:mnd // default
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         5 * time.Second,  //nolint:mnd // default
+		FailureThreshold: 5,                //nolint:mnd // default
+		OpenTimeout:      30 * time.Second, //nolint:mnd // default
+	}
+}
+
+// Client sends HTTP requests with timeouts, retries idempotent requests with jittered exponential backoff
+// and stops calling an endpoint for a while when it keeps failing. Endpoints are told apart by host.
+type Client struct {
+	name       string
+	logger     *slog.Logger
+	config     Config
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func NewClient(name string, logger *slog.Logger, config Config) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = config.HeaderTimeout
+
+	return &Client{
+		name:   name,
+		logger: logger,
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+		},
+		breakers: make(map[string]*breaker),
+	}
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type breaker struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// Do sends the request. A request that failed with a network error, 429 or a 5xx status is retried when
+// it is idempotent and its body can be replayed. The response of the last attempt is returned.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.Host
+	attempts := 1
+	if c.retryable(req) {
+		attempts += c.config.MaxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.allow(req.Context(), endpoint); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		// Requests the caller cancelled tell nothing about the endpoint
+		if err != nil && req.Context().Err() != nil {
+			c.abandon(endpoint)
+			return nil, err
+		}
+		failed := err != nil ||
+			resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		c.record(req.Context(), endpoint, failed)
+		if !failed || attempt >= attempts {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+
+		retries.Add(c.metricKey(endpoint), 1)
+		c.logger.WarnContext(req.Context(), "retrying HTTP request",
+			slog.String("client", c.name),
+			slog.String("endpoint", endpoint),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay))
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryable reports whether sending the request again can't do any harm.
+func (c *Client) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return c.config.RetryPost
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the next attempt: the Retry-After of the response when it has one,
+// otherwise an exponential delay with jitter, so that clients don't retry all at once.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.config.MaxDelay)
+		}
+	}
+
+	delay := min(c.config.BaseDelay<<(attempt-1), c.config.MaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1) //nolint:gosec // jitter doesn't need a secure source
+}
+
+// rewind returns a copy of the request with a fresh body for the next attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("can't replay request body: %w", err)
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, nil
+}
+
+// allow rejects the request while the breaker of the endpoint is open. Once the open timeout passes,
+// a single request is let through to find out whether the endpoint recovered.
+func (c *Client) allow(ctx context.Context, endpoint string) error {
+	if c.config.FailureThreshold <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.breaker(endpoint)
+	switch b.state {
+	case breakerClosed:
+		return nil
+	case breakerOpen:
+		if time.Since(b.openedAt) >= c.config.OpenTimeout {
+			c.transition(ctx, endpoint, b, breakerHalfOpen)
+			return nil
+		}
+	case breakerHalfOpen:
+	}
+
+	return fmt.Errorf("%s %s: %w", c.name, endpoint, ErrCircuitOpen)
+}
+
+// record updates the breaker of the endpoint with the outcome of an attempt.
+func (c *Client) record(ctx context.Context, endpoint string, failed bool) {
+	if c.config.FailureThreshold <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.breaker(endpoint)
+	if !failed {
+		b.failures = 0
+		c.transition(ctx, endpoint, b, breakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= c.config.FailureThreshold {
+		b.openedAt = time.Now()
+		c.transition(ctx, endpoint, b, breakerOpen)
+	}
+}
+
+// abandon lets another request probe the endpoint when the one that was let through got cancelled.
+// The breaker stays past its open timeout, so it isn't reported as opened again.
+func (c *Client) abandon(endpoint string) {
+	if c.config.FailureThreshold <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b := c.breaker(endpoint); b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		c.publish(endpoint, b)
+	}
+}
+
+func (c *Client) breaker(endpoint string) *breaker {
+	b, exists := c.breakers[endpoint]
+	if !exists {
+		b = &breaker{}
+		c.breakers[endpoint] = b
+		c.publish(endpoint, b)
+	}
+	return b
+}
+
+// transition changes the breaker state, logs and publishes it.
+func (c *Client) transition(ctx context.Context, endpoint string, b *breaker, state breakerState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	c.publish(endpoint, b)
+
+	attrs := []any{
+		slog.String("client", c.name),
+		slog.String("endpoint", endpoint),
+		slog.String("state", state.String()),
+	}
+	if state == breakerOpen {
+		breakerOpens.Add(c.metricKey(endpoint), 1)
+		c.logger.WarnContext(ctx, "circuit breaker opened", append(attrs, slog.Int("failures", b.failures))...)
+		return
+	}
+	c.logger.InfoContext(ctx, "circuit breaker state changed", attrs...)
+}
+
+func (c *Client) publish(endpoint string, b *breaker) {
+	state := new(expvar.String)
+	state.Set(b.state.String())
+	breakerStates.Set(c.metricKey(endpoint), state)
+}
+
+func (c *Client) metricKey(endpoint string) string {
+	return c.name + "/" + endpoint
+}
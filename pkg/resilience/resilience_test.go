@@ -0,0 +1,237 @@
+package resilience_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vladimish/talk/pkg/resilience"
+)
+
+func get(t *testing.T, client *resilience.Client, url string) (int, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := resilience.NewClient("test", slog.Default(), resilience.Config{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+	})
+
+	// Closed to open after the threshold of consecutive failures
+	failing.Store(true)
+	for range 2 {
+		status, err := get(t, client, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+	}
+	_, err := get(t, client, server.URL)
+	require.ErrorIs(t, err, resilience.ErrCircuitOpen)
+	assert.Equal(t, int32(2), hits.Load(), "an open breaker doesn't send the request")
+
+	// Half-open after the open timeout, a failed probe opens it again
+	time.Sleep(60 * time.Millisecond)
+	status, err := get(t, client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	_, err = get(t, client, server.URL)
+	require.ErrorIs(t, err, resilience.ErrCircuitOpen)
+	assert.Equal(t, int32(3), hits.Load())
+
+	// A successful probe closes it
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	for range 3 {
+		status, err = get(t, client, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	}
+	assert.Equal(t, int32(6), hits.Load())
+}
+
+func TestClient_CircuitBreaker_Endpoints(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer healthy.Close()
+
+	client := resilience.NewClient("test", slog.Default(), resilience.Config{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+	})
+
+	_, err := get(t, client, failing.URL)
+	require.NoError(t, err)
+	_, err = get(t, client, failing.URL)
+	require.ErrorIs(t, err, resilience.ErrCircuitOpen)
+
+	// Every endpoint has its own breaker
+	status, err := get(t, client, healthy.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		retryPost bool
+		// replayable is whether the body can be sent again
+		replayable     bool
+		statuses       []int
+		expectedHits   int32
+		expectedStatus int
+	}{
+		{
+			name:           "idempotent request is retried until it succeeds",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedHits:   3,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "too many requests is retried",
+			method:         http.MethodDelete,
+			statuses:       []int{http.StatusTooManyRequests, http.StatusOK},
+			expectedHits:   2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "retries stop at the limit",
+			method:         http.MethodGet,
+			statuses:       slices.Repeat([]int{http.StatusInternalServerError}, 6),
+			expectedHits:   4,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "client error isn't retried",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusBadRequest, http.StatusOK},
+			expectedHits:   1,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "POST isn't retried by default",
+			method:         http.MethodPost,
+			replayable:     true,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedHits:   1,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "POST of a client that allows it is retried with the same body",
+			method:         http.MethodPost,
+			retryPost:      true,
+			replayable:     true,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedHits:   2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "body that can't be replayed isn't retried",
+			method:         http.MethodPost,
+			retryPost:      true,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedHits:   1,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := hits.Add(1)
+				if r.Method == http.MethodPost {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					assert.Equal(t, "payload", string(body))
+				}
+				w.WriteHeader(tt.statuses[attempt-1])
+			}))
+			defer server.Close()
+
+			client := resilience.NewClient("test", slog.Default(), resilience.Config{
+				MaxRetries: 3,
+				BaseDelay:  time.Millisecond,
+				MaxDelay:   5 * time.Millisecond,
+				RetryPost:  tt.retryPost,
+			})
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader("payload")
+				if !tt.replayable {
+					body = io.NopCloser(body)
+				}
+			}
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, server.URL, body)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedHits, hits.Load())
+		})
+	}
+}
+
+func TestClient_CancelledContextStopsRetrying(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := resilience.NewClient("test", slog.Default(), resilience.Config{
+		MaxRetries: 5,
+		BaseDelay:  10 * time.Second,
+		MaxDelay:   10 * time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	started := time.Now()
+	_, err = client.Do(req)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), hits.Load())
+	assert.Less(t, time.Since(started), time.Second, "the backoff isn't waited out")
+}
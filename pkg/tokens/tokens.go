@@ -0,0 +1,40 @@
+package tokens
+
+import "unicode/utf8"
+
+const (
+	// asciiCharsPerToken is the average number of ASCII characters per token for BPE tokenizers.
+	asciiCharsPerToken = 4
+	// nonASCIICharsPerToken is a conservative average for Cyrillic, CJK and other scripts.
+	nonASCIICharsPerToken = 2
+	// messageOverhead accounts for the role and separators each chat message adds.
+	messageOverhead = 4
+)
+
+// Estimate returns an approximate token count for text.
+// It intentionally overestimates rather than underestimates, so budgets stay safe.
+func Estimate(text string) int {
+	if text == "" {
+		return 0
+	}
+
+	var ascii, other int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return ceilDiv(ascii, asciiCharsPerToken) + ceilDiv(other, nonASCIICharsPerToken)
+}
+
+// EstimateMessage returns an approximate token count for a single chat message including its overhead.
+func EstimateMessage(text string) int {
+	return Estimate(text) + messageOverhead
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
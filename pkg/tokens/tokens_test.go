@@ -0,0 +1,37 @@
+package tokens_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vladimish/talk/pkg/tokens"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "empty text", text: "", expected: 0},
+		{name: "short text takes a whole token", text: "Hi", expected: 1},
+		{name: "ascii text", text: "Where is the treasure?", expected: 6},
+		{name: "long ascii text", text: strings.Repeat("a", 100_000), expected: 25_000},
+		{name: "cyrillic text", text: "Привет", expected: 3},
+		{name: "cjk text", text: "你好世界", expected: 2},
+		{name: "mixed scripts are rounded up separately", text: "Hi, мир", expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tokens.Estimate(tt.text))
+		})
+	}
+}
+
+func TestEstimateMessage(t *testing.T) {
+	assert.Equal(t, 4, tokens.EstimateMessage(""))
+	assert.Equal(t, 10, tokens.EstimateMessage("Where is the treasure?"))
+}